@@ -0,0 +1,52 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// An Auditor receives a record of each API request which may change something on the server,
+// i.e. any request other than GET, HEAD, and OPTIONS. Requests stopped by a dry run are not audited.
+// An Auditor must be safe for concurrent use.
+type Auditor interface {
+	Audit(entry AuditEntry)
+}
+
+type AuditEntry struct {
+	Time   time.Time
+	Method string
+	URL    string
+	// A status code of the last attempt; zero if the request failed without a response
+	StatusCode int
+	Error      string
+	// A hex encoded SHA-256 of the request body; empty if there is no body
+	BodySha256 string
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func (a *Api) audit(started time.Time, method, url string, data []byte, res *http.Response, err error) {
+	if a.config.Auditor == nil || !isMutatingMethod(method) {
+		return
+	}
+	entry := AuditEntry{Time: started, Method: method, URL: url}
+	if len(data) > 0 {
+		sum := sha256.Sum256(data)
+		entry.BodySha256 = hex.EncodeToString(sum[:])
+	}
+	if res != nil {
+		entry.StatusCode = res.StatusCode
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	a.config.Auditor.Audit(entry)
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AuthProviderToken   = "token"
+	AuthProviderOAuth   = "oauth"
+	AuthProviderEnv     = "env"
+	AuthProviderCommand = "command"
+
+	// A token is sent in the OSF-TOKEN header (or the one set by the TOKEN_HEADER environment variable)
+	TokenTypeApiToken = "token"
+	// A token is sent as an OAuth2 bearer token in the Authorization header
+	TokenTypeBearer = "bearer"
+)
+
+// An AuthProvider sets credentials on each API request.
+type AuthProvider interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthConfig selects and configures an AuthProvider.
+// It maps to the "auth" section of the fioctl.yaml.
+type AuthConfig struct {
+	// One of: token, oauth, env, command. When empty, a static token is used if set, OAuth otherwise.
+	Provider string `mapstructure:"provider"`
+	// An environment variable holding a token for the "env" provider
+	Env string `mapstructure:"env"`
+	// A shell command printing a token to its standard output for the "command" provider
+	Command string `mapstructure:"command"`
+	// How to send a token obtained by the "env" and "command" providers: token (default) or bearer
+	TokenType string `mapstructure:"token_type"`
+}
+
+// NewAuthProvider creates an external AuthProvider (env or command) from its configuration.
+// Static token and OAuth providers need credentials, so they are created directly.
+func NewAuthProvider(cfg AuthConfig) (AuthProvider, error) {
+	switch cfg.TokenType {
+	case "", TokenTypeApiToken, TokenTypeBearer:
+	default:
+		return nil, fmt.Errorf("Invalid auth token type: %s", cfg.TokenType)
+	}
+	bearer := cfg.TokenType == TokenTypeBearer
+
+	switch cfg.Provider {
+	case AuthProviderEnv:
+		if len(cfg.Env) == 0 {
+			return nil, errors.New("The env auth provider requires the auth.env option")
+		}
+		return &EnvAuthProvider{Variable: cfg.Env, Bearer: bearer}, nil
+	case AuthProviderCommand:
+		if len(cfg.Command) == 0 {
+			return nil, errors.New("The command auth provider requires the auth.command option")
+		}
+		return &CommandAuthProvider{Command: cfg.Command, Bearer: bearer}, nil
+	default:
+		return nil, fmt.Errorf("Invalid auth provider: %s", cfg.Provider)
+	}
+}
+
+// TokenHeaderName returns a header which API tokens are sent in: OSF-TOKEN, or the one set by TOKEN_HEADER.
+func TokenHeaderName() string {
+	headerName := os.Getenv("TOKEN_HEADER")
+	if len(headerName) == 0 {
+		headerName = "OSF-TOKEN"
+	}
+	return headerName
+}
+
+func setToken(req *http.Request, token string, bearer bool) {
+	if bearer {
+		tok := base64.StdEncoding.EncodeToString([]byte(token))
+		req.Header.Set("Authorization", "Bearer "+tok)
+	} else {
+		req.Header.Set(TokenHeaderName(), token)
+	}
+}
+
+// TokenAuthProvider sends a static API token from https://app.foundries.io/settings/tokens/
+type TokenAuthProvider struct {
+	Token string
+}
+
+func (p *TokenAuthProvider) Authenticate(req *http.Request) error {
+	logrus.Debug("Using API token for http request")
+	setToken(req, p.Token, false)
+	return nil
+}
+
+// OAuthAuthProvider sends an OAuth2 access token obtained via client credentials.
+// A caller is responsible for refreshing the token before creating the provider.
+type OAuthAuthProvider struct {
+	Config OAuthConfig
+}
+
+func (p *OAuthAuthProvider) Authenticate(req *http.Request) error {
+	if len(p.Config.AccessToken) == 0 {
+		return errors.New("OAuth access token is not set. Please run: \"fioctl login\" first")
+	}
+	logrus.Debug("Using oauth token for http request")
+	setToken(req, p.Config.AccessToken, true)
+	return nil
+}
+
+// EnvAuthProvider sends a token read from an environment variable.
+// This allows CI systems to inject credentials without writing them into the fioctl.yaml.
+type EnvAuthProvider struct {
+	Variable string
+	Bearer   bool
+}
+
+func (p *EnvAuthProvider) Authenticate(req *http.Request) error {
+	token := strings.TrimSpace(os.Getenv(p.Variable))
+	if len(token) == 0 {
+		return fmt.Errorf("Environment variable %s with an API token is not set", p.Variable)
+	}
+	logrus.Debugf("Using token from %s environment variable for http request", p.Variable)
+	setToken(req, token, p.Bearer)
+	return nil
+}
+
+// CommandAuthProvider sends a token printed by an external command, akin to git credential helpers.
+// The command is run once per process, and its (trimmed) standard output is used as a token.
+// A failed command is run again for the next request, e.g. after a transient failure or a cancellation.
+type CommandAuthProvider struct {
+	Command string
+	Bearer  bool
+
+	mu    sync.Mutex
+	token string
+}
+
+func (p *CommandAuthProvider) Authenticate(req *http.Request) error {
+	token, err := p.getToken(req.Context())
+	if err != nil {
+		return err
+	}
+	logrus.Debug("Using token from auth command for http request")
+	setToken(req, token, p.Bearer)
+	return nil
+}
+
+func (p *CommandAuthProvider) getToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.token) > 0 {
+		return p.token, nil
+	}
+	logrus.Debugf("Running auth command: %s", p.Command)
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", p.Command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", p.Command)
+	}
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("Auth command failed: %w", err)
+	}
+	token := strings.TrimSpace(out.String())
+	if len(token) == 0 {
+		return "", errors.New("Auth command did not print a token")
+	}
+	p.token = token
+	return token, nil
+}
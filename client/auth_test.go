@@ -0,0 +1,73 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestAuthProviders(t *testing.T) {
+	srv := newTestServer(t)
+	filter := map[string]string{"factory": testFactory}
+
+	api := srv.NewApi(client.Config{Token: "wrong"})
+	_, err := api.DeviceList(filter, "", 1, 10)
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, client.ErrUnauthorized))
+
+	t.Setenv("FIOCTL_TEST_TOKEN", srv.Token())
+	for _, tokenType := range []string{client.TokenTypeApiToken, client.TokenTypeBearer} {
+		auth, err := client.NewAuthProvider(client.AuthConfig{
+			Provider: client.AuthProviderEnv, Env: "FIOCTL_TEST_TOKEN", TokenType: tokenType,
+		})
+		require.Nil(t, err)
+		api = srv.NewApi(client.Config{AuthProvider: auth})
+		_, err = api.DeviceList(filter, "", 1, 10)
+		require.Nil(t, err, tokenType)
+	}
+
+	auth, err := client.NewAuthProvider(client.AuthConfig{
+		Provider: client.AuthProviderCommand, Command: "echo " + srv.Token(),
+	})
+	require.Nil(t, err)
+	api = srv.NewApi(client.Config{AuthProvider: auth})
+	_, err = api.DeviceList(filter, "", 1, 10)
+	require.Nil(t, err)
+
+	reqs := srv.Requests()
+	assert.Equal(t, srv.Token(), reqs[len(reqs)-1].Header.Get("OSF-TOKEN"))
+}
+
+func TestCommandAuthProviderRetries(t *testing.T) {
+	srv := newTestServer(t)
+	filter := map[string]string{"factory": testFactory}
+	marker := filepath.Join(t.TempDir(), "ran")
+	counter := filepath.Join(t.TempDir(), "count")
+
+	// The command fails the first time only, and counts its successful runs
+	auth, err := client.NewAuthProvider(client.AuthConfig{
+		Provider: client.AuthProviderCommand,
+		Command: fmt.Sprintf("if [ -f %[1]s ]; then echo x >> %[2]s; echo %[3]s; else touch %[1]s; exit 1; fi",
+			marker, counter, srv.Token()),
+	})
+	require.Nil(t, err)
+	api := srv.NewApi(client.Config{AuthProvider: auth})
+	_, err = api.DeviceList(filter, "", 1, 10)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Auth command failed")
+
+	for range 2 {
+		_, err = api.DeviceList(filter, "", 1, 10)
+		require.Nil(t, err)
+	}
+	runs, err := os.ReadFile(counter)
+	require.Nil(t, err)
+	assert.Equal(t, "x\n", string(runs))
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CacheConfig controls an on-disk cache of large read-only API responses (e.g. targets.json).
+// Cached responses are always revalidated with the server using an ETag or Last-Modified,
+// so a cache only saves downloading a response which has not changed.
+// It maps to the "cache" section of the fioctl.yaml.
+type CacheConfig struct {
+	// Do not read nor write cached responses
+	Disabled bool `mapstructure:"disabled"`
+	// Do not use cached responses, but still update the cache with fresh ones
+	Refresh bool `mapstructure:"refresh"`
+	// A cache directory; the default is ~/.cache/fioctl (or $XDG_CACHE_HOME/fioctl)
+	Dir string `mapstructure:"dir"`
+}
+
+// DefaultCacheDir returns a default location of the response cache.
+func DefaultCacheDir() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); len(xdg) > 0 {
+		return filepath.Join(xdg, "fioctl"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "fioctl"), nil
+}
+
+// responseCache keeps validated responses per factory in <dir>/<factory>/<sha256 of URL>.{json,body}
+type responseCache struct {
+	dir     string
+	refresh bool
+}
+
+type cacheEntry struct {
+	Url          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last-modified,omitempty"`
+	Stored       time.Time `json:"stored"`
+}
+
+func newResponseCache(cfg CacheConfig) *responseCache {
+	if cfg.Disabled {
+		return nil
+	}
+	dir := cfg.Dir
+	if len(dir) == 0 {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
+			logrus.Debugf("Response cache is disabled: %s", err)
+			return nil
+		}
+	}
+	return &responseCache{dir: dir, refresh: cfg.Refresh}
+}
+
+func (c *responseCache) paths(factory, url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	base := filepath.Join(c.dir, factory, hex.EncodeToString(sum[:]))
+	return base + ".json", base + ".body"
+}
+
+func (c *responseCache) load(factory, url string) (*cacheEntry, []byte) {
+	if c.refresh {
+		return nil, nil
+	}
+	metaPath, bodyPath := c.paths(factory, url)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var entry cacheEntry
+	if err = json.Unmarshal(meta, &entry); err != nil || entry.Url != url {
+		return nil, nil
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil
+	}
+	return &entry, body
+}
+
+func (c *responseCache) store(factory, url string, res *http.Response, body []byte) {
+	entry := cacheEntry{
+		Url:          url,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		Stored:       time.Now().UTC(),
+	}
+	if len(entry.ETag) == 0 && len(entry.LastModified) == 0 {
+		// Nothing to revalidate against
+		return
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	metaPath, bodyPath := c.paths(factory, url)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0700); err != nil {
+		logrus.Debugf("Unable to create a cache directory: %s", err)
+		return
+	}
+	// Write a body first, so that a metadata never points to a stale body
+	if err := writeFileAtomic(bodyPath, body); err != nil {
+		logrus.Debugf("Unable to write a cached response: %s", err)
+	} else if err := writeFileAtomic(metaPath, meta); err != nil {
+		logrus.Debugf("Unable to write a cached response: %s", err)
+	}
+}
+
+// invalidate drops all cached responses of a factory.
+func (c *responseCache) invalidate(factory string) {
+	if err := os.RemoveAll(filepath.Join(c.dir, factory)); err != nil {
+		logrus.Debugf("Unable to invalidate a response cache: %s", err)
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// cachedGet is like the Get, but revalidates a response cached for a factory instead of downloading it again.
+func (a *Api) cachedGet(factory, url string) (*[]byte, error) {
+	if a.cache == nil {
+		return a.Get(url)
+	}
+	log := logrus.WithField("url", url)
+	entry, cached := a.cache.load(factory, url)
+	headers := map[string]string{}
+	if entry != nil {
+		if len(entry.ETag) > 0 {
+			headers["If-None-Match"] = entry.ETag
+		}
+		if len(entry.LastModified) > 0 {
+			headers["If-Modified-Since"] = entry.LastModified
+		}
+	}
+	res, err := a.RawGet(url, &headers)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		log.Debugf("Using a cached response stored at %s", entry.Stored)
+		return &cached, nil
+	}
+	body, err := readResponse(res)
+	if err == nil {
+		a.cache.store(factory, url, res, *body)
+	}
+	return body, err
+}
+
+// InvalidateCache drops all cached responses of a factory.
+// It is called automatically by API methods changing cached resources.
+func (a *Api) InvalidateCache(factory string) {
+	if a.cache != nil {
+		a.cache.invalidate(factory)
+	}
+}
@@ -0,0 +1,75 @@
+package client_test
+
+import (
+	"net/http"
+	"testing"
+
+	canonical "github.com/docker/go/canonical/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tuf "github.com/theupdateframework/notary/tuf/data"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestResponseCache(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddTarget(client.Target{Length: 1, Custom: &client.TufCustom{Name: "lmp", Version: "1"}})
+	tracer := &memTracer{}
+	dir := t.TempDir()
+	api := srv.NewApi(client.Config{Tracer: tracer, Cache: client.CacheConfig{Dir: dir}})
+
+	statuses := func() (res []int) {
+		for _, entry := range tracer.entries {
+			res = append(res, entry.Response.StatusCode)
+		}
+		tracer.entries = nil
+		return
+	}
+
+	first, err := api.TargetsListRaw(testFactory)
+	require.Nil(t, err)
+	second, err := api.TargetsListRaw(testFactory)
+	require.Nil(t, err)
+	assert.Equal(t, *first, *second)
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotModified}, statuses())
+
+	// A change made by fioctl drops cached responses
+	signed := canonical.RawMessage(`{}`)
+	wave := client.WaveCreate{Name: "w1", Version: "1", Tag: "main", Targets: tuf.Signed{Signed: &signed}}
+	require.Nil(t, api.FactoryCreateWave(testFactory, &wave))
+	_, err = api.TargetsListRaw(testFactory)
+	require.Nil(t, err)
+	assert.Equal(t, []int{http.StatusCreated, http.StatusOK}, statuses())
+
+	// So do other changes of Targets and waves
+	for _, change := range []func() error{
+		func() error { return api.FactorySignWave(testFactory, "w1", nil) },
+		func() error {
+			_, err := api.FactoryRolloutWave(testFactory, "w1", client.WaveRolloutOptions{})
+			return err
+		},
+	} {
+		_, err = api.TargetsListRaw(testFactory)
+		require.Nil(t, err)
+		assert.Equal(t, []int{http.StatusNotModified}, statuses())
+		require.Nil(t, change())
+		_, err = api.TargetsListRaw(testFactory)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statuses()[1])
+	}
+
+	// A change made elsewhere is detected by a revalidation
+	srv.AddTarget(client.Target{Length: 1, Custom: &client.TufCustom{Name: "lmp", Version: "2"}})
+	targets, err := api.TargetsList(testFactory)
+	require.Nil(t, err)
+	assert.Len(t, targets, 2)
+	statuses()
+
+	refresh := srv.NewApi(client.Config{Tracer: tracer, Cache: client.CacheConfig{Dir: dir, Refresh: true}})
+	_, err = refresh.TargetsListRaw(testFactory)
+	require.Nil(t, err)
+	_, err = refresh.TargetsListRaw(testFactory)
+	require.Nil(t, err)
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statuses())
+}
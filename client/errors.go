@@ -0,0 +1,125 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors allowing to check an APIError kind with errors.Is, e.g.:
+//
+//	if errors.Is(err, client.ErrNotFound) { ... }
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrTooMany      = errors.New("too many requests")
+	ErrServer       = errors.New("server error")
+
+	// ErrDryRun matches a DryRunError
+	ErrDryRun = errors.New("dry run")
+)
+
+// DryRunError is returned instead of sending a request which would change something
+// on the server when Config.DryRun is set. The body has secrets redacted.
+type DryRunError struct {
+	Method string
+	URL    string
+	Body   string
+}
+
+func (err *DryRunError) Error() string {
+	msg := fmt.Sprintf("Dry run: not sending %s %s", err.Method, err.URL)
+	if len(err.Body) > 0 {
+		msg += "\n" + err.Body
+	}
+	return msg
+}
+
+func (err *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+// APIError is returned in case if we've successfully received an HTTP response which contains
+// an unexpected HTTP status code
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// A request ID assigned by the server (if any), useful for a support request
+	RequestID string
+
+	// A message returned by the server (if any)
+	ServerMessage string
+	// A list of errors returned by the server (if any)
+	Errors []string
+	// Errors per input field returned by the server (if any)
+	FieldErrors map[string]string
+
+	// A complete human-readable error message
+	Message  string
+	Response *http.Response
+}
+
+// HttpError is an old name of the APIError kept for backward compatibility
+type HttpError = APIError
+
+func (err *APIError) Error() string {
+	return err.Message
+}
+
+func (err *APIError) statusCode() int {
+	if err.StatusCode == 0 && err.Response != nil {
+		return err.Response.StatusCode
+	}
+	return err.StatusCode
+}
+
+// Is allows to match an APIError against sentinel errors by its status code.
+func (err *APIError) Is(target error) bool {
+	code := err.statusCode()
+	switch target {
+	case ErrBadRequest:
+		return code == http.StatusBadRequest
+	case ErrUnauthorized:
+		return code == http.StatusUnauthorized
+	case ErrForbidden:
+		return code == http.StatusForbidden
+	case ErrNotFound:
+		return code == http.StatusNotFound
+	case ErrConflict:
+		return code == http.StatusConflict
+	case ErrTooMany:
+		return code == http.StatusTooManyRequests
+	case ErrServer:
+		return code >= 500 && code <= 599
+	}
+	return false
+}
+
+// This is much better than err.(*APIError) as it also accounts for wrapped errors.
+func AsAPIError(err error) *APIError {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError
+	} else {
+		return nil
+	}
+}
+
+// AsHttpError is an old name of the AsAPIError kept for backward compatibility
+func AsHttpError(err error) *HttpError {
+	return AsAPIError(err)
+}
+
+func requestIdFromHeaders(headers http.Header) string {
+	for _, name := range []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Trace-Id"} {
+		if val := headers.Get(name); len(val) > 0 {
+			return val
+		}
+	}
+	return ""
+}
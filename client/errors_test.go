@@ -0,0 +1,45 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestAPIError(t *testing.T) {
+	srv := newTestServer(t)
+	api := srv.NewApi(client.Config{})
+
+	dapi := api.DeviceApiByName(testFactory, "missing")
+	_, err := dapi.Get()
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+	assert.False(t, errors.Is(err, client.ErrConflict))
+	herr := client.AsAPIError(err)
+	require.NotNil(t, herr)
+	assert.Equal(t, http.MethodGet, herr.Method)
+	assert.Equal(t, http.StatusNotFound, herr.StatusCode)
+	assert.Equal(t, "Device not found: missing", herr.ServerMessage)
+	assert.NotEmpty(t, herr.RequestID)
+	assert.Contains(t, herr.Error(), "Request ID: "+herr.RequestID)
+
+	srv.AddDevice(client.Device{Name: "dev"})
+	dapi = api.DeviceApiByName(testFactory, "dev")
+	err = dapi.SetGroup("missing")
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, client.ErrBadRequest))
+	herr = client.AsAPIError(err)
+	require.NotNil(t, herr)
+	assert.Equal(t, map[string]string{"group": "No such group: missing"}, herr.FieldErrors)
+
+	_, err = api.FactoryCreateDeviceGroup(testFactory, "grp", nil)
+	require.Nil(t, err)
+	_, err = api.FactoryCreateDeviceGroup(testFactory, "grp", nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "A device group with this name already exists", err.Error())
+}
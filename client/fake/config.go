@@ -0,0 +1,93 @@
+package fake
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// A user recorded as an author of all changes made via the fake server
+const fakeUser = "fake-user"
+
+// Config lists are kept newest first, the same way the API returns them.
+// Each change creates a new config entry holding a complete set of files.
+
+func newConfig(req client.ConfigCreateRequest, files []client.ConfigFile) client.DeviceConfig {
+	return client.DeviceConfig{
+		CreatedAt: now(),
+		CreatedBy: fakeUser,
+		Reason:    req.Reason,
+		Files:     files,
+	}
+}
+
+func createConfig(configs []client.DeviceConfig, req client.ConfigCreateRequest) []client.DeviceConfig {
+	return slices.Insert(configs, 0, newConfig(req, req.Files))
+}
+
+func patchConfig(configs []client.DeviceConfig, req client.ConfigCreateRequest) []client.DeviceConfig {
+	var files []client.ConfigFile
+	if len(configs) > 0 {
+		files = slices.Clone(configs[0].Files)
+	}
+	for _, file := range req.Files {
+		idx := slices.IndexFunc(files, func(f client.ConfigFile) bool { return f.Name == file.Name })
+		if idx < 0 {
+			files = append(files, file)
+		} else {
+			files[idx] = file
+		}
+	}
+	return slices.Insert(configs, 0, newConfig(req, files))
+}
+
+func deleteConfigFile(configs []client.DeviceConfig, name string) ([]client.DeviceConfig, bool) {
+	if len(configs) == 0 {
+		return configs, false
+	}
+	files := slices.DeleteFunc(slices.Clone(configs[0].Files), func(f client.ConfigFile) bool {
+		return f.Name == name
+	})
+	if len(files) == len(configs[0].Files) {
+		return configs, false
+	}
+	req := client.ConfigCreateRequest{Reason: "Delete " + name}
+	return slices.Insert(configs, 0, newConfig(req, files)), true
+}
+
+// Handles a config endpoint for a given method, updating configs in place.
+func handleConfig(w http.ResponseWriter, r *http.Request, s *Server, configs *[]client.DeviceConfig) {
+	switch r.Method {
+	case http.MethodGet:
+		paginate(s, w, r, "config", *configs)
+		return
+	case http.MethodDelete:
+		var ok bool
+		name := r.PathValue("file")
+		if *configs, ok = deleteConfigFile(*configs, name); !ok {
+			writeError(w, http.StatusNotFound, "Config file not found: "+name)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var req client.ConfigCreateRequest
+	if !readJson(w, r, &req) {
+		return
+	}
+	for _, file := range req.Files {
+		if len(file.Name) == 0 {
+			writeFieldErrors(w, http.StatusBadRequest, "Invalid config",
+				map[string]string{"files": "A file name is required"})
+			return
+		}
+	}
+	if r.Method == http.MethodPost {
+		*configs = createConfig(*configs, req)
+	} else {
+		*configs = patchConfig(*configs, req)
+	}
+	w.WriteHeader(http.StatusCreated)
+}
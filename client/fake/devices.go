@@ -0,0 +1,281 @@
+package fake
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// AddDevice adds a device to the factory, filling in its UUID, factory, and timestamps unless set.
+// It returns the device as stored by the server.
+func (s *Server) AddDevice(d client.Device) client.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(d.Uuid) == 0 {
+		d.Uuid = newUuid()
+	}
+	d.Factory = s.Factory
+	if len(d.LastSeen) == 0 {
+		d.LastSeen = now()
+	}
+	if len(d.ChangeMeta.CreatedAt) == 0 {
+		d.ChangeMeta.CreatedAt = now()
+	}
+	if d.Group != nil && len(d.GroupName) == 0 {
+		d.GroupName = d.Group.Name
+	}
+	d.Group = nil
+	s.devices = append(s.devices, &d)
+	return d
+}
+
+// Device returns a device by its name.
+func (s *Server) Device(name string) (client.Device, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := s.findDevice(name, false); d != nil {
+		return *d, true
+	}
+	return client.Device{}, false
+}
+
+// Devices returns all devices of the factory.
+func (s *Server) Devices() []client.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.devices)
+}
+
+// DeniedDevices returns all devices deleted from the factory.
+func (s *Server) DeniedDevices() []client.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.deniedDevices)
+}
+
+// DeviceConfigs returns a config history of a device by its name, newest first.
+func (s *Server) DeviceConfigs(name string) []client.DeviceConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := s.findDevice(name, false); d != nil {
+		return slices.Clone(s.deviceConfigs[d.Uuid])
+	}
+	return nil
+}
+
+// AddDeviceUpdate adds an update with its events to the history of a device by its name.
+func (s *Server) AddDeviceUpdate(name string, update client.Update, events ...client.UpdateEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.findDevice(name, false)
+	if d == nil {
+		panic("No such device: " + name)
+	}
+	s.deviceUpdates[d.Uuid] = slices.Insert(s.deviceUpdates[d.Uuid], 0, update)
+	s.updateEvents[d.Uuid+"/"+update.CorrelationId] = events
+}
+
+// SetAppsStates sets apps states reported by a device by its name.
+func (s *Server) SetAppsStates(name string, states client.AppsStates) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.findDevice(name, false)
+	if d == nil {
+		panic("No such device: " + name)
+	}
+	s.appsStates[d.Uuid] = states
+}
+
+func (s *Server) registerDevices(mux *http.ServeMux) {
+	s.route(mux, "GET /ota/devices/{$}", s.listDevices)
+	s.route(mux, "GET /ota/devices/{device}/{$}", s.withDevice(s.getDevice))
+	s.route(mux, "PATCH /ota/devices/{device}/{$}", s.withDevice(s.patchDevice))
+	s.route(mux, "DELETE /ota/devices/{device}/{$}", s.withDevice(s.deleteDevice))
+	s.route(mux, "GET /ota/devices/{device}/updates/{$}", s.withDevice(
+		func(w http.ResponseWriter, r *http.Request, d *client.Device) {
+			paginate(s, w, r, "updates", s.deviceUpdates[d.Uuid])
+		}))
+	s.route(mux, "GET /ota/devices/{device}/updates/{correlation}/{$}", s.withDevice(
+		func(w http.ResponseWriter, r *http.Request, d *client.Device) {
+			events, ok := s.updateEvents[d.Uuid+"/"+r.PathValue("correlation")]
+			if !ok {
+				writeError(w, http.StatusNotFound, "Update not found")
+				return
+			}
+			writeJson(w, http.StatusOK, events)
+		}))
+	s.route(mux, "GET /ota/devices/{device}/apps-states/{$}", s.withDevice(
+		func(w http.ResponseWriter, r *http.Request, d *client.Device) {
+			writeJson(w, http.StatusOK, s.appsStates[d.Uuid])
+		}))
+
+	deviceConfig := s.withDevice(func(w http.ResponseWriter, r *http.Request, d *client.Device) {
+		configs := s.deviceConfigs[d.Uuid]
+		handleConfig(w, r, s, &configs)
+		s.deviceConfigs[d.Uuid] = configs
+	})
+	s.route(mux, "GET /ota/devices/{device}/config/{$}", deviceConfig)
+	s.route(mux, "POST /ota/devices/{device}/config/{$}", deviceConfig)
+	s.route(mux, "PATCH /ota/devices/{device}/config/{$}", deviceConfig)
+	s.route(mux, "DELETE /ota/devices/{device}/config/{file}/{$}", deviceConfig)
+
+	s.route(mux, "GET /ota/factories/{factory}/denied-devices/{$}", func(w http.ResponseWriter, r *http.Request) {
+		paginate(s, w, r, "devices", copyAll(s.deniedDevices))
+	})
+	s.route(mux, "DELETE /ota/factories/{factory}/denied-devices/{uuid}/{$}", func(w http.ResponseWriter, r *http.Request) {
+		idx := slices.IndexFunc(s.deniedDevices, func(d *client.Device) bool { return d.Uuid == r.PathValue("uuid") })
+		if idx < 0 {
+			writeError(w, http.StatusNotFound, "Device not found")
+			return
+		}
+		s.deniedDevices = slices.Delete(s.deniedDevices, idx, idx+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// Must be called with the s.mu held.
+func (s *Server) findDevice(id string, byUuid bool) *client.Device {
+	for _, d := range s.devices {
+		if (byUuid && d.Uuid == id) || (!byUuid && d.Name == id) {
+			return d
+		}
+	}
+	return nil
+}
+
+type deviceHandler func(w http.ResponseWriter, r *http.Request, d *client.Device)
+
+func (s *Server) withDevice(handler deviceHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := s.findDevice(r.PathValue("device"), r.URL.Query().Get("by-uuid") == "1")
+		if d == nil {
+			writeError(w, http.StatusNotFound, "Device not found: "+r.PathValue("device"))
+			return
+		}
+		handler(w, r, d)
+	}
+}
+
+func (s *Server) listDevices(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	devices := slices.DeleteFunc(copyAll(s.devices), func(d client.Device) bool {
+		return !deviceMatches(d, query.Get)
+	})
+	for _, sortBy := range slices.Backward(strings.Split(query.Get("sortby"), ",")) {
+		desc := strings.HasPrefix(sortBy, "-")
+		var key func(d client.Device) string
+		switch strings.TrimPrefix(sortBy, "-") {
+		case "name":
+			key = func(d client.Device) string { return d.Name }
+		case "last_seen":
+			key = func(d client.Device) string { return d.LastSeen }
+		default:
+			continue
+		}
+		slices.SortStableFunc(devices, func(a, b client.Device) int {
+			if desc {
+				return strings.Compare(key(b), key(a))
+			}
+			return strings.Compare(key(a), key(b))
+		})
+	}
+	paginate(s, w, r, "devices", devices)
+}
+
+func deviceMatches(d client.Device, query func(string) string) bool {
+	if name := query("name"); len(name) > 0 {
+		// The API allows "%" wildcards in a name filter
+		if ok, _ := path.Match(strings.ReplaceAll(name, "%", "*"), d.Name); !ok {
+			return false
+		}
+	}
+	if group := query("group"); len(group) > 0 && group != d.GroupName {
+		return false
+	}
+	if tag := query("match_tag"); len(tag) > 0 && tag != d.Tag {
+		return false
+	}
+	if target := query("target_name"); len(target) > 0 && target != d.TargetName {
+		return false
+	}
+	if uuid := query("uuid"); len(uuid) > 0 && uuid != d.Uuid {
+		return false
+	}
+	switch query("prod") {
+	case "1":
+		return d.IsProd
+	case "0":
+		return !d.IsProd
+	}
+	return true
+}
+
+func (s *Server) getDevice(w http.ResponseWriter, r *http.Request, d *client.Device) {
+	res := *d
+	if len(res.GroupName) > 0 {
+		if g := s.findGroup(res.GroupName); g != nil {
+			res.Group = g
+		}
+	}
+	if configs := s.deviceConfigs[d.Uuid]; len(configs) > 0 {
+		res.ActiveConfig = &configs[0]
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+func (s *Server) patchDevice(w http.ResponseWriter, r *http.Request, d *client.Device) {
+	var req map[string]string
+	if !readJson(w, r, &req) {
+		return
+	}
+	if owner, ok := req["owner"]; ok {
+		d.Owner = owner
+	}
+	if name, ok := req["name"]; ok {
+		if other := s.findDevice(name, false); other != nil && other != d {
+			writeFieldErrors(w, http.StatusConflict, "Device name is already taken",
+				map[string]string{"name": name})
+			return
+		}
+		d.Name = name
+	}
+	if group, ok := req["group"]; ok {
+		if len(group) > 0 && s.findGroup(group) == nil {
+			writeFieldErrors(w, http.StatusBadRequest, "Invalid device group",
+				map[string]string{"group": "No such group: " + group})
+			return
+		}
+		d.GroupName = group
+	}
+	d.ChangeMeta.UpdatedAt = now()
+	d.ChangeMeta.UpdatedBy = fakeUser
+	writeJson(w, http.StatusOK, d)
+}
+
+func (s *Server) deleteDevice(w http.ResponseWriter, r *http.Request, d *client.Device) {
+	s.devices = slices.DeleteFunc(s.devices, func(other *client.Device) bool { return other == d })
+	// Deleted devices are denied to register again with the same UUID
+	s.deniedDevices = append(s.deniedDevices, d)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func copyAll[T any](items []*T) []T {
+	res := make([]T, 0, len(items))
+	for _, item := range items {
+		res = append(res, *item)
+	}
+	return res
+}
+
+func newUuid() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
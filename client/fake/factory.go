@@ -0,0 +1,528 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// AddDeviceGroup adds a device group to the factory, returning it as stored by the server.
+func (s *Server) AddDeviceGroup(g client.DeviceGroup) client.DeviceGroup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g.Id = s.newId()
+	if len(g.ChangeMeta.CreatedAt) == 0 {
+		g.ChangeMeta.CreatedAt = now()
+		g.ChangeMeta.CreatedBy = fakeUser
+	}
+	s.groups = append(s.groups, &g)
+	return g
+}
+
+// DeviceGroups returns all device groups of the factory.
+func (s *Server) DeviceGroups() []client.DeviceGroup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.groups)
+}
+
+// FactoryConfigs returns a factory config history, newest first.
+func (s *Server) FactoryConfigs() []client.DeviceConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.factoryConfigs)
+}
+
+// GroupConfigs returns a config history of a device group, newest first.
+func (s *Server) GroupConfigs(group string) []client.DeviceConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.groupConfigs[group])
+}
+
+// Waves returns all waves of the factory, including their targets.
+func (s *Server) Waves() []client.Wave {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.waves)
+}
+
+// SetCA sets the factory PKI certificates; until it is called the factory PKI is not configured.
+func (s *Server) SetCA(certs client.CaCerts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certs = &certs
+}
+
+// CA returns the factory PKI certificates, or nil if the factory PKI is not configured.
+func (s *Server) CA() *client.CaCerts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.certs == nil {
+		return nil
+	}
+	certs := *s.certs
+	return &certs
+}
+
+// AddEventQueue adds an event queue to the factory.
+func (s *Server) AddEventQueue(q client.EventQueue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eventQueues = append(s.eventQueues, q)
+}
+
+// EventQueues returns all event queues of the factory.
+func (s *Server) EventQueues() []client.EventQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.eventQueues)
+}
+
+// AddUser adds a user to the factory.
+func (s *Server) AddUser(u client.FactoryUserAccessDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = append(s.users, u)
+}
+
+// AddTeam adds a team to the factory.
+func (s *Server) AddTeam(t client.FactoryTeamDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teams = append(s.teams, t)
+}
+
+func (s *Server) registerFactory(mux *http.ServeMux) {
+	mux.HandleFunc("GET /ota/factories/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, []client.Factory{{Name: s.Factory, Id: s.Factory}})
+	})
+
+	factoryConfig := func(w http.ResponseWriter, r *http.Request) {
+		handleConfig(w, r, s, &s.factoryConfigs)
+	}
+	s.route(mux, "GET /ota/factories/{factory}/config/{$}", factoryConfig)
+	s.route(mux, "POST /ota/factories/{factory}/config/{$}", factoryConfig)
+	s.route(mux, "PATCH /ota/factories/{factory}/config/{$}", factoryConfig)
+	s.route(mux, "DELETE /ota/factories/{factory}/config/{file}/{$}", factoryConfig)
+
+	s.route(mux, "GET /ota/factories/{factory}/device-groups/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, map[string]any{"groups": copyAll(s.groups)})
+	})
+	s.route(mux, "POST /ota/factories/{factory}/device-groups/{$}", s.createGroup)
+	s.route(mux, "PATCH /ota/factories/{factory}/device-groups/{group}/{$}", s.withGroup(s.patchGroup))
+	s.route(mux, "DELETE /ota/factories/{factory}/device-groups/{group}/{$}", s.withGroup(s.deleteGroup))
+
+	groupConfig := s.withGroup(func(w http.ResponseWriter, r *http.Request, g *client.DeviceGroup) {
+		configs := s.groupConfigs[g.Name]
+		handleConfig(w, r, s, &configs)
+		s.groupConfigs[g.Name] = configs
+	})
+	s.route(mux, "GET /ota/factories/{factory}/device-groups/{group}/config/{$}", groupConfig)
+	s.route(mux, "POST /ota/factories/{factory}/device-groups/{group}/config/{$}", groupConfig)
+	s.route(mux, "PATCH /ota/factories/{factory}/device-groups/{group}/config/{$}", groupConfig)
+	s.route(mux, "DELETE /ota/factories/{factory}/device-groups/{group}/config/{file}/{$}", groupConfig)
+
+	s.route(mux, "GET /ota/factories/{factory}/waves/{$}", s.listWaves)
+	s.route(mux, "POST /ota/factories/{factory}/waves/{$}", s.createWave)
+	s.route(mux, "GET /ota/factories/{factory}/waves/{wave}/{$}", s.withWave(
+		func(w http.ResponseWriter, r *http.Request, wave *client.Wave) {
+			res := *wave
+			if r.URL.Query().Get("show-targets") != "1" {
+				res.Targets = nil
+			}
+			writeJson(w, http.StatusOK, res)
+		}))
+	s.route(mux, "POST /ota/factories/{factory}/waves/{wave}/sign/{$}", s.withWave(
+		func(w http.ResponseWriter, r *http.Request, wave *client.Wave) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	s.route(mux, "POST /ota/factories/{factory}/waves/{wave}/rollout/{$}", s.withWave(s.rolloutWave))
+	s.route(mux, "POST /ota/factories/{factory}/waves/{wave}/cancel/{$}", s.withWave(
+		func(w http.ResponseWriter, r *http.Request, wave *client.Wave) {
+			s.finishWave(w, wave, "canceled")
+		}))
+	s.route(mux, "POST /ota/factories/{factory}/waves/{wave}/complete/{$}", s.withWave(
+		func(w http.ResponseWriter, r *http.Request, wave *client.Wave) {
+			s.finishWave(w, wave, "complete")
+		}))
+
+	s.route(mux, "GET /ota/factories/{factory}/certs/{$}", func(w http.ResponseWriter, r *http.Request) {
+		if s.certs == nil {
+			// This is how the API tells that the factory PKI is not configured yet
+			w.WriteHeader(http.StatusPartialContent)
+			return
+		}
+		writeJson(w, http.StatusOK, s.certs)
+	})
+	s.route(mux, "POST /ota/factories/{factory}/certs/{$}", s.createCA)
+	s.route(mux, "PATCH /ota/factories/{factory}/certs/{$}", s.patchCA)
+
+	s.route(mux, "GET /ota/factories/{factory}/event-queues/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, s.eventQueues)
+	})
+	s.route(mux, "POST /ota/factories/{factory}/event-queues/{$}", s.createEventQueue)
+	s.route(mux, "DELETE /ota/factories/{factory}/event-queues/{label}/{$}", func(w http.ResponseWriter, r *http.Request) {
+		idx := slices.IndexFunc(s.eventQueues, func(q client.EventQueue) bool { return q.Label == r.PathValue("label") })
+		if idx < 0 {
+			writeError(w, http.StatusNotFound, "Event queue not found: "+r.PathValue("label"))
+			return
+		}
+		s.eventQueues = slices.Delete(s.eventQueues, idx, idx+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.route(mux, "GET /ota/factories/{factory}/users/{$}", func(w http.ResponseWriter, r *http.Request) {
+		users := make([]client.FactoryUser, 0, len(s.users))
+		for _, u := range s.users {
+			users = append(users, client.FactoryUser{PolisId: u.PolisId, Name: u.Name, Role: u.Role})
+		}
+		writeJson(w, http.StatusOK, users)
+	})
+	s.route(mux, "GET /ota/factories/{factory}/users/{user}/{$}", func(w http.ResponseWriter, r *http.Request) {
+		idx := slices.IndexFunc(s.users, func(u client.FactoryUserAccessDetails) bool {
+			return u.PolisId == r.PathValue("user")
+		})
+		if idx < 0 {
+			writeError(w, http.StatusNotFound, "User not found: "+r.PathValue("user"))
+			return
+		}
+		writeJson(w, http.StatusOK, s.users[idx])
+	})
+	s.route(mux, "GET /ota/factories/{factory}/teams/{$}", func(w http.ResponseWriter, r *http.Request) {
+		teams := make([]client.FactoryTeam, 0, len(s.teams))
+		for _, t := range s.teams {
+			teams = append(teams, client.FactoryTeam{Name: t.Name, Description: t.Description})
+		}
+		writeJson(w, http.StatusOK, teams)
+	})
+	s.route(mux, "GET /ota/factories/{factory}/teams/{team}", func(w http.ResponseWriter, r *http.Request) {
+		idx := slices.IndexFunc(s.teams, func(t client.FactoryTeamDetails) bool { return t.Name == r.PathValue("team") })
+		if idx < 0 {
+			writeError(w, http.StatusNotFound, "Team not found: "+r.PathValue("team"))
+			return
+		}
+		writeJson(w, http.StatusOK, s.teams[idx])
+	})
+}
+
+// Must be called with the s.mu held.
+func (s *Server) findGroup(name string) *client.DeviceGroup {
+	for _, g := range s.groups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+type groupHandler func(w http.ResponseWriter, r *http.Request, g *client.DeviceGroup)
+
+func (s *Server) withGroup(handler groupHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g := s.findGroup(r.PathValue("group"))
+		if g == nil {
+			writeError(w, http.StatusNotFound, "Device group not found: "+r.PathValue("group"))
+			return
+		}
+		handler(w, r, g)
+	}
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	var req map[string]string
+	if !readJson(w, r, &req) {
+		return
+	}
+	if len(req["name"]) == 0 {
+		writeFieldErrors(w, http.StatusBadRequest, "Invalid device group",
+			map[string]string{"name": "A name is required"})
+		return
+	}
+	if s.findGroup(req["name"]) != nil {
+		writeError(w, http.StatusConflict, "Device group already exists: "+req["name"])
+		return
+	}
+	g := &client.DeviceGroup{
+		Id:          s.newId(),
+		Name:        req["name"],
+		Description: req["description"],
+		ChangeMeta:  client.ChangeMeta{CreatedAt: now(), CreatedBy: fakeUser},
+	}
+	s.groups = append(s.groups, g)
+	writeJson(w, http.StatusCreated, g)
+}
+
+func (s *Server) patchGroup(w http.ResponseWriter, r *http.Request, g *client.DeviceGroup) {
+	var req map[string]string
+	if !readJson(w, r, &req) {
+		return
+	}
+	if name, ok := req["name"]; ok && name != g.Name {
+		if s.findGroup(name) != nil {
+			writeError(w, http.StatusConflict, "Device group already exists: "+name)
+			return
+		}
+		for _, d := range s.devices {
+			if d.GroupName == g.Name {
+				d.GroupName = name
+			}
+		}
+		if configs, ok := s.groupConfigs[g.Name]; ok {
+			delete(s.groupConfigs, g.Name)
+			s.groupConfigs[name] = configs
+		}
+		g.Name = name
+	}
+	if desc, ok := req["description"]; ok {
+		g.Description = desc
+	}
+	g.ChangeMeta.UpdatedAt = now()
+	g.ChangeMeta.UpdatedBy = fakeUser
+	writeJson(w, http.StatusOK, g)
+}
+
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request, g *client.DeviceGroup) {
+	for _, d := range s.devices {
+		if d.GroupName == g.Name {
+			writeError(w, http.StatusConflict, "There are devices assigned to this device group")
+			return
+		}
+	}
+	s.groups = slices.DeleteFunc(s.groups, func(other *client.DeviceGroup) bool { return other == g })
+	delete(s.groupConfigs, g.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Must be called with the s.mu held.
+func (s *Server) findWave(name string) *client.Wave {
+	for _, w := range s.waves {
+		if w.Name == name {
+			return w
+		}
+	}
+	return nil
+}
+
+type waveHandler func(w http.ResponseWriter, r *http.Request, wave *client.Wave)
+
+func (s *Server) withWave(handler waveHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		wave := s.findWave(r.PathValue("wave"))
+		if wave == nil {
+			writeError(w, http.StatusNotFound, "Wave not found: "+r.PathValue("wave"))
+			return
+		}
+		handler(w, r, wave)
+	}
+}
+
+func (s *Server) listWaves(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var waves []client.Wave
+	for _, wave := range slices.Backward(s.waves) {
+		if status := query.Get("status"); len(status) > 0 && status != wave.Status {
+			continue
+		}
+		if tag := query.Get("tag"); len(tag) > 0 && tag != wave.Tag {
+			continue
+		}
+		res := *wave
+		res.Targets = nil
+		waves = append(waves, res)
+	}
+	paginate(s, w, r, "waves", waves)
+}
+
+func (s *Server) createWave(w http.ResponseWriter, r *http.Request) {
+	var req client.WaveCreate
+	if !readJson(w, r, &req) {
+		return
+	}
+	if len(req.Name) == 0 || len(req.Tag) == 0 || len(req.Version) == 0 {
+		writeError(w, http.StatusBadRequest, "A wave name, version, and tag are required")
+		return
+	}
+	if s.findWave(req.Name) != nil {
+		writeError(w, http.StatusConflict, "Wave already exists: "+req.Name)
+		return
+	}
+	targets, err := json.Marshal(req.Targets)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	raw := json.RawMessage(targets)
+	s.waves = append(s.waves, &client.Wave{
+		Name:          req.Name,
+		Version:       req.Version,
+		Tag:           req.Tag,
+		Targets:       &raw,
+		Status:        "active",
+		RolloutGroups: make(map[string]client.WaveRolloutGroupRef),
+		ChangeMeta:    client.ChangeMeta{CreatedAt: now(), CreatedBy: fakeUser},
+	})
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) rolloutWave(w http.ResponseWriter, r *http.Request, wave *client.Wave) {
+	var opts client.WaveRolloutOptions
+	if !readJson(w, r, &opts) {
+		return
+	}
+	if wave.Status != "active" {
+		writeError(w, http.StatusConflict, "Wave is not active: "+wave.Status)
+		return
+	}
+	var group *client.DeviceGroup
+	if len(opts.Group) > 0 {
+		if group = s.findGroup(opts.Group); group == nil {
+			writeError(w, http.StatusBadRequest, "Device group not found: "+opts.Group)
+			return
+		}
+	}
+
+	var devices []*client.Device
+	for _, d := range s.devices {
+		if d.Tag != wave.Tag {
+			continue
+		}
+		if group != nil && d.GroupName != group.Name {
+			continue
+		}
+		if len(opts.Uuids) > 0 && !slices.Contains(opts.Uuids, d.Uuid) {
+			continue
+		}
+		devices = append(devices, d)
+	}
+	isFullGroup := opts.Limit == 0 && opts.Percentage == 0 && len(opts.Uuids) == 0
+	if opts.Percentage > 0 {
+		devices = devices[:(len(devices)*opts.Percentage+99)/100]
+	}
+	if opts.Limit > 0 && opts.Limit < len(devices) {
+		devices = devices[:opts.Limit]
+	}
+
+	res := client.WaveRolloutResult{DeviceNum: len(devices)}
+	for _, d := range devices {
+		if opts.PrintUuids {
+			res.DeviceUuids = append(res.DeviceUuids, d.Uuid)
+		}
+		if opts.PrintNames {
+			res.DeviceNames = append(res.DeviceNames, d.Name)
+		}
+	}
+	if !opts.DryRun {
+		history := client.RolloutHistory{
+			RolloutBy:     fakeUser,
+			RolloutAt:     now(),
+			IsFullGroup:   isFullGroup && group != nil,
+			IsFactoryWide: isFullGroup && group == nil,
+			DeviceNumber:  len(devices),
+		}
+		if group != nil {
+			history.GroupName = group.Name
+			wave.RolloutGroups[group.Name] = client.WaveRolloutGroupRef{
+				GroupId: group.Id, GroupName: group.Name, CreatedAt: now(), CreatedBy: fakeUser,
+			}
+		}
+		wave.History = append(wave.History, history)
+	}
+	res.Wave = *wave
+	res.Targets = nil
+	writeJson(w, http.StatusOK, res)
+}
+
+func (s *Server) finishWave(w http.ResponseWriter, wave *client.Wave, status string) {
+	if wave.Status != "active" {
+		writeError(w, http.StatusConflict, "Wave is not active: "+wave.Status)
+		return
+	}
+	if status == "complete" && wave.Targets != nil {
+		// Completing a wave makes its targets available to all production devices
+		var targets client.AtsTufTargets
+		if err := json.Unmarshal(*wave.Targets, &targets); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.prodTargets[wave.Tag] = targets
+	}
+	wave.Status = status
+	wave.ChangeMeta.UpdatedAt = now()
+	wave.ChangeMeta.UpdatedBy = fakeUser
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) createCA(w http.ResponseWriter, r *http.Request) {
+	var opts client.CaCreateOptions
+	if !readJson(w, r, &opts) {
+		return
+	}
+	if opts.FirstTimeInit && s.certs != nil {
+		writeError(w, http.StatusConflict, "Factory PKI is already configured")
+		return
+	}
+	if s.certs == nil {
+		s.certs = &client.CaCerts{ChangeMeta: client.ChangeMeta{CreatedAt: now(), CreatedBy: fakeUser}}
+	}
+	var csrs client.CaCsrs
+	if opts.CreateOnlineCa {
+		csrs.CaCsr = fakePem("CERTIFICATE REQUEST", "online-ca")
+	}
+	if opts.CreateEstCert {
+		csrs.EstCsr = fakePem("CERTIFICATE REQUEST", "est-tls")
+	}
+	if opts.CreateTlsCert {
+		csrs.TlsCsr = fakePem("CERTIFICATE REQUEST", "tls")
+	}
+	writeJson(w, http.StatusCreated, csrs)
+}
+
+func (s *Server) patchCA(w http.ResponseWriter, r *http.Request) {
+	var req client.CaCerts
+	if !readJson(w, r, &req) {
+		return
+	}
+	if s.certs == nil {
+		writeError(w, http.StatusBadRequest, "Factory PKI is not configured")
+		return
+	}
+	for _, field := range []struct{ dst, src *string }{
+		{&s.certs.RootCrt, &req.RootCrt},
+		{&s.certs.CaCrt, &req.CaCrt},
+		{&s.certs.EstCrt, &req.EstCrt},
+		{&s.certs.TlsCrt, &req.TlsCrt},
+		{&s.certs.CaRevokeCrl, &req.CaRevokeCrl},
+	} {
+		if len(*field.src) > 0 {
+			*field.dst = *field.src
+		}
+	}
+	s.certs.ChangeMeta.UpdatedAt = now()
+	s.certs.ChangeMeta.UpdatedBy = fakeUser
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) createEventQueue(w http.ResponseWriter, r *http.Request) {
+	var q client.EventQueue
+	if !readJson(w, r, &q) {
+		return
+	}
+	if slices.ContainsFunc(s.eventQueues, func(other client.EventQueue) bool { return other.Label == q.Label }) {
+		writeError(w, http.StatusConflict, "Event queue already exists: "+q.Label)
+		return
+	}
+	s.eventQueues = append(s.eventQueues, q)
+	if q.Type == "pull" {
+		// Pull queues return credentials of a subscriber
+		writeJson(w, http.StatusCreated, map[string]string{"type": "service_account", "client_email": q.Label + "@fake"})
+		return
+	}
+	writeJson(w, http.StatusCreated, map[string]string{})
+}
+
+func fakePem(kind, content string) string {
+	return "-----BEGIN " + kind + "-----\nfake-" + content + "\n-----END " + kind + "-----\n"
+}
@@ -0,0 +1,181 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+const (
+	RunStatusQueued  = "QUEUED"
+	RunStatusRunning = "RUNNING"
+	RunStatusPassed  = "PASSED"
+	RunStatusFailed  = "FAILED"
+)
+
+type jobservRun struct {
+	name      string
+	status    string
+	artifacts map[string]string
+}
+
+type jobservBuild struct {
+	id   int
+	runs []*jobservRun
+}
+
+func (b *jobservBuild) passed() bool {
+	for _, run := range b.runs {
+		if run.status != RunStatusPassed {
+			return false
+		}
+	}
+	return true
+}
+
+// SetJobservRun creates or updates a JobServ run of a given build, creating the build if needed.
+// Artifacts map artifact names to their content; a "console.log" artifact is tailed by fioctl.
+// While a run is queued or running, its console.log is served incrementally as it would be by JobServ.
+func (s *Server) SetJobservRun(build int, run, status string, artifacts map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.findBuild(build)
+	if b == nil {
+		b = &jobservBuild{id: build}
+		s.builds = append(s.builds, b)
+		slices.SortFunc(s.builds, func(x, y *jobservBuild) int { return x.id - y.id })
+	}
+	r := b.findRun(run)
+	if r == nil {
+		r = &jobservRun{name: run, artifacts: make(map[string]string)}
+		b.runs = append(b.runs, r)
+	}
+	r.status = status
+	for name, content := range artifacts {
+		r.artifacts[name] = content
+	}
+}
+
+// AppendJobservLog appends text to the console.log of an existing JobServ run and changes its status.
+func (s *Server) AppendJobservLog(build int, run, status, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.findBuild(build)
+	if b == nil || b.findRun(run) == nil {
+		panic(fmt.Sprintf("No such JobServ run: %d/%s", build, run))
+	}
+	r := b.findRun(run)
+	r.status = status
+	r.artifacts["console.log"] += text
+}
+
+// Must be called with the s.mu held.
+func (s *Server) findBuild(id int) *jobservBuild {
+	for _, b := range s.builds {
+		if b.id == id {
+			return b
+		}
+	}
+	return nil
+}
+
+func (b *jobservBuild) findRun(name string) *jobservRun {
+	for _, r := range b.runs {
+		if r.name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *Server) runUrl(build int, run string) string {
+	return fmt.Sprintf("%s/projects/%s/lmp/builds/%d/runs/%s/", s.URL, s.Factory, build, run)
+}
+
+func (s *Server) jobservRun(build int, r *jobservRun) client.JobservRun {
+	run := client.JobservRun{Name: r.name, Url: s.runUrl(build, r.name), Artifacts: []string{}}
+	for name := range r.artifacts {
+		run.Artifacts = append(run.Artifacts, name)
+	}
+	slices.Sort(run.Artifacts)
+	return run
+}
+
+func (s *Server) registerJobserv(mux *http.ServeMux) {
+	s.route(mux, "GET /projects/{factory}/lmp/builds/latest/{$}", func(w http.ResponseWriter, r *http.Request) {
+		all := r.URL.Query().Get("all") == "1"
+		for _, b := range slices.Backward(s.builds) {
+			if all || b.passed() {
+				writeJson(w, http.StatusOK, map[string]any{
+					"data": map[string]any{"build": client.JobservBuild{ID: b.id}},
+				})
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "No builds found")
+	})
+	s.route(mux, "GET /projects/{factory}/lmp/builds/{build}/runs/{$}", s.withBuild(
+		func(w http.ResponseWriter, r *http.Request, b *jobservBuild) {
+			runs := make([]client.JobservRun, 0, len(b.runs))
+			for _, run := range b.runs {
+				runs = append(runs, s.jobservRun(b.id, run))
+			}
+			writeJson(w, http.StatusOK, map[string]any{"data": map[string]any{"runs": runs}})
+		}))
+	s.route(mux, "GET /projects/{factory}/lmp/builds/{build}/runs/{run}/{$}", s.withRun(
+		func(w http.ResponseWriter, r *http.Request, b *jobservBuild, run *jobservRun) {
+			writeJson(w, http.StatusOK, map[string]any{"data": map[string]any{"run": s.jobservRun(b.id, run)}})
+		}))
+	s.route(mux, "GET /projects/{factory}/lmp/builds/{build}/runs/{run}/{artifact...}", s.withRun(
+		func(w http.ResponseWriter, r *http.Request, b *jobservBuild, run *jobservRun) {
+			name := r.PathValue("artifact")
+			content, ok := run.artifacts[name]
+			if !ok {
+				writeError(w, http.StatusNotFound, "Artifact not found: "+name)
+				return
+			}
+			if name == "console.log" && (run.status == RunStatusQueued || run.status == RunStatusRunning) {
+				// An unfinished run log is streamed from a requested offset along with a run status.
+				// Once a run is finished, the whole log is returned without a status.
+				offset, _ := strconv.Atoi(r.Header.Get("X-OFFSET"))
+				content = content[min(max(offset, 0), len(content)):]
+				w.Header().Set("X-RUN-STATUS", run.status)
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(content))
+		}))
+}
+
+type buildHandler func(w http.ResponseWriter, r *http.Request, b *jobservBuild)
+
+func (s *Server) withBuild(handler buildHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("build"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Build not found: "+r.PathValue("build"))
+			return
+		}
+		b := s.findBuild(id)
+		if b == nil {
+			writeError(w, http.StatusNotFound, "Build not found: "+r.PathValue("build"))
+			return
+		}
+		handler(w, r, b)
+	}
+}
+
+type runHandler func(w http.ResponseWriter, r *http.Request, b *jobservBuild, run *jobservRun)
+
+func (s *Server) withRun(handler runHandler) http.HandlerFunc {
+	return s.withBuild(func(w http.ResponseWriter, r *http.Request, b *jobservBuild) {
+		run := b.findRun(r.PathValue("run"))
+		if run == nil {
+			writeError(w, http.StatusNotFound, "Run not found: "+r.PathValue("run"))
+			return
+		}
+		handler(w, r, b, run)
+	})
+}
@@ -0,0 +1,387 @@
+// Package fake provides an in-memory stand-in for the Foundries.io API server.
+//
+// It implements the subset of OTA and JobServ endpoints used by fioctl, so that the client package,
+// subcommands, and external automation can be tested hermetically:
+//
+//	srv := fake.NewServer("acme")
+//	defer srv.Close()
+//	srv.AddDevice(client.Device{Name: "dev1"})
+//	api := srv.NewApi(client.Config{})
+//	dl, err := api.DeviceList(map[string]string{"factory": "acme"}, "", 1, 10)
+//
+// Faults (latency, 5xx, 429 with a Retry-After) can be injected to exercise retry and error paths.
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tuf "github.com/theupdateframework/notary/tuf/data"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+const (
+	// A token accepted by the server unless changed via Server.Token
+	DefaultToken = "fake-token"
+	// A page size used by list endpoints when a "limit" query parameter is not given
+	DefaultPageSize = 20
+)
+
+// A Fault makes the server misbehave for matching requests.
+type Fault struct {
+	// An HTTP method to match; any method if empty
+	Method string
+	// A URL path prefix to match; any path if empty
+	Path string
+	// How many matching requests to affect; zero means all of them
+	Count int
+
+	// A delay before handling (or failing) a request
+	Latency time.Duration
+	// An HTTP status code to respond with instead of handling a request; zero to only add latency
+	Status int
+	// A value of the Retry-After header sent along with the Status
+	RetryAfter string
+	// A response body sent along with the Status; a generic JSON message is sent if empty
+	Body string
+}
+
+// A Request is a copy of an HTTP request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Server is an httptest.Server holding a state of a single factory in memory.
+// All exported methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// The factory served by this server; requests for other factories get a 404
+	Factory string
+
+	mu sync.Mutex
+	// A token expected in the OSF-TOKEN (or as a bearer in the Authorization) header; any if empty
+	token    string
+	faults   []*Fault
+	requests []Request
+
+	nextId         int
+	devices        []*client.Device
+	deniedDevices  []*client.Device
+	deviceConfigs  map[string][]client.DeviceConfig
+	deviceUpdates  map[string][]client.Update
+	updateEvents   map[string][]client.UpdateEvent
+	appsStates     map[string]client.AppsStates
+	factoryConfigs []client.DeviceConfig
+	groups         []*client.DeviceGroup
+	groupConfigs   map[string][]client.DeviceConfig
+	targets        tuf.Files
+	prodTargets    map[string]client.AtsTufTargets
+	waves          []*client.Wave
+	ciRoots        []client.AtsTufRoot
+	prodRoots      []client.AtsTufRoot
+	certs          *client.CaCerts
+	eventQueues    []client.EventQueue
+	users          []client.FactoryUserAccessDetails
+	teams          []client.FactoryTeamDetails
+	builds         []*jobservBuild
+}
+
+// NewServer starts a fake API server for a given factory.
+// A caller should call Close when finished to shut it down.
+func NewServer(factory string) *Server {
+	s := &Server{
+		Factory:       factory,
+		token:         DefaultToken,
+		deviceConfigs: make(map[string][]client.DeviceConfig),
+		deviceUpdates: make(map[string][]client.Update),
+		updateEvents:  make(map[string][]client.UpdateEvent),
+		appsStates:    make(map[string]client.AppsStates),
+		groupConfigs:  make(map[string][]client.DeviceConfig),
+		targets:       make(tuf.Files),
+		prodTargets:   make(map[string]client.AtsTufTargets),
+	}
+	mux := http.NewServeMux()
+	s.registerDevices(mux)
+	s.registerFactory(mux)
+	s.registerTuf(mux)
+	s.registerJobserv(mux)
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// NewApi creates an API client talking to this server.
+// Unless set in the config, the client authenticates with the server token,
+// and retries requests with a tiny backoff to keep tests fast.
+func (s *Server) NewApi(config client.Config) *client.Api {
+	if len(config.Token) == 0 && config.AuthProvider == nil {
+		config.Token = s.Token()
+	}
+	if config.AuthProvider == nil {
+		config.AuthProvider = &client.TokenAuthProvider{Token: config.Token}
+	}
+	if config.Retry.MinBackoff == 0 {
+		config.Retry.MinBackoff = time.Millisecond
+	}
+	if config.Retry.MaxBackoff == 0 {
+		config.Retry.MaxBackoff = 10 * time.Millisecond
+	}
+	if len(config.Cache.Dir) == 0 {
+		// Never touch a user's cache from tests
+		config.Cache.Disabled = true
+	}
+	config.Factory = s.Factory
+	api, err := client.NewApiClientE(s.URL, config, "", "fake")
+	if err != nil {
+		panic(fmt.Sprintf("Invalid config of a fake API client: %s", err))
+	}
+	return api
+}
+
+// Token returns a token expected by the server.
+func (s *Server) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// SetToken changes a token expected by the server; an empty token disables authentication.
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// InjectFault makes the server misbehave for requests matching the fault.
+// Faults are checked in the order they were injected; the first matching one wins.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns all requests received by the server so far, including failed ones.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests forgets all requests received by the server so far.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		requestId := fmt.Sprintf("fake-%d", len(s.requests))
+		fault := s.matchFault(r)
+		token := s.token
+		s.mu.Unlock()
+
+		w.Header().Set("X-Request-Id", requestId)
+		if fault != nil {
+			if fault.Latency > 0 {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(fault.Latency):
+				}
+			}
+			if fault.Status != 0 {
+				if len(fault.RetryAfter) > 0 {
+					w.Header().Set("Retry-After", fault.RetryAfter)
+				}
+				if len(fault.Body) > 0 {
+					w.WriteHeader(fault.Status)
+					_, _ = w.Write([]byte(fault.Body))
+				} else {
+					writeError(w, fault.Status, "Injected fault: "+http.StatusText(fault.Status))
+				}
+				return
+			}
+		}
+
+		if len(token) > 0 && !authenticated(r, token) {
+			writeError(w, http.StatusUnauthorized, "Invalid or missing API token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Registers a handler which runs with the s.mu held, and only for requests to the served factory.
+// A factory is taken either from a {factory} path wildcard or from a "factory" query parameter.
+func (s *Server) route(mux *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		factory := r.PathValue("factory")
+		if len(factory) == 0 {
+			factory = r.URL.Query().Get("factory")
+		}
+		if factory != s.Factory {
+			writeError(w, http.StatusNotFound, "Factory not found: "+factory)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		handler(w, r)
+	})
+}
+
+// Must be called with the s.mu held.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if len(f.Method) > 0 && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		if f.Count > 0 {
+			if f.Count -= 1; f.Count == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func authenticated(r *http.Request, token string) bool {
+	if r.Header.Get("OSF-TOKEN") == token {
+		return true
+	}
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		decoded, err := base64.StdEncoding.DecodeString(bearer)
+		return err == nil && string(decoded) == token
+	}
+	return false
+}
+
+func (s *Server) newId() int {
+	s.nextId += 1
+	return s.nextId
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Writes a JSON response with an ETag, or a 304 if a client already has it.
+func writeCachedJson(w http.ResponseWriter, r *http.Request, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, map[string]string{"message": message})
+}
+
+func writeFieldErrors(w http.ResponseWriter, status int, message string, errors map[string]string) {
+	writeJson(w, status, map[string]any{"message": message, "errors": errors})
+}
+
+// Decodes a JSON request body, writing a 400 response on failure.
+func readJson(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// Writes a single page of items, the way the API paginates "Next"-linked lists.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, key string, items any, total int) {
+	writeJson(w, http.StatusOK, map[string]any{key: items, "total": total, "next": s.nextPage(r, total)})
+}
+
+// Returns a slice of [start, end) indexes of the current page items, as requested by the page and limit query.
+func pageBounds(r *http.Request, total int) (int, int) {
+	page, limit := pageQuery(r)
+	start := min((page-1)*limit, total)
+	return start, min(start+limit, total)
+}
+
+func pageQuery(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = DefaultPageSize
+	}
+	return page, limit
+}
+
+func (s *Server) nextPage(r *http.Request, total int) *string {
+	page, limit := pageQuery(r)
+	if page*limit >= total {
+		return nil
+	}
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page+1))
+	next := s.URL + r.URL.Path + "?" + query.Encode()
+	return &next
+}
+
+func paginate[T any](s *Server, w http.ResponseWriter, r *http.Request, key string, items []T) {
+	start, end := pageBounds(r, len(items))
+	s.writePage(w, r, key, items[start:end], len(items))
+}
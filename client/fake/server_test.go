@@ -0,0 +1,129 @@
+package fake
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	canonical "github.com/docker/go/canonical/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tuf "github.com/theupdateframework/notary/tuf/data"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+const testFactory = "acme"
+
+func newTestServer(t *testing.T) (*Server, *client.Api) {
+	srv := NewServer(testFactory)
+	t.Cleanup(srv.Close)
+	return srv, srv.NewApi(client.Config{})
+}
+
+func TestOtherFactory(t *testing.T) {
+	_, api := newTestServer(t)
+	_, err := api.FactoryListDeviceGroup("other")
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+}
+
+func TestTargetsAndWaves(t *testing.T) {
+	srv, api := newTestServer(t)
+	srv.AddTarget(client.Target{
+		Length: 1,
+		Custom: &client.TufCustom{Name: "intel-corei7-64-lmp", Version: "42", Tags: []string{"main"}},
+	})
+	srv.AddDevice(client.Device{Name: "dev1", Tag: "main"})
+
+	targets, err := api.TargetsList(testFactory, "42")
+	require.Nil(t, err)
+	require.Contains(t, targets, "intel-corei7-64-lmp-42")
+	custom, err := api.TargetCustom(targets["intel-corei7-64-lmp-42"])
+	require.Nil(t, err)
+	assert.Equal(t, []string{"main"}, custom.Tags)
+
+	prod, err := api.ProdTargetsGet(testFactory, "main", false)
+	require.Nil(t, err)
+	assert.Nil(t, prod)
+
+	signed := canonical.RawMessage(`{}`)
+	wave := client.WaveCreate{Name: "w1", Version: "42", Tag: "main", Targets: tuf.Signed{Signed: &signed}}
+	require.Nil(t, api.FactoryCreateWave(testFactory, &wave))
+	res, err := api.FactoryRolloutWave(testFactory, "w1", client.WaveRolloutOptions{PrintNames: true})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"dev1"}, res.DeviceNames)
+	require.Nil(t, api.FactoryCompleteWave(testFactory, "w1"))
+	assert.True(t, errors.Is(api.FactoryCancelWave(testFactory, "w1"), client.ErrConflict))
+
+	waves, err := client.Collect(api.FactoryListWavesIter(testFactory, 10, "complete", "", client.PaginateOptions{}))
+	require.Nil(t, err)
+	require.Len(t, waves, 1)
+	assert.Len(t, waves[0].History, 1)
+	prod, err = api.ProdTargetsGet(testFactory, "main", true)
+	require.Nil(t, err)
+	assert.NotNil(t, prod)
+}
+
+func TestTufRoots(t *testing.T) {
+	srv, api := newTestServer(t)
+	_, err := api.TufRootGet(testFactory)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+
+	for ver := 1; ver <= 2; ver++ {
+		root := client.AtsTufRoot{}
+		root.Signed.Version = ver
+		srv.AddTufRoot(root, false)
+	}
+	root, err := api.TufRootGet(testFactory)
+	require.Nil(t, err)
+	assert.Equal(t, 2, root.Signed.Version)
+	root, err = api.TufRootGetVer(testFactory, 1)
+	require.Nil(t, err)
+	assert.Equal(t, 1, root.Signed.Version)
+	_, err = api.TufProdRootGet(testFactory)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+}
+
+func TestCA(t *testing.T) {
+	srv, api := newTestServer(t)
+	_, err := api.FactoryGetCA(testFactory)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Factory PKI is not configured")
+
+	csrs, err := api.FactoryCreateCA(testFactory, client.CaCreateOptions{FirstTimeInit: true, CreateTlsCert: true})
+	require.Nil(t, err)
+	assert.NotEmpty(t, csrs.TlsCsr)
+	require.Nil(t, api.FactoryPatchCA(testFactory, client.CaCerts{TlsCrt: "tls"}))
+	assert.Equal(t, "tls", srv.CA().TlsCrt)
+}
+
+func TestJobserv(t *testing.T) {
+	srv, api := newTestServer(t)
+	srv.SetJobservRun(1, "build-amd64", RunStatusPassed, map[string]string{"console.log": "done"})
+	srv.SetJobservRun(2, "build-amd64", RunStatusRunning, map[string]string{"console.log": "step 1\n"})
+
+	build, err := api.JobservLatestBuild(testFactory, true)
+	require.Nil(t, err)
+	assert.Equal(t, 1, build.ID)
+	build, err = api.JobservLatestBuild(testFactory, false)
+	require.Nil(t, err)
+	assert.Equal(t, 2, build.ID)
+
+	runs, err := api.JobservRuns(testFactory, 2)
+	require.Nil(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, []string{"console.log"}, runs[0].Artifacts)
+	run, err := api.JobservRun(runs[0].Url)
+	require.Nil(t, err)
+	assert.Equal(t, "build-amd64", run.Name)
+
+	srv.AppendJobservLog(2, "build-amd64", RunStatusRunning, "step 2\n")
+	headers := map[string]string{"X-OFFSET": "7"}
+	res, err := api.RawGet(runs[0].Url+"console.log", &headers)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, RunStatusRunning, res.Header.Get("X-RUN-STATUS"))
+	assert.Equal(t, "step 2\n", string(body))
+}
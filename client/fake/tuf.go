@@ -0,0 +1,166 @@
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	canonical "github.com/docker/go/canonical/json"
+	tuf "github.com/theupdateframework/notary/tuf/data"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// AddTarget adds a CI target to the factory targets.json, filling in its hashes unless set.
+func (s *Server) AddTarget(t client.Target) {
+	custom, err := json.Marshal(t.Custom)
+	if err != nil {
+		panic(err)
+	}
+	raw := canonical.RawMessage(custom)
+	meta := tuf.FileMeta{Length: t.Length, Hashes: t.Hashes, Custom: &raw}
+	if len(meta.Hashes) == 0 {
+		sum := sha256.Sum256([]byte(t.Name()))
+		meta.Hashes = tuf.Hashes{"sha256": sum[:]}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.targets[t.Name()] = meta
+}
+
+// SetProdTargets sets production targets for a given tag.
+func (s *Server) SetProdTargets(tag string, targets client.AtsTufTargets) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prodTargets[tag] = targets
+}
+
+// AddTufRoot adds a new version of the CI (or production) TUF root.
+func (s *Server) AddTufRoot(root client.AtsTufRoot, prod bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prod {
+		s.prodRoots = append(s.prodRoots, root)
+	} else {
+		s.ciRoots = append(s.ciRoots, root)
+	}
+}
+
+func (s *Server) registerTuf(mux *http.ServeMux) {
+	s.route(mux, "GET /ota/repo/{factory}/api/v1/user_repo/{metadata}", s.getTufMetadata)
+	s.route(mux, "POST /ota/repo/{factory}/api/v1/user_repo/root", func(w http.ResponseWriter, r *http.Request) {
+		var root client.AtsTufRoot
+		if !readJson(w, r, &root) {
+			return
+		}
+		roots := &s.ciRoots
+		if r.URL.Query().Get("production") == "1" {
+			roots = &s.prodRoots
+		}
+		if expected := len(*roots) + 1; root.Signed.Version != expected {
+			writeError(w, http.StatusBadRequest, "Invalid root version, expected: "+strconv.Itoa(expected))
+			return
+		}
+		*roots = append(*roots, root)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	s.route(mux, "GET /ota/factories/{factory}/targets/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, http.StatusOK, s.filterTargets("", r.URL.Query().Get("version")))
+	})
+	s.route(mux, "GET /ota/factories/{factory}/targets/{target}", func(w http.ResponseWriter, r *http.Request) {
+		meta, ok := s.targets[r.PathValue("target")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Target not found: "+r.PathValue("target"))
+			return
+		}
+		writeJson(w, http.StatusOK, meta)
+	})
+	s.route(mux, "GET /ota/factories/{factory}/prod-targets/{$}", func(w http.ResponseWriter, r *http.Request) {
+		res := make(map[string]client.AtsTufTargets)
+		for _, tag := range strings.Split(r.URL.Query().Get("tag"), ",") {
+			if targets, ok := s.prodTargets[tag]; ok {
+				res[tag] = targets
+			}
+		}
+		writeTargetsMap(w, res)
+	})
+	s.route(mux, "GET /ota/factories/{factory}/wave-targets/{$}", func(w http.ResponseWriter, r *http.Request) {
+		res := make(map[string]client.AtsTufTargets)
+		for _, name := range strings.Split(r.URL.Query().Get("name"), ",") {
+			if wave := s.findWave(name); wave != nil && wave.Status == "active" && wave.Targets != nil {
+				var targets client.AtsTufTargets
+				if err := json.Unmarshal(*wave.Targets, &targets); err == nil {
+					res[name] = targets
+				}
+			}
+		}
+		writeTargetsMap(w, res)
+	})
+}
+
+func writeTargetsMap(w http.ResponseWriter, res map[string]client.AtsTufTargets) {
+	if len(res) == 0 {
+		writeError(w, http.StatusNotFound, "No production targets found")
+		return
+	}
+	writeJson(w, http.StatusOK, res)
+}
+
+// Must be called with the s.mu held.
+func (s *Server) filterTargets(tag, version string) tuf.Files {
+	res := make(tuf.Files)
+	for name, meta := range s.targets {
+		var custom client.TufCustom
+		if meta.Custom != nil {
+			_ = json.Unmarshal([]byte(*meta.Custom), &custom)
+		}
+		if len(tag) > 0 && !slices.Contains(custom.Tags, tag) {
+			continue
+		}
+		if len(version) > 0 && custom.Version != version {
+			continue
+		}
+		res[name] = meta
+	}
+	return res
+}
+
+func (s *Server) getTufMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata := r.PathValue("metadata")
+	if metadata == "targets.json" {
+		targets := client.AtsTufTargets{
+			Signatures: []tuf.Signature{},
+			Signed: client.AtsTargetsMeta{
+				SignedCommon: tuf.SignedCommon{
+					Type:    "Targets",
+					Expires: time.Now().UTC().AddDate(1, 0, 0).Truncate(24 * time.Hour),
+					Version: len(s.targets),
+				},
+				Targets: s.filterTargets(r.URL.Query().Get("tag"), ""),
+			},
+		}
+		writeCachedJson(w, r, targets)
+		return
+	}
+
+	roots := s.ciRoots
+	if r.URL.Query().Get("production") == "1" {
+		roots = s.prodRoots
+	}
+	if metadata == "root.json" && len(roots) > 0 {
+		writeCachedJson(w, r, roots[len(roots)-1])
+		return
+	}
+	if ver, ok := strings.CutSuffix(metadata, ".root.json"); ok {
+		if v, err := strconv.Atoi(ver); err == nil && v > 0 && v <= len(roots) {
+			writeJson(w, http.StatusOK, roots[v-1])
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "TUF metadata not found: "+metadata)
+}
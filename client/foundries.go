@@ -2,13 +2,13 @@ package client
 
 import (
 	"bytes"
-	"crypto/tls"
-	"crypto/x509"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"os"
 	"strconv"
@@ -26,6 +26,18 @@ type Config struct {
 	ClientCredentials  OAuthConfig
 	ExtraHeaders       map[string]string
 	InsecureSkipVerify bool
+	Retry              RetryPolicy
+	Server             ServerConfig
+	Auth               AuthConfig
+	Cache              CacheConfig
+	// If not set, a static Token or ClientCredentials are used to authenticate requests
+	AuthProvider AuthProvider `mapstructure:"-"`
+	// If set, receives a (redacted) record of every HTTP exchange with the API server
+	Tracer Tracer `mapstructure:"-"`
+	// If set, receives a record of every request which may change something on the server
+	Auditor Auditor `mapstructure:"-"`
+	// If set, requests other than GET, HEAD, and OPTIONS fail with a DryRunError instead of being sent
+	DryRun bool `mapstructure:"dry_run"`
 }
 
 type Api struct {
@@ -33,6 +45,9 @@ type Api struct {
 	config    Config
 	client    http.Client
 	clientVer string
+	ctx       context.Context
+	auth      AuthProvider
+	cache     *responseCache
 }
 
 type ConfigFile struct {
@@ -305,62 +320,76 @@ type WireGuardIp struct {
 	Enabled bool   `json:"enabled"`
 }
 
-// This is an error returned in case if we've successfully received an HTTP response which contains
-// an unexpected HTTP status code
-type HttpError struct {
-	Message  string
-	Response *http.Response
-}
-
-func (err *HttpError) Error() string {
-	return err.Message
-}
-
-// This is much better than err.(HttpError) as it also accounts for wrapped errors.
-func AsHttpError(err error) *HttpError {
-	var httpError *HttpError
-	if errors.As(err, &httpError) {
-		return httpError
-	} else {
-		return nil
+// NewApiClient creates an API client like NewApiClientE, but exits the program if the server config is invalid.
+//
+// Deprecated: Use NewApiClientE, which returns an error instead.
+func NewApiClient(serverUrl string, config Config, caCertPath string, version string) *Api {
+	api, err := NewApiClientE(serverUrl, config, caCertPath, version)
+	if err != nil {
+		logrus.Fatal(err)
 	}
+	return api
 }
 
-func NewApiClient(serverUrl string, config Config, caCertPath string, version string) *Api {
-	tlsCfg := &tls.Config{
-		InsecureSkipVerify: config.InsecureSkipVerify,
-	}
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = tlsCfg
-	// targets/artifacts.go needs to know the Content-Length in order to
-	// compute the download progress. If certain services like CloudFlare
-	// see the client accepts compressed responsed (content-encoding not
-	// content-type) then it will give a compressed response. Golang will
-	// automagically decompress as you read the response *and* set
-	// content-length to -1 thereby breaking our download progress logic
-	http.DefaultTransport.(*http.Transport).DisableCompression = true
+// NewApiClientE creates an API client with its own HTTP transport configured by config.Server.
+// A non-empty caCertPath takes precedence over the config.Server.CaCert.
+// It returns an error if the server config is invalid, e.g. a CA bundle cannot be read.
+func NewApiClientE(serverUrl string, config Config, caCertPath string, version string) (*Api, error) {
+	serverCfg := config.Server
+	if config.InsecureSkipVerify {
+		serverCfg.InsecureSkipVerify = true
+	}
 	if len(caCertPath) > 0 {
-		rootCAs, _ := x509.SystemCertPool()
-		if rootCAs == nil {
-			rootCAs = x509.NewCertPool()
-		}
-
-		certs, err := os.ReadFile(caCertPath)
-		if err != nil {
-			logrus.Fatalf("Failed to append %q to RootCAs: %v", caCertPath, err)
-		}
-
-		if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
-			logrus.Warning("No certs appended, using system certs only")
-		}
-		tlsCfg.RootCAs = rootCAs
+		serverCfg.CaCert = caCertPath
+	}
+	transport, err := NewTransport(serverCfg)
+	if err != nil {
+		return nil, err
+	}
+	var roundTripper http.RoundTripper = transport
+	if config.Tracer != nil {
+		roundTripper = NewTracingTransport(transport, config.Tracer)
 	}
 	api := Api{
 		serverUrl: strings.TrimRight(serverUrl, "/"),
 		config:    config,
-		client:    *http.DefaultClient,
+		client:    http.Client{Transport: roundTripper, Timeout: serverCfg.Timeout},
 		clientVer: version,
+		auth:      config.AuthProvider,
+		cache:     newResponseCache(config.Cache),
+	}
+	if api.auth == nil {
+		if len(config.Token) > 0 {
+			api.auth = &TokenAuthProvider{Token: config.Token}
+		} else if len(config.ClientCredentials.AccessToken) > 0 {
+			api.auth = &OAuthAuthProvider{Config: config.ClientCredentials}
+		}
+	}
+	return &api, nil
+}
+
+// WithContext returns a shallow copy of the Api with all its requests bound to the given context.
+// This allows a caller to cancel or time-bound any API call, for example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	targets, err := api.WithContext(ctx).TargetsList(factory)
+func (a *Api) WithContext(ctx context.Context) *Api {
+	if ctx == nil {
+		panic("nil context")
+	}
+	copied := *a
+	copied.ctx = ctx
+	return &copied
+}
+
+// Context returns the context all requests of this Api are bound to.
+// It returns a background context if the Api was not bound to any context.
+func (a *Api) Context() context.Context {
+	if a.ctx != nil {
+		return a.ctx
 	}
-	return &api
+	return context.Background()
 }
 
 // GetHttpClient returns a reference to the object's underlying http.Client so that callers may
@@ -369,6 +398,11 @@ func (a *Api) GetHttpClient() *http.Client {
 	return &a.client
 }
 
+// ServerUrl returns the base URL of the API server, e.g. https://api.foundries.io
+func (a *Api) ServerUrl() string {
+	return a.serverUrl
+}
+
 func httpLogger(req *http.Request) logrus.FieldLogger {
 	return logrus.WithFields(logrus.Fields{"url": req.URL.String(), "method": req.Method})
 }
@@ -405,8 +439,15 @@ func readResponse(res *http.Response) (*[]byte, error) {
 		log.Debugf(errBody)
 
 		// Still return a body, a caller might need it, but also return an error
-		msg := fmt.Sprintf("HTTP error during %s '%s': %s",
-			res.Request.Method, res.Request.URL.String(), res.Status)
+		apiErr := &APIError{
+			Method:     res.Request.Method,
+			URL:        res.Request.URL.String(),
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+			RequestID:  requestIdFromHeaders(res.Header),
+			Response:   res,
+		}
+		msg := fmt.Sprintf("HTTP error during %s '%s': %s", apiErr.Method, apiErr.URL, apiErr.Status)
 
 		// Some APIs return well-formatted errors, try to use them
 		var (
@@ -425,26 +466,30 @@ func readResponse(res *http.Response) (*[]byte, error) {
 
 		if merr := json.Unmarshal(body, &listErrors); merr == nil {
 			if listErrors.Msg != "" {
-				msg += "\n= " + listErrors.Msg
+				apiErr.ServerMessage = listErrors.Msg
 			} else if listErrors.Message != "" {
-				msg += "\n= " + listErrors.Message
+				apiErr.ServerMessage = listErrors.Message
 			} else {
 				useGenericError = true
 			}
-			if !useGenericError && listErrors.Errors != nil {
+			if !useGenericError {
+				msg += "\n= " + apiErr.ServerMessage
+				apiErr.Errors = listErrors.Errors
 				for _, emsg := range listErrors.Errors {
 					msg += "\n * " + emsg
 				}
 			}
 		} else if merr = json.Unmarshal(body, &dictErrors); merr == nil {
 			if dictErrors.Msg != "" {
-				msg += "\n= " + dictErrors.Msg
+				apiErr.ServerMessage = dictErrors.Msg
 			} else if dictErrors.Message != "" {
-				msg += "\n= " + dictErrors.Message
+				apiErr.ServerMessage = dictErrors.Message
 			} else {
 				useGenericError = true
 			}
-			if !useGenericError && dictErrors.Errors != nil {
+			if !useGenericError {
+				msg += "\n= " + apiErr.ServerMessage
+				apiErr.FieldErrors = dictErrors.Errors
 				for field, emsg := range dictErrors.Errors {
 					msg += fmt.Sprintf("\n * %s: %s", field, emsg)
 				}
@@ -460,7 +505,11 @@ func readResponse(res *http.Response) (*[]byte, error) {
 				msg += "\n= Error body too long, try to use the --verbose option"
 			}
 		}
-		err = &HttpError{msg, res}
+		if len(apiErr.RequestID) > 0 {
+			msg += "\n= Request ID: " + apiErr.RequestID
+		}
+		apiErr.Message = msg
+		err = apiErr
 	}
 	return &body, err
 }
@@ -480,16 +529,13 @@ func parseJobServResponse(resp *[]byte, err error, runName string) (string, stri
 	return pr.JobServUrl + fmt.Sprintf("runs/%s/console.log", runName), pr.WebUrl, nil
 }
 
-func (a *Api) setReqHeaders(req *http.Request, jsonContent bool) {
+func (a *Api) setReqHeaders(req *http.Request, jsonContent bool) error {
 	req.Header.Set("User-Agent", "fioctl-"+a.clientVer)
 
-	if len(a.config.Token) > 0 {
-		logrus.Debug("Using API token for http request")
-		headerName := os.Getenv("TOKEN_HEADER")
-		if len(headerName) == 0 {
-			headerName = "OSF-TOKEN"
+	if a.auth != nil {
+		if err := a.auth.Authenticate(req); err != nil {
+			return err
 		}
-		req.Header.Set(headerName, a.config.Token)
 	}
 
 	for k, v := range a.config.ExtraHeaders {
@@ -497,48 +543,116 @@ func (a *Api) setReqHeaders(req *http.Request, jsonContent bool) {
 		req.Header.Set(k, v)
 	}
 
-	if len(a.config.ClientCredentials.AccessToken) > 0 {
-		logrus.Debug("Using oauth token for http request")
-		tok := base64.StdEncoding.EncodeToString([]byte(a.config.ClientCredentials.AccessToken))
-		req.Header.Set("Authorization", "Bearer "+tok)
-	}
-
 	if jsonContent {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	return nil
+}
+
+// SetAuthProvider replaces the way this Api authenticates its requests.
+func (a *Api) SetAuthProvider(auth AuthProvider) {
+	a.auth = auth
 }
 
 func (a *Api) GetOauthConfig() OAuthConfig {
 	return a.config.ClientCredentials
 }
 
-func (a *Api) rawMethod(method, url string, data []byte, headers *map[string]string) (*http.Response, error) {
+func (a *Api) newRequest(
+	ctx context.Context, method, url string, data []byte, headers *map[string]string,
+) (*http.Request, error) {
 	var body io.Reader
 	if data != nil {
-		body = bytes.NewBuffer(data)
+		body = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	a.setReqHeaders(req, data != nil)
+	if err = a.setReqHeaders(req, data != nil); err != nil {
+		return nil, err
+	}
 	if headers != nil {
 		for key, val := range *headers {
 			req.Header.Set(key, val)
 		}
 	}
+	return req, nil
+}
 
-	res, err := a.client.Do(req)
-	if err != nil {
+// Returns a DryRunError for a request which would change something on the server.
+func dryRunError(method, url string, data []byte, headers *map[string]string) error {
+	if !isMutatingMethod(method) {
+		return nil
+	}
+	mimeType := "application/json"
+	if headers != nil {
+		for key, val := range *headers {
+			if strings.EqualFold(key, "Content-Type") {
+				mimeType = val
+			}
+		}
+	}
+	body := traceBody(mimeType, data, int64(len(data)))
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(body), "", "  ") == nil {
+		body = indented.String()
+	}
+	return &DryRunError{Method: method, URL: url, Body: body}
+}
 
-		httpLogger(req).Debugf("Network Error: %s", err)
-		return nil, err
+func (a *Api) rawMethod(method, url string, data []byte, headers *map[string]string) (*http.Response, error) {
+	if a.config.DryRun {
+		if err := dryRunError(method, url, data, headers); err != nil {
+			return nil, err
+		}
 	}
+	started := time.Now()
+	res, err := a.sendWithRetries(method, url, data, headers)
+	a.audit(started, method, url, data, res, err)
 	return res, err
 }
 
+func (a *Api) sendWithRetries(method, url string, data []byte, headers *map[string]string) (*http.Response, error) {
+	ctx := a.Context()
+	policy := a.config.Retry.withDefaults()
+	if !policy.allowsMethod(method) {
+		policy.MaxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := a.newRequest(ctx, method, url, data, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := a.client.Do(req)
+		if err != nil {
+			httpLogger(req).Debugf("Network Error: %s", err)
+		}
+		if attempt >= policy.MaxAttempts || !policy.shouldRetry(ctx, res, err) {
+			return res, err
+		}
+
+		wait := policy.backoff(attempt, res)
+		if res != nil {
+			httpLogger(req).Debugf("Retrying after HTTP error %s in %s (attempt %d of %d)",
+				res.Status, wait, attempt+1, policy.MaxAttempts)
+			// Drain the body so that the underlying connection can be reused
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		} else {
+			httpLogger(req).Debugf("Retrying after network error in %s (attempt %d of %d)",
+				wait, attempt+1, policy.MaxAttempts)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
 func (a *Api) RawGet(url string, headers *map[string]string) (*http.Response, error) {
 	return a.rawMethod(http.MethodGet, url, nil, headers)
 }
@@ -644,6 +758,12 @@ func (a *Api) FactoryListConfigCont(url string) (*DeviceConfigList, error) {
 	return a.DeviceListConfigCont(url)
 }
 
+func (a *Api) FactoryListConfigIter(factory string, opts PaginateOptions) iter.Seq2[DeviceConfig, error] {
+	url := a.serverUrl + "/ota/factories/" + factory + "/config/"
+	logrus.Debugf("FactoryListConfigIter with url: %s", url)
+	return a.DeviceListConfigContIter(url, opts)
+}
+
 func (a *Api) GroupCreateConfig(factory, group string, cfg ConfigCreateRequest) error {
 	data, err := json.Marshal(cfg)
 	if err != nil {
@@ -689,6 +809,12 @@ func (a *Api) GroupListConfigCont(url string) (*DeviceConfigList, error) {
 	return a.DeviceListConfigCont(url)
 }
 
+func (a *Api) GroupListConfigIter(factory, group string, opts PaginateOptions) iter.Seq2[DeviceConfig, error] {
+	url := a.serverUrl + "/ota/factories/" + factory + "/device-groups/" + group + "/config/"
+	logrus.Debugf("GroupListConfigIter with url: %s", url)
+	return a.DeviceListConfigContIter(url, opts)
+}
+
 func (a *Api) FactoryStatus(factory string, inactiveThreshold int) (*FactoryStatus, error) {
 	url := fmt.Sprintf("%s/ota/factories/%s/status/?offline-threshold=%d", a.serverUrl, factory, inactiveThreshold)
 	logrus.Debugf("FactoryStatus with url: %s", url)
@@ -718,7 +844,7 @@ func (a *Api) FactoryCreateDeviceGroup(factory string, name string, description
 	logrus.Debugf("Creating new factory device group: %s", url)
 	resp, err := a.Post(url, data)
 	if err != nil {
-		if herr := AsHttpError(err); herr != nil && herr.Response.StatusCode == 409 {
+		if errors.Is(err, ErrConflict) {
 			err = fmt.Errorf("A device group with this name already exists")
 		}
 		return nil, err
@@ -736,7 +862,7 @@ func (a *Api) FactoryDeleteDeviceGroup(factory string, name string) error {
 	url := a.serverUrl + "/ota/factories/" + factory + "/device-groups/" + name + "/"
 	logrus.Debugf("Deleting factory device group: %s", url)
 	_, err := a.Delete(url, nil)
-	if herr := AsHttpError(err); herr != nil && herr.Response.StatusCode == 409 {
+	if errors.Is(err, ErrConflict) {
 		err = fmt.Errorf("There are devices assigned to this device group")
 	}
 	return err
@@ -758,7 +884,7 @@ func (a *Api) FactoryPatchDeviceGroup(factory string, name string, new_name *str
 	url := a.serverUrl + "/ota/factories/" + factory + "/device-groups/" + name + "/"
 	logrus.Debugf("Updating factory device group :%s", url)
 	_, err = a.Patch(url, data)
-	if herr := AsHttpError(err); herr != nil && herr.Response.StatusCode == 409 {
+	if errors.Is(err, ErrConflict) {
 		err = fmt.Errorf("A device group with this name already exists")
 	}
 	return err
@@ -826,6 +952,7 @@ func (a *Api) TufTargetMetadataRefresh(
 	if err != nil {
 		return nil, err
 	}
+	defer a.InvalidateCache(factory)
 	r, err := a.Post(url, b)
 	if err != nil {
 		return nil, err
@@ -840,7 +967,7 @@ func (a *Api) TufTargetMetadataRefresh(
 
 func (a *Api) TargetsListRaw(factory string) (*[]byte, error) {
 	url := a.serverUrl + "/ota/repo/" + factory + "/api/v1/user_repo/targets.json"
-	return a.Get(url)
+	return a.cachedGet(factory, url)
 }
 
 func (a *Api) TargetGet(factory string, targetName string) (*tuf.FileMeta, error) {
@@ -863,7 +990,7 @@ func (a *Api) TargetsList(factory string, version ...string) (tuf.Files, error)
 	if len(version) == 1 {
 		url += "?version=" + version[0]
 	}
-	body, err := a.Get(url)
+	body, err := a.cachedGet(factory, url)
 	if err != nil {
 		return nil, err
 	}
@@ -911,6 +1038,7 @@ func (t *Target) DeriveTarget(newVer int) *Target {
 
 func (a *Api) TargetsPut(factory string, data []byte) (string, string, error) {
 	url := a.serverUrl + "/ota/factories/" + factory + "/targets/"
+	defer a.InvalidateCache(factory)
 	resp, err := a.Put(url, data)
 	if err != nil {
 		return "", "", err
@@ -920,6 +1048,7 @@ func (a *Api) TargetsPut(factory string, data []byte) (string, string, error) {
 
 func (a *Api) TargetsPost(factory string, data []byte) error {
 	url := a.serverUrl + "/ota/factories/" + factory + "/targets/"
+	defer a.InvalidateCache(factory)
 	_, err := a.Post(url, data)
 	return err
 }
@@ -939,6 +1068,7 @@ func (a *Api) TargetUpdateTags(factory string, updates UpdateTargets) (string, s
 	}
 
 	url := a.serverUrl + "/ota/factories/" + factory + "/targets/"
+	defer a.InvalidateCache(factory)
 	resp, err := a.Patch(url, data)
 	return parseJobServResponse(resp, err, "UpdateTargets")
 }
@@ -955,6 +1085,7 @@ func (a *Api) TargetDeleteTargets(factory string, target_names []string) (string
 	}
 
 	url := a.serverUrl + "/ota/factories/" + factory + "/targets/"
+	defer a.InvalidateCache(factory)
 	resp, err := a.Delete(url, data)
 	return parseJobServResponse(resp, err, "UpdateTargets")
 }
@@ -976,8 +1107,8 @@ func (a *Api) TargetComposeApp(factory string, targetName string, app string) (*
 
 	body, err := a.Get(url)
 	if err != nil {
-		if herr := AsHttpError(err); herr != nil {
-			logrus.Debugf("HTTP error %s received, try to parse a partial response", herr.Response.Status)
+		if herr := AsAPIError(err); herr != nil {
+			logrus.Debugf("HTTP error %s received, try to parse a partial response", herr.Status)
 		} else {
 			return nil, err
 		}
@@ -1081,52 +1212,52 @@ func (a *Api) JobservRunArtifact(factory string, build int, run string, artifact
 	return a.RawGet(url, nil)
 }
 
-func (a *Api) JobservTailRun(factory string, build int, run string, artifact string) {
+func (a *Api) JobservTailRun(factory string, build int, run string, artifact string) error {
 	url := a.serverUrl + "/projects/" + factory + "/lmp/builds/" + strconv.Itoa(build) + "/runs/" + run + "/" + artifact
-	a.JobservTail(url)
+	return a.JobservTail(url)
 }
 
-func (a *Api) JobservTail(url string) {
+// JobservTail prints a log of a run until the run completes.
+// It returns an error wrapping the context error if the context is cancelled first, e.g. by a Ctrl-C.
+func (a *Api) JobservTail(url string) error {
+	ctx := a.Context()
 	offset := 0
 	status := ""
 	for {
 		headers := map[string]string{"X-OFFSET": strconv.Itoa(offset)}
 		resp, err := a.RawGet(url, &headers)
 		if err != nil {
-			fmt.Printf("Unable to get '%s': %s\n", url, err)
-			return
+			return fmt.Errorf("Unable to get '%s': %w", url, err)
 		}
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		body, err := readResponse(resp)
 		if err != nil {
-			fmt.Printf("Unable to read response body: %s", err)
-			return
-		}
-		if resp.StatusCode != 200 {
-			fmt.Printf("Unable to get '%s': HTTP_%d\n=%s", url, resp.StatusCode, body)
-			return
+			return err
 		}
 
 		newstatus := resp.Header.Get("X-RUN-STATUS")
 		if newstatus == "QUEUED" {
 			if status == "" {
-				os.Stdout.Write(body)
+				os.Stdout.Write(*body)
 			} else {
 				os.Stdout.WriteString(".")
 			}
 		} else if len(newstatus) == 0 {
-			body = body[offset:]
-			os.Stdout.Write(body)
-			return
+			os.Stdout.Write((*body)[offset:])
+			return nil
 		} else {
 			if newstatus != status {
 				color.New(color.FgGreen).Printf("\n--- Status change: %s -> %s\n", status, newstatus)
 			}
-			os.Stdout.Write(body)
-			offset += len(body)
+			os.Stdout.Write(*body)
+			offset += len(*body)
 		}
 		status = newstatus
-		time.Sleep(5 * time.Second)
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return fmt.Errorf("Stopped tailing '%s': %w", url, ctx.Err())
+		case <-time.After(5 * time.Second):
+		}
 	}
 }
 
@@ -1172,21 +1303,32 @@ func (a *Api) FactoryCreateWave(factory string, wave *WaveCreate) error {
 		return err
 	}
 
+	defer a.InvalidateCache(factory)
 	_, err = a.Post(url, data)
 	return err
 }
 
-func (a *Api) FactoryListWaves(factory string, limit, page uint64, status, tag string) (*WaveList, error) {
-	url := fmt.Sprintf("%s/ota/factories/%s/waves/?limit=%d&page=%d",
-		a.serverUrl, factory, limit, page)
+func factoryListWavesUrl(serverUrl, factory string, limit, page uint64, status, tag string) string {
+	url := fmt.Sprintf("%s/ota/factories/%s/waves/?limit=%d", serverUrl, factory, limit)
+	if page > 0 {
+		url += fmt.Sprintf("&page=%d", page)
+	}
 	if len(status) > 0 {
 		url += "&status=" + status
 	}
 	if len(tag) > 0 {
 		url += "&tag=" + tag
 	}
+	return url
+}
+
+func (a *Api) FactoryListWaves(factory string, limit, page uint64, status, tag string) (*WaveList, error) {
+	url := factoryListWavesUrl(a.serverUrl, factory, limit, page, status, tag)
 	logrus.Debugf("Listing factory waves %s", url)
+	return a.FactoryListWavesCont(url)
+}
 
+func (a *Api) FactoryListWavesCont(url string) (*WaveList, error) {
 	body, err := a.Get(url)
 	if err != nil {
 		return nil, err
@@ -1197,6 +1339,22 @@ func (a *Api) FactoryListWaves(factory string, limit, page uint64, status, tag s
 	return &waves, err
 }
 
+// FactoryListWavesIter lazily iterates over all matching waves, walking as many pages as needed.
+// The limit argument defines a page size.
+func (a *Api) FactoryListWavesIter(
+	factory string, limit uint64, status, tag string, opts PaginateOptions,
+) iter.Seq2[Wave, error] {
+	url := factoryListWavesUrl(a.serverUrl, factory, limit, 0, status, tag)
+	logrus.Debugf("Listing all factory waves %s", url)
+	return Paginate(a.Context(), url, func(ctx context.Context, url string) ([]Wave, *string, error) {
+		wl, err := a.WithContext(ctx).FactoryListWavesCont(url)
+		if err != nil {
+			return nil, nil, err
+		}
+		return wl.Waves, wl.Next, nil
+	}, opts)
+}
+
 func (a *Api) FactoryGetWave(factory string, wave string, showTargets bool) (*Wave, error) {
 	url := a.serverUrl + "/ota/factories/" + factory + "/waves/" + wave + "/"
 	if showTargets {
@@ -1223,6 +1381,7 @@ func (a *Api) FactorySignWave(factory string, wave string, signatures []tuf.Sign
 		return err
 	}
 
+	defer a.InvalidateCache(factory)
 	_, err = a.Post(url, data)
 	return err
 }
@@ -1238,6 +1397,7 @@ func (a *Api) FactoryRolloutWave(
 		return nil, err
 	}
 
+	defer a.InvalidateCache(factory)
 	body, err := a.Post(url, data)
 	if err != nil {
 		return nil, err
@@ -1251,6 +1411,7 @@ func (a *Api) FactoryRolloutWave(
 func (a *Api) FactoryCancelWave(factory string, wave string) error {
 	url := a.serverUrl + "/ota/factories/" + factory + "/waves/" + wave + "/cancel/"
 	logrus.Debugf("Canceling factory wave %s", url)
+	defer a.InvalidateCache(factory)
 	_, err := a.Post(url, nil)
 	return err
 }
@@ -1258,6 +1419,7 @@ func (a *Api) FactoryCancelWave(factory string, wave string) error {
 func (a *Api) FactoryCompleteWave(factory string, wave string) error {
 	url := a.serverUrl + "/ota/factories/" + factory + "/waves/" + wave + "/complete/"
 	logrus.Debugf("Completing factory wave %s", url)
+	defer a.InvalidateCache(factory)
 	_, err := a.Post(url, nil)
 	return err
 }
@@ -1281,20 +1443,20 @@ func (a *Api) FactoryWaveStatus(factory string, wave string, inactiveThreshold i
 func (a *Api) ProdTargetsList(factory string, failNotExist bool, tags ...string) (map[string]AtsTufTargets, error) {
 	url := a.serverUrl + "/ota/factories/" + factory + "/prod-targets/?tag=" + strings.Join(tags, ",")
 	logrus.Debugf("Fetching factory production targets %s", url)
-	return a.prodTargetsList(url, failNotExist)
+	return a.prodTargetsList(factory, url, failNotExist)
 }
 
 func (a *Api) WaveTargetsList(factory string, failNotExist bool, names ...string) (map[string]AtsTufTargets, error) {
 	url := a.serverUrl + "/ota/factories/" + factory + "/wave-targets/?name=" + strings.Join(names, ",")
 	logrus.Debugf("Fetching factory production wave targets %s", url)
-	return a.prodTargetsList(url, failNotExist)
+	return a.prodTargetsList(factory, url, failNotExist)
 }
 
-func (a *Api) prodTargetsList(url string, failNotExist bool) (map[string]AtsTufTargets, error) {
-	body, err := a.Get(url)
+func (a *Api) prodTargetsList(factory, url string, failNotExist bool) (map[string]AtsTufTargets, error) {
+	body, err := a.cachedGet(factory, url)
 	if err != nil {
 		if !failNotExist {
-			if herr := AsHttpError(err); herr != nil && herr.Response.StatusCode == 404 {
+			if errors.Is(err, ErrNotFound) {
 				return nil, nil
 			}
 		}
@@ -1,9 +1,11 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	netUrl "net/url"
 	"strconv"
 	"strings"
@@ -147,6 +149,12 @@ func (a *Api) DeviceApiByUuid(factory, uuid string) DeviceApi {
 	}
 }
 
+// WithContext returns a copy of the DeviceApi with all its requests bound to the given context.
+func (d DeviceApi) WithContext(ctx context.Context) DeviceApi {
+	d.api = d.api.WithContext(ctx)
+	return d
+}
+
 type DeviceList struct {
 	Devices []Device `json:"devices"`
 	Total   int      `json:"total"`
@@ -204,8 +212,7 @@ func (a *DeviceApi) Get() (*Device, error) {
 	return &d, nil
 }
 
-func (a *Api) DeviceList(filterBy map[string]string, sortBy string, page, limit uint64) (*DeviceList, error) {
-	url := a.serverUrl + "/ota/devices/?"
+func deviceListQuery(filterBy map[string]string, sortBy string, page, limit uint64) string {
 	query := netUrl.Values{}
 	for key, val := range filterBy {
 		if len(val) > 0 {
@@ -219,11 +226,25 @@ func (a *Api) DeviceList(filterBy map[string]string, sortBy string, page, limit
 		query.Set("page", strconv.FormatUint(page, 10))
 	}
 	query.Set("limit", strconv.FormatUint(limit, 10))
-	url += query.Encode()
+	return query.Encode()
+}
+
+func (a *Api) DeviceList(filterBy map[string]string, sortBy string, page, limit uint64) (*DeviceList, error) {
+	url := a.serverUrl + "/ota/devices/?" + deviceListQuery(filterBy, sortBy, page, limit)
 	logrus.Debugf("DeviceList with url: %s", url)
 	return a.DeviceListCont(url)
 }
 
+// DeviceListIter lazily iterates over all devices matching the filters, walking as many pages as needed.
+// The limit argument defines a page size.
+func (a *Api) DeviceListIter(
+	filterBy map[string]string, sortBy string, limit uint64, opts PaginateOptions,
+) iter.Seq2[Device, error] {
+	url := a.serverUrl + "/ota/devices/?" + deviceListQuery(filterBy, sortBy, 1, limit)
+	logrus.Debugf("DeviceListIter with url: %s", url)
+	return a.DeviceListContIter(url, opts)
+}
+
 func (a *Api) DeviceListCont(url string) (*DeviceList, error) {
 	logrus.Debugf("DeviceListCont with url: %s", url)
 	body, err := a.Get(url)
@@ -239,12 +260,28 @@ func (a *Api) DeviceListCont(url string) (*DeviceList, error) {
 	return &devices, nil
 }
 
+func (a *Api) DeviceListContIter(url string, opts PaginateOptions) iter.Seq2[Device, error] {
+	return Paginate(a.Context(), url, func(ctx context.Context, url string) ([]Device, *string, error) {
+		dl, err := a.WithContext(ctx).DeviceListCont(url)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dl.Devices, dl.Next, nil
+	}, opts)
+}
+
 func (a *Api) DeviceListDenied(factory string, page, limit uint64) (*DeviceList, error) {
 	url := a.serverUrl + "/ota/factories/" + factory + "/denied-devices/"
 	url += fmt.Sprintf("?limit=%d&page=%d", limit, page)
 	return a.DeviceListCont(url)
 }
 
+func (a *Api) DeviceListDeniedIter(factory string, limit uint64, opts PaginateOptions) iter.Seq2[Device, error] {
+	url := a.serverUrl + "/ota/factories/" + factory + "/denied-devices/"
+	url += fmt.Sprintf("?limit=%d", limit)
+	return a.DeviceListContIter(url, opts)
+}
+
 func (d *DeviceApi) url(resource string) string {
 	url := d.api.serverUrl + "/ota/devices/" + d.id
 	url += resource
@@ -319,6 +356,17 @@ func (d *DeviceApi) ListUpdatesCont(url string) (*UpdateList, error) {
 	return &updates, nil
 }
 
+func (d *DeviceApi) ListUpdatesIter(opts PaginateOptions) iter.Seq2[Update, error] {
+	return Paginate(d.api.Context(), d.url("/updates/"), func(ctx context.Context, url string) ([]Update, *string, error) {
+		dapi := d.WithContext(ctx)
+		ul, err := dapi.ListUpdatesCont(url)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ul.Updates, ul.Next, nil
+	}, opts)
+}
+
 func (d *DeviceApi) UpdateEvents(correlationId string) ([]UpdateEvent, error) {
 	var events []UpdateEvent
 	body, err := d.api.Get(d.url("/updates/" + correlationId + "/"))
@@ -363,6 +411,12 @@ func (d *DeviceApi) ListConfig() (*DeviceConfigList, error) {
 	return d.api.DeviceListConfigCont(url)
 }
 
+func (d *DeviceApi) ListConfigIter(opts PaginateOptions) iter.Seq2[DeviceConfig, error] {
+	url := d.url("/config/")
+	logrus.Debugf("DeviceListConfigIter with url: %s", url)
+	return d.api.DeviceListConfigContIter(url, opts)
+}
+
 func (a *Api) DeviceListConfigCont(url string) (*DeviceConfigList, error) {
 	body, err := a.Get(url)
 	if err != nil {
@@ -377,6 +431,16 @@ func (a *Api) DeviceListConfigCont(url string) (*DeviceConfigList, error) {
 	return &config, nil
 }
 
+func (a *Api) DeviceListConfigContIter(url string, opts PaginateOptions) iter.Seq2[DeviceConfig, error] {
+	return Paginate(a.Context(), url, func(ctx context.Context, url string) ([]DeviceConfig, *string, error) {
+		dcl, err := a.WithContext(ctx).DeviceListConfigCont(url)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dcl.Configs, dcl.Next, nil
+	}, opts)
+}
+
 func (d *DeviceApi) DeleteConfig(filename string) error {
 	url := d.url("/config/" + filename + "/")
 	logrus.Debugf("Deleting config file: %s", url)
@@ -405,6 +469,12 @@ func (d *DeviceApi) Tests() (*TargetTestList, error) {
 	return ttl.NextPage()
 }
 
+func (d *DeviceApi) TestsIter(opts PaginateOptions) iter.Seq2[TargetTest, error] {
+	url := d.url("/tests/")
+	logrus.Debugf("Device.TestsIter with url: %s", url)
+	return d.api.targetTestsIter(url, opts)
+}
+
 func (d *DeviceApi) TestGet(testId string) (*TargetTest, error) {
 	url := d.url("/tests/" + testId)
 	logrus.Debugf("DeviceTriggerResults with url: %s", url)
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"iter"
 	"strconv"
 
 	"github.com/sirupsen/logrus"
@@ -43,6 +45,12 @@ func (a Api) TargetTestingApi(factory string) TargetTestingApi {
 	return TargetTestingApi{api: a, factory: factory}
 }
 
+// WithContext returns a copy of the TargetTestingApi with all its requests bound to the given context.
+func (a TargetTestingApi) WithContext(ctx context.Context) TargetTestingApi {
+	a.api = *a.api.WithContext(ctx)
+	return a
+}
+
 // Return a list of Targets that have been tested
 func (a TargetTestingApi) Versions() ([]int, error) {
 	url := a.api.serverUrl + "/ota/factories/" + a.factory + "/testing/"
@@ -70,6 +78,22 @@ func (a TargetTestingApi) Tests(target int) (*TargetTestList, error) {
 	return ttl.NextPage()
 }
 
+func (a TargetTestingApi) TestsIter(target int, opts PaginateOptions) iter.Seq2[TargetTest, error] {
+	url := a.api.serverUrl + "/ota/factories/" + a.factory + "/targets/" + strconv.Itoa(target) + "/testing/"
+	logrus.Debugf("TargetTestsIter with url: %s", url)
+	return a.api.targetTestsIter(url, opts)
+}
+
+func (a *Api) targetTestsIter(url string, opts PaginateOptions) iter.Seq2[TargetTest, error] {
+	return Paginate(a.Context(), url, func(ctx context.Context, url string) ([]TargetTest, *string, error) {
+		ttl, err := TargetTestList{api: *a.WithContext(ctx), Next: &url}.NextPage()
+		if err != nil {
+			return nil, nil, err
+		}
+		return ttl.Tests, ttl.Next, nil
+	}, opts)
+}
+
 func (t TargetTestList) NextPage() (*TargetTestList, error) {
 	if t.Next == nil {
 		return nil, nil
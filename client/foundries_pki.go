@@ -6,6 +6,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/sirupsen/logrus"
 )
@@ -42,7 +43,7 @@ func (a *Api) FactoryGetCA(factory string) (CaCerts, error) {
 
 	body, err := a.Get(url)
 	if err != nil {
-		if herr := AsHttpError(err); herr != nil && herr.Response.StatusCode == 206 {
+		if herr := AsAPIError(err); herr != nil && herr.StatusCode == http.StatusPartialContent {
 			err = errors.New("Factory PKI is not configured. Please, see `fioctl keys ca create`.")
 		}
 		return resp, err
@@ -0,0 +1,152 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+)
+
+const testFactory = "acme"
+
+func newTestServer(t *testing.T) *fake.Server {
+	srv := fake.NewServer(testFactory)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func addTestDevices(srv *fake.Server, count int) {
+	for i := 1; i <= count; i++ {
+		srv.AddDevice(client.Device{Name: fmt.Sprintf("device-%02d", i), Tag: "main"})
+	}
+}
+
+func TestConfigs(t *testing.T) {
+	srv := newTestServer(t)
+	api := srv.NewApi(client.Config{})
+	srv.AddDeviceGroup(client.DeviceGroup{Name: "grp"})
+
+	require.Nil(t, api.GroupCreateConfig(testFactory, "grp", client.ConfigCreateRequest{
+		Reason: "initial",
+		Files:  []client.ConfigFile{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+	}))
+	require.Nil(t, api.GroupPatchConfig(testFactory, "grp", client.ConfigCreateRequest{
+		Reason: "patch",
+		Files:  []client.ConfigFile{{Name: "b", Value: "3"}},
+	}, false))
+	require.Nil(t, api.GroupDeleteConfig(testFactory, "grp", "a"))
+	err := api.GroupDeleteConfig(testFactory, "grp", "a")
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+
+	configs, err := client.Collect(api.GroupListConfigIter(testFactory, "grp", client.PaginateOptions{}))
+	require.Nil(t, err)
+	require.Len(t, configs, 3)
+	assert.Equal(t, []client.ConfigFile{{Name: "b", Value: "3"}}, configs[0].Files)
+	assert.Equal(t, "initial", configs[2].Reason)
+}
+
+func TestDryRun(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDevice(client.Device{Name: "dev"})
+	api := srv.NewApi(client.Config{DryRun: true})
+
+	_, err := api.DeviceList(map[string]string{"factory": testFactory}, "", 1, 10)
+	require.Nil(t, err)
+
+	dapi := api.DeviceApiByName(testFactory, "dev")
+	err = dapi.CreateConfig(client.ConfigCreateRequest{
+		Reason: "test",
+		Files:  []client.ConfigFile{{Name: "plain", Value: "plain-secret", Unencrypted: true}},
+	})
+	require.True(t, errors.Is(err, client.ErrDryRun))
+	var dryRun *client.DryRunError
+	require.True(t, errors.As(err, &dryRun))
+	assert.Equal(t, "POST", dryRun.Method)
+	assert.Contains(t, dryRun.Body, `"reason": "test"`)
+	assert.NotContains(t, dryRun.Body, "plain-secret")
+
+	require.Len(t, srv.Requests(), 1)
+	_, found := srv.Device("dev")
+	assert.True(t, found)
+}
+
+type testAuditor struct {
+	entries []client.AuditEntry
+}
+
+func (a *testAuditor) Audit(entry client.AuditEntry) {
+	a.entries = append(a.entries, entry)
+}
+
+func TestAudit(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDevice(client.Device{Name: "dev"})
+	auditor := &testAuditor{}
+	api := srv.NewApi(client.Config{Auditor: auditor})
+
+	// Read-only requests are not audited
+	dapi := api.DeviceApiByName(testFactory, "dev")
+	_, err := dapi.Get()
+	require.Nil(t, err)
+	require.Len(t, auditor.entries, 0)
+
+	require.Nil(t, dapi.Rename("dev2"))
+	missing := api.DeviceApiByName(testFactory, "missing")
+	require.NotNil(t, missing.Delete())
+	require.Len(t, auditor.entries, 2)
+	assert.Equal(t, "PATCH", auditor.entries[0].Method)
+	assert.Equal(t, http.StatusOK, auditor.entries[0].StatusCode)
+	assert.Len(t, auditor.entries[0].BodySha256, 64)
+	assert.Equal(t, "DELETE", auditor.entries[1].Method)
+	assert.Equal(t, http.StatusNotFound, auditor.entries[1].StatusCode)
+	assert.Contains(t, auditor.entries[1].URL, "/ota/devices/missing/")
+	assert.Empty(t, auditor.entries[1].BodySha256)
+
+	// Nothing is sent by a dry run, so there is nothing to audit
+	api = srv.NewApi(client.Config{Auditor: auditor, DryRun: true})
+	dapi = api.DeviceApiByName(testFactory, "dev2")
+	require.NotNil(t, dapi.Delete())
+	require.Len(t, auditor.entries, 2)
+}
+
+func TestJobservTail(t *testing.T) {
+	srv := newTestServer(t)
+	status := "RUNNING"
+	jobserv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing/console.log":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			if len(status) > 0 {
+				w.Header().Set("X-RUN-STATUS", status)
+			}
+			_, _ = w.Write([]byte("log line\n"))
+		}
+	}))
+	defer jobserv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	api := srv.NewApi(client.Config{}).WithContext(ctx)
+	time.AfterFunc(100*time.Millisecond, cancel)
+	err := api.JobservTail(jobserv.URL + "/run/console.log")
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	api = srv.NewApi(client.Config{})
+	err = api.JobservTail(jobserv.URL + "/missing/console.log")
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+
+	// A run is complete once there is no status
+	status = ""
+	require.Nil(t, api.JobservTail(jobserv.URL+"/run/console.log"))
+}
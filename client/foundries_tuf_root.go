@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -136,6 +137,7 @@ func (a *Api) TufProdRootPost(factory string, root []byte) (string, error) {
 func (a *Api) TufRootUpdatesApply(factory, txid string) (err error) {
 	url := a.serverUrl + "/ota/repo/" + factory + "/api/v1/user_repo/root/updates/apply"
 	data, _ := json.Marshal(map[string]string{"txid": txid})
+	defer a.InvalidateCache(factory)
 	_, err = a.Post(url, data)
 	return
 }
@@ -178,7 +180,7 @@ func (a *Api) TufRootUpdatesInit(
 	})
 	if body, err = a.Post(url, data); err == nil {
 		err = json.Unmarshal(*body, &res)
-	} else if herr := AsHttpError(err); herr != nil && herr.Response.StatusCode == 409 {
+	} else if herr := AsAPIError(err); herr != nil && errors.Is(herr, ErrConflict) {
 		herr.Message += "\n=Only one TUF root updates transaction can be active at a time"
 	}
 	return
@@ -209,7 +211,7 @@ func (a *Api) tufRootGet(factory string, prod bool, version int) (*AtsTufRoot, e
 		url += "?production=1"
 	}
 	logrus.Debugf("Fetch root %s", url)
-	body, err := a.Get(url)
+	body, err := a.cachedGet(factory, url)
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +225,7 @@ func (a *Api) tufRootPost(factory string, prod bool, root []byte) (string, error
 	if prod {
 		url += "?production=1"
 	}
+	defer a.InvalidateCache(factory)
 	body, err := a.Post(url, root)
 	if body != nil {
 		return string(*body), err
@@ -18,7 +18,7 @@ type FactoryUserAccessDetails struct {
 
 func (a *Api) UsersList(factory string) ([]FactoryUser, error) {
 	url := a.serverUrl + "/ota/factories/" + factory + "/users/"
-	body, err := a.Get(url)
+	body, err := a.cachedGet(factory, url)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -31,6 +32,9 @@ type OAuthConfig struct {
 type ClientCredentials struct {
 	Config      OAuthConfig
 	InsecureSSL bool
+
+	ctx    context.Context
+	tracer Tracer
 }
 
 type Org struct {
@@ -73,17 +77,29 @@ func (c *ClientCredentials) updateConfig(r OAuthResponse) {
 
 // Perform a POST request.
 func (c *ClientCredentials) post(uri string, data url.Values) (*[]byte, error) {
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: c.InsecureSSL,
-			},
+	var transport http.RoundTripper = &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: c.InsecureSSL,
 		},
 	}
-	res, err := client.PostForm(uri, data)
+	if c.tracer != nil {
+		transport = NewTracingTransport(transport, c.tracer)
+	}
+	client := &http.Client{Transport: transport}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
@@ -181,9 +197,19 @@ func (c *ClientCredentials) Get() error {
 	return nil
 }
 
+// SetContext binds all token requests to the given context.
+func (c *ClientCredentials) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// SetTracer makes all token requests recorded by the given tracer.
+func (c *ClientCredentials) SetTracer(tracer Tracer) {
+	c.tracer = tracer
+}
+
 func NewClientCredentials(c OAuthConfig) ClientCredentials {
 	if len(c.URL) == 0 {
 		c.URL = OauthURL
 	}
-	return ClientCredentials{c, false}
+	return ClientCredentials{Config: c}
 }
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// A PageFetcher fetches a single page of a "Next"-linked list endpoint.
+// It returns the page items and a URL of the next page (nil for the last page).
+type PageFetcher[T any] func(ctx context.Context, url string) ([]T, *string, error)
+
+type PaginateOptions struct {
+	// Stop after yielding this many items; zero means no limit.
+	Limit int
+	// Fetch the next page in the background while the current page is being consumed.
+	Prefetch bool
+}
+
+type fetchedPage[T any] struct {
+	items []T
+	next  *string
+	err   error
+}
+
+// Paginate lazily yields all items of a "Next"-linked list endpoint starting from a given URL.
+// Pages are fetched on demand, so breaking out of the loop stops fetching further pages.
+// An error is yielded once with a zero item, after which the iteration stops.
+func Paginate[T any](ctx context.Context, url string, fetch PageFetcher[T], opts PaginateOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		// Cancel a background prefetch of a page nobody is going to consume
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		get := func(url string) <-chan fetchedPage[T] {
+			ch := make(chan fetchedPage[T], 1)
+			do := func() {
+				items, next, err := fetch(ctx, url)
+				ch <- fetchedPage[T]{items, next, err}
+			}
+			if opts.Prefetch {
+				go do()
+			} else {
+				do()
+			}
+			return ch
+		}
+
+		count := 0
+		pending := get(url)
+		for pending != nil {
+			page := <-pending
+			if page.err != nil {
+				var zero T
+				yield(zero, page.err)
+				return
+			}
+			pending = nil
+			limitReached := opts.Limit > 0 && count+len(page.items) >= opts.Limit
+			if opts.Prefetch && page.next != nil && !limitReached {
+				pending = get(*page.next)
+			}
+			for _, item := range page.items {
+				if !yield(item, nil) {
+					return
+				}
+				if count += 1; opts.Limit > 0 && count >= opts.Limit {
+					return
+				}
+			}
+			if !opts.Prefetch && page.next != nil {
+				pending = get(*page.next)
+			}
+		}
+	}
+}
+
+// Collect gathers all items yielded by a paginated iterator, stopping at the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
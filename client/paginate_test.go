@@ -0,0 +1,61 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+)
+
+func TestPaginate(t *testing.T) {
+	srv := newTestServer(t)
+	addTestDevices(srv, 25)
+	api := srv.NewApi(client.Config{})
+	filter := map[string]string{"factory": testFactory}
+
+	for _, prefetch := range []bool{false, true} {
+		srv.ResetRequests()
+		devices, err := client.Collect(api.DeviceListIter(filter, "name", 10, client.PaginateOptions{Prefetch: prefetch}))
+		require.Nil(t, err)
+		require.Len(t, devices, 25)
+		assert.Equal(t, "device-01", devices[0].Name)
+		assert.Equal(t, "device-25", devices[24].Name)
+		assert.Len(t, srv.Requests(), 3)
+	}
+
+	srv.ResetRequests()
+	devices, err := client.Collect(api.DeviceListIter(filter, "-name", 10, client.PaginateOptions{Limit: 5}))
+	require.Nil(t, err)
+	require.Len(t, devices, 5)
+	assert.Equal(t, "device-25", devices[0].Name)
+	assert.Len(t, srv.Requests(), 1)
+
+	srv.ResetRequests()
+	count := 0
+	for _, err := range api.DeviceListIter(filter, "", 10, client.PaginateOptions{}) {
+		require.Nil(t, err)
+		if count += 1; count == 12 {
+			break
+		}
+	}
+	assert.Len(t, srv.Requests(), 2)
+}
+
+func TestPaginateError(t *testing.T) {
+	srv := newTestServer(t)
+	addTestDevices(srv, 25)
+	api := srv.NewApi(client.Config{Retry: client.RetryPolicy{MaxAttempts: 1}})
+
+	// Fail the second page only
+	srv.InjectFault(fake.Fault{Path: "/ota/devices/", Count: 1})
+	srv.InjectFault(fake.Fault{Path: "/ota/devices/", Status: http.StatusInternalServerError, Count: 1})
+	devices, err := client.Collect(api.DeviceListIter(map[string]string{"factory": testFactory}, "", 10, client.PaginateOptions{}))
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, client.ErrServer))
+	assert.Len(t, devices, 10)
+}
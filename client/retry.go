@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryMinBackoff  = 1 * time.Second
+	DefaultRetryMaxBackoff  = 30 * time.Second
+
+	// A server may ask us to wait for a very long time; we do not want to hang forever.
+	maxRetryAfter = 5 * time.Minute
+)
+
+// RetryPolicy controls how the Api retries requests failed with a transient error.
+// A request is retried on a network error and on the HTTP 429, 502, 503, and 504 status codes.
+// Only idempotent requests are retried unless RetryNonIdempotent is set.
+// Zero values are replaced with the defaults; set MaxAttempts to 1 to disable retries.
+type RetryPolicy struct {
+	MaxAttempts        int           `mapstructure:"max_attempts"`
+	MinBackoff         time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff         time.Duration `mapstructure:"max_backoff"`
+	RetryNonIdempotent bool          `mapstructure:"non_idempotent"`
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = DefaultRetryMinBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	return p
+}
+
+func (p RetryPolicy) allowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return p.RetryNonIdempotent
+	}
+}
+
+// shouldRetry decides if a given attempt result is transient and worth another try.
+func (p RetryPolicy) shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before the next attempt (counting from 1).
+// An exponential backoff with an "equal jitter" is used, unless a server tells us otherwise.
+func (p RetryPolicy) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if wait, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return wait
+		}
+	}
+	wait := p.MinBackoff
+	for i := 1; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func parseRetryAfter(val string) (time.Duration, bool) {
+	if len(val) == 0 {
+		return 0, false
+	}
+	var wait time.Duration
+	if secs, err := strconv.Atoi(val); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(val); err == nil {
+		wait = time.Until(at)
+	} else {
+		return 0, false
+	}
+	if wait < 0 {
+		wait = 0
+	} else if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait, true
+}
+
+// sleepContext waits for a given duration, returning early with an error if the context is done.
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
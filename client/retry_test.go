@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+)
+
+func TestRetryTransientErrors(t *testing.T) {
+	srv := newTestServer(t)
+	addTestDevices(srv, 1)
+	api := srv.NewApi(client.Config{})
+
+	srv.InjectFault(fake.Fault{Path: "/ota/devices/", Status: http.StatusServiceUnavailable, Count: 2})
+	dl, err := api.DeviceList(map[string]string{"factory": testFactory}, "", 1, 10)
+	require.Nil(t, err)
+	assert.Equal(t, 1, dl.Total)
+	assert.Len(t, srv.Requests(), 3)
+
+	srv.ResetRequests()
+	srv.InjectFault(fake.Fault{Path: "/ota/devices/", Status: http.StatusBadGateway})
+	_, err = api.DeviceList(map[string]string{"factory": testFactory}, "", 1, 10)
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, client.ErrServer))
+	assert.Len(t, srv.Requests(), client.DefaultRetryMaxAttempts)
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv := newTestServer(t)
+	addTestDevices(srv, 1)
+	api := srv.NewApi(client.Config{})
+
+	srv.InjectFault(fake.Fault{Status: http.StatusTooManyRequests, RetryAfter: "1", Count: 1})
+	start := time.Now()
+	_, err := api.DeviceList(map[string]string{"factory": testFactory}, "", 1, 10)
+	require.Nil(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Len(t, srv.Requests(), 2)
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	srv := newTestServer(t)
+	api := srv.NewApi(client.Config{})
+	description := "test group"
+
+	srv.InjectFault(fake.Fault{Method: http.MethodPost, Status: http.StatusServiceUnavailable, Count: 1})
+	_, err := api.FactoryCreateDeviceGroup(testFactory, "grp", &description)
+	require.NotNil(t, err)
+	assert.Len(t, srv.Requests(), 1)
+
+	api = srv.NewApi(client.Config{Retry: client.RetryPolicy{RetryNonIdempotent: true}})
+	srv.ResetRequests()
+	srv.InjectFault(fake.Fault{Method: http.MethodPost, Status: http.StatusServiceUnavailable, Count: 1})
+	grp, err := api.FactoryCreateDeviceGroup(testFactory, "grp", &description)
+	require.Nil(t, err)
+	assert.Equal(t, "grp", grp.Name)
+	assert.Len(t, srv.Requests(), 2)
+}
+
+func TestRetryCanceled(t *testing.T) {
+	srv := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	api := srv.NewApi(client.Config{}).WithContext(ctx)
+
+	srv.InjectFault(fake.Fault{Latency: time.Second})
+	_, err := api.DeviceList(map[string]string{"factory": testFactory}, "", 1, 10)
+	require.NotNil(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Len(t, srv.Requests(), 1)
+}
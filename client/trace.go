@@ -0,0 +1,284 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// A replacement of secret values in traced requests and responses
+	TraceRedacted = "REDACTED"
+
+	// Bodies larger than this are truncated (text) or elided (JSON and binary) in traces
+	traceBodyLimit = 256 * 1024
+)
+
+// A Tracer receives a record of each HTTP exchange made by an API client.
+// Secrets are already redacted from the entry by the time it is passed to a Tracer.
+// A Tracer must be safe for concurrent use.
+type Tracer interface {
+	Trace(entry TraceEntry)
+}
+
+type TraceMessage struct {
+	Header http.Header
+	// A (possibly redacted, truncated, or elided) body
+	Body string
+	// A size of the original body
+	BodySize int64
+	// A Content-Type of the original body
+	MimeType string
+}
+
+type TraceRequest struct {
+	Method string
+	URL    string
+	TraceMessage
+}
+
+type TraceResponse struct {
+	StatusCode int
+	Status     string
+	TraceMessage
+}
+
+type TraceEntry struct {
+	Started time.Time
+	// A time until the response headers were received
+	Wait time.Duration
+	// A time until the response body was consumed
+	Duration time.Duration
+	Request  TraceRequest
+	// Nil if the request failed without a response
+	Response *TraceResponse
+	Error    string
+}
+
+// NewTracingTransport wraps an HTTP transport so that all exchanges made through it are passed to a tracer.
+// A response is traced once its body is fully read or closed.
+func NewTracingTransport(base http.RoundTripper, tracer Tracer) http.RoundTripper {
+	return &tracingTransport{base: base, tracer: tracer}
+}
+
+type tracingTransport struct {
+	base   http.RoundTripper
+	tracer Tracer
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := TraceEntry{
+		Started: time.Now(),
+		Request: TraceRequest{Method: req.Method, URL: req.URL.String()},
+	}
+	entry.Request.Header = redactHeader(req.Header)
+	entry.Request.MimeType = req.Header.Get("Content-Type")
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var data []byte
+		var err error
+		if req.GetBody != nil {
+			var body io.ReadCloser
+			if body, err = req.GetBody(); err == nil {
+				data, err = io.ReadAll(body)
+				body.Close()
+			}
+		} else {
+			// Consume the body, and give a transport its copy
+			data, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			req.Body = io.NopCloser(bytes.NewReader(data))
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry.Request.BodySize = int64(len(data))
+		entry.Request.Body = traceBody(entry.Request.MimeType, data, int64(len(data)))
+	}
+
+	res, err := t.base.RoundTrip(req)
+	entry.Wait = time.Since(entry.Started)
+	if err != nil {
+		entry.Duration = entry.Wait
+		entry.Error = err.Error()
+		t.tracer.Trace(entry)
+		return res, err
+	}
+
+	entry.Response = &TraceResponse{StatusCode: res.StatusCode, Status: res.Status}
+	entry.Response.Header = redactHeader(res.Header)
+	entry.Response.MimeType = res.Header.Get("Content-Type")
+	res.Body = &tracedBody{ReadCloser: res.Body, entry: entry, tracer: t.tracer}
+	return res, nil
+}
+
+type tracedBody struct {
+	io.ReadCloser
+	entry  TraceEntry
+	tracer Tracer
+	buf    bytes.Buffer
+	size   int64
+	once   sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if room := traceBodyLimit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	if err == io.EOF {
+		b.finish("")
+	} else if err != nil {
+		b.finish(err.Error())
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish("")
+	return err
+}
+
+func (b *tracedBody) finish(errMsg string) {
+	b.once.Do(func() {
+		b.entry.Duration = time.Since(b.entry.Started)
+		b.entry.Error = errMsg
+		b.entry.Response.BodySize = b.size
+		b.entry.Response.Body = traceBody(b.entry.Response.MimeType, b.buf.Bytes(), b.size)
+		b.tracer.Trace(b.entry)
+	})
+}
+
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "authorization", "proxy-authorization", "cookie", "set-cookie", strings.ToLower(TokenHeaderName()):
+		return true
+	}
+	return strings.Contains(name, "token") || strings.Contains(name, "secret")
+}
+
+func redactHeader(header http.Header) http.Header {
+	res := header.Clone()
+	for name := range res {
+		if isSecretHeader(name) {
+			res[name] = []string{TraceRedacted}
+		}
+	}
+	return res
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	if name == "token" || strings.HasSuffix(name, "_token") || strings.HasSuffix(name, "-token") {
+		return true
+	}
+	for _, part := range []string{"secret", "password", "private", ".sec"} {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a body suitable for a trace: secrets are redacted, and binary or too large content is elided.
+func traceBody(mimeType string, data []byte, size int64) string {
+	if size == 0 {
+		return ""
+	}
+	truncated := size > int64(len(data)) || len(data) > traceBodyLimit
+	if len(data) > traceBodyLimit {
+		data = data[:traceBodyLimit]
+	}
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	trimmed := bytes.TrimSpace(data)
+	isJson := strings.HasSuffix(mediaType, "json") ||
+		(len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['))
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(data)); err == nil && !truncated {
+			for key := range values {
+				if isSecretField(key) {
+					values[key] = []string{TraceRedacted}
+				}
+			}
+			return values.Encode()
+		}
+		return fmt.Sprintf("<%d bytes of form data elided>", size)
+	case isJson:
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if truncated || dec.Decode(&v) != nil {
+			// Secrets cannot be reliably redacted from a document we cannot parse
+			return fmt.Sprintf("<%d bytes of JSON elided>", size)
+		}
+		if redacted, err := json.Marshal(redactJson(v)); err == nil {
+			return string(redacted)
+		}
+		return fmt.Sprintf("<%d bytes of JSON elided>", size)
+	case !utf8.Valid(data):
+		return fmt.Sprintf("<%d bytes of binary data elided>", size)
+	case truncated:
+		return string(data) + fmt.Sprintf("...(truncated body of %d bytes)", size)
+	default:
+		return string(data)
+	}
+}
+
+func redactJson(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for key, item := range val {
+			if isSecretField(key) {
+				if item != nil {
+					val[key] = TraceRedacted
+				}
+			} else if key == "files" {
+				val[key] = redactConfigFiles(item)
+			} else {
+				val[key] = redactJson(item)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = redactJson(item)
+		}
+	}
+	return v
+}
+
+// Config file values are secrets: unencrypted ones are redacted, and ECIES-encrypted ones are elided.
+func redactConfigFiles(v any) any {
+	files, ok := v.([]any)
+	if !ok {
+		return redactJson(v)
+	}
+	for _, item := range files {
+		file, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		value, ok := file["value"].(string)
+		if !ok {
+			continue
+		}
+		if unencrypted, _ := file["unencrypted"].(bool); unencrypted {
+			file["value"] = TraceRedacted
+		} else {
+			file["value"] = fmt.Sprintf("<%d bytes of ECIES-encrypted payload elided>", len(value))
+		}
+	}
+	return files
+}
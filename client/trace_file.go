@@ -0,0 +1,270 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileTracer writes traced HTTP exchanges into a file:
+// as an HTTP Archive (HAR 1.2) if a file name ends with ".har", or as JSON lines otherwise.
+// Each entry is written as soon as it is traced, so that a trace survives a crash.
+// A HAR file is only valid JSON after Close, but its entries can still be recovered otherwise.
+type FileTracer struct {
+	har bool
+
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+	// Number of HAR entries written so far
+	count int
+	// The first write error, returned by Close
+	err error
+}
+
+// NewFileTracer creates a tracer writing into a given file, making sure the file can be written.
+// The version is recorded as a creator version in HAR files.
+func NewFileTracer(path, version string) (*FileTracer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	t := &FileTracer{file: f, har: strings.HasSuffix(strings.ToLower(path), ".har")}
+	if t.har {
+		creator, err := json.Marshal(map[string]string{"name": "fioctl", "version": version})
+		if err == nil {
+			_, err = fmt.Fprintf(f, "{\n  \"log\": {\n    \"version\": \"1.2\",\n    \"creator\": %s,\n    \"entries\": [", creator)
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *FileTracer) Trace(entry TraceEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed || t.err != nil {
+		return
+	}
+	var buf []byte
+	if t.har {
+		buf, t.err = json.MarshalIndent(newHarEntry(entry), "      ", "  ")
+		if t.err == nil {
+			sep := ",\n      "
+			if t.count == 0 {
+				sep = "\n      "
+			}
+			buf = append([]byte(sep), buf...)
+			t.count++
+		}
+	} else if buf, t.err = json.Marshal(newJsonlEntry(entry)); t.err == nil {
+		buf = append(buf, '\n')
+	}
+	if t.err == nil {
+		_, t.err = t.file.Write(buf)
+	}
+}
+
+// Close finishes and closes the file. Entries traced after Close are discarded.
+// It returns the first error of writing the file, if any. It is safe to call Close more than once.
+func (t *FileTracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return t.err
+	}
+	t.closed = true
+	if t.har && t.err == nil {
+		_, t.err = t.file.WriteString("\n    ]\n  }\n}\n")
+	}
+	if err := t.file.Close(); t.err == nil {
+		t.err = err
+	}
+	return t.err
+}
+
+type jsonlMessage struct {
+	Headers  map[string]string `json:"headers"`
+	Body     string            `json:"body,omitempty"`
+	BodySize int64             `json:"bodySize"`
+}
+
+type jsonlRequest struct {
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	jsonlMessage
+}
+
+type jsonlResponse struct {
+	Status     int    `json:"status"`
+	StatusText string `json:"statusText"`
+	jsonlMessage
+}
+
+type jsonlEntry struct {
+	Started  string         `json:"started"`
+	WaitMs   float64        `json:"waitMs"`
+	TimeMs   float64        `json:"timeMs"`
+	Request  jsonlRequest   `json:"request"`
+	Response *jsonlResponse `json:"response,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+func newJsonlEntry(e TraceEntry) jsonlEntry {
+	line := jsonlEntry{
+		Started: e.Started.UTC().Format(time.RFC3339Nano),
+		WaitMs:  millis(e.Wait),
+		TimeMs:  millis(e.Duration),
+		Request: jsonlRequest{
+			Method:       e.Request.Method,
+			Url:          e.Request.URL,
+			jsonlMessage: jsonlMessage{flatHeaders(e.Request.Header), e.Request.Body, e.Request.BodySize},
+		},
+		Error: e.Error,
+	}
+	if e.Response != nil {
+		line.Response = &jsonlResponse{
+			Status:     e.Response.StatusCode,
+			StatusText: http.StatusText(e.Response.StatusCode),
+			jsonlMessage: jsonlMessage{
+				flatHeaders(e.Response.Header), e.Response.Body, e.Response.BodySize,
+			},
+		}
+	}
+	return line
+}
+
+// See http://www.softwareishard.com/blog/har-12-spec/
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	Url         string         `json:"url"`
+	HttpVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HttpVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectUrl string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+func newHarEntry(e TraceEntry) harEntry {
+	entry := harEntry{
+		StartedDateTime: e.Started.UTC().Format(time.RFC3339Nano),
+		Time:            millis(e.Duration),
+		Request: harRequest{
+			Method:      e.Request.Method,
+			Url:         e.Request.URL,
+			HttpVersion: "HTTP/1.1",
+			Headers:     harHeaders(e.Request.Header),
+			QueryString: []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    e.Request.BodySize,
+		},
+		Response: harResponse{
+			HttpVersion: "HTTP/1.1",
+			Headers:     []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Wait: millis(e.Wait), Receive: millis(e.Duration - e.Wait)},
+		Comment: e.Error,
+	}
+	if u, err := url.Parse(e.Request.URL); err == nil {
+		for name, values := range u.Query() {
+			for _, val := range values {
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{name, val})
+			}
+		}
+	}
+	if e.Request.BodySize > 0 {
+		entry.Request.PostData = &harPostData{MimeType: e.Request.MimeType, Text: e.Request.Body}
+	}
+	if e.Response != nil {
+		entry.Response.Status = e.Response.StatusCode
+		entry.Response.StatusText = http.StatusText(e.Response.StatusCode)
+		entry.Response.Headers = harHeaders(e.Response.Header)
+		entry.Response.BodySize = e.Response.BodySize
+		entry.Response.Content = harContent{
+			Size: e.Response.BodySize, MimeType: e.Response.MimeType, Text: e.Response.Body,
+		}
+	}
+	return entry
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func flatHeaders(header http.Header) map[string]string {
+	res := make(map[string]string, len(header))
+	for name, values := range header {
+		res[name] = strings.Join(values, ", ")
+	}
+	return res
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	res := make([]harNameValue, 0, len(header))
+	for name, values := range header {
+		for _, val := range values {
+			res = append(res, harNameValue{name, val})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
@@ -0,0 +1,132 @@
+package client_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+type memTracer struct {
+	mu      sync.Mutex
+	entries []client.TraceEntry
+}
+
+func (t *memTracer) Trace(entry client.TraceEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, entry)
+}
+
+func TestTraceRedacted(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDevice(client.Device{Name: "dev"})
+	tracer := &memTracer{}
+	api := srv.NewApi(client.Config{Tracer: tracer})
+
+	dapi := api.DeviceApiByName(testFactory, "dev")
+	require.Nil(t, dapi.CreateConfig(client.ConfigCreateRequest{
+		Reason: "test",
+		Files: []client.ConfigFile{
+			{Name: "plain", Value: "plain-secret", Unencrypted: true},
+			{Name: "encrypted", Value: "BASE64-ECIES-PAYLOAD"},
+		},
+	}))
+	_, err := dapi.ListConfig()
+	require.Nil(t, err)
+
+	require.Len(t, tracer.entries, 2)
+	for _, entry := range tracer.entries {
+		assert.Equal(t, client.TraceRedacted, entry.Request.Header.Get("OSF-TOKEN"))
+		require.NotNil(t, entry.Response)
+		assert.NotEmpty(t, entry.Response.Header.Get("X-Request-Id"))
+		for _, body := range []string{entry.Request.Body, entry.Response.Body} {
+			assert.NotContains(t, body, "plain-secret")
+			assert.NotContains(t, body, "BASE64-ECIES-PAYLOAD")
+			assert.NotContains(t, body, srv.Token())
+		}
+	}
+	assert.Contains(t, tracer.entries[0].Request.Body, "ECIES-encrypted payload elided")
+	assert.Contains(t, tracer.entries[1].Response.Body, `"reason":"test"`)
+}
+
+func TestFileTracer(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+
+	for _, name := range []string{"trace.jsonl", "trace.har"} {
+		path := filepath.Join(dir, name)
+		tracer, err := client.NewFileTracer(path, "test")
+		require.Nil(t, err)
+		api := srv.NewApi(client.Config{Tracer: tracer})
+		_, err = api.DeviceList(map[string]string{"factory": testFactory}, "", 1, 10)
+		require.Nil(t, err)
+		_, err = api.DeviceList(map[string]string{"factory": "other"}, "", 1, 10)
+		require.NotNil(t, err)
+
+		// Entries are written as they are traced, so that a trace survives a crash
+		data, err := os.ReadFile(path)
+		require.Nil(t, err)
+		assert.Contains(t, string(data), "/devices/")
+		require.Nil(t, tracer.Close())
+		require.Nil(t, tracer.Close())
+
+		data, err = os.ReadFile(path)
+		require.Nil(t, err)
+		assert.NotContains(t, string(data), srv.Token())
+		if strings.HasSuffix(name, ".har") {
+			var har struct {
+				Log struct {
+					Version string `json:"version"`
+					Entries []struct {
+						Response struct {
+							Status int `json:"status"`
+						} `json:"response"`
+					} `json:"entries"`
+				} `json:"log"`
+			}
+			require.Nil(t, json.Unmarshal(data, &har))
+			assert.Equal(t, "1.2", har.Log.Version)
+			require.Len(t, har.Log.Entries, 2)
+			assert.Equal(t, 404, har.Log.Entries[1].Response.Status)
+		} else {
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			require.Len(t, lines, 2)
+			var entry struct {
+				Request struct {
+					Method string `json:"method"`
+				} `json:"request"`
+			}
+			require.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+			assert.Equal(t, "GET", entry.Request.Method)
+		}
+	}
+}
+
+func TestFileTracerEmptyHar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.har")
+	tracer, err := client.NewFileTracer(path, "test")
+	require.Nil(t, err)
+	require.Nil(t, tracer.Close())
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	var har struct {
+		Log struct {
+			Creator struct {
+				Version string `json:"version"`
+			} `json:"creator"`
+			Entries []any `json:"entries"`
+		} `json:"log"`
+	}
+	require.Nil(t, json.Unmarshal(data, &har))
+	assert.Equal(t, "test", har.Log.Creator.Version)
+	assert.NotNil(t, har.Log.Entries)
+	assert.Empty(t, har.Log.Entries)
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ServerConfig describes how to connect to an API server.
+// It maps to the "server" section of the fioctl.yaml.
+type ServerConfig struct {
+	Url                string `mapstructure:"url"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	// A PEM bundle of CA certificates trusted in addition to the system ones
+	CaCert string `mapstructure:"ca_cert"`
+	// A PEM client certificate and its private key for mutual TLS
+	ClientCert string `mapstructure:"client_cert"`
+	ClientKey  string `mapstructure:"client_key"`
+	// An HTTP proxy URL; proxy environment variables are used if not set
+	Proxy string `mapstructure:"proxy"`
+
+	// Timeouts are not limited when set to zero, except for those having a non-zero default.
+	// The Timeout limits the whole request, including reading the response body.
+	Timeout               time.Duration `mapstructure:"timeout"`
+	DialTimeout           time.Duration `mapstructure:"dial_timeout"`
+	TLSHandshakeTimeout   time.Duration `mapstructure:"tls_handshake_timeout"`
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout"`
+
+	MaxIdleConns        int `mapstructure:"max_idle_conns"`
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int `mapstructure:"max_conns_per_host"`
+}
+
+// NewTransport creates an HTTP transport owned by a single caller.
+// Unlike tweaking the http.DefaultTransport, it does not affect other API clients in the same process.
+func NewTransport(cfg ServerConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// targets/artifacts.go needs to know the Content-Length in order to
+	// compute the download progress. If certain services like CloudFlare
+	// see the client accepts compressed responsed (content-encoding not
+	// content-type) then it will give a compressed response. Golang will
+	// automagically decompress as you read the response *and* set
+	// content-length to -1 thereby breaking our download progress logic
+	transport.DisableCompression = true
+
+	tlsCfg := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if len(cfg.CaCert) > 0 {
+		rootCAs, _ := x509.SystemCertPool()
+		if rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		certs, err := os.ReadFile(cfg.CaCert)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to append %q to RootCAs: %w", cfg.CaCert, err)
+		}
+
+		if ok := rootCAs.AppendCertsFromPEM(certs); !ok {
+			logrus.Warning("No certs appended, using system certs only")
+		}
+		tlsCfg.RootCAs = rootCAs
+	}
+	if len(cfg.ClientCert) > 0 || len(cfg.ClientKey) > 0 {
+		if len(cfg.ClientCert) == 0 || len(cfg.ClientKey) == 0 {
+			return nil, fmt.Errorf("Both client certificate and client key must be set for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load a client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsCfg
+
+	if len(cfg.Proxy) > 0 {
+		proxyUrl, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy URL %q: %w", cfg.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if cfg.DialTimeout > 0 {
+		dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	return transport, nil
+}
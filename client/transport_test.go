@@ -0,0 +1,63 @@
+package client_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport, err := client.NewTransport(client.ServerConfig{
+		InsecureSkipVerify:    true,
+		Proxy:                 "http://proxy.example.com:3128",
+		ResponseHeaderTimeout: 5 * time.Second,
+		MaxConnsPerHost:       3,
+	})
+	require.Nil(t, err)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	assert.Equal(t, 5*time.Second, transport.ResponseHeaderTimeout)
+	assert.Equal(t, 3, transport.MaxConnsPerHost)
+	req, err := http.NewRequest("GET", "https://api.foundries.io/ota/devices/", nil)
+	require.Nil(t, err)
+	proxy, err := transport.Proxy(req)
+	require.Nil(t, err)
+	assert.Equal(t, "proxy.example.com:3128", proxy.Host)
+
+	// Other clients in the same process are not affected
+	def := http.DefaultTransport.(*http.Transport)
+	assert.True(t, def.TLSClientConfig == nil || !def.TLSClientConfig.InsecureSkipVerify)
+	assert.NotEqual(t, 3, def.MaxConnsPerHost)
+}
+
+func TestNewTransportInvalid(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	for _, cfg := range []client.ServerConfig{
+		{CaCert: missing},
+		{ClientCert: missing},
+		{ClientCert: missing, ClientKey: missing},
+		{Proxy: "http://proxy example.com"},
+	} {
+		_, err := client.NewTransport(cfg)
+		assert.NotNil(t, err, "%+v", cfg)
+	}
+
+	// An API client reports an invalid server config instead of exiting
+	api, err := client.NewApiClientE("https://api.foundries.io", client.Config{
+		Server: client.ServerConfig{CaCert: missing},
+	}, "", "test")
+	assert.Nil(t, api)
+	assert.ErrorContains(t, err, "missing.pem")
+}
+
+func TestNewApiClient(t *testing.T) {
+	// The old constructor keeps working for programs embedding the client package
+	api := client.NewApiClient("https://api.foundries.io/", client.Config{}, "", "test")
+	require.NotNil(t, api)
+	assert.Equal(t, "https://api.foundries.io", api.ServerUrl())
+}
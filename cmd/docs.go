@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
@@ -13,10 +12,10 @@ var docsRstCmd = &cobra.Command{
 	Short:  "Generate RST docs for this tool",
 	Hidden: true,
 	Args:   cobra.MaximumNArgs(1),
-	Run:    doGenRstDocs,
+	RunE:   doGenRstDocs,
 }
 
-func doGenRstDocs(cmd *cobra.Command, args []string) {
+func doGenRstDocs(cmd *cobra.Command, args []string) error {
 	outDir := "./"
 	if len(args) == 1 {
 		outDir = args[0]
@@ -32,11 +31,7 @@ func doGenRstDocs(cmd *cobra.Command, args []string) {
 	}
 
 	rootCmd.DisableAutoGenTag = true
-	err := doc.GenReSTTreeCustom(rootCmd, outDir, filePrepender, linkHandler)
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return doc.GenReSTTreeCustom(rootCmd, outDir, filePrepender, linkHandler)
 }
 
 var docsMdCmd = &cobra.Command{
@@ -44,10 +39,10 @@ var docsMdCmd = &cobra.Command{
 	Short:  "Generate markdown docs for this tool",
 	Hidden: true,
 	Args:   cobra.MaximumNArgs(1),
-	Run:    doGenMdDocs,
+	RunE:   doGenMdDocs,
 }
 
-func doGenMdDocs(cmd *cobra.Command, args []string) {
+func doGenMdDocs(cmd *cobra.Command, args []string) error {
 	outDir := "./"
 	if len(args) == 1 {
 		outDir = args[0]
@@ -55,9 +50,5 @@ func doGenMdDocs(cmd *cobra.Command, args []string) {
 	fmt.Println("Generating docs at:", outDir)
 
 	rootCmd.DisableAutoGenTag = true
-	err := doc.GenMarkdownTree(rootCmd, outDir)
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return doc.GenMarkdownTree(rootCmd, outDir)
 }
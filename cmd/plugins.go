@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+// Any "fioctl-<name>" executable on the PATH is run as "fioctl <name>", like git and kubectl plugins.
+const pluginPrefix = "fioctl-"
+
+var pluginNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// findPlugins returns paths of plugin executables by their names; the first one on the PATH wins.
+func findPlugins() map[string]string {
+	plugins := make(map[string]string)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if len(dir) == 0 {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), pluginPrefix)
+			if !ok {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				if name, ok = strings.CutSuffix(name, ".exe"); !ok {
+					continue
+				}
+			}
+			if _, found := plugins[name]; found || !pluginNameRe.MatchString(name) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if fi, err := os.Stat(path); err != nil || fi.IsDir() || (runtime.GOOS != "windows" && fi.Mode()&0o111 == 0) {
+				continue
+			}
+			plugins[name] = path
+		}
+	}
+	return plugins
+}
+
+// addPluginCommands adds plugins found on the PATH as commands listed in their own help section.
+// Built-in commands always win over plugins with the same name.
+func addPluginCommands(root *cobra.Command) {
+	plugins := findPlugins()
+	if len(plugins) == 0 {
+		return
+	}
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	root.AddGroup(
+		&cobra.Group{ID: "builtin", Title: "Available Commands:"},
+		&cobra.Group{ID: "plugins", Title: "Plugin Commands:"},
+	)
+	for _, c := range root.Commands() {
+		c.GroupID = "builtin"
+	}
+	root.SetHelpCommandGroupID("builtin")
+	root.SetCompletionCommandGroupID("builtin")
+
+	for _, name := range names {
+		if c, _, err := root.Find([]string{name}); err == nil && c != root {
+			logrus.Debugf("Ignoring plugin %s shadowed by a built-in command", plugins[name])
+			continue
+		}
+		root.AddCommand(newPluginCommand(name, plugins[name]))
+	}
+}
+
+// Returns a raw token sent in a request authenticated by an auth provider, and the header it is sent in.
+func requestToken(req *http.Request) (token, header string, err error) {
+	if value := req.Header.Get("Authorization"); len(value) > 0 {
+		token = value
+		if bearer, ok := strings.CutPrefix(value, "Bearer "); ok {
+			// Bearer tokens are sent base64 encoded
+			if raw, err := base64.StdEncoding.DecodeString(bearer); err == nil {
+				token = string(raw)
+			}
+		}
+		return token, "Authorization: " + value, nil
+	}
+	name := client.TokenHeaderName()
+	if value := req.Header.Get(name); len(value) > 0 {
+		return value, name + ": " + value, nil
+	}
+	return "", "", fmt.Errorf("An auth provider did not set the Authorization or %s header", name)
+}
+
+func newPluginCommand(name, path string) *cobra.Command {
+	return &cobra.Command{
+		Use:     name,
+		Short:   "Plugin " + path,
+		GroupID: "plugins",
+		Long: fmt.Sprintf(`Run the %s plugin.
+
+Global flags (e.g. --profile or --factory) must precede the plugin name;
+all arguments after it are passed to the plugin as is. The plugin receives:
+  FIOCTL_FACTORY       A factory selected by --factory or the config file
+  FIOCTL_SERVER_URL    A URL of the API server
+  FIOCTL_ACCESS_TOKEN  A fresh API token or OAuth access token
+  FIOCTL_AUTH_HEADER   An HTTP header to send the token in, e.g. "Authorization: Bearer <token>"
+  FIOCTL_CONFIG        A path of the config file
+  FIOCTL_PROFILE       A name of the active config profile, if any`, path),
+		// All flags after the plugin name belong to the plugin
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(cmd, path)
+		},
+	}
+}
+
+func runPlugin(cmd *cobra.Command, path string) error {
+	// Cobra does not parse flags of this command, so global flags are parsed here.
+	// The command line is: fioctl [global flags] <plugin> [plugin args]
+	flags := pflag.NewFlagSet("fioctl", pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.AddFlagSet(cmd.Root().PersistentFlags())
+	flags.StringP("factory", "f", "", "")
+	flags.StringP("token", "t", "", "")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return subcommands.UsageError(err)
+	}
+	pluginArgs := flags.Args()
+	if len(pluginArgs) == 0 || pluginArgs[0] != cmd.Name() {
+		return subcommands.UsageError(errors.New("Global flags must precede a plugin name"))
+	}
+	pluginArgs = pluginArgs[1:]
+	for _, name := range []string{"factory", "token"} {
+		if flag := flags.Lookup(name); flag.Changed {
+			viper.Set(name, flag.Value.String())
+		}
+	}
+	// Flags like --profile or --config may change what is loaded
+	if err := setupConfig(); err != nil {
+		return err
+	}
+
+	api, err := subcommands.Login(&cobra.Command{Use: cmd.Name()})
+	if err != nil {
+		return err
+	}
+	// Ask an auth provider how it authenticates requests, so that a plugin can do the same
+	req, err := http.NewRequest(http.MethodGet, api.ServerUrl(), nil)
+	if err != nil {
+		return err
+	}
+	if err = subcommands.Config.AuthProvider.Authenticate(req); err != nil {
+		return subcommands.AuthError(err)
+	}
+	token, header, err := requestToken(req)
+	if err != nil {
+		return subcommands.AuthError(err)
+	}
+
+	configPath := viper.ConfigFileUsed()
+	if len(configPath) == 0 {
+		if configPath, err = getConfigDir(); err != nil {
+			return err
+		}
+		configPath = filepath.Join(configPath, "fioctl.yaml")
+	}
+
+	logrus.Debugf("Running plugin %s %v", path, pluginArgs)
+	c := exec.CommandContext(cmd.Context(), path, pluginArgs...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"FIOCTL_FACTORY="+viper.GetString("factory"),
+		"FIOCTL_SERVER_URL="+api.ServerUrl(),
+		"FIOCTL_ACCESS_TOKEN="+token,
+		"FIOCTL_AUTH_HEADER="+header,
+		"FIOCTL_CONFIG="+configPath,
+		"FIOCTL_PROFILE="+subcommands.ProfileName,
+	)
+	err = c.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// A plugin reports its own errors
+		if code := exitErr.ExitCode(); code > 0 {
+			return subcommands.SilentExit(code)
+		}
+	}
+	return err
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 
 	homedir "github.com/mitchellh/go-homedir"
@@ -14,6 +17,7 @@ import (
 
 	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
+	"github.com/foundriesio/fioctl/subcommands/audit"
 	cfgcmd "github.com/foundriesio/fioctl/subcommands/config"
 	"github.com/foundriesio/fioctl/subcommands/devices"
 	"github.com/foundriesio/fioctl/subcommands/docker"
@@ -24,6 +28,7 @@ import (
 	"github.com/foundriesio/fioctl/subcommands/keys"
 	"github.com/foundriesio/fioctl/subcommands/login"
 	"github.com/foundriesio/fioctl/subcommands/logout"
+	"github.com/foundriesio/fioctl/subcommands/profiles"
 	"github.com/foundriesio/fioctl/subcommands/secrets"
 	"github.com/foundriesio/fioctl/subcommands/status"
 	"github.com/foundriesio/fioctl/subcommands/targets"
@@ -34,25 +39,47 @@ import (
 )
 
 var (
-	cfgFile string
-	config  client.Config
-	verbose bool
+	cfgFile   string
+	profile   string
+	config    client.Config
+	configErr error
+	verbose   bool
+	traceFile string
+	tracer    *client.FileTracer
 )
 
 var rootCmd = &cobra.Command{
-	Use:               "fioctl",
-	Short:             "Manage Foundries Factories",
+	Use:   "fioctl",
+	Short: "Manage Foundries Factories",
+	Long: `Manage Foundries Factories.
+
+Exit codes:
+  0    Success
+  1    An error not covered by other codes
+  2    Invalid command line arguments or flags
+  3    Not logged in, invalid credentials, or not enough permissions
+  4    A requested resource does not exist
+  5    A resource is in a conflicting state (e.g. it already exists)
+  6    A bulk operation failed for some of its items
+  7    The API server could not be reached, or a request timed out
+  8    A waited for condition was not met in time
+  130  Interrupted by Ctrl-C`,
 	PersistentPreRunE: rootArgValidation,
+	// Errors are printed by Execute, so that they go to stderr with a proper exit code
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func Execute() {
 	if strings.Contains(os.Args[0], docker.DOCKER_CREDS_HELPER) {
 		if len(os.Args) != 2 || os.Args[1] != "get" {
-			fmt.Printf("Usage: %s get\n", os.Args[0])
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Usage: %s get\n", os.Args[0])
+			os.Exit(subcommands.ExitUsage)
 		}
 		initConfig()
-		os.Exit(docker.RunCredsHelper())
+		exitOnError(configErr)
+		exitOnError(docker.RunCredsHelper())
+		os.Exit(subcommands.ExitOk)
 	}
 	if strings.Contains(os.Args[0], git.GIT_CREDS_HELPER) {
 		if len(os.Args) != 2 || os.Args[1] != "get" {
@@ -60,12 +87,63 @@ func Execute() {
 			return
 		}
 		initConfig()
-		os.Exit(git.RunCredsHelper())
+		exitOnError(configErr)
+		exitOnError(git.RunCredsHelper())
+		os.Exit(subcommands.ExitOk)
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel all in-flight API requests on the first Ctrl-C.
+	// A second Ctrl-C falls back to the default behavior and kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return subcommands.UsageError(err)
+	})
+	addPluginCommands(rootCmd)
+	markUsageErrors(rootCmd)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if errors.Is(err, client.ErrDryRun) {
+		// A preview of the first change a command would make is its successful outcome
 		fmt.Println(err)
-		os.Exit(1)
+		err = nil
+	}
+	if traceErr := closeTracer(); err == nil {
+		err = traceErr
+	}
+	if err != nil {
+		subcommands.PrintError(err)
+		code := subcommands.ExitCode(err)
+		if ctx.Err() != nil {
+			code = subcommands.ExitInterrupted
+		} else if code == subcommands.ExitUsage && !errors.Is(err, subcommands.ErrSilentExit) &&
+			!errors.Is(err, subcommands.ErrInvalidConfig) {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
+		}
+		os.Exit(code)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		subcommands.PrintError(err)
+		os.Exit(subcommands.ExitCode(err))
+	}
+}
+
+// Cobra returns plain errors for invalid arguments; mark them to exit with a usage error code.
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			return subcommands.UsageError(validate(c, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
 	}
 }
 
@@ -75,9 +153,41 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/.config/fioctl.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print verbose logging")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "", "",
+		"Name of a config file profile to use (default is $FIOCTL_PROFILE or the current profile)")
+	rootCmd.PersistentFlags().StringVarP(&subcommands.OutputFormat, "output", "o", subcommands.OutputTable,
+		"Output format of list and show commands: table, json, yaml, csv, ndjson, or template=<go-template>")
+	rootCmd.PersistentFlags().StringVarP(&traceFile, "trace-file", "", "",
+		"Record all API requests and responses into this file with secrets redacted; "+
+			"in HAR format if the file name ends with .har, as JSON lines otherwise")
+	rootCmd.PersistentFlags().Int("retry-attempts", 0,
+		fmt.Sprintf("Maximum attempts for an API request failed with a transient error; 1 disables retries (default %d)",
+			client.DefaultRetryMaxAttempts))
+	rootCmd.PersistentFlags().Bool("retry-non-idempotent", false,
+		"Also retry non-idempotent API requests (POST and PATCH). These may be applied twice by the server.")
+	_ = viper.BindPFlag("retry.max_attempts", rootCmd.PersistentFlags().Lookup("retry-attempts"))
+	_ = viper.BindPFlag("retry.non_idempotent", rootCmd.PersistentFlags().Lookup("retry-non-idempotent"))
+	rootCmd.PersistentFlags().Bool("no-cache", false,
+		"Do not read nor write cached API responses (e.g. Targets and TUF metadata)")
+	rootCmd.PersistentFlags().Bool("refresh", false,
+		"Download API responses again instead of using cached ones, and update the cache")
+	_ = viper.BindPFlag("cache.disabled", rootCmd.PersistentFlags().Lookup("no-cache"))
+	_ = viper.BindPFlag("cache.refresh", rootCmd.PersistentFlags().Lookup("refresh"))
+	rootCmd.PersistentFlags().Bool("dry-run", false,
+		"Do not change anything: print API requests a command would send to change something "+
+			"(with secrets redacted). Read-only requests are sent as usual. Commands changing several devices "+
+			"preview the change of each of them; other commands stop at their first change. "+
+			"Commands with their own --dry-run flag (targets add, waves init, waves rollout, "+
+			"and keys ca revoke-device-ca) use it instead.")
+	_ = viper.BindPFlag("dry_run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	// There is no shorthand, as -y is taken by some commands
+	rootCmd.PersistentFlags().BoolVarP(&subcommands.AssumeYes, "yes", "", false,
+		"Do not ask to confirm destructive operations (e.g. deleting devices or pruning Targets); "+
+			"required to run them when stdin is not a terminal")
 
 	rootCmd.AddCommand(completionCmd)
 
+	rootCmd.AddCommand(audit.NewCommand())
 	rootCmd.AddCommand(cfgcmd.NewCommand())
 	rootCmd.AddCommand(devices.NewCommand())
 	rootCmd.AddCommand(docker.NewCommand())
@@ -88,6 +198,7 @@ func init() {
 	rootCmd.AddCommand(keys.NewCommand())
 	rootCmd.AddCommand(login.NewCommand())
 	rootCmd.AddCommand(logout.NewCommand())
+	rootCmd.AddCommand(profiles.NewCommand())
 	rootCmd.AddCommand(users.NewCommand())
 	rootCmd.AddCommand(teams.NewCommand())
 	rootCmd.AddCommand(secrets.NewCommand())
@@ -107,30 +218,51 @@ func rootArgValidation(cmd *cobra.Command, args []string) error {
 	if strings.HasPrefix(cmd.Name(), "__complete") {
 		return nil
 	}
+	if configErr != nil {
+		return configErr
+	}
 	for pos, val := range args {
 		if len(strings.TrimSpace(val)) == 0 {
-			return fmt.Errorf("Empty values or values containing only white space are not allowed for positional argument at %d\n", pos)
+			return subcommands.UsageError(fmt.Errorf(
+				"Empty values or values containing only white space are not allowed for positional argument at %d", pos))
 		}
 	}
-	return nil
+	return subcommands.UsageError(subcommands.ValidateOutputFormat())
 }
 
-func getConfigDir() string {
+func getConfigDir() (string, error) {
 	config, err := homedir.Expand("~/.config")
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return "", err
 	}
 	if _, err := os.Stat(config); errors.Is(err, fs.ErrNotExist) {
 		if err := os.Mkdir(config, 0755); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			return "", err
 		}
 	}
-	return config
+	return config, nil
 }
 
+// Cobra initializers cannot fail, so an error is kept to be returned before a command runs
 func initConfig() {
+	configErr = setupConfig()
+}
+
+// Loads the config, and sets up the tracer and the auditor it configures.
+// Plugin commands run it again once they parse global flags, so it keeps the tracer set up before.
+func setupConfig() error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	if err := initTracer(); err != nil {
+		return err
+	}
+	initAuditor()
+	subcommands.Config = config
+	return nil
+}
+
+func loadConfig() error {
 	cfgFileFromEnv := os.Getenv("FIOCTL_CONFIG")
 	if len(cfgFileFromEnv) > 0 {
 		cfgFile = cfgFileFromEnv
@@ -140,7 +272,11 @@ func initConfig() {
 		viper.SetConfigFile(cfgFile)
 	} else {
 		// Search config in home directory with name "fioctl" (without extension).
-		viper.AddConfigPath(getConfigDir())
+		dir, err := getConfigDir()
+		if err != nil {
+			return err
+		}
+		viper.AddConfigPath(dir)
 		viper.SetConfigName("fioctl")
 		viper.SetConfigType("yaml")
 	}
@@ -152,23 +288,71 @@ func initConfig() {
 			logrus.Debug("Config file not found")
 		} else {
 			// Config file was found but another error was produced
-			fmt.Println("ERROR: ", err)
-			os.Exit(1)
+			return err
 		}
 	}
 	if verbose {
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+	if err := subcommands.ApplyProfile(profile); err != nil {
+		return err
+	}
 
 	if err := viper.Unmarshal(&config); err != nil {
-		panic(fmt.Sprintf("Unexpected failure parsing configuration: %s", err))
+		return fmt.Errorf("Unexpected failure parsing configuration: %w", err)
 	}
-	subcommands.Config = config
+	return nil
+}
+
+func initTracer() error {
+	if len(traceFile) == 0 || tracer != nil {
+		// A trace file must not be truncated by loading the config again
+		return nil
+	}
+	var err error
+	if tracer, err = client.NewFileTracer(traceFile, version.Commit); err != nil {
+		return fmt.Errorf("Unable to create a trace file: %w", err)
+	}
+	config.Tracer = tracer
+	// Finish the trace file also when fioctl exits via logrus.Fatal
+	logrus.RegisterExitHandler(func() { _ = closeTracer() })
+	return nil
+}
+
+func initAuditor() {
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	// A command line is already parsed, so that a running command is found
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+	if err != nil {
+		cmd = rootCmd
+	}
+	journal, err := subcommands.NewAuditJournal(cmd, args)
+	if err != nil {
+		// Auditing must not get in the way of using fioctl
+		logrus.Warn(err)
+	} else if journal != nil {
+		config.Auditor = journal
+	}
+}
+
+func closeTracer() error {
+	if tracer != nil {
+		if err := tracer.Close(); err != nil {
+			return fmt.Errorf("Unable to write a trace file: %w", err)
+		}
+	}
+	return nil
 }
 
 var completionCmd = &cobra.Command{
 	Use:   "completion [bash|zsh|powershell]",
 	Short: "Generate completion script",
+	Long: `Generate completion script.
+
+Besides commands and flags, the script completes names of devices, Targets, waves,
+device groups, tags, event queues, config files, and remote actions. These are listed
+through the API for the factory given by --factory (or the config file) using your
+credentials, and cached for a minute. Use --no-cache or --refresh to bypass the cache.`,
 	Example: `
 # Bash:
 $ source <(fioctl completion bash)
@@ -196,24 +380,17 @@ $ fioctl completion fish > ~/.config/fish/completions/fioctl.fish
 	DisableFlagsInUseLine: true,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		switch args[0] {
 		case "bash":
-			if err := cmd.Root().GenBashCompletion(os.Stdout); err != nil {
-				logrus.Fatal(err)
-			}
+			return cmd.Root().GenBashCompletion(os.Stdout)
 		case "zsh":
-			if err := cmd.Root().GenZshCompletion(os.Stdout); err != nil {
-				logrus.Fatal(err)
-			}
+			return cmd.Root().GenZshCompletion(os.Stdout)
 		case "fish":
-			if err := cmd.Root().GenFishCompletion(os.Stdout, true); err != nil {
-				logrus.Fatal(err)
-			}
+			return cmd.Root().GenFishCompletion(os.Stdout, true)
 		case "powershell":
-			if err := cmd.Root().GenPowerShellCompletion(os.Stdout); err != nil {
-				logrus.Fatal(err)
-			}
+			return cmd.Root().GenPowerShellCompletion(os.Stdout)
 		}
+		return nil
 	},
 }
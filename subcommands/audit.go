@@ -0,0 +1,278 @@
+package subcommands
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// AuditRecord is a line of the audit journal, recording an API request which may have changed something.
+// These fields are a part of the fioctl interface, as teams may process a shared journal with their own tools.
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Host     string    `json:"host"`
+	Profile  string    `json:"profile,omitempty"`
+	Factory  string    `json:"factory,omitempty"`
+	Command  string    `json:"command"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	// A status code of the response; zero if the request failed without a response
+	Status     int    `json:"status"`
+	Error      string `json:"error,omitempty"`
+	BodySha256 string `json:"body_sha256,omitempty"`
+}
+
+// Failed returns true if a request did not succeed.
+func (r AuditRecord) Failed() bool {
+	return r.Status < 200 || r.Status >= 300 || len(r.Error) > 0
+}
+
+// AuditJournalPath returns a path of the audit journal: either the audit.path setting,
+// or $XDG_STATE_HOME/fioctl/audit.jsonl (by default ~/.local/state/fioctl/audit.jsonl).
+func AuditJournalPath() (string, error) {
+	if path := viper.GetString("audit.path"); len(path) > 0 {
+		return homedir.Expand(path)
+	}
+	if xdg := os.Getenv("XDG_STATE_HOME"); len(xdg) > 0 {
+		return filepath.Join(xdg, "fioctl", "audit.jsonl"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "fioctl", "audit.jsonl"), nil
+}
+
+// AuditJournal appends a record of each audited API request to a JSON lines file.
+// Each record is written as soon as a request completes, with a single write to a file opened for appending,
+// so that several fioctl processes (e.g. of different engineers sharing a journal) can write into one file.
+type AuditJournal struct {
+	path    string
+	mode    os.FileMode
+	command string
+	user    string
+	host    string
+
+	mu       sync.Mutex
+	warnOnce sync.Once
+}
+
+// NewAuditJournal returns a journal recording requests made by a given command line of a command, or nil if
+// auditing is turned off by the audit.disabled setting.
+func NewAuditJournal(cmd *cobra.Command, args []string) (*AuditJournal, error) {
+	if viper.GetBool("audit.disabled") {
+		return nil, nil
+	}
+	path, err := AuditJournalPath()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to find the audit journal: %w", err)
+	}
+	j := &AuditJournal{path: path, mode: 0o600, command: strings.Join(redactCommandLine(cmd, args), " ")}
+	if len(viper.GetString("audit.path")) > 0 {
+		// A shared journal is usually written by a group of users
+		j.mode = 0o660
+	}
+	if u, err := user.Current(); err == nil {
+		j.user = u.Username
+	}
+	j.host, _ = os.Hostname()
+	return j, nil
+}
+
+func (j *AuditJournal) Audit(entry client.AuditEntry) {
+	record := AuditRecord{
+		Time:       entry.Time.UTC(),
+		User:       j.user,
+		Host:       j.host,
+		Profile:    ProfileName,
+		Factory:    viper.GetString("factory"),
+		Command:    j.command,
+		Method:     entry.Method,
+		Endpoint:   entry.URL,
+		Status:     entry.StatusCode,
+		Error:      entry.Error,
+		BodySha256: entry.BodySha256,
+	}
+	if err := j.append(record); err != nil {
+		// The request is already made, so failing a command would only hide its outcome
+		j.warnOnce.Do(func() {
+			logrus.Warnf("Unable to write the audit journal %s: %s", j.path, err)
+		})
+	}
+}
+
+func (j *AuditJournal) append(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err = os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, j.mode)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(line)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReadAuditJournal returns all records of a journal in the order they were written.
+// A missing journal has no records. Lines which cannot be parsed (e.g. a partially written one) are skipped.
+func ReadAuditJournal(path string) ([]AuditRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to read the audit journal: %w", err)
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			logrus.Debugf("Skipping invalid line %d of the audit journal: %s", lineNo, err)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Unable to read the audit journal: %w", err)
+	}
+	return records, nil
+}
+
+// Flags whose values are secrets, in addition to those with a secret-like name
+var secretFlags = map[string]bool{"t": true, "token": true, "hsm-pin": true}
+
+// Commands whose <name>=<value> arguments are secrets, or contents of config files which may include secrets
+var secretArgsCommands = []string{"secrets update", "config set"}
+
+func isSecretFlag(name string) bool {
+	if secretFlags[name] {
+		return true
+	}
+	for _, part := range []string{"secret", "password", "passphrase"} {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a flag of a command by its name or shorthand, including flags inherited from parent commands.
+func lookupFlag(cmd *cobra.Command, name string, shorthand bool) *pflag.Flag {
+	if cmd == nil {
+		return nil
+	}
+	for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+		var f *pflag.Flag
+		if shorthand {
+			f = flags.ShorthandLookup(name)
+		} else {
+			f = flags.Lookup(name)
+		}
+		if f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// Tells if a flag takes a value, and if that value is a secret.
+// Unknown flags are assumed to take a value only if it is a secret.
+func flagKind(cmd *cobra.Command, name string, shorthand bool) (takesValue, secret bool) {
+	if f := lookupFlag(cmd, name, shorthand); f != nil {
+		return len(f.NoOptDefVal) == 0, isSecretFlag(f.Name)
+	}
+	secret = isSecretFlag(name)
+	return secret, secret
+}
+
+// Redacts values of secret flags, and values of <name>=<value> arguments of commands like "secrets update",
+// so that a command line of a given command can be recorded without secrets.
+// Without a command, all <name>=<value> arguments are redacted.
+func redactCommandLine(cmd *cobra.Command, args []string) []string {
+	secretArgs := cmd == nil
+	if cmd != nil {
+		path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name())
+		for _, c := range secretArgsCommands {
+			secretArgs = secretArgs || strings.HasSuffix(path, " "+c)
+		}
+	}
+
+	res := make([]string, len(args))
+	// What the next argument is: a value of a flag (and if it is a secret one), or a positional argument
+	nextIsValue, nextIsSecret := false, false
+	positionalOnly := false
+	for i, arg := range args {
+		res[i] = arg
+		switch {
+		case i == 0:
+			// A name of the fioctl binary
+		case nextIsValue:
+			if nextIsSecret {
+				res[i] = client.TraceRedacted
+			}
+			nextIsValue, nextIsSecret = false, false
+		case positionalOnly || arg == "-" || !strings.HasPrefix(arg, "-"):
+			if secretArgs && strings.Contains(arg, "=") {
+				name, _, _ := strings.Cut(arg, "=")
+				res[i] = name + "=" + client.TraceRedacted
+			}
+		case arg == "--":
+			positionalOnly = true
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			takesValue, secret := flagKind(cmd, name, false)
+			if hasValue && secret {
+				res[i] = arg[:strings.Index(arg, "=")+1] + client.TraceRedacted
+			} else if !hasValue && takesValue {
+				nextIsValue, nextIsSecret = true, secret
+			}
+		default:
+			// Shorthands may be combined, e.g. -vt TOKEN; the first one taking a value takes the rest of them
+			for j := 1; j < len(arg); j++ {
+				takesValue, secret := flagKind(cmd, arg[j:j+1], true)
+				if !takesValue {
+					continue
+				}
+				value := strings.TrimPrefix(arg[j+1:], "=")
+				if len(value) == 0 {
+					nextIsValue, nextIsSecret = true, secret
+				} else if secret {
+					res[i] = arg[:len(arg)-len(value)] + client.TraceRedacted
+				}
+				break
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the local journal of changes made by fioctl",
+	Long: `Query the local journal of changes made by fioctl.
+
+Every API request which may change something (i.e. other than GET, HEAD, and OPTIONS) is appended
+to a JSON lines journal as soon as it completes. Each line records a time, the local user and host,
+a profile and a factory, the command line (with secrets redacted), the method and endpoint of a request,
+a response status, and a SHA-256 of a request body.
+
+The journal is kept in $XDG_STATE_HOME/fioctl/audit.jsonl (by default ~/.local/state/fioctl/audit.jsonl).
+Teams may point fioctl at a shared journal, or turn it off, in the config file:
+
+  audit:
+    path: /shared/fioctl/audit.jsonl
+    disabled: false`,
+}
+
+func NewCommand() *cobra.Command {
+	return cmd
+}
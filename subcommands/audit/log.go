@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func init() {
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show changes made by fioctl, the most recent last",
+		Args:  cobra.NoArgs,
+		RunE:  doLog,
+		Example: `
+# Show the last 20 changes:
+fioctl audit log
+
+# Show all failed changes to the "prod" factory within the last 2 days:
+fioctl audit log --factory prod --failed --since 48h -n0
+
+# Show who deleted devices since a given date in a shared journal:
+fioctl audit log --file /shared/fioctl/audit.jsonl --command "devices delete" --since 2024-05-01`,
+	}
+	cmd.AddCommand(logCmd)
+	logCmd.Flags().String("file", "", "Journal file to read (default is the journal fioctl writes into)")
+	logCmd.Flags().String("since", "", "Only show changes made after a date (e.g. 2024-05-01 or RFC 3339) or within a duration (e.g. 24h)")
+	logCmd.Flags().String("factory", "", "Only show changes made to this factory")
+	logCmd.Flags().String("user", "", "Only show changes made by this local user")
+	logCmd.Flags().String("command", "", "Only show changes made by command lines containing this text")
+	logCmd.Flags().Bool("failed", false, "Only show requests which failed")
+	logCmd.Flags().IntP("limit", "n", 20, "Show at most this many most recent changes; 0 shows all")
+}
+
+func doLog(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("file")
+	since, _ := cmd.Flags().GetString("since")
+	factory, _ := cmd.Flags().GetString("factory")
+	user, _ := cmd.Flags().GetString("user")
+	command, _ := cmd.Flags().GetString("command")
+	failed, _ := cmd.Flags().GetBool("failed")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	var sinceTime time.Time
+	if len(since) > 0 {
+		var err error
+		if sinceTime, err = parseSince(since); err != nil {
+			return subcommands.UsageError(err)
+		}
+	}
+	if len(path) == 0 {
+		var err error
+		if path, err = subcommands.AuditJournalPath(); err != nil {
+			return err
+		}
+	}
+
+	records, err := subcommands.ReadAuditJournal(path)
+	if err != nil {
+		return err
+	}
+	matched := []subcommands.AuditRecord{}
+	for _, r := range records {
+		if (!sinceTime.IsZero() && r.Time.Before(sinceTime)) ||
+			(len(factory) > 0 && r.Factory != factory) ||
+			(len(user) > 0 && r.User != user) ||
+			(len(command) > 0 && !strings.Contains(r.Command, command)) ||
+			(failed && !r.Failed()) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+
+	out := subcommands.NewOutput(matched, "TIME", "USER", "FACTORY", "STATUS", "REQUEST", "COMMAND")
+	for _, r := range matched {
+		status := fmt.Sprint(r.Status)
+		if len(r.Error) > 0 {
+			status = "error"
+		}
+		userHost := r.User
+		if len(r.Host) > 0 {
+			userHost += "@" + r.Host
+		}
+		out.AddLine(r.Time.Local().Format(time.DateTime), userHost, r.Factory, status,
+			r.Method+" "+endpointPath(r.Endpoint), r.Command)
+	}
+	return out.Print()
+}
+
+func parseSince(since string) (time.Time, error) {
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, since, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid --since value: %s. Use a date (e.g. 2024-05-01 or RFC 3339) or a duration (e.g. 24h)", since)
+}
+
+// A server URL is the same for most entries, so the table only shows a path and a query
+func endpointPath(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && len(u.Path) > 0 {
+		return u.RequestURI()
+	}
+	return endpoint
+}
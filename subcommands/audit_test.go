@@ -0,0 +1,118 @@
+package subcommands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestAuditJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared", "audit.jsonl")
+	viper.Set("audit.path", path)
+	viper.Set("factory", "acme")
+	defer viper.Set("audit.path", "")
+	defer viper.Set("factory", "")
+
+	root := newAuditTestCommands()
+	secrets, _, err := root.Find([]string{"secrets", "update"})
+	require.Nil(t, err)
+	args := []string{"fioctl", "-t", "tok1", "--token=tok2", "-ttok3", "--hsm-pin", "1234",
+		"secrets", "update", "name=value", "-f", "acme"}
+	j, err := NewAuditJournal(secrets, args)
+	require.Nil(t, err)
+	j.Audit(client.AuditEntry{Time: time.Now(), Method: "PATCH", URL: "https://api/x", StatusCode: 200})
+	j.Audit(client.AuditEntry{Time: time.Now(), Method: "DELETE", URL: "https://api/y", Error: "timeout"})
+
+	records, err := ReadAuditJournal(path)
+	require.Nil(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "fioctl -t REDACTED --token=REDACTED -tREDACTED --hsm-pin REDACTED "+
+		"secrets update name=REDACTED -f acme", records[0].Command)
+	assert.Equal(t, "acme", records[0].Factory)
+	assert.Equal(t, "PATCH", records[0].Method)
+	assert.False(t, records[0].Failed())
+	assert.True(t, records[1].Failed())
+
+	records, err = ReadAuditJournal(filepath.Join(t.TempDir(), "missing.jsonl"))
+	require.Nil(t, err)
+	require.Len(t, records, 0)
+}
+
+// A command tree with flags like those of fioctl
+func newAuditTestCommands() *cobra.Command {
+	root := &cobra.Command{Use: "fioctl"}
+	root.PersistentFlags().BoolP("verbose", "v", false, "")
+	root.PersistentFlags().Bool("yes", false, "")
+	root.PersistentFlags().StringP("output", "o", "", "")
+	newGroup := func(name string) *cobra.Command {
+		cmd := &cobra.Command{Use: name}
+		cmd.PersistentFlags().StringP("factory", "f", "", "")
+		cmd.PersistentFlags().StringP("token", "t", "", "")
+		root.AddCommand(cmd)
+		return cmd
+	}
+	newCommand := func(parent *cobra.Command, name string) *cobra.Command {
+		cmd := &cobra.Command{Use: name}
+		cmd.Flags().String("hsm-pin", "", "")
+		cmd.Flags().String("selector", "", "")
+		parent.AddCommand(cmd)
+		return cmd
+	}
+	newCommand(newGroup("secrets"), "update")
+	newCommand(newGroup("config"), "set")
+	devices := newGroup("devices")
+	newCommand(devices, "delete")
+	newCommand(newCommand(devices, "config"), "set")
+	return root
+}
+
+func TestRedactCommandLine(t *testing.T) {
+	root := newAuditTestCommands()
+	for _, tc := range []struct {
+		cmd  []string
+		args string
+		res  string
+	}{
+		{
+			[]string{"secrets", "update"},
+			"fioctl secrets update -vt tok1 --yes name=value other=a=b",
+			"fioctl secrets update -vt REDACTED --yes name=REDACTED other=REDACTED",
+		},
+		{
+			[]string{"secrets", "update"},
+			"fioctl secrets update -vttok2 -vt=tok3 -- --name=value",
+			"fioctl secrets update -vtREDACTED -vt=REDACTED -- --name=REDACTED",
+		},
+		{
+			[]string{"devices", "config", "set"},
+			"fioctl devices config set dev-1 --token tok4 -o json app.env=KEY=value",
+			"fioctl devices config set dev-1 --token REDACTED -o json app.env=REDACTED",
+		},
+		{
+			[]string{"devices", "delete"},
+			"fioctl devices delete --selector group=prod,name=dev-% --yes -vt tok5 -f acme",
+			"fioctl devices delete --selector group=prod,name=dev-% --yes -vt REDACTED -f acme",
+		},
+		{
+			[]string{"devices", "delete"},
+			"fioctl devices delete --selector=group=prod --hsm-pin=1234 --client-secret s3cr3t extra=value",
+			"fioctl devices delete --selector=group=prod --hsm-pin=REDACTED --client-secret REDACTED extra=value",
+		},
+	} {
+		cmd, _, err := root.Find(tc.cmd)
+		require.Nil(t, err)
+		assert.Equal(t, tc.res, strings.Join(redactCommandLine(cmd, strings.Fields(tc.args)), " "), tc.args)
+	}
+
+	// Without a command, all values which may be secrets are redacted
+	assert.Equal(t, []string{"fioctl", "-t", "REDACTED", "a=REDACTED"},
+		redactCommandLine(nil, []string{"fioctl", "-t", "tok", "a=b"}))
+}
@@ -2,20 +2,19 @@ package subcommands
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/cheynewallace/tabby"
 	canonical "github.com/docker/go/canonical/json"
-	homedir "github.com/mitchellh/go-homedir"
 	"github.com/shurcooL/go/indentwriter"
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	yaml "gopkg.in/yaml.v2"
 
 	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands/version"
@@ -30,9 +29,15 @@ func RequireFactory(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringP("token", "t", "", "API token from https://app.foundries.io/settings/tokens/")
 }
 
-func Login(cmd *cobra.Command) *client.Api {
+func Login(cmd *cobra.Command) (*client.Api, error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	ca := os.Getenv("CACERT")
-	DieNotNil(viper.BindPFlags(cmd.Flags()))
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return nil, err
+	}
 	Config.Token = viper.GetString("token")
 	url := viper.GetString("server.url")
 	if len(url) == 0 {
@@ -41,44 +46,127 @@ func Login(cmd *cobra.Command) *client.Api {
 	if viper.GetBool("server.insecure_skip_verify") {
 		Config.InsecureSkipVerify = true
 	}
+
+	provider := Config.Auth.Provider
 	if len(Config.Token) > 0 {
-		if cmd.Flags().Lookup("factory") != nil && len(viper.GetString("factory")) == 0 {
-			DieNotNil(fmt.Errorf("Required flag \"factory\" not set"))
+		// An explicitly given API token always wins
+		provider = client.AuthProviderToken
+	}
+	switch provider {
+	case client.AuthProviderToken:
+		if len(Config.Token) == 0 {
+			return nil, AuthError(fmt.Errorf("The token auth provider requires a \"token\" to be set"))
+		}
+		if err := assertFactoryFlag(cmd); err != nil {
+			return nil, err
+		}
+		Config.AuthProvider = &client.TokenAuthProvider{Token: Config.Token}
+	case client.AuthProviderEnv, client.AuthProviderCommand:
+		if err := assertFactoryFlag(cmd); err != nil {
+			return nil, err
 		}
-		return client.NewApiClient(url, Config, ca, version.Commit)
+		auth, err := client.NewAuthProvider(Config.Auth)
+		if err != nil {
+			return nil, AuthError(err)
+		}
+		Config.AuthProvider = auth
+	case "", client.AuthProviderOAuth:
+		if err := loginOAuth(ctx, cmd); err != nil {
+			return nil, err
+		}
+		Config.AuthProvider = &client.OAuthAuthProvider{Config: Config.ClientCredentials}
+	default:
+		return nil, AuthError(fmt.Errorf("Invalid auth provider: %s", provider))
+	}
+	api, err := client.NewApiClientE(url, Config, ca, version.Commit)
+	if err != nil {
+		return nil, ConfigError(err)
+	}
+	return api.WithContext(ctx), nil
+}
+
+func assertFactoryFlag(cmd *cobra.Command) error {
+	if cmd.Flags().Lookup("factory") != nil && len(viper.GetString("factory")) == 0 {
+		return UsageError(fmt.Errorf("Required flag \"factory\" not set"))
 	}
+	return nil
+}
 
+// Make sure there is a fresh OAuth access token in Config.ClientCredentials
+func loginOAuth(ctx context.Context, cmd *cobra.Command) error {
 	if len(Config.ClientCredentials.ClientId) == 0 {
-		DieNotNil(fmt.Errorf("Please run: \"fioctl login\" first"))
+		return AuthError(fmt.Errorf("Please run: \"fioctl login\" first"))
 	}
-	if cmd.Flags().Lookup("factory") != nil && len(viper.GetString("factory")) == 0 {
-		DieNotNil(fmt.Errorf("Required flag \"factory\" not set"))
+	if err := assertFactoryFlag(cmd); err != nil {
+		return err
+	}
+	if err := LoadCredentials(); err != nil {
+		return err
 	}
 	creds := client.NewClientCredentials(Config.ClientCredentials)
+	creds.SetContext(ctx)
+	creds.SetTracer(Config.Tracer)
 	if viper.GetBool("server.insecure_skip_verify") {
 		creds.InsecureSSL = true
 	}
 
 	expired, err := creds.IsExpired()
-	DieNotNil(err)
+	if err != nil {
+		return AuthError(err)
+	}
 
 	if !expired && len(creds.Config.AccessToken) > 0 {
-		return client.NewApiClient(url, Config, ca, version.Commit)
+		return nil
 	}
 
 	if len(creds.Config.AccessToken) == 0 {
-		DieNotNil(creds.Get())
+		err = creds.Get()
 	} else if creds.HasRefreshToken() {
-		DieNotNil(creds.Refresh())
+		err = creds.Refresh()
 	} else {
-		DieNotNil(fmt.Errorf("Missing refresh token"))
+		err = fmt.Errorf("Missing refresh token")
+	}
+	if err != nil {
+		if ExitCode(err) == ExitError {
+			err = AuthError(err)
+		}
+		return err
+	}
+	if err = SaveOauthConfig(creds.Config); err != nil {
+		return err
 	}
-	SaveOauthConfig(creds.Config)
 	Config.ClientCredentials = creds.Config
-	return client.NewApiClient(url, Config, ca, version.Commit)
+	return nil
 }
 
-func SaveOauthConfig(c client.OAuthConfig) {
+// SaveOauthConfig saves client credentials into the config file, and their secrets into a credential store.
+// Plaintext secrets written by older fioctl versions are kept in the config file; see MigrateOauthConfig.
+func SaveOauthConfig(c client.OAuthConfig) error {
+	if legacyPlaintextCredentials {
+		return saveOauthConfig(c, nil)
+	}
+	store, err := NewCredentialStore()
+	if err != nil {
+		return err
+	}
+	return saveOauthConfig(c, store)
+}
+
+// MigrateOauthConfig works like SaveOauthConfig, but also moves plaintext secrets out of the config file.
+func MigrateOauthConfig(c client.OAuthConfig) error {
+	store, err := NewCredentialStore()
+	if err != nil {
+		return err
+	}
+	if err = saveOauthConfig(c, store); err != nil {
+		return err
+	}
+	legacyPlaintextCredentials = false
+	return nil
+}
+
+// Secrets are written into the config file if the store is nil.
+func saveOauthConfig(c client.OAuthConfig, store CredentialStore) error {
 	viper.Set("clientcredentials.client_id", c.ClientId)
 	viper.Set("clientcredentials.client_secret", c.ClientSecret)
 
@@ -89,61 +177,67 @@ func SaveOauthConfig(c client.OAuthConfig) {
 	viper.Set("clientcredentials.created", c.Created)
 	viper.Set("clientcredentials.url", c.URL)
 
-	// viper.WriteConfig isn't so great for this. It doesn't just write
-	// these values but any other flags that were present when this runs.
-	// This gets run automatically when "logging in". So you sometimes
-	// accidentally write CLI flags viper finds to the file, that you
-	// don't intend to be saved. So we do it the hard way:
-	name := viper.ConfigFileUsed()
-	if len(name) == 0 {
-		logrus.Debug("Guessing config file from path")
-		path, err := homedir.Expand("~/.config")
-		DieNotNil(err)
-		name = filepath.Join(path, "fioctl.yaml")
-	}
-	// Try to read in config
-	cfg := make(map[string]interface{})
-	buf, err := os.ReadFile(name)
-	if err == nil {
-		DieNotNil(yaml.Unmarshal(buf, &cfg), "Unable unmarshal configuration:")
-	}
-	val := viper.Get("clientcredentials")
-	cfg["clientcredentials"] = val
-	if len(c.DefaultOrg) > 0 {
-		cfg["factory"] = c.DefaultOrg
+	creds := map[string]interface{}{
+		"client_id":  c.ClientId,
+		"token_type": c.TokenType,
+		"expires_in": c.ExpiresIn,
+		"created":    c.Created,
+		"url":        c.URL,
 	}
-	cfg["server"] = map[string]interface{}{
-		"insecure_skip_verify": viper.GetBool("server.insecure_skip_verify"),
-		"url":                  viper.GetString("server.url"),
+	secrets := CredentialSecrets{
+		ClientSecret: c.ClientSecret,
+		AccessToken:  c.AccessToken,
+		RefreshToken: c.RefreshToken,
+	}
+	if store == nil {
+		creds["client_secret"] = secrets.ClientSecret
+		creds["access_token"] = secrets.AccessToken
+		creds["refresh_token"] = secrets.RefreshToken
+	} else if key, err := credentialsKey(); err != nil {
+		return err
+	} else if secrets.IsEmpty() {
+		if err := store.Delete(key); err != nil {
+			return fmt.Errorf("Unable to delete credentials from the %s store: %w", store.Name(), err)
+		}
+	} else if err := store.Save(key, secrets); err != nil {
+		return fmt.Errorf("Unable to save credentials into the %s store: %w", store.Name(), err)
 	}
-	buf, err = yaml.Marshal(cfg)
-	DieNotNil(err, "Unable to marshall oauth config:")
-	DieNotNil(os.WriteFile(name, buf, os.FileMode(0644)), "Unable to update config: ")
-}
-
-// An os.Exit exits immediately, skipping all deferred functions
-// We need a way to execute the finalizing code in some cases.
-type LastWill = func()
-
-var onLastWill []LastWill
-
-func AddLastWill(lastWill LastWill) {
-	onLastWill = append(onLastWill, lastWill)
-}
 
-func DieNotNil(err error, message ...string) {
+	cfg, err := LoadConfigFile()
 	if err != nil {
-		parts := []interface{}{"ERROR:"}
-		for _, p := range message {
-			parts = append(parts, p)
-		}
-		parts = append(parts, err)
-		fmt.Println(parts...)
-		for _, w := range onLastWill {
-			w()
+		return err
+	}
+	// Only the active profile is updated, if there is one
+	settings := cfg.Settings()
+	settings["clientcredentials"] = creds
+	if store != nil {
+		// Secrets must be read from the same store they were saved into
+		credentials, ok := settings["credentials"].(map[string]interface{})
+		if !ok {
+			credentials = make(map[string]interface{})
 		}
-		os.Exit(1)
+		credentials["store"] = store.Name()
+		settings["credentials"] = credentials
+		viper.Set("credentials.store", store.Name())
+	}
+	if len(c.DefaultOrg) > 0 {
+		settings["factory"] = c.DefaultOrg
 	}
+	// Keep other server settings (e.g. a CA bundle or a proxy) intact
+	server, ok := settings["server"].(map[string]interface{})
+	if !ok {
+		server = make(map[string]interface{})
+	}
+	server["insecure_skip_verify"] = viper.GetBool("server.insecure_skip_verify")
+	server["url"] = viper.GetString("server.url")
+	settings["server"] = server
+	return cfg.Save()
+}
+
+// IsTerminal returns true if a file is an interactive terminal (and not e.g. a pipe).
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
 }
 
 func Tabby(indent int, columns ...interface{}) *tabby.Tabby {
@@ -172,15 +266,16 @@ func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func AssertWritable(path string) {
+func AssertWritable(path string) error {
 	st, err := os.Stat(path)
-	DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, st.Mode())
 	if err != nil {
-		fmt.Println("ERROR: File is not writeable:", path)
-		os.Exit(1)
+		return fmt.Errorf("File is not writeable: %s", path)
 	}
-	f.Close()
+	return f.Close()
 }
 
 func IsSliceSetEqual[T comparable](first, second []T) bool {
@@ -269,26 +364,24 @@ func FindWritableDirInPath(helperPath string) string {
 	return ""
 }
 
-func ShowPages(showPage uint64, next *string) {
-	if next != nil {
-		fmt.Print("\nNext page can be viewed with: ")
-		found := false
-		for i := 0; i < len(os.Args); i++ {
-			arg := os.Args[i]
-			if (len(arg) > 2 && arg[:2] == "-p") || (len(arg) > 7 && arg[:7] == "--page=") {
-				fmt.Printf("-p%d ", showPage+1)
-				found = true
-			} else if arg == "-p" || arg == "--page" {
-				fmt.Printf("-p%d ", showPage+1)
-				found = true
-				i++
-			} else {
-				fmt.Print(os.Args[i], " ")
-			}
-		}
-		if !found {
-			fmt.Print("-p", showPage+1)
+// ShowPages tells how to view the next page of a list, and all pages if a command has the --all flag.
+func ShowPages(cmd *cobra.Command, showPage uint64, next *string) {
+	if next == nil || !IsTableOutput() {
+		return
+	}
+	// A command line without its page flag
+	var args []string
+	for i := 0; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if arg == "-p" || arg == "--page" {
+			i++
+		} else if !strings.HasPrefix(arg, "-p") && !strings.HasPrefix(arg, "--page=") {
+			args = append(args, arg)
 		}
-		fmt.Println()
+	}
+	cmdline := strings.Join(args, " ")
+	fmt.Printf("\nNext page can be viewed with: %s -p%d\n", cmdline, showPage+1)
+	if cmd.Flags().Lookup("all") != nil {
+		fmt.Printf("All pages can be viewed with: %s --all\n", cmdline)
 	}
 }
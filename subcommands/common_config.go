@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"os"
 	"regexp"
 	"strings"
@@ -38,28 +39,32 @@ type SetConfigOptions struct {
 	FileArgs    []string
 	IsRawFile   bool
 	SetFunc     func(client.ConfigCreateRequest) error
-	EncryptFunc func(string) string
+	EncryptFunc func(string) (string, error)
 }
 
-func SetConfig(opts *SetConfigOptions) {
+func SetConfig(opts *SetConfigOptions) error {
 	cfg := client.ConfigCreateRequest{Reason: opts.Reason}
 	if opts.IsRawFile {
 		if len(opts.FileArgs) != 1 {
-			DieNotNil(fmt.Errorf("Raw file only accepts one file argument"))
+			return UsageError(fmt.Errorf("Raw file only accepts one file argument"))
+		}
+		if err := ReadConfig(opts.FileArgs[0], &cfg); err != nil {
+			return err
 		}
-		ReadConfig(opts.FileArgs[0], &cfg)
 	} else {
 		for _, keyval := range opts.FileArgs {
 			parts := strings.SplitN(keyval, "=", 2)
 			if len(parts) != 2 {
-				DieNotNil(fmt.Errorf("Invalid file=content argument: %s", keyval))
+				return UsageError(fmt.Errorf("Invalid file=content argument: %s", keyval))
 			}
 			// support for filename=filecontent format
 			content := parts[1]
 			if len(content) > 0 && content[0] == '=' {
 				// support for filename==/file/path.ext format
 				data, err := os.ReadFile(content[1:])
-				DieNotNil(err, "Unable to read config file:")
+				if err != nil {
+					return fmt.Errorf("Unable to read config file: %w", err)
+				}
 				content = string(data)
 			}
 			cfg.Files = append(cfg.Files, client.ConfigFile{Name: parts[0], Value: content})
@@ -70,56 +75,48 @@ func SetConfig(opts *SetConfigOptions) {
 		for i := range cfg.Files {
 			file := &cfg.Files[i]
 			if !file.Unencrypted {
-				file.Value = opts.EncryptFunc(file.Value)
+				var err error
+				if file.Value, err = opts.EncryptFunc(file.Value); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	DieNotNil(opts.SetFunc(cfg))
+	return opts.SetFunc(cfg)
 }
 
 type LogConfigsOptions struct {
 	UserLookup    map[string]client.FactoryUser
 	Limit         int
 	ShowAppliedAt bool
-	ListFunc      func() (*client.DeviceConfigList, error)
-	ListContFunc  func(string) (*client.DeviceConfigList, error)
+	Configs       iter.Seq2[client.DeviceConfig, error]
 }
 
-func LogConfigs(opts *LogConfigsOptions) {
-	var dcl *client.DeviceConfigList
+func LogConfigs(opts *LogConfigsOptions) error {
 	listLimit := opts.Limit
-	for {
-		var err error
-		if dcl == nil {
-			dcl, err = opts.ListFunc()
-		} else {
-			if dcl.Next != nil {
-				dcl, err = opts.ListContFunc(*dcl.Next)
-			} else {
-				return
-			}
+	for cfg, err := range opts.Configs {
+		if err != nil {
+			return err
 		}
-		DieNotNil(err)
-		for _, cfg := range dcl.Configs {
-			if len(cfg.CreatedBy) > 0 {
-				if v, ok := opts.UserLookup[cfg.CreatedBy]; ok {
-					cfg.CreatedBy = fmt.Sprintf("%s / %s", v.PolisId, v.Name)
-				} else {
-					cfg.CreatedBy = fmt.Sprintf("%s / ?", cfg.CreatedBy)
-				}
-			}
-			PrintConfig(&cfg, opts.ShowAppliedAt, true, "")
-			if listLimit -= 1; listLimit == 0 {
-				return
+		if len(cfg.CreatedBy) > 0 {
+			if v, ok := opts.UserLookup[cfg.CreatedBy]; ok {
+				cfg.CreatedBy = fmt.Sprintf("%s / %s", v.PolisId, v.Name)
 			} else {
-				fmt.Println("")
+				cfg.CreatedBy = fmt.Sprintf("%s / ?", cfg.CreatedBy)
 			}
 		}
+		PrintConfig(&cfg, opts.ShowAppliedAt, true, "")
+		if listLimit -= 1; listLimit == 0 {
+			return nil
+		} else {
+			fmt.Println("")
+		}
 	}
+	return nil
 }
 
-func ReadConfig(configFile string, cfg *client.ConfigCreateRequest) {
+func ReadConfig(configFile string, cfg *client.ConfigCreateRequest) error {
 	var content []byte
 	var err error
 
@@ -130,8 +127,13 @@ func ReadConfig(configFile string, cfg *client.ConfigCreateRequest) {
 		content, err = os.ReadFile(configFile)
 	}
 
-	DieNotNil(err, "Unable to read config file:")
-	DieNotNil(json.Unmarshal(content, cfg), "Unable to parse config file:")
+	if err != nil {
+		return fmt.Errorf("Unable to read config file: %w", err)
+	}
+	if err = json.Unmarshal(content, cfg); err != nil {
+		return fmt.Errorf("Unable to parse config file: %w", err)
+	}
+	return nil
 }
 
 func PrintConfig(cfg *client.DeviceConfig, showAppliedAt, highlightFirstLine bool, indent string) {
@@ -202,30 +204,38 @@ type SetUpdatesConfigOptions struct {
 	Device     *client.Device
 	ListFunc   func() (*client.DeviceConfigList, error)
 	SetFunc    func(client.ConfigCreateRequest, bool) error
+	// Where the current and the new configuration is printed; os.Stdout if not set
+	Out io.Writer
 }
 
-func SetUpdatesConfig(opts *SetUpdatesConfigOptions, reportedTag string, reportedApps []string) {
-	DieNotNil(validateUpdateArgs(opts))
+func SetUpdatesConfig(opts *SetUpdatesConfigOptions, reportedTag string, reportedApps []string) error {
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if err := validateUpdateArgs(opts); err != nil {
+		return UsageError(err)
+	}
 
 	dcl, err := opts.ListFunc()
-	if !opts.IsForced {
-		DieNotNil(err, "Failed to fetch existing config changelog (override with --force):")
+	if err != nil && !opts.IsForced {
+		return fmt.Errorf("Failed to fetch existing config changelog (override with --force): %w", err)
 	}
 	sota, err := loadSotaConfig(dcl)
-	if !opts.IsForced {
-		DieNotNil(err, "Invalid FIO toml file (override with --force):")
+	if err != nil && !opts.IsForced {
+		return fmt.Errorf("Invalid FIO toml file (override with --force): %w", err)
 	}
 
 	if opts.UpdateApps == "" && opts.UpdateTag == "" {
 		if opts.Device != nil {
-			fmt.Println("= Reporting to server with")
-			fmt.Println(" Tag: ", opts.Device.Tag)
-			fmt.Println(" Apps: ", strings.Join(opts.Device.DockerApps, ","))
-			fmt.Println("")
+			fmt.Fprintln(out, "= Reporting to server with")
+			fmt.Fprintln(out, " Tag: ", opts.Device.Tag)
+			fmt.Fprintln(out, " Apps: ", strings.Join(opts.Device.DockerApps, ","))
+			fmt.Fprintln(out)
 		}
-		fmt.Println("= Configured overrides")
-		fmt.Println(sota)
-		return
+		fmt.Fprintln(out, "= Configured overrides")
+		fmt.Fprintln(out, sota)
+		return nil
 	}
 
 	configuredApps := sota.GetDefault("pacman.docker_apps", "").(string)
@@ -236,20 +246,21 @@ func SetUpdatesConfig(opts *SetUpdatesConfigOptions, reportedTag string, reporte
 		if strings.TrimSpace(opts.UpdateApps) == "," {
 			opts.UpdateApps = ""
 		}
-		fmt.Printf("Currently configured apps: [%s]\n", configuredApps)
+		fmt.Fprintf(out, "Currently configured apps: [%s]\n", configuredApps)
 		if reportedApps != nil {
-			fmt.Printf("Apps reported as installed on device: [%s]\n", strings.Join(reportedApps, ","))
+			fmt.Fprintf(out, "Apps reported as installed on device: [%s]\n", strings.Join(reportedApps, ","))
 		}
 		if strings.TrimSpace(opts.UpdateApps) == "-" {
-			fmt.Printf("Setting apps to system default.\n")
-			if sota.Has("pacman.docker_apps") {
-				DieNotNil(sota.Delete("pacman.docker_apps"))
-			}
-			if sota.Has("pacman.compose_apps") {
-				DieNotNil(sota.Delete("pacman.compose_apps"))
+			fmt.Fprintf(out, "Setting apps to system default.\n")
+			for _, key := range []string{"pacman.docker_apps", "pacman.compose_apps"} {
+				if sota.Has(key) {
+					if err := sota.Delete(key); err != nil {
+						return err
+					}
+				}
 			}
 		} else {
-			fmt.Printf("Setting apps to [%s]\n", opts.UpdateApps)
+			fmt.Fprintf(out, "Setting apps to [%s]\n", opts.UpdateApps)
 			sota.Set("pacman.docker_apps", opts.UpdateApps)
 			sota.Set("pacman.compose_apps", opts.UpdateApps)
 		}
@@ -259,29 +270,33 @@ func SetUpdatesConfig(opts *SetUpdatesConfigOptions, reportedTag string, reporte
 		if strings.TrimSpace(opts.UpdateTag) == "," {
 			opts.UpdateTag = ""
 		}
-		fmt.Printf("Currently configured tag: %s\n", configuredTag)
+		fmt.Fprintf(out, "Currently configured tag: %s\n", configuredTag)
 		if len(reportedTag) > 0 {
-			fmt.Printf("Tag reported by device: %s\n", reportedTag)
+			fmt.Fprintf(out, "Tag reported by device: %s\n", reportedTag)
 		}
 		if strings.TrimSpace(opts.UpdateTag) == "-" {
-			fmt.Printf("Setting tag to system default.\n")
+			fmt.Fprintf(out, "Setting tag to system default.\n")
 			if sota.Has("pacman.tags") {
-				DieNotNil(sota.Delete("pacman.tags"))
+				if err := sota.Delete("pacman.tags"); err != nil {
+					return err
+				}
 			}
 		} else {
-			fmt.Printf("Setting tag to %s\n", opts.UpdateTag)
+			fmt.Fprintf(out, "Setting tag to %s\n", opts.UpdateTag)
 			sota.Set("pacman.tags", opts.UpdateTag)
 		}
 		changed = true
 	}
 
 	if !changed {
-		fmt.Println("No changes found. Device is already configured with the specified options.")
-		os.Exit(0)
+		fmt.Fprintln(out, "No changes found. Device is already configured with the specified options.")
+		return nil
 	}
 
 	newToml, err := sota.ToTomlString()
-	DieNotNil(err, "Unable to encode toml:")
+	if err != nil {
+		return fmt.Errorf("Unable to encode toml: %w", err)
+	}
 
 	cfg := client.ConfigCreateRequest{
 		Reason: "Override aktualizr-lite update configuration ",
@@ -295,10 +310,10 @@ func SetUpdatesConfig(opts *SetUpdatesConfigOptions, reportedTag string, reporte
 		},
 	}
 	if opts.IsDryRun {
-		fmt.Println(newToml)
-	} else {
-		DieNotNil(opts.SetFunc(cfg, opts.IsForced))
+		fmt.Fprintln(out, newToml)
+		return nil
 	}
+	return opts.SetFunc(cfg, opts.IsForced)
 }
 
 func loadSotaConfig(dcl *client.DeviceConfigList) (sota *toml.Tree, err error) {
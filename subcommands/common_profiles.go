@@ -0,0 +1,226 @@
+package subcommands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of the fioctl.yaml holding named profiles.
+// A profile may contain any settings of the top level (e.g. factory, server, clientcredentials),
+// which override the top level settings when that profile is active.
+const (
+	ProfilesKey       = "profiles"
+	CurrentProfileKey = "current_profile"
+)
+
+// ProfileName is the name of the active profile; it is empty if the top level settings are used.
+var ProfileName string
+
+// ErrProfileNotFound is returned when a requested profile is not defined in the config file.
+var ErrProfileNotFound = errors.New("Profile not found")
+
+func ProfileNotFoundError(name string) error {
+	return WithExitCode(fmt.Errorf("%w: %s", ErrProfileNotFound, name), ExitNotFound)
+}
+
+// Top level settings which identify a user. A profile never inherits these, so that it does not
+// use credentials of another factory or server by accident.
+var profileCredentialKeys = []string{"clientcredentials", "token", "auth"}
+
+// ApplyProfile merges the settings of a profile over the top level settings read by viper.
+// The profile is selected by a given name, or by the FIOCTL_PROFILE environment variable,
+// or by the current_profile key of the config file, in this order.
+func ApplyProfile(name string) error {
+	if len(name) == 0 {
+		name = os.Getenv("FIOCTL_PROFILE")
+	}
+	if len(name) == 0 {
+		name = viper.GetString(CurrentProfileKey)
+	}
+	if len(name) == 0 {
+		return nil
+	}
+	cfg, err := LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	name = cfg.profileKey(name)
+	settings := cfg.Profile(name)
+	if settings == nil {
+		return ProfileNotFoundError(name)
+	}
+	// Start over from the config file, as a profile may be applied more than once (e.g. by completions)
+	base := make(map[string]interface{}, len(cfg.Values))
+	for key, val := range cfg.Values {
+		if !slices.Contains(profileCredentialKeys, strings.ToLower(key)) {
+			base[key] = val
+		}
+	}
+	buf, err := yaml.Marshal(base)
+	if err != nil {
+		return fmt.Errorf("Unable to apply profile %s: %w", name, err)
+	}
+	viper.SetConfigType("yaml")
+	if err = viper.ReadConfig(bytes.NewReader(buf)); err != nil {
+		return fmt.Errorf("Unable to apply profile %s: %w", name, err)
+	}
+	if err = viper.MergeConfigMap(settings); err != nil {
+		return fmt.Errorf("Unable to apply profile %s: %w", name, err)
+	}
+	logrus.Debugf("Using profile: %s", name)
+	ProfileName = name
+	return nil
+}
+
+// ConfigFile is a raw content of the fioctl.yaml, used to update some of its values and keep others intact.
+// viper.WriteConfig isn't so great for this, as it also writes all flags and environment variables it knows.
+type ConfigFile struct {
+	Path   string
+	Values map[string]interface{}
+}
+
+// Returns a path of the fioctl.yaml in use, or of the one to create
+func configFilePath() (string, error) {
+	name := viper.ConfigFileUsed()
+	if len(name) == 0 {
+		logrus.Debug("Guessing config file from path")
+		path, err := homedir.Expand("~/.config")
+		if err != nil {
+			return "", err
+		}
+		name = filepath.Join(path, "fioctl.yaml")
+	}
+	return name, nil
+}
+
+func LoadConfigFile() (*ConfigFile, error) {
+	name, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ConfigFile{Path: name, Values: make(map[string]interface{})}
+	buf, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Unable to read configuration: %w", err)
+	}
+	if err = yaml.Unmarshal(buf, &cfg.Values); err != nil {
+		return nil, fmt.Errorf("Unable unmarshal configuration: %w", err)
+	}
+	if cfg.Values == nil {
+		// An empty file
+		cfg.Values = make(map[string]interface{})
+	}
+	return cfg, nil
+}
+
+func (c *ConfigFile) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Values); err != nil {
+		return fmt.Errorf("Unable to marshall config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("Unable to marshall config: %w", err)
+	}
+	// The config may hold credentials, so it must only be readable by its owner
+	if err := os.WriteFile(c.Path, buf.Bytes(), os.FileMode(0600)); err != nil {
+		return fmt.Errorf("Unable to update config: %w", err)
+	}
+	// WriteFile does not change the mode of an existing file
+	if err := os.Chmod(c.Path, os.FileMode(0600)); err != nil {
+		return fmt.Errorf("Unable to update config: %w", err)
+	}
+	return nil
+}
+
+// Profiles returns the names of all profiles sorted alphabetically.
+func (c *ConfigFile) Profiles() []string {
+	profiles, _ := c.Values[ProfilesKey].(map[string]interface{})
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile names are case insensitive, like all other keys read by viper.
+// Returns the name of an existing profile matching a given name, or that name if there is none.
+func (c *ConfigFile) profileKey(name string) string {
+	profiles, _ := c.Values[ProfilesKey].(map[string]interface{})
+	if _, ok := profiles[name]; ok {
+		return name
+	}
+	for key := range profiles {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// Profile returns the settings of a profile, or nil if it does not exist.
+func (c *ConfigFile) Profile(name string) map[string]interface{} {
+	profiles, _ := c.Values[ProfilesKey].(map[string]interface{})
+	if profile, ok := profiles[c.profileKey(name)]; ok {
+		if settings, ok := profile.(map[string]interface{}); ok {
+			return settings
+		}
+		// A profile with no settings
+		return make(map[string]interface{})
+	}
+	return nil
+}
+
+func (c *ConfigFile) SetProfile(name string, settings map[string]interface{}) {
+	profiles, ok := c.Values[ProfilesKey].(map[string]interface{})
+	if !ok {
+		profiles = make(map[string]interface{})
+		c.Values[ProfilesKey] = profiles
+	}
+	profiles[c.profileKey(name)] = settings
+}
+
+func (c *ConfigFile) DeleteProfile(name string) {
+	name = c.profileKey(name)
+	if profiles, ok := c.Values[ProfilesKey].(map[string]interface{}); ok {
+		delete(profiles, name)
+	}
+	if strings.EqualFold(c.CurrentProfile(), name) {
+		delete(c.Values, CurrentProfileKey)
+	}
+}
+
+func (c *ConfigFile) CurrentProfile() string {
+	name, _ := c.Values[CurrentProfileKey].(string)
+	return name
+}
+
+// Settings returns the settings of the active profile, or the top level settings if no profile is active.
+// These are the settings changed by the login and the token refresh.
+func (c *ConfigFile) Settings() map[string]interface{} {
+	if len(ProfileName) == 0 {
+		return c.Values
+	}
+	settings := c.Profile(ProfileName)
+	if settings == nil {
+		settings = make(map[string]interface{})
+	}
+	c.SetProfile(ProfileName, settings)
+	return settings
+}
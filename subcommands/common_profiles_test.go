@@ -0,0 +1,61 @@
+package subcommands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fioctl.yaml")
+	require.Nil(t, os.WriteFile(path, []byte(`
+factory: acme
+token: top-token
+server:
+  url: https://api.example.com
+  ca_cert: /etc/ca.pem
+clientcredentials:
+  client_id: top-client
+  access_token: top-access
+profiles:
+  Staging:
+    factory: acme-staging
+    server:
+      url: https://api.staging.example.com
+`), 0o600))
+	viper.Reset()
+	defer viper.Reset()
+	defer func() { ProfileName = "" }()
+	viper.SetConfigFile(path)
+	require.Nil(t, viper.ReadInConfig())
+
+	err := ApplyProfile("missing")
+	require.ErrorIs(t, err, ErrProfileNotFound)
+
+	require.Nil(t, ApplyProfile("staging"))
+	assert.Equal(t, "Staging", ProfileName)
+	assert.Equal(t, "acme-staging", viper.GetString("factory"))
+	assert.Equal(t, "https://api.staging.example.com", viper.GetString("server.url"))
+	assert.Equal(t, "/etc/ca.pem", viper.GetString("server.ca_cert"))
+	// Credentials of the top level are not inherited
+	assert.Empty(t, viper.GetString("token"))
+	assert.Empty(t, viper.GetString("clientcredentials.client_id"))
+	assert.Empty(t, viper.GetString("clientcredentials.access_token"))
+
+	cfg, err := LoadConfigFile()
+	require.Nil(t, err)
+	cfg.Settings()["factory"] = "acme-qa"
+	require.Nil(t, cfg.Save())
+	cfg, err = LoadConfigFile()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Staging"}, cfg.Profiles())
+	assert.Equal(t, "acme-qa", cfg.Profile("STAGING")["factory"])
+
+	// A profile can be applied again, e.g. by completions
+	require.Nil(t, ApplyProfile("STAGING"))
+	assert.Equal(t, "acme-qa", viper.GetString("factory"))
+}
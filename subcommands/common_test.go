@@ -0,0 +1,59 @@
+package subcommands
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowPages(t *testing.T) {
+	withAll := &cobra.Command{Use: "list"}
+	withAll.Flags().Bool("all", false, "")
+	withoutAll := &cobra.Command{Use: "list"}
+	next := "https://api/next"
+
+	origArgs := os.Args
+	defer func() { os.Args = origArgs }()
+	for _, tc := range []struct {
+		args []string
+		cmd  *cobra.Command
+		next *string
+		out  string
+	}{
+		{
+			[]string{"fioctl", "devices", "list", "-p", "2", "-n", "50"}, withAll, &next,
+			"\nNext page can be viewed with: fioctl devices list -n 50 -p3\n" +
+				"All pages can be viewed with: fioctl devices list -n 50 --all\n",
+		},
+		{
+			[]string{"fioctl", "waves", "list", "--page=2"}, withAll, &next,
+			"\nNext page can be viewed with: fioctl waves list -p3\n" +
+				"All pages can be viewed with: fioctl waves list --all\n",
+		},
+		{
+			[]string{"fioctl", "waves", "list", "-p2", "--page", "2"}, withoutAll, &next,
+			"\nNext page can be viewed with: fioctl waves list -p3\n",
+		},
+		{[]string{"fioctl", "waves", "list", "-p2"}, withAll, nil, ""},
+	} {
+		os.Args = tc.args
+		assert.Equal(t, tc.out, captureStdout(t, func() { ShowPages(tc.cmd, 2, tc.next) }), tc.args)
+	}
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	orig := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = orig
+	require.Nil(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.Nil(t, err)
+	return string(out)
+}
@@ -0,0 +1,316 @@
+package subcommands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// Shell completions are listed through the API, so they are cached for a while
+// to keep pressing <TAB> snappy.
+const completionCacheTTL = time.Minute
+
+// The maximum number of devices or waves listed for a completion
+const completionLimit = 1000
+
+// CompletionLister lists the names of factory resources to complete a command line argument.
+type CompletionLister func(api *client.Api, factory string) ([]string, error)
+
+type completionCacheEntry struct {
+	Stored time.Time `json:"stored"`
+	Items  []string  `json:"items"`
+}
+
+// NewCompletionFunc returns a cobra completion callback which lists resources of a given kind.
+func NewCompletionFunc(kind string, list CompletionLister) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return Complete(cmd, toComplete, kind, "", list)
+	}
+}
+
+// Complete lists resources of a given kind for the factory of a command (i.e. its --factory flag or
+// a config file) using the active credentials, and returns those starting with a typed text.
+// A key is what a listing depends on besides a factory, e.g. a device whose config files are listed;
+// listings with different keys are cached separately.
+func Complete(cmd *cobra.Command, toComplete, kind, key string, list CompletionLister) ([]cobra.Completion, cobra.ShellCompDirective) {
+	items, err := completionItems(cmd, kind, key, list)
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("Unable to list %s: %s", kind, err), false)
+		return nil, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveError
+	}
+	completions := make([]cobra.Completion, 0, len(items))
+	for _, item := range items {
+		if strings.HasPrefix(item, toComplete) {
+			completions = append(completions, item)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteArgs returns a completion callback which completes each positional argument with its own callback.
+// A nil callback (or a missing one) means that an argument is not completed.
+func CompleteArgs(funcs ...cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) < len(funcs) && funcs[len(args)] != nil {
+			return funcs[len(args)](cmd, args, toComplete)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// RegisterFlagCompletion sets a completion callback of a flag; it panics if a flag does not exist.
+func RegisterFlagCompletion(cmd *cobra.Command, flag string, fn cobra.CompletionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, fn); err != nil {
+		panic(fmt.Sprintf("Unable to register a completion of the --%s flag: %s", flag, err))
+	}
+}
+
+func completionItems(cmd *cobra.Command, kind, key string, list CompletionLister) ([]string, error) {
+	// The root command's config loader runs before the completed command's flags are parsed
+	if flag := cmd.Flags().Lookup("profile"); flag != nil && flag.Changed && !strings.EqualFold(flag.Value.String(), ProfileName) {
+		if err := ApplyProfile(flag.Value.String()); err != nil {
+			return nil, err
+		}
+		tracer, auditor := Config.Tracer, Config.Auditor
+		if err := viper.Unmarshal(&Config); err != nil {
+			return nil, err
+		}
+		Config.Tracer, Config.Auditor = tracer, auditor
+	}
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return nil, err
+	}
+	factory := viper.GetString("factory")
+
+	cachePath := completionCachePath(factory, kind, key)
+	if len(cachePath) > 0 {
+		if buf, err := os.ReadFile(cachePath); err == nil {
+			var entry completionCacheEntry
+			if err = json.Unmarshal(buf, &entry); err == nil && time.Since(entry.Stored) < completionCacheTTL {
+				return entry.Items, nil
+			}
+		}
+	}
+
+	// There is no way to answer a passphrase prompt while a shell is completing a command line
+	passphrasePrompt = false
+	api, err := Login(cmd)
+	if err != nil {
+		return nil, err
+	}
+	items, err := list(api, factory)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cachePath) > 0 {
+		buf, err := json.Marshal(completionCacheEntry{Stored: time.Now(), Items: items})
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
+		}
+		if err == nil {
+			err = os.WriteFile(cachePath, buf, 0o600)
+		}
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("Unable to cache %s: %s", kind, err), false)
+		}
+	}
+	return items, nil
+}
+
+// Completions are cached per server, profile, factory, kind, and key; an empty path means no caching.
+func completionCachePath(factory, kind, key string) string {
+	if viper.GetBool("cache.disabled") || viper.GetBool("cache.refresh") {
+		return ""
+	}
+	dir := Config.Cache.Dir
+	if len(dir) == 0 {
+		var err error
+		if dir, err = client.DefaultCacheDir(); err != nil {
+			return ""
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(
+		[]string{viper.GetString("server.url"), ProfileName, factory, kind, key}, "\x00")))
+	return filepath.Join(dir, "completion", hex.EncodeToString(sum[:])+".json")
+}
+
+// CompleteDevices completes device names, or device UUIDs if a command has the --by-uuid flag set.
+func CompleteDevices(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	// Large factories have too many devices to list them all, so they are filtered by a typed prefix
+	byUuid, _ := cmd.Flags().GetBool("by-uuid")
+	filterBy := map[string]string{"name": toComplete + "*"}
+	if byUuid {
+		filterBy = map[string]string{"uuid": toComplete}
+	}
+	key := strconv.FormatBool(byUuid) + ":" + toComplete
+	return Complete(cmd, toComplete, "devices", key, func(api *client.Api, factory string) ([]string, error) {
+		filterBy["factory"] = factory
+		var items []string
+		opts := client.PaginateOptions{Limit: completionLimit}
+		for device, err := range api.DeviceListIter(filterBy, "name", completionLimit, opts) {
+			if err != nil {
+				return nil, err
+			}
+			if byUuid {
+				items = append(items, device.Uuid)
+			} else {
+				items = append(items, device.Name)
+			}
+		}
+		return items, nil
+	})
+}
+
+// CompleteTargetVersions completes Target versions, the most recent first.
+var CompleteTargetVersions = NewCompletionFunc("target-versions", func(api *client.Api, factory string) ([]string, error) {
+	targets, err := completionTargets(api, factory)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	var versions []int
+	for _, custom := range targets {
+		if ver, err := strconv.Atoi(custom.Version); err == nil && !seen[ver] {
+			seen[ver] = true
+			versions = append(versions, ver)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	items := make([]string, len(versions))
+	for i, ver := range versions {
+		items[i] = strconv.Itoa(ver)
+	}
+	return items, nil
+})
+
+// CompleteTargetNames completes Target names.
+var CompleteTargetNames = NewCompletionFunc("target-names", func(api *client.Api, factory string) ([]string, error) {
+	targets, err := completionTargets(api, factory)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+})
+
+// CompleteTags completes tags of Targets.
+var CompleteTags = NewCompletionFunc("tags", func(api *client.Api, factory string) ([]string, error) {
+	targets, err := completionTargets(api, factory)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var tags []string
+	for _, custom := range targets {
+		for _, tag := range custom.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+})
+
+func completionTargets(api *client.Api, factory string) (map[string]*client.TufCustom, error) {
+	targets, err := api.TargetsList(factory)
+	if err != nil {
+		return nil, err
+	}
+	customs := make(map[string]*client.TufCustom, len(targets))
+	for name, target := range targets {
+		if custom, err := api.TargetCustom(target); err == nil {
+			customs[name] = custom
+		}
+	}
+	return customs, nil
+}
+
+// CompleteWaves completes wave names, the most recent first.
+var CompleteWaves = NewCompletionFunc("waves", func(api *client.Api, factory string) ([]string, error) {
+	var names []string
+	for wave, err := range api.FactoryListWavesIter(factory, 100, "", "", client.PaginateOptions{Limit: completionLimit}) {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, wave.Name)
+	}
+	return names, nil
+})
+
+// CompleteDeviceGroups completes device group names.
+var CompleteDeviceGroups = NewCompletionFunc("device-groups", func(api *client.Api, factory string) ([]string, error) {
+	groups, err := api.FactoryListDeviceGroup(factory)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(*groups))
+	for _, group := range *groups {
+		names = append(names, group.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+})
+
+// CompleteEventQueues completes event queue labels.
+var CompleteEventQueues = NewCompletionFunc("event-queues", func(api *client.Api, factory string) ([]string, error) {
+	queues, err := api.EventQueuesList(factory)
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(queues))
+	for _, queue := range queues {
+		labels = append(labels, queue.Label)
+	}
+	sort.Strings(labels)
+	return labels, nil
+})
+
+// CompleteFactoryConfigFiles completes the names of factory config files,
+// or device group config files if a command has the --group flag set.
+func CompleteFactoryConfigFiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	group, _ := cmd.Flags().GetString("group")
+	return Complete(cmd, toComplete, "config-files", group, func(api *client.Api, factory string) ([]string, error) {
+		var dcl *client.DeviceConfigList
+		var err error
+		if len(group) > 0 {
+			dcl, err = api.GroupListConfig(factory, group)
+		} else {
+			dcl, err = api.FactoryListConfig(factory)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ConfigFileNames(dcl), nil
+	})
+}
+
+// ConfigFileNames returns the file names of the most recent config in a list.
+func ConfigFileNames(dcl *client.DeviceConfigList) []string {
+	var names []string
+	if len(dcl.Configs) > 0 {
+		for _, file := range dcl.Configs[0].Files {
+			names = append(names, file.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
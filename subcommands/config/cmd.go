@@ -14,8 +14,9 @@ var (
 var cmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage configuration common to all devices in a Factory",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 }
 
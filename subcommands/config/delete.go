@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -10,25 +12,39 @@ import (
 
 func init() {
 	deleteCmd := &cobra.Command{
-		Use:   "delete <file>",
-		Short: "Delete file from the current configuration",
-		Run:   doConfigDelete,
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <file>",
+		Short:             "Delete file from the current configuration",
+		RunE:              doConfigDelete,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteFactoryConfigFiles),
 	}
 	cmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().StringP("group", "g", "", "Device group to use")
+	subcommands.RegisterFlagCompletion(deleteCmd, "group", subcommands.CompleteDeviceGroups)
 }
 
-func doConfigDelete(cmd *cobra.Command, args []string) {
+func doConfigDelete(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	group, _ := cmd.Flags().GetString("group")
 	filename := args[0]
 
+	action := fmt.Sprintf("Delete a file from the config of the %s factory", factory)
+	if group != "" {
+		action = fmt.Sprintf("Delete a file from the config of the %s device group", group)
+	}
+	if err := subcommands.Confirm(action, []string{filename}); err != nil {
+		return err
+	}
 	if group == "" {
 		logrus.Debugf("Deleting file %s from config for %s", filename, factory)
-		subcommands.DieNotNil(api.FactoryDeleteConfig(factory, filename))
+		if err := api.FactoryDeleteConfig(factory, filename); err != nil {
+			return err
+		}
 	} else {
 		logrus.Debugf("Deleting file %s from config for %s group %s", filename, factory, group)
-		subcommands.DieNotNil(api.GroupDeleteConfig(factory, group, filename))
+		if err := api.GroupDeleteConfig(factory, group, filename); err != nil {
+			return err
+		}
 	}
+	return nil
 }
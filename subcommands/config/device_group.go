@@ -1,9 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 
-	"github.com/cheynewallace/tabby"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -21,48 +21,51 @@ func init() {
 	groupCmd.AddCommand(&cobra.Command{
 		Use:   "list",
 		Short: "Show available device groups",
-		Run:   doListDeviceGroup,
+		RunE:  doListDeviceGroup,
 	})
 	groupCmd.AddCommand(&cobra.Command{
 		Use:   "create <name> [<description>]",
 		Short: "Create a new device group",
-		Run:   doCreateDeviceGroup,
+		RunE:  doCreateDeviceGroup,
 		Args:  cobra.RangeArgs(1, 2),
 	})
 	groupCmd.AddCommand(&cobra.Command{
-		Use:   "delete <name>",
-		Short: "Delete an existing device group",
-		Run:   doDeleteDeviceGroup,
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <name>",
+		Short:             "Delete an existing device group",
+		RunE:              doDeleteDeviceGroup,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteDeviceGroups),
 	})
 
 	updateCmd := &cobra.Command{
-		Use:   "update <name>",
-		Short: "Rename an existing device group",
-		Run:   doUpdateDeviceGroup,
-		Args:  cobra.ExactArgs(1),
+		Use:               "update <name>",
+		Short:             "Rename an existing device group",
+		RunE:              doUpdateDeviceGroup,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteDeviceGroups),
 	}
 	groupCmd.AddCommand(updateCmd)
 	updateCmd.Flags().StringP("name", "n", "", "Change a device group name")
 	updateCmd.Flags().StringP("description", "d", "", "Change a device group description")
 }
 
-func doListDeviceGroup(cmd *cobra.Command, args []string) {
+func doListDeviceGroup(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Showing list of device groups for %s", factory)
 
 	lst, err := api.FactoryListDeviceGroup(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	t := tabby.New()
-	t.AddHeader("NAME", "DESCRIPTION", "CREATED AT", "UPDATED AT")
+	out := subcommands.NewOutput(*lst, "NAME", "DESCRIPTION", "CREATED AT", "UPDATED AT")
 	for _, grp := range *lst {
-		t.AddLine(grp.Name, grp.Description, grp.ChangeMeta.CreatedAt, grp.ChangeMeta.UpdatedAt)
+		out.AddLine(grp.Name, grp.Description, grp.ChangeMeta.CreatedAt, grp.ChangeMeta.UpdatedAt)
 	}
-	t.Print()
+	return out.Print()
 }
 
-func doCreateDeviceGroup(cmd *cobra.Command, args []string) {
+func doCreateDeviceGroup(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name := args[0]
 	logrus.Debugf("Creating a new device group %s for %s", name, factory)
@@ -73,25 +76,27 @@ func doCreateDeviceGroup(cmd *cobra.Command, args []string) {
 	}
 
 	grp, err := api.FactoryCreateDeviceGroup(factory, name, description)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Name: \t\t%s\n", grp.Name)
 	if grp.Description != "" {
 		fmt.Printf("Description: \t%s\n", grp.Description)
 	}
 	fmt.Printf("Created At: \t%s\n\n", grp.ChangeMeta.CreatedAt)
+	return nil
 }
 
-func doDeleteDeviceGroup(cmd *cobra.Command, args []string) {
+func doDeleteDeviceGroup(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name := args[0]
 	logrus.Debugf("Deleting a device group %s from %s", name, factory)
 
-	err := api.FactoryDeleteDeviceGroup(factory, name)
-	subcommands.DieNotNil(err)
+	return api.FactoryDeleteDeviceGroup(factory, name)
 }
 
-func doUpdateDeviceGroup(cmd *cobra.Command, args []string) {
+func doUpdateDeviceGroup(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	old_name := args[0]
 	var new_name, new_desc *string
@@ -108,9 +113,8 @@ func doUpdateDeviceGroup(cmd *cobra.Command, args []string) {
 	}
 
 	if new_name == nil && new_desc == nil {
-		subcommands.DieNotNil(fmt.Errorf("At least one attribute should be modified"))
+		return subcommands.UsageError(errors.New("At least one attribute should be modified"))
 	}
 
-	err := api.FactoryPatchDeviceGroup(factory, old_name, new_name, new_desc)
-	subcommands.DieNotNil(err)
+	return api.FactoryPatchDeviceGroup(factory, old_name, new_name, new_desc)
 }
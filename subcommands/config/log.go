@@ -13,40 +13,36 @@ func init() {
 	logCmd := &cobra.Command{
 		Use:   "log",
 		Short: "Show a configuration changelog",
-		Run:   doConfigLog,
+		RunE:  doConfigLog,
 	}
 	cmd.AddCommand(logCmd)
 	logCmd.Flags().StringP("group", "g", "", "Device group to use")
+	subcommands.RegisterFlagCompletion(logCmd, "group", subcommands.CompleteDeviceGroups)
 	logCmd.Flags().IntP("limit", "n", 0, "Limit the number of results displayed")
 }
 
-func doConfigLog(cmd *cobra.Command, args []string) {
+func doConfigLog(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	listLimit, _ := cmd.Flags().GetInt("limit")
 	group, _ := cmd.Flags().GetString("group")
 
 	lookups, err := api.UsersGetLookups(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if group == "" {
 		logrus.Debugf("Showing config history for %s", factory)
-		subcommands.LogConfigs(&subcommands.LogConfigsOptions{
-			Limit: listLimit,
-			ListFunc: func() (*client.DeviceConfigList, error) {
-				return api.FactoryListConfig(factory)
-			},
-			ListContFunc: api.FactoryListConfigCont,
-			UserLookup:   lookups,
-		})
-	} else {
-		logrus.Debugf("Showing config history for %s group %s", factory, group)
-		subcommands.LogConfigs(&subcommands.LogConfigsOptions{
-			Limit: listLimit,
-			ListFunc: func() (*client.DeviceConfigList, error) {
-				return api.GroupListConfig(factory, group)
-			},
-			ListContFunc: api.GroupListConfigCont,
-			UserLookup:   lookups,
+		return subcommands.LogConfigs(&subcommands.LogConfigsOptions{
+			Limit:      listLimit,
+			Configs:    api.FactoryListConfigIter(factory, client.PaginateOptions{Prefetch: true}),
+			UserLookup: lookups,
 		})
 	}
+	logrus.Debugf("Showing config history for %s group %s", factory, group)
+	return subcommands.LogConfigs(&subcommands.LogConfigsOptions{
+		Limit:      listLimit,
+		Configs:    api.GroupListConfigIter(factory, group, client.PaginateOptions{Prefetch: true}),
+		UserLookup: lookups,
+	})
 }
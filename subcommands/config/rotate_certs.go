@@ -14,7 +14,7 @@ func init() {
 	rotateCmd := &cobra.Command{
 		Use:   "rotate-certs",
 		Short: "Rotate device x509 keypairs in this group used to connect to the device gateway",
-		Run:   doCertRotate,
+		RunE:  doCertRotate,
 		Long: `This command will send a fioconfig change to a device to instruct it to perform
 a certificate rotation using the EST server configured with "fioctl keys est".
 
@@ -22,6 +22,7 @@ This command will only work for devices running LmP version 90 and later.`,
 	}
 	cmd.AddCommand(rotateCmd)
 	rotateCmd.Flags().StringP("group", "g", "", "Device group to use")
+	subcommands.RegisterFlagCompletion(rotateCmd, "group", subcommands.CompleteDeviceGroups)
 	rotateCmd.Flags().StringP("est-resource", "e", "/.well-known/est", "Path to the EST resource on your server")
 	rotateCmd.Flags().IntP("est-port", "p", 8443, "EST server port")
 	rotateCmd.Flags().StringP("reason", "r", "", "reason for changing the cert")
@@ -33,7 +34,7 @@ This command will only work for devices running LmP version 90 and later.`,
 	_ = cmd.MarkFlagRequired("group")
 }
 
-func doCertRotate(cmd *cobra.Command, args []string) {
+func doCertRotate(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	estResource, _ := cmd.Flags().GetString("est-resource")
 	estPort, _ := cmd.Flags().GetInt("est-port")
@@ -54,7 +55,9 @@ func doCertRotate(cmd *cobra.Command, args []string) {
 	} else {
 		var err error
 		url, err = api.FactoryEstUrl(factory, estPort, estResource)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 	}
 	logrus.Debugf("Using EST server: %s", url)
 
@@ -69,7 +72,7 @@ func doCertRotate(cmd *cobra.Command, args []string) {
 	if dryRun {
 		fmt.Println("Config file would be:")
 		fmt.Println(ccr.Files[0].Value)
-		return
+		return nil
 	}
-	subcommands.DieNotNil(api.GroupPatchConfig(factory, group, ccr, false))
+	return api.GroupPatchConfig(factory, group, ccr, false)
 }
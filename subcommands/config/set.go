@@ -54,17 +54,18 @@ create a device group wide configuration instead.`,
   # fioctl will read in tmp.json and upload it to the OTA server.
   # Instead of using ./tmp.json, the command can take a "-" and will read the
   # content from STDIN instead of a file.`,
-		Run:  doConfigSet,
+		RunE: doConfigSet,
 		Args: cobra.MinimumNArgs(1),
 	}
 	cmd.AddCommand(setCmd)
 	setCmd.Flags().StringP("group", "g", "", "Device group to use")
+	subcommands.RegisterFlagCompletion(setCmd, "group", subcommands.CompleteDeviceGroups)
 	setCmd.Flags().StringP("reason", "m", "", "Add a message to store as the \"reason\" for this change")
 	setCmd.Flags().BoolP("raw", "", false, "Use raw configuration file")
 	setCmd.Flags().BoolP("create", "", false, "Replace the whole config with these values. Default is to merge these values with the existing config values")
 }
 
-func doConfigSet(cmd *cobra.Command, args []string) {
+func doConfigSet(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	group, _ := cmd.Flags().GetString("group")
 	reason, _ := cmd.Flags().GetString("reason")
@@ -91,5 +92,5 @@ func doConfigSet(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
-	subcommands.SetConfig(&opts)
+	return subcommands.SetConfig(&opts)
 }
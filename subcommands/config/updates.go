@@ -13,7 +13,7 @@ func init() {
 	configUpdatesCmd := &cobra.Command{
 		Use:   "updates",
 		Short: "Configure aktualizr-lite settings for how updates are applied to a device group",
-		Run:   doConfigUpdates,
+		RunE:  doConfigUpdates,
 		Long: `View or change configuration parameters used by aktualizr-lite for updating devices
 in a device group. When run without options, prints out the current configuration.`,
 		Example: `
@@ -47,8 +47,10 @@ in a device group. When run without options, prints out the current configuratio
 	}
 	cmd.AddCommand(configUpdatesCmd)
 	configUpdatesCmd.Flags().StringP("group", "g", "", "Device group to use")
+	subcommands.RegisterFlagCompletion(configUpdatesCmd, "group", subcommands.CompleteDeviceGroups)
 	configUpdatesCmd.Flags().StringP("tag", "", "", "Tag for devices to follow")
 	configUpdatesCmd.Flags().StringP("tags", "", "", "Tag for devices to follow")
+	subcommands.RegisterFlagCompletion(configUpdatesCmd, "tag", subcommands.CompleteTags)
 	configUpdatesCmd.Flags().StringP("apps", "", "", "comma,separate,list")
 	configUpdatesCmd.Flags().BoolP("dryrun", "", false, "Only show what would be changed")
 	configUpdatesCmd.Flags().BoolP("force", "", false, "DANGER: For a config on a device that might result in corruption")
@@ -56,7 +58,7 @@ in a device group. When run without options, prints out the current configuratio
 	_ = configUpdatesCmd.Flags().MarkHidden("tags") // assign for go linter
 }
 
-func doConfigUpdates(cmd *cobra.Command, args []string) {
+func doConfigUpdates(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	group, _ := cmd.Flags().GetString("group")
 	updateApps, _ := cmd.Flags().GetString("apps")
@@ -82,5 +84,5 @@ func doConfigUpdates(cmd *cobra.Command, args []string) {
 	opts.SetFunc = func(cfg client.ConfigCreateRequest, force bool) error {
 		return api.GroupPatchConfig(factory, group, cfg, force)
 	}
-	subcommands.SetUpdatesConfig(&opts, "", nil)
+	return subcommands.SetUpdatesConfig(&opts, "", nil)
 }
@@ -2,7 +2,6 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -10,7 +9,6 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 var wireguardDisable bool
@@ -19,7 +17,7 @@ func init() {
 	wireguardCmd := &cobra.Command{
 		Use:   "wireguard",
 		Short: "Show current wireguard server config for Factory",
-		Run:   doWireguard,
+		RunE:  doWireguard,
 		Args:  cobra.MinimumNArgs(0),
 	}
 	cmd.AddCommand(wireguardCmd)
@@ -41,7 +39,7 @@ func (w WireguardServerConfig) Marshall() string {
 	return buff
 }
 
-func (w *WireguardServerConfig) Unmarshall(configVal string) {
+func (w *WireguardServerConfig) Unmarshall(configVal string) error {
 	w.Enabled = true
 	for _, line := range strings.Split(configVal, "\n") {
 		parts := strings.SplitN(line, "=", 2)
@@ -59,34 +57,38 @@ func (w *WireguardServerConfig) Unmarshall(configVal string) {
 		} else if k == "enabled" {
 			w.Enabled = v != "0"
 		} else {
-			fmt.Println("ERROR: Unexpected client config key: ", k)
-			os.Exit(1)
+			return fmt.Errorf("Unexpected client config key: %s", k)
 		}
 	}
+	return nil
 }
 
-func LoadWireguardServerConfig(factory string, api *client.Api) WireguardServerConfig {
+func LoadWireguardServerConfig(factory string, api *client.Api) (WireguardServerConfig, error) {
+	wsc := WireguardServerConfig{}
 	dcl, err := api.FactoryListConfig(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return wsc, err
+	}
 
-	wsc := WireguardServerConfig{}
 	if len(dcl.Configs) > 0 {
 		for _, cfgFile := range dcl.Configs[0].Files {
 			if cfgFile.Name == "wireguard-server" {
 				logrus.Debugf("Found existing server config: %s", cfgFile.Value)
-				wsc.Unmarshall(cfgFile.Value)
-				break
+				return wsc, wsc.Unmarshall(cfgFile.Value)
 			}
 		}
 	}
-	return wsc
+	return wsc, nil
 }
 
-func doWireguard(cmd *cobra.Command, args []string) {
+func doWireguard(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Creating new config for %s", factory)
 
-	wsc := LoadWireguardServerConfig(factory, api)
+	wsc, err := LoadWireguardServerConfig(factory, api)
+	if err != nil {
+		return err
+	}
 
 	if wireguardDisable {
 		wsc.Enabled = false
@@ -102,7 +104,9 @@ func doWireguard(cmd *cobra.Command, args []string) {
 		}
 		cfg.Files[0].Value = wsc.Marshall()
 
-		subcommands.DieNotNil(api.FactoryPatchConfig(factory, cfg, false))
+		if err := api.FactoryPatchConfig(factory, cfg, false); err != nil {
+			return err
+		}
 	} else {
 		fmt.Println("Enabled:", wsc.Enabled)
 		if len(wsc.Endpoint) > 0 {
@@ -116,4 +120,5 @@ func doWireguard(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	return nil
 }
@@ -0,0 +1,66 @@
+package subcommands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// AssumeYes is set by the --yes flag to run destructive commands without asking for a confirmation.
+var AssumeYes bool
+
+// ErrAborted is returned when a user declines to confirm a destructive command.
+var ErrAborted = errors.New("Aborted")
+
+// Confirm prints what a destructive command is about to change, and asks a user to confirm it.
+// The action is a short sentence, e.g. "Delete 3 devices", followed by a list of affected items.
+// It fails unless a user answers "yes", or the --yes flag is given.
+// A user must run fioctl in a terminal to answer, so that scripts never hang on a prompt.
+// All of this goes to the stderr, so that an output of a command (e.g. -o json) stays valid.
+func Confirm(action string, items []string) error {
+	return confirm(action, items, "Continue? [y/N] ", func(answer string) bool {
+		answer = strings.ToLower(answer)
+		return answer == "y" || answer == "yes"
+	})
+}
+
+// ConfirmFactory works like Confirm, but a user must type the factory name to confirm.
+// It is used by commands which affect a whole factory and cannot be undone easily.
+func ConfirmFactory(factory, action string, items []string) error {
+	prompt := fmt.Sprintf("Type the factory name (%s) to continue: ", factory)
+	return confirm(action, items, prompt, func(answer string) bool {
+		return answer == factory
+	})
+}
+
+func confirm(action string, items []string, prompt string, accept func(answer string) bool) error {
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, action)
+	} else {
+		fmt.Fprintln(os.Stderr, action+":")
+		for _, item := range items {
+			fmt.Fprintln(os.Stderr, " ", item)
+		}
+	}
+	// Nothing is changed by a dry run, so there is nothing to confirm
+	if AssumeYes || viper.GetBool("dry_run") {
+		return nil
+	}
+	if !IsTerminal(os.Stdin) {
+		return UsageError(errors.New("Refusing to continue without a confirmation: stdin is not a terminal. " +
+			"Use --yes to confirm."))
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return ErrAborted
+	}
+	if !accept(strings.TrimSpace(answer)) {
+		return ErrAborted
+	}
+	return nil
+}
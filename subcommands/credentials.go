@@ -0,0 +1,239 @@
+package subcommands
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Backends of a credential store, selected by the credentials.store setting of the fioctl.yaml.
+// By default, the Secret Service is used where available, and a passphrase-encrypted file otherwise.
+// Secrets are only kept in the fioctl.yaml if the plaintext store is chosen explicitly.
+const (
+	CredentialStoreFile          = "file"
+	CredentialStoreSecretService = "secret-service"
+	CredentialStorePlaintext     = "plaintext"
+)
+
+// CredentialSecrets are the secret fields of OAuth client credentials.
+// Unless the plaintext store is chosen, these are never written into the fioctl.yaml.
+type CredentialSecrets struct {
+	ClientSecret string `json:"client_secret"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s CredentialSecrets) IsEmpty() bool {
+	return len(s.ClientSecret) == 0 && len(s.AccessToken) == 0 && len(s.RefreshToken) == 0
+}
+
+// CredentialKey identifies secrets of a profile in a given config file.
+// The top level credentials of a config file are stored as the "default" profile.
+type CredentialKey struct {
+	ConfigFile string
+	Profile    string
+}
+
+func (k CredentialKey) String() string {
+	return k.Profile + "@" + k.ConfigFile
+}
+
+// CredentialStore keeps credential secrets of each profile outside of the fioctl.yaml.
+type CredentialStore interface {
+	Name() string
+	// Load returns nil if there are no secrets stored for a key
+	Load(key CredentialKey) (*CredentialSecrets, error)
+	Save(key CredentialKey, secrets CredentialSecrets) error
+	// Delete does nothing if there are no secrets stored for a key
+	Delete(key CredentialKey) error
+}
+
+// Set when the fioctl.yaml holds plaintext secrets written by older fioctl versions.
+// These are kept in place (e.g. on a token refresh) until they are migrated by the "fioctl login".
+var legacyPlaintextCredentials bool
+
+// Unset when nobody can answer a passphrase prompt, e.g. during a shell completion
+var passphrasePrompt = true
+
+// HasPlaintextCredentials returns true if the config file holds plaintext secrets of older fioctl versions.
+func HasPlaintextCredentials() bool {
+	return legacyPlaintextCredentials
+}
+
+// NewCredentialStore returns a credential store selected by the credentials.store setting.
+// It returns nil for the plaintext store.
+func NewCredentialStore() (CredentialStore, error) {
+	switch name := viper.GetString("credentials.store"); name {
+	case "":
+		if store := newSecretServiceStore(); store != nil {
+			return store, nil
+		}
+		logrus.Debug("The Secret Service is not available, using a passphrase-encrypted credentials file")
+		return newFileStore()
+	case CredentialStoreFile:
+		return newFileStore()
+	case CredentialStoreSecretService:
+		if store := newSecretServiceStore(); store != nil {
+			return store, nil
+		}
+		return nil, errors.New("The Secret Service is not available: secret-tool is not installed or there is no D-Bus session")
+	case CredentialStorePlaintext:
+		return nil, nil
+	default:
+		return nil, UsageError(fmt.Errorf("Invalid credentials store: %s. Allowed values: %s, %s, %s",
+			name, CredentialStoreFile, CredentialStoreSecretService, CredentialStorePlaintext))
+	}
+}
+
+// Returns a key of the active profile's credentials in a store.
+// Different config files may have profiles of the same name, so the key includes a config file path.
+func credentialsKey() (CredentialKey, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return CredentialKey{}, err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return CredentialKey{}, err
+	}
+	key := CredentialKey{ConfigFile: path, Profile: ProfileName}
+	if len(key.Profile) == 0 {
+		key.Profile = "default"
+	}
+	return key, nil
+}
+
+// LoadCredentials reads secrets of Config.ClientCredentials from a credential store.
+// It must be called before using the client credentials.
+func LoadCredentials() error {
+	creds := &Config.ClientCredentials
+	if len(creds.ClientId) == 0 {
+		// Not logged in
+		return nil
+	}
+	if len(creds.ClientSecret) > 0 {
+		legacyPlaintextCredentials = viper.GetString("credentials.store") != CredentialStorePlaintext
+		if legacyPlaintextCredentials {
+			logrus.Debug("Using plaintext credentials from the config file; run \"fioctl login\" to move them into a credential store")
+		}
+		return nil
+	}
+	store, err := NewCredentialStore()
+	if err != nil || store == nil {
+		return err
+	}
+	key, err := credentialsKey()
+	if err != nil {
+		return err
+	}
+	secrets, err := store.Load(key)
+	if err != nil {
+		return AuthError(fmt.Errorf("Unable to read credentials from the %s store: %w", store.Name(), err))
+	}
+	if secrets != nil {
+		creds.ClientSecret = secrets.ClientSecret
+		creds.AccessToken = secrets.AccessToken
+		creds.RefreshToken = secrets.RefreshToken
+	}
+	return nil
+}
+
+// WipeCredentials deletes credential secrets of the active profile from all stores which may hold them.
+func WipeCredentials() error {
+	key, err := credentialsKey()
+	if err != nil {
+		return err
+	}
+	var stores []CredentialStore
+	if store, err := newFileStore(); err == nil {
+		stores = append(stores, store)
+	}
+	if store := newSecretServiceStore(); store != nil {
+		stores = append(stores, store)
+	}
+	for _, store := range stores {
+		if err := store.Delete(key); err != nil {
+			return fmt.Errorf("Unable to delete credentials from the %s store: %w", store.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Reads a passphrase for a given purpose from the FIOCTL_CREDENTIALS_PASSPHRASE environment variable,
+// the output of the credentials.passphrase_command, or a terminal, in this order.
+func readPassphrase(prompt string, confirm bool) (string, error) {
+	if pass := os.Getenv("FIOCTL_CREDENTIALS_PASSPHRASE"); len(pass) > 0 {
+		return pass, nil
+	}
+	if command := viper.GetString("credentials.passphrase_command"); len(command) > 0 {
+		logrus.Debugf("Running passphrase command: %s", command)
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.Command("cmd", "/C", command)
+		} else {
+			c = exec.Command("sh", "-c", command)
+		}
+		var out bytes.Buffer
+		c.Stdout = &out
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return "", fmt.Errorf("Passphrase command failed: %w", err)
+		}
+		pass := strings.TrimRight(out.String(), "\r\n")
+		if len(pass) == 0 {
+			return "", errors.New("Passphrase command did not print a passphrase")
+		}
+		return pass, nil
+	}
+	if !passphrasePrompt || !IsTerminal(os.Stdin) {
+		return "", AuthError(errors.New("A passphrase is required to access the credentials file. " +
+			"Set FIOCTL_CREDENTIALS_PASSPHRASE or credentials.passphrase_command, or run fioctl in a terminal. " +
+			"Alternatively, set credentials.store to plaintext to keep the secrets in the config file unencrypted."))
+	}
+	pass, err := promptHidden(prompt)
+	if err != nil || !confirm {
+		return pass, err
+	}
+	again, err := promptHidden("Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if pass != again {
+		return "", errors.New("Passphrases do not match")
+	}
+	return pass, nil
+}
+
+func promptHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	// There is no portable way to turn off the echo in the standard library
+	if runtime.GOOS != "windows" {
+		stty := exec.Command("stty", "-echo")
+		stty.Stdin = os.Stdin
+		if err := stty.Run(); err == nil {
+			defer func() {
+				restore := exec.Command("stty", "echo")
+				restore.Stdin = os.Stdin
+				_ = restore.Run()
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	pass := strings.TrimRight(line, "\r\n")
+	if len(pass) == 0 {
+		return "", errors.New("A passphrase must not be empty")
+	}
+	return pass, nil
+}
@@ -0,0 +1,170 @@
+package subcommands
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Parameters of the scrypt key derivation recommended for interactive logins
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = chacha20poly1305.KeySize
+)
+
+// fileStore keeps credential secrets of each profile in a passphrase-encrypted file <dir>/<profile>-<config hash>.enc.
+// A key is derived from a passphrase with scrypt, and secrets are encrypted with XChaCha20-Poly1305.
+// It works without a desktop session, e.g. on headless Linux hosts.
+type fileStore struct {
+	dir string
+	// A passphrase is asked once per process
+	passphrase string
+}
+
+// The content of an encrypted credentials file
+type encryptedCredentials struct {
+	Version    int    `json:"version"`
+	Kdf        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+var cachedFileStore *fileStore
+
+func newFileStore() (CredentialStore, error) {
+	if cachedFileStore != nil {
+		return cachedFileStore, nil
+	}
+	dir := viper.GetString("credentials.dir")
+	if len(dir) == 0 {
+		dir = "~/.config/fioctl/credentials"
+	}
+	dir, err := homedir.Expand(dir)
+	if err != nil {
+		return nil, err
+	}
+	cachedFileStore = &fileStore{dir: dir}
+	return cachedFileStore, nil
+}
+
+func (s *fileStore) Name() string {
+	return CredentialStoreFile
+}
+
+// A file name includes a hash of the config file path, as profiles of different config files may share a name
+func (s *fileStore) path(key CredentialKey) string {
+	hash := sha256.Sum256([]byte(key.ConfigFile))
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%x.enc", key.Profile, hash[:8]))
+}
+
+func (s *fileStore) Load(key CredentialKey) (*CredentialSecrets, error) {
+	buf, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var enc encryptedCredentials
+	if err = json.Unmarshal(buf, &enc); err != nil {
+		return nil, fmt.Errorf("Malformed credentials file %s: %w", s.path(key), err)
+	}
+	if enc.Version != 1 || enc.Kdf != "scrypt" {
+		return nil, fmt.Errorf("Unsupported credentials file format: version %d, kdf %s", enc.Version, enc.Kdf)
+	}
+	if len(s.passphrase) == 0 {
+		if s.passphrase, err = readPassphrase("Passphrase of the credentials file: ", false); err != nil {
+			return nil, err
+		}
+	}
+	aeadKey, err := scrypt.Key([]byte(s.passphrase), enc.Salt, enc.N, enc.R, enc.P, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(aeadKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("Malformed credentials file %s: invalid nonce", s.path(key))
+	}
+	// A key is authenticated, so that credentials files cannot be swapped
+	plain, err := aead.Open(nil, enc.Nonce, enc.Ciphertext, []byte(key.String()))
+	if err != nil {
+		// Do not keep a wrong passphrase
+		s.passphrase = ""
+		return nil, errors.New("Invalid passphrase, or the credentials file is corrupted")
+	}
+	var secrets CredentialSecrets
+	if err = json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("Malformed credentials file %s: %w", s.path(key), err)
+	}
+	return &secrets, nil
+}
+
+func (s *fileStore) Save(key CredentialKey, secrets CredentialSecrets) error {
+	if len(s.passphrase) == 0 {
+		var err error
+		if s.passphrase, err = readPassphrase("New passphrase of the credentials file: ", true); err != nil {
+			return err
+		}
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	enc := encryptedCredentials{
+		Version: 1,
+		Kdf:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 16),
+		Nonce:   make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	if _, err = rand.Read(enc.Salt); err != nil {
+		return err
+	}
+	if _, err = rand.Read(enc.Nonce); err != nil {
+		return err
+	}
+	aeadKey, err := scrypt.Key([]byte(s.passphrase), enc.Salt, enc.N, enc.R, enc.P, scryptKeyLen)
+	if err != nil {
+		return err
+	}
+	aead, err := chacha20poly1305.NewX(aeadKey)
+	if err != nil {
+		return err
+	}
+	enc.Ciphertext = aead.Seal(nil, enc.Nonce, plain, []byte(key.String()))
+	buf, err := json.Marshal(enc)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(key), buf, 0o600)
+}
+
+func (s *fileStore) Delete(key CredentialKey) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
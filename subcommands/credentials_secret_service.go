@@ -0,0 +1,89 @@
+package subcommands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// secretServiceStore keeps credential secrets in the Secret Service (e.g. GNOME Keyring or KWallet)
+// using the secret-tool of libsecret.
+type secretServiceStore struct {
+	tool string
+}
+
+func newSecretServiceStore() CredentialStore {
+	if len(os.Getenv("DBUS_SESSION_BUS_ADDRESS")) == 0 {
+		return nil
+	}
+	tool, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil
+	}
+	return &secretServiceStore{tool: tool}
+}
+
+func (s *secretServiceStore) Name() string {
+	return CredentialStoreSecretService
+}
+
+func (s *secretServiceStore) attributes(key CredentialKey) []string {
+	return []string{"application", "fioctl", "config", key.ConfigFile, "profile", key.Profile}
+}
+
+func (s *secretServiceStore) run(stdin []byte, args ...string) ([]byte, error) {
+	logrus.Debugf("Running secret-tool %s", args[0])
+	cmd := exec.Command(s.tool, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, err
+}
+
+func (s *secretServiceStore) Load(key CredentialKey) (*CredentialSecrets, error) {
+	out, err := s.run(nil, append([]string{"lookup"}, s.attributes(key)...)...)
+	var exitErr *exec.ExitError
+	if len(out) == 0 && (err == nil || errors.As(err, &exitErr)) {
+		// The secret-tool exits with 1 and prints nothing if there is no such secret
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var secrets CredentialSecrets
+	if err = json.Unmarshal(out, &secrets); err != nil {
+		return nil, fmt.Errorf("Malformed credentials in the Secret Service: %w", err)
+	}
+	return &secrets, nil
+}
+
+func (s *secretServiceStore) Save(key CredentialKey, secrets CredentialSecrets) error {
+	buf, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"store", "--label", "fioctl credentials (" + key.String() + ")"}, s.attributes(key)...)
+	_, err = s.run(buf, args...)
+	return err
+}
+
+func (s *secretServiceStore) Delete(key CredentialKey) error {
+	_, err := s.run(nil, append([]string{"clear"}, s.attributes(key)...)...)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// Nothing to delete
+		return nil
+	}
+	return err
+}
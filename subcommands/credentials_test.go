@@ -0,0 +1,166 @@
+package subcommands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "correct horse")
+	store := &fileStore{dir: filepath.Join(dir, "credentials")}
+	key := CredentialKey{ConfigFile: "/home/me/.config/fioctl.yaml", Profile: "default"}
+
+	secrets, err := store.Load(key)
+	require.Nil(t, err)
+	require.Nil(t, secrets)
+
+	saved := CredentialSecrets{ClientSecret: "secret", AccessToken: "access", RefreshToken: "refresh"}
+	require.Nil(t, store.Save(key, saved))
+
+	fi, err := os.Stat(store.path(key))
+	require.Nil(t, err)
+	require.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+	buf, err := os.ReadFile(store.path(key))
+	require.Nil(t, err)
+	require.NotContains(t, string(buf), "secret")
+
+	secrets, err = (&fileStore{dir: store.dir}).Load(key)
+	require.Nil(t, err)
+	require.Equal(t, saved, *secrets)
+
+	// Profiles of the same name in another config file do not share secrets
+	otherKey := CredentialKey{ConfigFile: "/tmp/fioctl.yaml", Profile: "default"}
+	require.NotEqual(t, store.path(key), store.path(otherKey))
+	other := CredentialSecrets{ClientSecret: "other"}
+	require.Nil(t, store.Save(otherKey, other))
+	secrets, err = (&fileStore{dir: store.dir}).Load(key)
+	require.Nil(t, err)
+	require.Equal(t, saved, *secrets)
+	secrets, err = (&fileStore{dir: store.dir}).Load(otherKey)
+	require.Nil(t, err)
+	require.Equal(t, other, *secrets)
+
+	// Files are bound to their key
+	require.Nil(t, os.Rename(store.path(key), store.path(otherKey)))
+	_, err = (&fileStore{dir: store.dir}).Load(otherKey)
+	require.NotNil(t, err)
+	require.Nil(t, os.Rename(store.path(otherKey), store.path(key)))
+
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "wrong")
+	_, err = (&fileStore{dir: store.dir}).Load(key)
+	require.EqualError(t, err, "Invalid passphrase, or the credentials file is corrupted")
+
+	require.Nil(t, store.Delete(key))
+	require.Nil(t, store.Delete(key))
+	secrets, err = store.Load(key)
+	require.Nil(t, err)
+	require.Nil(t, secrets)
+}
+
+// Credential helpers run by git or docker own the stdin, so they must never prompt for a passphrase
+func TestCredentialsHelperWithoutTerminal(t *testing.T) {
+	stdin, w, err := os.Pipe()
+	require.Nil(t, err)
+	defer w.Close()
+	origStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = origStdin }()
+
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "")
+	viper.Set("credentials.dir", filepath.Join(t.TempDir(), "credentials"))
+	viper.SetConfigFile(filepath.Join(t.TempDir(), "fioctl.yaml"))
+	defer viper.Reset()
+	defer viper.Set("credentials.dir", "")
+	defer viper.Set("credentials.store", "")
+	origConfig := Config
+	defer func() { Config = origConfig }()
+	defer func() { cachedFileStore = nil }()
+
+	login := func() (*client.Api, error) {
+		cmd := &cobra.Command{Use: "git-credential-helper"}
+		if err := LoadCredentials(); err != nil {
+			return nil, err
+		}
+		return Login(cmd)
+	}
+	creds := client.OAuthConfig{
+		ClientId:     "id",
+		ClientSecret: "secret",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresIn:    3600,
+		Created:      time.Now().Format(time.RFC3339),
+	}
+
+	// Without a Secret Service, secrets are kept in an encrypted file by default
+	viper.Set("credentials.store", "")
+	store, err := NewCredentialStore()
+	require.Nil(t, err)
+	require.Equal(t, CredentialStoreFile, store.Name())
+
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "correct horse")
+	key, err := credentialsKey()
+	require.Nil(t, err)
+	require.Nil(t, store.Save(key, CredentialSecrets{
+		ClientSecret: "secret", AccessToken: "access", RefreshToken: "refresh",
+	}))
+	cachedFileStore = nil
+
+	// It fails instead of waiting for a passphrase
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "")
+	Config.ClientCredentials = creds
+	Config.ClientCredentials.ClientSecret = ""
+	Config.ClientCredentials.AccessToken = ""
+	_, err = login()
+	require.NotNil(t, err)
+	require.Equal(t, ExitAuth, ExitCode(err))
+	require.Contains(t, err.Error(), "FIOCTL_CREDENTIALS_PASSPHRASE")
+
+	// It works with a passphrase from the environment
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "correct horse")
+	_, err = login()
+	require.Nil(t, err)
+	require.Equal(t, "access", Config.ClientCredentials.AccessToken)
+
+	// Secrets are only read from the config file if the plaintext store is chosen explicitly
+	viper.Set("credentials.store", CredentialStorePlaintext)
+	store, err = NewCredentialStore()
+	require.Nil(t, err)
+	require.Nil(t, store)
+	t.Setenv("FIOCTL_CREDENTIALS_PASSPHRASE", "")
+	Config.ClientCredentials = creds
+	Config.ClientCredentials.AccessToken = "plain"
+	_, err = login()
+	require.Nil(t, err)
+	require.False(t, HasPlaintextCredentials())
+	require.Equal(t, "plain", Config.ClientCredentials.AccessToken)
+}
+
+func TestCredentialsKey(t *testing.T) {
+	defer viper.Reset()
+	origProfile := ProfileName
+	defer func() { ProfileName = origProfile }()
+
+	dir := t.TempDir()
+	viper.SetConfigFile(filepath.Join(dir, "fioctl.yaml"))
+	ProfileName = ""
+	key, err := credentialsKey()
+	require.Nil(t, err)
+	require.Equal(t, CredentialKey{ConfigFile: filepath.Join(dir, "fioctl.yaml"), Profile: "default"}, key)
+
+	ProfileName = "prod"
+	viper.SetConfigFile(filepath.Join(dir, "other.yaml"))
+	key, err = credentialsKey()
+	require.Nil(t, err)
+	require.Equal(t, CredentialKey{ConfigFile: filepath.Join(dir, "other.yaml"), Profile: "prod"}, key)
+}
@@ -0,0 +1,322 @@
+package devices
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func init() {
+	healthCmd := &cobra.Command{
+		Use:   "apps-health",
+		Short: "Summarize the states of Apps reported by many devices",
+		RunE:  doAppsHealth,
+		Args:  cobra.NoArgs,
+		Long: `Summarize the states of Apps reported by all devices of a Factory, or by devices matching a selector.
+
+The latest Apps states reported by each device are counted per App and per service.
+A service is failing on a device if it is unhealthy, or if it is not running.
+Apps which a device runs from another URI than the one in the Target it runs are also listed.
+
+An API request is made per device, so without a --selector a report on a large factory takes a while.
+
+` + selectorConditions,
+		Example: `
+# Show the health of Apps in the "us-east" device group:
+fioctl devices apps-health --selector group=us-east
+
+# Show on which devices the "shellhttpd" App is failing, as JSON:
+fioctl devices apps-health --app shellhttpd -o json`,
+	}
+	cmd.AddCommand(healthCmd)
+	addDeviceQueryFlags(healthCmd, "Only include devices matching a selector",
+		"Number of devices looked up at the same time")
+	healthCmd.Flags().String("app", "", "Only include this App")
+	healthCmd.Flags().IntP("top", "n", 10, "Number of top failing services to show")
+}
+
+type appsHealthReport struct {
+	Devices int `json:"devices"`
+	// Devices which reported Apps states
+	Reporting  int               `json:"reporting"`
+	Apps       []appHealth       `json:"apps"`
+	Services   []serviceHealth   `json:"services"`
+	Mismatches []appMismatch     `json:"uri-mismatches"`
+	Errors     map[string]string `json:"errors,omitempty"`
+	apps       map[string]*appHealth
+	services   map[[2]string]*serviceHealth
+	mismatches map[[3]string]*appMismatch
+}
+
+// Device names per App state
+type appHealth struct {
+	App     string              `json:"app"`
+	Devices int                 `json:"devices"`
+	States  map[string][]string `json:"states"`
+}
+
+// Device names per state and health of a service, and those where a service is failing
+type serviceHealth struct {
+	App     string              `json:"app"`
+	Service string              `json:"service"`
+	Devices int                 `json:"devices"`
+	States  map[string][]string `json:"states"`
+	Health  map[string][]string `json:"health"`
+	Failing []string            `json:"failing"`
+}
+
+type appMismatch struct {
+	App      string   `json:"app"`
+	Uri      string   `json:"uri"`
+	Expected string   `json:"expected-uri"`
+	Devices  []string `json:"devices"`
+}
+
+func doAppsHealth(cmd *cobra.Command, args []string) error {
+	factory := viper.GetString("factory")
+	appName, _ := cmd.Flags().GetString("app")
+	top, _ := cmd.Flags().GetInt("top")
+	filterBy, predicates, err := parseDeviceQuery()
+	if err != nil {
+		return err
+	}
+	devices, err := listDevices(filterBy, predicates)
+	if err != nil {
+		return err
+	}
+	targetApps, err := targetComposeApps(factory)
+	if err != nil {
+		return err
+	}
+
+	states := make([]*client.AppsState, len(devices))
+	errs := make([]error, len(devices))
+	forEachDevice(devices, func(i int, d *client.Device) {
+		logrus.Debugf("Looking up Apps states of %s", d.Name)
+		s, err := d.Api.GetAppsStates()
+		if err != nil {
+			errs[i] = err
+		} else if len(s.States) > 0 {
+			states[i] = &s.States[0]
+		}
+	})
+
+	report := &appsHealthReport{
+		Devices:    len(devices),
+		apps:       make(map[string]*appHealth),
+		services:   make(map[[2]string]*serviceHealth),
+		mismatches: make(map[[3]string]*appMismatch),
+	}
+	for i, d := range devices {
+		if errs[i] != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[d.Name] = errs[i].Error()
+		} else if states[i] != nil {
+			report.add(&d, states[i], appName, targetApps[d.TargetName])
+		}
+	}
+	report.sort()
+
+	// A report is printed anyway, so that it shows why devices could not be looked up
+	var lookupErr error
+	if len(report.Errors) == len(devices) && len(devices) > 0 {
+		lookupErr = fmt.Errorf("Unable to look up Apps states of all %d device(s)", len(devices))
+	}
+	if !subcommands.IsTableOutput() {
+		if err = subcommands.PrintData(report); err != nil {
+			return err
+		}
+	} else {
+		report.print(top)
+	}
+	return lookupErr
+}
+
+// Returns URIs of Apps per Target name
+func targetComposeApps(factory string) (map[string]map[string]string, error) {
+	targets, err := api.TargetsList(factory)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]map[string]string, len(targets))
+	for name, meta := range targets {
+		custom, err := api.TargetCustom(meta)
+		if err != nil {
+			logrus.Debugf("Unable to parse the Target %s: %s", name, err)
+			continue
+		}
+		apps := make(map[string]string, len(custom.ComposeApps))
+		for app, ca := range custom.ComposeApps {
+			apps[app] = ca.Uri
+		}
+		res[name] = apps
+	}
+	return res, nil
+}
+
+func orNone(s string) string {
+	if len(s) == 0 {
+		return "none"
+	}
+	return s
+}
+
+func (r *appsHealthReport) add(d *client.Device, state *client.AppsState, appName string, targetApps map[string]string) {
+	reported := false
+	for name, app := range state.Apps {
+		if len(appName) > 0 && name != appName {
+			continue
+		}
+		reported = true
+		a := r.apps[name]
+		if a == nil {
+			a = &appHealth{App: name, States: make(map[string][]string)}
+			r.apps[name] = a
+		}
+		a.Devices++
+		a.States[orNone(app.State)] = append(a.States[orNone(app.State)], d.Name)
+
+		for _, srv := range app.Services {
+			key := [2]string{name, srv.Name}
+			s := r.services[key]
+			if s == nil {
+				s = &serviceHealth{
+					App: name, Service: srv.Name, States: make(map[string][]string), Health: make(map[string][]string),
+				}
+				r.services[key] = s
+			}
+			s.Devices++
+			s.States[orNone(srv.State)] = append(s.States[orNone(srv.State)], d.Name)
+			s.Health[orNone(srv.Health)] = append(s.Health[orNone(srv.Health)], d.Name)
+			if srv.Health == "unhealthy" || srv.State != "running" {
+				s.Failing = append(s.Failing, d.Name)
+			}
+		}
+
+		if expected, ok := targetApps[name]; ok && len(app.Uri) > 0 && app.Uri != expected {
+			key := [3]string{name, app.Uri, expected}
+			m := r.mismatches[key]
+			if m == nil {
+				m = &appMismatch{App: name, Uri: app.Uri, Expected: expected}
+				r.mismatches[key] = m
+			}
+			m.Devices = append(m.Devices, d.Name)
+		}
+	}
+	if reported || len(appName) == 0 {
+		r.Reporting++
+	}
+}
+
+func (r *appsHealthReport) sort() {
+	r.Apps = make([]appHealth, 0, len(r.apps))
+	for _, a := range r.apps {
+		r.Apps = append(r.Apps, *a)
+	}
+	slices.SortFunc(r.Apps, func(a, b appHealth) int { return cmp.Compare(a.App, b.App) })
+
+	r.Services = make([]serviceHealth, 0, len(r.services))
+	for _, s := range r.services {
+		if s.Failing == nil {
+			s.Failing = []string{}
+		}
+		r.Services = append(r.Services, *s)
+	}
+	slices.SortFunc(r.Services, func(a, b serviceHealth) int {
+		return cmp.Or(cmp.Compare(a.App, b.App), cmp.Compare(a.Service, b.Service))
+	})
+
+	r.Mismatches = make([]appMismatch, 0, len(r.mismatches))
+	for _, m := range r.mismatches {
+		r.Mismatches = append(r.Mismatches, *m)
+	}
+	slices.SortFunc(r.Mismatches, func(a, b appMismatch) int {
+		return cmp.Or(cmp.Compare(len(b.Devices), len(a.Devices)), cmp.Compare(a.App, b.App), cmp.Compare(a.Uri, b.Uri))
+	})
+}
+
+// Formats counts of a bucket, e.g. "running=10 exited=2", the largest first
+func bucketCounts(buckets map[string][]string) string {
+	keys := make([]string, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(buckets[b]), len(buckets[a])), cmp.Compare(a, b))
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", k, len(buckets[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func deviceExamples(names []string) string {
+	if len(names) > 3 {
+		return fmt.Sprintf("%s (+%d)", strings.Join(names[:3], ", "), len(names)-3)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (r *appsHealthReport) print(top int) {
+	scope := "the factory"
+	if len(deviceSelector) > 0 {
+		scope = deviceSelector
+	}
+	fmt.Printf("Devices:\t%d (%s)\n", r.Devices, scope)
+	fmt.Printf("Reporting:\t%d\n", r.Reporting)
+	if len(r.Errors) > 0 {
+		fmt.Printf("Lookup errors:\t%d\n", len(r.Errors))
+	}
+	if len(r.Apps) == 0 {
+		return
+	}
+
+	fmt.Println("\nApps:")
+	t := subcommands.Tabby(1, "APP", "DEVICES", "STATES")
+	for _, a := range r.Apps {
+		t.AddLine(a.App, a.Devices, bucketCounts(a.States))
+	}
+	t.Print()
+
+	fmt.Println("\nServices:")
+	t = subcommands.Tabby(1, "APP", "SERVICE", "DEVICES", "STATES", "HEALTH")
+	for _, s := range r.Services {
+		t.AddLine(s.App, s.Service, s.Devices, bucketCounts(s.States), bucketCounts(s.Health))
+	}
+	t.Print()
+
+	failing := slices.DeleteFunc(slices.Clone(r.Services), func(s serviceHealth) bool { return len(s.Failing) == 0 })
+	slices.SortStableFunc(failing, func(a, b serviceHealth) int {
+		return cmp.Compare(len(b.Failing)*a.Devices, len(a.Failing)*b.Devices)
+	})
+	if len(failing) > 0 && top > 0 {
+		fmt.Println("\nTop failing services:")
+		t = subcommands.Tabby(1, "APP", "SERVICE", "FAILING", "DEVICES")
+		for _, s := range failing[:min(top, len(failing))] {
+			pct := float64(len(s.Failing)) / float64(s.Devices) * 100
+			t.AddLine(s.App, s.Service, fmt.Sprintf("%d of %d (%.1f%%)", len(s.Failing), s.Devices, pct),
+				deviceExamples(s.Failing))
+		}
+		t.Print()
+	}
+
+	if len(r.Mismatches) > 0 {
+		fmt.Println("\nApps running another URI than their Target's:")
+		t = subcommands.Tabby(1, "APP", "URI", "TARGET'S URI", "DEVICES")
+		for _, m := range r.Mismatches {
+			t.AddLine(m.App, m.Uri, m.Expected, fmt.Sprintf("%d: %s", len(m.Devices), deviceExamples(m.Devices)))
+		}
+		t.Print()
+	}
+}
@@ -0,0 +1,31 @@
+package devices
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+)
+
+func TestAppsHealthLookupErrors(t *testing.T) {
+	srv := newTestServer(t)
+	for _, name := range []string{"dev-1", "dev-2"} {
+		d := srv.AddDevice(client.Device{Name: name})
+		srv.InjectFault(fake.Fault{Path: "/ota/devices/" + d.Uuid + "/", Status: 403})
+	}
+
+	for _, output := range []string{"table", "json"} {
+		out, err := runCommand(t, output, "apps-health")
+		require.NotNil(t, err, output)
+		assert.Contains(t, err.Error(), "all 2 device(s)")
+		if output == "json" {
+			var report appsHealthReport
+			require.Nil(t, json.Unmarshal([]byte(out), &report))
+			assert.Len(t, report.Errors, 2)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/spf13/cobra"
 )
 
@@ -17,17 +16,19 @@ func init() {
 	appsStatesCmd := &cobra.Command{
 		Use:   "apps-states <name>",
 		Short: "List the states of Apps reported by a device",
-		Run:   doListStates,
+		RunE:  doListStates,
 		Args:  cobra.ExactArgs(1),
 	}
 	cmd.AddCommand(appsStatesCmd)
 	appsStatesCmd.Flags().IntVarP(&asListLimit, "limit", "n", 1, "Limit the number of App states to display.")
 }
 
-func doListStates(cmd *cobra.Command, args []string) {
+func doListStates(cmd *cobra.Command, args []string) error {
 	d := getDeviceApi(cmd, args[0])
 	states, err := d.GetAppsStates()
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	printAppsState := func(appsState map[string]client.AppState, stateFilter string, filterIn bool) {
 		for name, state := range appsState {
@@ -73,4 +74,5 @@ func doListStates(cmd *cobra.Command, args []string) {
 		printAppsState(s.Apps, "healthy", true)
 		fmt.Println()
 	}
+	return nil
 }
@@ -1,27 +1,46 @@
 package devices
 
 import (
-	"github.com/foundriesio/fioctl/subcommands"
+	"io"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
 )
 
 func init() {
-	cmd.AddCommand(&cobra.Command{
+	chownCmd := &cobra.Command{
 		Use:   "chown <device> <new-owner-id>",
 		Short: "Change the device's owner",
-		Run:   doChown,
-		Args:  cobra.ExactArgs(2),
+		RunE:  doChown,
+		Args:  selectorArgs(cobra.ExactArgs(2)),
 		Long: `Change the owner of a device. This command can only be run by Factory admins 
 and owners. The new owner-id can be found by running 'fioctl users'`,
-	})
+		Example: `
+# Change the owner of all devices in the "lab" device group:
+fioctl devices chown --selector group=lab <new-owner-id>`,
+	}
+	cmd.AddCommand(chownCmd)
+	addSelectorFlags(chownCmd)
 }
 
-func doChown(cmd *cobra.Command, args []string) {
+func doChown(cmd *cobra.Command, args []string) error {
+	if len(deviceSelector) > 0 {
+		owner := deviceArgs(args)[1]
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		return runBulk(cmd, "Change the owner of", devices, func(d *client.Device, out io.Writer) error {
+			return d.Api.Chown(owner)
+		})
+	}
+
 	logrus.Debug("Chown %r", args)
 	device := args[0]
 	owner := args[1]
 
 	d := getDeviceApi(cmd, device)
-	subcommands.DieNotNil(d.Chown(owner))
+	return d.Chown(owner)
 }
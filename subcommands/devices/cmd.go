@@ -21,8 +21,9 @@ var cmd = &cobra.Command{
 	Use:     "devices",
 	Aliases: []string{"device"},
 	Short:   "Manage devices registered to a Factory",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 }
 
@@ -40,12 +41,11 @@ var updatesCmd = &cobra.Command{
 	Use:   "updates <device> [<update-id>]",
 	Short: "Show updates performed on a device",
 	Args:  cobra.RangeArgs(1, 2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 1 {
-			doListUpdates(cmd, args)
-		} else {
-			doShowUpdate(cmd, args)
+			return doListUpdates(cmd, args)
 		}
+		return doShowUpdate(cmd, args)
 	},
 	Example: `
 # List all updates performed on a device:
@@ -58,7 +58,7 @@ fioctl devices updates <device> -n2
 fioctl devices updates <device> <update-id>
 
 # Show the most recent update with bash help:
-fioctl devices updates <device> $(fioctl devices updates <device> -n1 | tail -n1 | cut -f1 -d\ )
+fioctl devices updates <device> $(fioctl devices updates <device> -n1 -o 'template={{index . 0 "correlation-id"}}')
 `,
 }
 
@@ -72,6 +72,7 @@ func NewCommand() *cobra.Command {
 	cmd.AddCommand(updatesCmd)
 
 	addUuidFlagToChildren(cmd)
+	addDeviceCompletionToChildren(cmd)
 
 	return cmd
 }
@@ -87,6 +88,26 @@ func addUuidFlagToChildren(c *cobra.Command) {
 	}
 }
 
+// Complete a device argument of all commands taking one, unless a command completes its arguments itself.
+func addDeviceCompletionToChildren(c *cobra.Command) {
+	ignores := []string{"list-denied", "list", "delete-denied"}
+	for _, child := range c.Commands() {
+		if child.HasSubCommands() {
+			addDeviceCompletionToChildren(child)
+		} else if !slices.Contains(ignores, child.Name()) && child.ValidArgsFunction == nil {
+			if child.Name() == "delete" && child.Parent() == cmd {
+				// Deletes many devices at once
+				child.ValidArgsFunction = subcommands.CompleteDevices
+			} else {
+				child.ValidArgsFunction = subcommands.CompleteArgs(subcommands.CompleteDevices)
+			}
+		}
+		if child.Flags().Lookup("selector") != nil && child.ValidArgsFunction != nil && child.Name() != "delete" {
+			child.ValidArgsFunction = selectorCompletion(child.ValidArgsFunction)
+		}
+	}
+}
+
 func getDeviceApi(cmd *cobra.Command, name string) client.DeviceApi {
 	byUuid, err := cmd.Flags().GetBool("by-uuid")
 	if err != nil {
@@ -98,9 +119,7 @@ func getDeviceApi(cmd *cobra.Command, name string) client.DeviceApi {
 	return api.DeviceApiByName(viper.GetString("factory"), name)
 }
 
-func getDevice(cmd *cobra.Command, name string) *client.Device {
+func getDevice(cmd *cobra.Command, name string) (*client.Device, error) {
 	dapi := getDeviceApi(cmd, name)
-	d, err := dapi.Get()
-	subcommands.DieNotNil(err)
-	return d
+	return dapi.Get()
 }
@@ -0,0 +1,73 @@
+package devices
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+const testFactory = "acme"
+
+// Adds flags which fioctl adds to all devices subcommands
+var initCommands sync.Once
+
+// Points the package api at a fake server
+func newTestServer(t *testing.T) *fake.Server {
+	initCommands.Do(func() { NewCommand() })
+	srv := fake.NewServer(testFactory)
+	t.Cleanup(srv.Close)
+	api = srv.NewApi(client.Config{})
+	viper.Set("factory", testFactory)
+	t.Cleanup(func() { viper.Set("factory", "") })
+	return srv
+}
+
+// Runs a devices subcommand against the api set up by newTestServer, and returns what it printed.
+// The output format is one of the global --output flag values.
+func runCommand(t *testing.T, output string, args ...string) (string, error) {
+	c, rest, err := cmd.Find(args)
+	require.Nil(t, err)
+	defer func() {
+		// Flags of commands are global
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			if slice, ok := f.Value.(pflag.SliceValue); ok {
+				_ = slice.Replace(nil)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	}()
+	require.Nil(t, c.ParseFlags(rest))
+
+	origOutput := subcommands.OutputFormat
+	subcommands.OutputFormat = output
+	defer func() { subcommands.OutputFormat = origOutput }()
+	require.Nil(t, subcommands.ValidateOutputFormat())
+
+	r, w, err := os.Pipe()
+	require.Nil(t, err)
+	origStdout := os.Stdout
+	os.Stdout = w
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, r)
+		close(done)
+	}()
+	err = c.RunE(c, c.Flags().Args())
+	os.Stdout = origStdout
+	w.Close()
+	<-done
+	return out.String(), err
+}
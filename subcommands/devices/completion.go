@@ -0,0 +1,44 @@
+package devices
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+// Completes the names of config files of a device given as the first argument.
+func completeConfigFiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return subcommands.Complete(cmd, toComplete, "device-config-files", deviceCompletionKey(cmd, args),
+		func(api *client.Api, factory string) ([]string, error) {
+			d := deviceCompletionApi(cmd, api, factory, args[0])
+			dcl, err := d.ListConfig()
+			if err != nil {
+				return nil, err
+			}
+			return subcommands.ConfigFileNames(dcl), nil
+		})
+}
+
+// Completes remote actions configured on a device given as the first argument.
+func completeTriggerActions(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return subcommands.Complete(cmd, toComplete, "trigger-actions", deviceCompletionKey(cmd, args),
+		func(api *client.Api, factory string) ([]string, error) {
+			return loadRemoteActions(deviceCompletionApi(cmd, api, factory, args[0]))
+		})
+}
+
+func deviceCompletionKey(cmd *cobra.Command, args []string) string {
+	byUuid, _ := cmd.Flags().GetBool("by-uuid")
+	return strconv.FormatBool(byUuid) + ":" + args[0]
+}
+
+// The package's api is only set by a PersistentPreRunE, which does not run during a completion.
+func deviceCompletionApi(cmd *cobra.Command, api *client.Api, factory, name string) client.DeviceApi {
+	if byUuid, _ := cmd.Flags().GetBool("by-uuid"); byUuid {
+		return api.DeviceApiByUuid(factory, name)
+	}
+	return api.DeviceApiByName(factory, name)
+}
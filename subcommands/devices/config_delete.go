@@ -1,6 +1,8 @@
 package devices
 
 import (
+	"fmt"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -9,16 +11,21 @@ import (
 
 func init() {
 	configCmd.AddCommand(&cobra.Command{
-		Use:   "delete <device> <file>",
-		Short: "Delete file from the current configuration",
-		Run:   doConfigDelete,
-		Args:  cobra.ExactArgs(2),
+		Use:               "delete <device> <file>",
+		Short:             "Delete file from the current configuration",
+		RunE:              doConfigDelete,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteDevices, completeConfigFiles),
 	})
 }
 
-func doConfigDelete(cmd *cobra.Command, args []string) {
+func doConfigDelete(cmd *cobra.Command, args []string) error {
 	logrus.Debug("Deleting file from device config")
 
+	action := fmt.Sprintf("Delete a file from the config of the %s device", args[0])
+	if err := subcommands.Confirm(action, []string{args[1]}); err != nil {
+		return err
+	}
 	d := getDeviceApi(cmd, args[0])
-	subcommands.DieNotNil(d.DeleteConfig(args[1]))
+	return d.DeleteConfig(args[1])
 }
@@ -2,10 +2,12 @@ package devices
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
 )
 
@@ -13,32 +15,52 @@ func init() {
 	groupCmd := &cobra.Command{
 		Use:   "group <device> [<group>]",
 		Short: "Assign a device to an existing Factory device group",
-		Run:   doConfigGroup,
-		Args:  cobra.RangeArgs(1, 2),
+		RunE:  doConfigGroup,
+		Args:  selectorArgs(cobra.RangeArgs(1, 2)),
+		ValidArgsFunction: subcommands.CompleteArgs(
+			subcommands.CompleteDevices, subcommands.CompleteDeviceGroups),
+		Example: `
+# Assign all devices following the "devel" tag to the "lab" device group:
+fioctl devices config group --selector tag=devel lab`,
 	}
 	groupCmd.Flags().Bool("unset", false, "Unset an associated device group")
 	configCmd.AddCommand(groupCmd)
+	addSelectorFlags(groupCmd)
 }
 
-func doConfigGroup(cmd *cobra.Command, args []string) {
+func doConfigGroup(cmd *cobra.Command, args []string) error {
+	args = deviceArgs(args)
 	device := args[0]
 	unset, _ := cmd.Flags().GetBool("unset")
 	var group string
 	if unset {
 		if len(args) == 2 {
-			subcommands.DieNotNil(fmt.Errorf("Cannot assign and unset a device group in one command"))
+			return fmt.Errorf("Cannot assign and unset a device group in one command")
 		}
 		group = ""
 		logrus.Debugf("Unsetting a device group from device %s", device)
 	} else {
 		if len(args) == 1 {
-			subcommands.DieNotNil(fmt.Errorf("Either device group or --unset option must be provided"))
+			return fmt.Errorf("Either device group or --unset option must be provided")
 		}
 		group = args[1]
 		logrus.Debugf("Assigning device %s to group %s", device, group)
 	}
 
+	if len(deviceSelector) > 0 {
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		action := "Assign the " + group + " device group to"
+		if unset {
+			action = "Unset a device group of"
+		}
+		return runBulk(cmd, action, devices, func(d *client.Device, out io.Writer) error {
+			return d.Api.SetGroup(group)
+		})
+	}
+
 	d := getDeviceApi(cmd, device)
-	err := d.SetGroup(group)
-	subcommands.DieNotNil(err)
+	return d.SetGroup(group)
 }
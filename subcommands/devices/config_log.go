@@ -13,31 +13,30 @@ func init() {
 	logConfigCmd := &cobra.Command{
 		Use:   "log <device>",
 		Short: "Show a changelog of the device's configuration",
-		Run:   doConfigLog,
+		RunE:  doConfigLog,
 		Args:  cobra.ExactArgs(1),
 	}
 	configCmd.AddCommand(logConfigCmd)
 	logConfigCmd.Flags().IntP("limit", "n", 0, "Limit the number of results displayed.")
 }
 
-func doConfigLog(cmd *cobra.Command, args []string) {
+func doConfigLog(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	device := args[0]
 	listLimit, _ := cmd.Flags().GetInt("limit")
 	logrus.Debugf("Showing device config log for %s", device)
 
 	lookups, err := api.UsersGetLookups(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	d := getDeviceApi(cmd, device)
 
-	subcommands.LogConfigs(&subcommands.LogConfigsOptions{
+	return subcommands.LogConfigs(&subcommands.LogConfigsOptions{
 		Limit:         listLimit,
 		ShowAppliedAt: true,
-		ListFunc: func() (*client.DeviceConfigList, error) {
-			return d.ListConfig()
-		},
-		ListContFunc: api.DeviceListConfigCont,
-		UserLookup:   lookups,
+		Configs:       d.ListConfigIter(client.PaginateOptions{Prefetch: true}),
+		UserLookup:    lookups,
 	})
 }
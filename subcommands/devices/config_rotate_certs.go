@@ -2,11 +2,14 @@ package devices
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
 )
 
@@ -14,12 +17,15 @@ func init() {
 	cmd := &cobra.Command{
 		Use:   "rotate-certs <device>",
 		Short: "Rotate a device's x509 keypair used to connect to the device gateway",
-		Args:  cobra.ExactArgs(1),
-		Run:   doConfigRotate,
+		Args:  selectorArgs(cobra.ExactArgs(1)),
+		RunE:  doConfigRotate,
 		Long: `This command will send a fioconfig change to a device, instructing it to perform
 a certificate rotation using the EST server configured with "fioctl keys est".
 
 This command will only work for devices running LmP version 90 and later.`,
+		Example: `
+# Rotate certificates of all production devices in the "lab" device group:
+fioctl devices config rotate-certs --selector group=lab,prod=true --reason "Yearly rotation"`,
 	}
 	cmd.Flags().StringP("est-resource", "e", "/.well-known/est", "The path the to EST resource on your server")
 	cmd.Flags().IntP("est-port", "p", 8443, "The EST server port")
@@ -29,11 +35,12 @@ This command will only work for devices running LmP version 90 and later.`,
 	cmd.Flags().StringP("server-name", "", "", "EST server name when not using the Foundries.io managed server. e.g. est.example.com")
 	cmd.Flags().BoolP("dryrun", "", false, "Show what the fioconfig entry will be and exit")
 	configCmd.AddCommand(cmd)
+	addSelectorFlags(cmd)
 	_ = cmd.MarkFlagRequired("reason")
 }
 
-func doConfigRotate(cmd *cobra.Command, args []string) {
-	name := args[0]
+func doConfigRotate(cmd *cobra.Command, args []string) error {
+	name := deviceArgs(args)[0]
 	estResource, _ := cmd.Flags().GetString("est-resource")
 	estPort, _ := cmd.Flags().GetInt("est-port")
 	keyIds, _ := cmd.Flags().GetString("hsm-pkey-ids")
@@ -48,16 +55,25 @@ func doConfigRotate(cmd *cobra.Command, args []string) {
 
 	logrus.Debugf("Rotating device certs for %s", name)
 
-	// Quick sanity check for device
-	d := getDevice(cmd, name)
-
+	var d *client.Device
 	var err error
+	factory := viper.GetString("factory")
+	if len(deviceSelector) == 0 {
+		// Quick sanity check for device
+		if d, err = getDevice(cmd, name); err != nil {
+			return err
+		}
+		factory = d.Factory
+	}
+
 	var url string
 	if len(serverName) > 0 {
 		url = fmt.Sprintf("https://%s:%d%s", serverName, estPort, estResource)
 	} else {
-		url, err = api.FactoryEstUrl(d.Factory, estPort, estResource)
-		subcommands.DieNotNil(err)
+		url, err = api.FactoryEstUrl(factory, estPort, estResource)
+		if err != nil {
+			return err
+		}
 	}
 	logrus.Debugf("Using EST server: %s", url)
 
@@ -72,7 +88,16 @@ func doConfigRotate(cmd *cobra.Command, args []string) {
 	if dryRun {
 		fmt.Println("Config file would be:")
 		fmt.Println(ccr.Files[0].Value)
-		return
+		return nil
+	}
+	if len(deviceSelector) > 0 {
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		return runBulk(cmd, "Rotate certificates of", devices, func(d *client.Device, out io.Writer) error {
+			return d.Api.PatchConfig(ccr, false)
+		})
 	}
-	subcommands.DieNotNil(d.Api.PatchConfig(ccr, false))
+	return d.Api.PatchConfig(ccr, false)
 }
@@ -6,7 +6,9 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 
 	ecies "github.com/foundriesio/go-ecies"
 	"github.com/sirupsen/logrus"
@@ -61,52 +63,110 @@ device's configuration and apply it. The maximum size of a config is 1Mb.`,
   # fioctl will read in tmp.json, encrypt its contents, and upload it
   # to the OTA server. Instead of using ./tmp.json, the command can take
   # a "-" and will read the content from STDIN instead of a file.
+
+  # Set the same file for all devices of the "lab" device group,
+  # encrypting it with each device's public key:
+  fioctl devices config set --selector group=lab npmtok="root"
 `,
-		Run:  doConfigSet,
-		Args: cobra.MinimumNArgs(2),
+		RunE: doConfigSet,
+		Args: selectorArgs(cobra.MinimumNArgs(2)),
 	}
 	configCmd.AddCommand(setConfigCmd)
+	addSelectorFlags(setConfigCmd)
 	setConfigCmd.Flags().StringP("reason", "m", "", "Add a message to store as the \"reason\" for this change")
 	setConfigCmd.Flags().BoolP("raw", "", false, "Use raw configuration file")
 	setConfigCmd.Flags().BoolP("create", "", false, "Replace the whole config with these values. Default is to merge these values with the existing config values")
 }
 
-func loadEciesPub(pubkey string) *ecies.PublicKey {
+func loadEciesPub(pubkey string) (*ecies.PublicKey, error) {
 	block, _ := pem.Decode([]byte(pubkey))
 	if block == nil {
-		subcommands.DieNotNil(fmt.Errorf("Failed to parse certificate PEM"))
-		return nil // return for go linter
+		return nil, errors.New("Failed to parse certificate PEM")
 	}
 
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
-	subcommands.DieNotNil(err, "Failed to parse DER encoded public key:")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse DER encoded public key: %w", err)
+	}
 
 	ecpub := pub.(*ecdsa.PublicKey)
-	return ecies.ImportECDSAPublic(ecpub)
+	return ecies.ImportECDSAPublic(ecpub), nil
 }
 
-func eciesEncrypt(content string, pubkey *ecies.PublicKey) string {
+func eciesEncrypt(content string, pubkey *ecies.PublicKey) (string, error) {
 	message := []byte(content)
 	enc, err := ecies.Encrypt(rand.Reader, pubkey, message, nil, nil)
-	subcommands.DieNotNil(err, "Failed to encrypt:")
-	return base64.StdEncoding.EncodeToString(enc)
+	if err != nil {
+		return "", fmt.Errorf("Failed to encrypt: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(enc), nil
 }
 
-func doConfigSet(cmd *cobra.Command, args []string) {
+func doConfigSet(cmd *cobra.Command, args []string) error {
+	args = deviceArgs(args)
 	name := args[0]
 	reason, _ := cmd.Flags().GetString("reason")
 	isRaw, _ := cmd.Flags().GetBool("raw")
 	shouldCreate, _ := cmd.Flags().GetBool("create")
 
+	if len(deviceSelector) > 0 {
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		// Files are read once, and encrypted for each device with its own key
+		return subcommands.SetConfig(&subcommands.SetConfigOptions{
+			FileArgs:  args[1:],
+			Reason:    reason,
+			IsRawFile: isRaw,
+			SetFunc: func(cfg client.ConfigCreateRequest) error {
+				return runBulk(cmd, "Change the config of", devices, func(d *client.Device, out io.Writer) error {
+					// A device list does not include public keys
+					device, err := d.Api.Get()
+					if err != nil {
+						return err
+					}
+					if len(device.PublicKey) == 0 {
+						return fmt.Errorf("Device has no public key to encrypt with")
+					}
+					pubkey, err := loadEciesPub(device.PublicKey)
+					if err != nil {
+						return err
+					}
+					encrypted := cfg
+					encrypted.Files = make([]client.ConfigFile, len(cfg.Files))
+					for i, file := range cfg.Files {
+						if !file.Unencrypted {
+							if file.Value, err = eciesEncrypt(file.Value, pubkey); err != nil {
+								return err
+							}
+						}
+						encrypted.Files[i] = file
+					}
+					if shouldCreate {
+						return d.Api.CreateConfig(encrypted)
+					}
+					return d.Api.PatchConfig(encrypted, false)
+				})
+			},
+		})
+	}
+
 	logrus.Debugf("Creating new device config for %s", name)
 	// Ensure the device has a public key we can encrypt with
-	device := getDevice(cmd, name)
+	device, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 	if len(device.PublicKey) == 0 {
-		subcommands.DieNotNil(fmt.Errorf("Device has no public key to encrypt with"))
+		return fmt.Errorf("Device has no public key to encrypt with")
+	}
+	pubkey, err := loadEciesPub(device.PublicKey)
+	if err != nil {
+		return err
 	}
-	pubkey := loadEciesPub(device.PublicKey)
 
-	subcommands.SetConfig(&subcommands.SetConfigOptions{
+	return subcommands.SetConfig(&subcommands.SetConfigOptions{
 		FileArgs:  args[1:],
 		Reason:    reason,
 		IsRawFile: isRaw,
@@ -117,7 +177,7 @@ func doConfigSet(cmd *cobra.Command, args []string) {
 				return device.Api.PatchConfig(cfg, false)
 			}
 		},
-		EncryptFunc: func(value string) string {
+		EncryptFunc: func(value string) (string, error) {
 			return eciesEncrypt(value, pubkey)
 		},
 	})
@@ -1,6 +1,9 @@
 package devices
 
 import (
+	"errors"
+	"io"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -12,8 +15,8 @@ func init() {
 	configUpdatesCmd := &cobra.Command{
 		Use:   "updates <device>",
 		Short: "Configure aktualizr-lite settings for how updates are applied to a device",
-		Run:   doConfigUpdates,
-		Args:  cobra.ExactArgs(1),
+		RunE:  doConfigUpdates,
+		Args:  selectorArgs(cobra.ExactArgs(1)),
 		Long: `View or change configuration parameters used by aktualizr-lite for updating a device.
 When run with no options, this command print out how the device is
 currently configured and reporting.`,
@@ -43,11 +46,16 @@ currently configured and reporting.`,
        - /usr/lib/sota/conf.d/
        - /var/sota/sota.toml
        - /etc/sota/conf.d/
-  fioctl devices config updates <device> --tag -`,
+  fioctl devices config updates <device> --tag -
+
+  # Make all devices of the "lab" device group follow the "devel" tag:
+  fioctl devices config updates --selector group=lab --tag devel`,
 	}
 	configCmd.AddCommand(configUpdatesCmd)
+	addSelectorFlags(configUpdatesCmd)
 	configUpdatesCmd.Flags().StringP("tag", "", "", "Target tag for device to follow")
 	configUpdatesCmd.Flags().StringP("tags", "", "", "Target tag for device to follow")
+	subcommands.RegisterFlagCompletion(configUpdatesCmd, "tag", subcommands.CompleteTags)
 	configUpdatesCmd.Flags().StringP("apps", "", "", "comma,separate,list")
 	configUpdatesCmd.Flags().BoolP("dryrun", "", false, "Only show what would be changed")
 	configUpdatesCmd.Flags().BoolP("force", "", false, "DANGER: For a config on a device that might result in corruption")
@@ -55,8 +63,8 @@ currently configured and reporting.`,
 	_ = configUpdatesCmd.Flags().MarkHidden("tags") // assign for go linter
 }
 
-func doConfigUpdates(cmd *cobra.Command, args []string) {
-	name := args[0]
+func doConfigUpdates(cmd *cobra.Command, args []string) error {
+	name := deviceArgs(args)[0]
 	updateApps, _ := cmd.Flags().GetString("apps")
 	updateTag, _ := cmd.Flags().GetString("tag")
 	if len(updateTag) == 0 {
@@ -66,11 +74,41 @@ func doConfigUpdates(cmd *cobra.Command, args []string) {
 	isDryRun, _ := cmd.Flags().GetBool("dryrun")
 	isForced, _ := cmd.Flags().GetBool("force")
 
+	if len(deviceSelector) > 0 {
+		if len(updateApps) == 0 && len(updateTag) == 0 {
+			return subcommands.UsageError(errors.New("Either --apps or --tag must be provided with a selector"))
+		}
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		return runBulk(cmd, "Change the update configuration of", devices, func(d *client.Device, out io.Writer) error {
+			return subcommands.SetUpdatesConfig(&subcommands.SetUpdatesConfigOptions{
+				UpdateApps: updateApps,
+				UpdateTag:  updateTag,
+				IsDryRun:   isDryRun,
+				IsForced:   isForced,
+				Device:     d,
+				ListFunc: func() (*client.DeviceConfigList, error) {
+					return d.Api.ListConfig()
+				},
+				SetFunc: func(cfg client.ConfigCreateRequest, force bool) error {
+					return d.Api.PatchConfig(cfg, force)
+				},
+				Out: out,
+			},
+				d.Tag, d.DockerApps)
+		})
+	}
+
 	logrus.Debugf("Configuring device updates for %s", name)
 
-	device := getDevice(cmd, name)
+	device, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 
-	subcommands.SetUpdatesConfig(&subcommands.SetUpdatesConfigOptions{
+	return subcommands.SetUpdatesConfig(&subcommands.SetUpdatesConfigOptions{
 		UpdateApps: updateApps,
 		UpdateTag:  updateTag,
 		IsDryRun:   isDryRun,
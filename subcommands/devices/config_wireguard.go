@@ -2,9 +2,9 @@ package devices
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
-	"os"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -20,8 +20,10 @@ func init() {
 	configCmd.AddCommand(&cobra.Command{
 		Use:   "wireguard <device> [enable|disable]",
 		Short: "Enable or disable wireguard VPN for this device",
-		Run:   doConfigWireguard,
+		RunE:  doConfigWireguard,
 		Args:  cobra.RangeArgs(1, 2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteDevices,
+			cobra.FixedCompletions([]cobra.Completion{"enable", "disable"}, cobra.ShellCompDirectiveNoFileComp)),
 	})
 }
 
@@ -39,7 +41,7 @@ func (w WireguardClientConfig) Marshall() string {
 	return buff
 }
 
-func (w *WireguardClientConfig) Unmarshall(configVal string) {
+func (w *WireguardClientConfig) Unmarshall(configVal string) error {
 	w.Enabled = true
 	for _, line := range strings.Split(configVal, "\n") {
 		parts := strings.SplitN(line, "=", 2)
@@ -54,27 +56,26 @@ func (w *WireguardClientConfig) Unmarshall(configVal string) {
 		} else if k == "pubkey" {
 			w.PublicKey = strings.TrimSpace(parts[1])
 		} else {
-			fmt.Println("ERROR: Unexpected client config key: ", k)
-			os.Exit(1)
+			return fmt.Errorf("Unexpected client config key: %s", k)
 		}
 	}
+	return nil
 }
 
-func loadWireguardClientConfig(d client.DeviceApi) WireguardClientConfig {
+func loadWireguardClientConfig(d client.DeviceApi) (WireguardClientConfig, error) {
 	dcl, err := d.ListConfig()
 	wcc := WireguardClientConfig{}
 	if err != nil {
-		return wcc
+		return wcc, nil
 	}
 	if len(dcl.Configs) > 0 {
 		for _, cfgFile := range dcl.Configs[0].Files {
 			if cfgFile.Name == "wireguard-client" {
-				wcc.Unmarshall(cfgFile.Value)
-				break
+				return wcc, wcc.Unmarshall(cfgFile.Value)
 			}
 		}
 	}
-	return wcc
+	return wcc, nil
 }
 
 // Convert an IP into an uint32 so we can easily compare
@@ -87,10 +88,12 @@ func ipToUint32(ipaddr string) (uint32, error) {
 }
 
 // Create a dictionary of device VPN addresses in the factory
-func factoryIps(factory string) map[uint32]bool {
+func factoryIps(factory string) (map[uint32]bool, error) {
 	ips := make(map[uint32]bool)
 	ipList, err := api.GetWireGuardIps(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 	for _, item := range ipList {
 		ip, err := ipToUint32(item.Ip)
 		if err != nil {
@@ -99,43 +102,48 @@ func factoryIps(factory string) map[uint32]bool {
 			ips[ip] = true
 		}
 	}
-	return ips
+	return ips, nil
 }
 
-func findVpnAddress(factory string) string {
-	wsc := config.LoadWireguardServerConfig(factory, api)
+func findVpnAddress(factory string) (string, error) {
+	wsc, err := config.LoadWireguardServerConfig(factory, api)
+	if err != nil {
+		return "", err
+	}
 	if len(wsc.VpnAddress) == 0 || !wsc.Enabled {
-		fmt.Println("ERROR: A wireguard server has not been configured for this Factory")
-		os.Exit(1)
+		return "", errors.New("A wireguard server has not been configured for this Factory")
 	}
 	logrus.Debugf("VPN server address is: %s", wsc.VpnAddress)
 	serverIp, err := ipToUint32(wsc.VpnAddress)
 	if err != nil {
-		fmt.Println("ERROR: Wireguard server has an invalid IP Address: ", wsc.VpnAddress)
-		os.Exit(1)
+		return "", fmt.Errorf("Wireguard server has an invalid IP Address: %s", wsc.VpnAddress)
 	}
 
-	ips := factoryIps(factory)
+	ips, err := factoryIps(factory)
+	if err != nil {
+		return "", err
+	}
 	for ip := serverIp + 1; ip < serverIp+10000; ip++ {
 		if _, ok := ips[ip]; !ok && byte(ip) != 0 {
 			logrus.Debugf("Found unique ip: %d", ip)
-			return fmt.Sprintf("%d.%d.%d.%d", byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip))
+			return fmt.Sprintf("%d.%d.%d.%d", byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip)), nil
 		}
 	}
 
-	fmt.Println("ERROR: Unable to find unique IP address for VPN")
-	os.Exit(1)
-	return ""
+	return "", errors.New("Unable to find unique IP address for VPN")
 }
 
-func doConfigWireguard(cmd *cobra.Command, args []string) {
+func doConfigWireguard(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debug("Configuring wireguard")
 
 	d := getDeviceApi(cmd, args[0])
 
 	// Ensure the device has a public key we can encrypt with
-	wcc := loadWireguardClientConfig(d)
+	wcc, err := loadWireguardClientConfig(d)
+	if err != nil {
+		return err
+	}
 	if len(args) == 1 {
 		fmt.Println("Enabled:", wcc.Enabled)
 		if len(wcc.Address) > 0 {
@@ -144,10 +152,9 @@ func doConfigWireguard(cmd *cobra.Command, args []string) {
 		if len(wcc.PublicKey) > 0 {
 			fmt.Println("Public Key:", wcc.PublicKey)
 		}
-		os.Exit(0)
+		return nil
 	} else if args[1] != "enable" && args[1] != "disable" {
-		fmt.Printf("Invalid argument: '%s'. Must be 'enable' or 'disable'\n", args[1])
-		os.Exit(0)
+		return subcommands.UsageError(fmt.Errorf("Invalid argument: '%s'. Must be 'enable' or 'disable'", args[1]))
 	}
 
 	cfg := client.ConfigCreateRequest{
@@ -163,17 +170,18 @@ func doConfigWireguard(cmd *cobra.Command, args []string) {
 
 	if args[1] == "enable" {
 		if len(wcc.PublicKey) == 0 {
-			fmt.Println("ERROR: Device has no public key for VPN")
-			os.Exit(1)
+			return errors.New("Device has no public key for VPN")
 		}
 		wcc.Enabled = true
 		if len(wcc.Address) == 0 {
 			fmt.Println("Finding a unique VPN address ...")
-			wcc.Address = findVpnAddress(factory)
+			if wcc.Address, err = findVpnAddress(factory); err != nil {
+				return err
+			}
 		}
 	} else {
 		wcc.Enabled = false
 	}
 	cfg.Files[0].Value = wcc.Marshall()
-	subcommands.DieNotNil(d.PatchConfig(cfg, false))
+	return d.PatchConfig(cfg, false)
 }
@@ -2,32 +2,61 @@ package devices
 
 import (
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
-	cmd.AddCommand(&cobra.Command{
-		Use:   "delete",
+	deleteCmd := &cobra.Command{
+		Use:   "delete [<device>...]",
 		Short: "Delete device(s) registered to a Factory.",
-		Run:   doDelete,
-		Args:  cobra.MinimumNArgs(1),
-	})
+		RunE:  doDelete,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(deviceSelector) > 0 {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		Example: `
+# Delete two devices:
+fioctl devices delete device-1 device-2
+
+# Delete all non-production devices not seen for 90 days:
+fioctl devices delete --selector prod=false,last-seen>90d`,
+	}
+	cmd.AddCommand(deleteCmd)
+	addSelectorFlags(deleteCmd)
 }
 
-func doDelete(cmd *cobra.Command, args []string) {
+func doDelete(cmd *cobra.Command, args []string) error {
+	if len(deviceSelector) > 0 {
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		return runBulk(cmd, "Delete", devices, func(d *client.Device, out io.Writer) error {
+			return d.Api.Delete()
+		})
+	}
+
 	logrus.Debug("Deleting %r", args)
 
+	action := fmt.Sprintf("Delete %d device(s) from the %s factory", len(args), viper.GetString("factory"))
+	if err := subcommands.Confirm(action, args); err != nil {
+		return err
+	}
 	for _, name := range args {
 		fmt.Printf("Deleting %s .. ", name)
 		d := getDeviceApi(cmd, name)
-		if err := d.Delete(); err != nil {
-			fmt.Printf("failed\n%s", err)
-			os.Exit(1)
-		} else {
-			fmt.Printf("ok\n")
+		if err := subcommands.PrintStepStatus(d.Delete()); err != nil {
+			return err
 		}
 	}
+	return nil
 }
@@ -3,17 +3,18 @@ package devices
 import (
 	"fmt"
 
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	cmd.AddCommand(&cobra.Command{
 		Use:   "delete-denied <uuid> [<uuid>...]",
 		Short: "Remove a device UUID from the deny list",
-		Run:   doDeleteDenied,
+		RunE:  doDeleteDenied,
 		Args:  cobra.MinimumNArgs(1),
 		Long: `Remove a device UUID from the deny list so that the UUID can be re-used.
 This is handy for Factories using HSMs and a factory-registration-reference
@@ -21,14 +22,16 @@ server.`,
 	})
 }
 
-func doDeleteDenied(cmd *cobra.Command, args []string) {
+func doDeleteDenied(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debug("Deleting %r", args)
 
 	for _, uuid := range args {
 		fmt.Printf("Deleting %s .. ", uuid)
 		d := api.DeviceApiByUuid(factory, uuid)
-		subcommands.DieNotNil(d.DeleteDenied())
-		fmt.Println("ok")
+		if err := subcommands.PrintStepStatus(d.DeleteDenied()); err != nil {
+			return err
+		}
 	}
+	return nil
 }
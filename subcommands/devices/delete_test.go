@@ -0,0 +1,51 @@
+package devices
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func TestDeleteDryRun(t *testing.T) {
+	srv := newTestServer(t)
+	api = srv.NewApi(client.Config{DryRun: true})
+	subcommands.AssumeYes = true
+	t.Cleanup(func() { subcommands.AssumeYes = false })
+	srv.AddDevice(client.Device{Name: "dev-1"})
+	srv.AddDevice(client.Device{Name: "dev-2"})
+
+	// Each device is previewed
+	out, err := runCommand(t, "table", "delete", "dev-1", "dev-2")
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "Deleting dev-1 .. dry run", lines[0])
+	assert.Regexp(t, "^Dry run: not sending DELETE .*/ota/devices/dev-1/", lines[1])
+	assert.Equal(t, "Deleting dev-2 .. dry run", lines[2])
+	assert.Regexp(t, "^Dry run: not sending DELETE .*/ota/devices/dev-2/", lines[3])
+
+	out, err = runCommand(t, "json", "delete", "--selector", "name=dev-%")
+	require.Nil(t, err)
+	var results []bulkResult
+	require.Nil(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 2)
+	for _, res := range results {
+		assert.True(t, res.Ok, res.Device)
+		assert.Contains(t, res.DryRun, "Dry run: not sending DELETE", res.Device)
+	}
+
+	out, err = runCommand(t, "table", "delete", "--selector", "name=dev-%")
+	require.Nil(t, err)
+	assert.Contains(t, out, "2 of 2 device(s) previewed by a dry run")
+
+	for _, name := range []string{"dev-1", "dev-2"} {
+		_, ok := srv.Device(name)
+		assert.True(t, ok, name)
+	}
+}
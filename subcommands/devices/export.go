@@ -0,0 +1,453 @@
+package devices
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+const (
+	exportCsv    = "csv"
+	exportJson   = "json"
+	exportNdjson = "ndjson"
+)
+
+// Columns which the device list does not include; each device is looked up to export them
+var detailColumns = map[string]column{
+	"lmp-ver": {func(d *client.Device) string { return d.LmpVer }},
+	"hostname": {func(d *client.Device) string {
+		if d.Network == nil {
+			return ""
+		}
+		return d.Network.Hostname
+	}},
+	"local-ipv4": {func(d *client.Device) string {
+		if d.Network == nil {
+			return ""
+		}
+		return d.Network.Ipv4
+	}},
+	"mac": {func(d *client.Device) string {
+		if d.Network == nil {
+			return ""
+		}
+		return d.Network.MAC
+	}},
+	"secondary-ecus": {func(d *client.Device) string {
+		ecus := make([]string, 0, len(d.Secondaries))
+		for _, ecu := range d.Secondaries {
+			ecus = append(ecus, fmt.Sprintf("%s/%s=%s", ecu.HardwareId, ecu.Serial, ecu.TargetName))
+		}
+		return strings.Join(ecus, ",")
+	}},
+}
+
+// A state of an interrupted export, saved next to an export file after each page
+type exportCheckpoint struct {
+	Selector string   `json:"selector"`
+	Format   string   `json:"format"`
+	Columns  []string `json:"columns"`
+	// A URL of the next page to export
+	Next string `json:"next"`
+	// A size of the export file after the last exported page
+	Offset int64 `json:"offset"`
+	Rows   int   `json:"rows"`
+}
+
+// Marks detail columns in a list of available columns
+const detailColumnNote = " (looks up each device)"
+
+// availableColumns returns sorted names of the device list columns, the detail columns, and the extra columns,
+// to be listed in a help of a command.
+func availableColumns(extra map[string]column) []string {
+	allCols := make([]string, 0, len(Columns)+len(detailColumns)+len(extra))
+	for k := range Columns {
+		allCols = append(allCols, k)
+	}
+	for k := range detailColumns {
+		allCols = append(allCols, k+detailColumnNote)
+	}
+	for k := range extra {
+		allCols = append(allCols, k)
+	}
+	sort.Strings(allCols)
+	return allCols
+}
+
+func init() {
+	defCols := []string{
+		"name", "uuid", "target", "status", "tag", "device-group", "apps", "up-to-date", "is-prod", "last-seen",
+	}
+	allCols := availableColumns(nil)
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export all devices of a Factory, walking through all pages of the device list",
+		RunE:  doExport,
+		Args:  cobra.NoArgs,
+		Long: `Export all devices of a Factory, walking through all pages of the device list.
+Devices are written out page by page as they are listed, sorted by name.
+
+When exporting into a file, the progress is saved after each page. If an export is interrupted
+(e.g. by Ctrl-C or a network failure), run the same command with --resume to continue it.
+
+Available columns:
+
+  * ` + strings.Join(allCols, "\n  * ") + `
+
+Columns of hardware info are named hw:<path>, see "fioctl devices hwinfo-report --help" for paths.
+
+` + selectorConditions,
+		Example: `
+# Export all devices into a CSV file:
+fioctl devices export --file devices.csv
+
+# Export production devices with their LmP versions and network info as JSON lines:
+fioctl devices export --selector prod=true --format ndjson --file prod.ndjson \
+  --columns name,uuid,target,lmp-ver,hostname,local-ipv4,mac
+
+# Continue an interrupted export:
+fioctl devices export --selector prod=true --format ndjson --file prod.ndjson \
+  --columns name,uuid,target,lmp-ver,hostname,local-ipv4,mac --resume`,
+	}
+	cmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("format", exportCsv, "Export format: csv, json, or ndjson (a JSON object per line)")
+	exportCmd.Flags().StringSlice("columns", defCols, "Columns to export")
+	exportCmd.Flags().String("file", "", "Write into this file instead of stdout")
+	exportCmd.Flags().Bool("resume", false, "Continue an interrupted export into the --file")
+	exportCmd.Flags().Uint64("page-size", 1000, "Number of devices listed per API request (at most 1000)")
+	addDeviceQueryFlags(exportCmd, "Only export devices matching a selector",
+		"Number of devices looked up at the same time, if some columns require it")
+}
+
+func doExport(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	columns, _ := cmd.Flags().GetStringSlice("columns")
+	path, _ := cmd.Flags().GetString("file")
+	resume, _ := cmd.Flags().GetBool("resume")
+	pageSize, _ := cmd.Flags().GetUint64("page-size")
+
+	if format != exportCsv && format != exportJson && format != exportNdjson {
+		return subcommands.UsageError(fmt.Errorf("Invalid format: %s. Allowed values: csv, json, ndjson", format))
+	}
+	if pageSize < 1 || pageSize > 1000 {
+		return subcommands.UsageError(fmt.Errorf("Invalid page size: %d", pageSize))
+	}
+	needDetails, needHardware := false, false
+	cols := make([]column, len(columns))
+	for idx, name := range columns {
+		if c, ok := detailColumns[name]; ok {
+			cols[idx] = c
+			needDetails = true
+			continue
+		}
+		c, err := deviceColumn(name)
+		if err != nil {
+			return subcommands.UsageError(err)
+		}
+		cols[idx] = c
+		needHardware = needHardware || isHwColumn(name)
+	}
+	if resume && len(path) == 0 {
+		return subcommands.UsageError(errors.New("Only an export into a --file can be resumed"))
+	}
+	filterBy, predicates, err := parseDeviceQuery()
+	if err != nil {
+		return err
+	}
+
+	exp := &deviceExport{
+		format:       format,
+		columns:      columns,
+		cols:         cols,
+		needDetails:  needDetails,
+		needHardware: needHardware,
+		predicates:   predicates,
+	}
+	state := exportCheckpoint{Selector: deviceSelector, Format: format, Columns: columns}
+	if len(path) == 0 {
+		exp.out = bufio.NewWriter(os.Stdout)
+		if err = exp.writeHeader(); err != nil {
+			return err
+		}
+	} else {
+		if err = exp.open(path, resume, &state); err != nil {
+			return err
+		}
+		defer exp.file.Close()
+	}
+
+	var dl *client.DeviceList
+	if len(state.Next) == 0 {
+		dl, err = api.DeviceList(filterBy, "name", 1, pageSize)
+	} else {
+		dl, err = api.DeviceListCont(state.Next)
+	}
+	for {
+		if err != nil {
+			return exp.interrupted(err)
+		}
+		if err = exp.writePage(dl.Devices); err != nil {
+			return exp.interrupted(err)
+		}
+		if dl.Next == nil {
+			break
+		}
+		state.Next = *dl.Next
+		state.Rows = exp.rows
+		if err = exp.saveCheckpoint(&state); err != nil {
+			return err
+		}
+		dl, err = api.DeviceListCont(*dl.Next)
+	}
+
+	if err = exp.writeFooter(); err != nil {
+		return err
+	}
+	if exp.file != nil {
+		if err = exp.file.Close(); err != nil {
+			return err
+		}
+		if err = os.Remove(exp.checkpointPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d device(s) into %s\n", exp.rows, path)
+	}
+	return nil
+}
+
+type deviceExport struct {
+	format       string
+	columns      []string
+	cols         []column
+	needDetails  bool
+	needHardware bool
+	predicates   []devicePredicate
+
+	path string
+	file *os.File
+	out  *bufio.Writer
+	rows int
+}
+
+// Opens an export file, either a new one, or an interrupted one to continue after its last exported page.
+func (e *deviceExport) open(path string, resume bool, state *exportCheckpoint) error {
+	e.path = path
+	if !resume {
+		var err error
+		if e.file, err = os.Create(path); err != nil {
+			return err
+		}
+		if err = os.Remove(e.checkpointPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		e.out = bufio.NewWriter(e.file)
+		return e.writeHeader()
+	}
+
+	buf, err := os.ReadFile(e.checkpointPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("There is no interrupted export to resume: %s does not exist", e.checkpointPath())
+	} else if err != nil {
+		return err
+	}
+	var saved exportCheckpoint
+	if err = json.Unmarshal(buf, &saved); err != nil {
+		return fmt.Errorf("Unable to read %s: %w", e.checkpointPath(), err)
+	}
+	if saved.Selector != state.Selector || saved.Format != state.Format ||
+		!slices.Equal(saved.Columns, state.Columns) {
+		return subcommands.UsageError(fmt.Errorf(
+			"An interrupted export was made with other options: --selector=%q --format=%s --columns=%s",
+			saved.Selector, saved.Format, strings.Join(saved.Columns, ",")))
+	}
+	*state = saved
+	if e.file, err = os.OpenFile(path, os.O_WRONLY, 0); err != nil {
+		return err
+	}
+	// Drop whatever was written after the last complete page
+	if err = e.file.Truncate(state.Offset); err != nil {
+		return err
+	}
+	if _, err = e.file.Seek(state.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	e.rows = state.Rows
+	e.out = bufio.NewWriter(e.file)
+	fmt.Fprintf(os.Stderr, "Resuming the export after %d device(s)\n", e.rows)
+	return nil
+}
+
+func (e *deviceExport) checkpointPath() string {
+	return e.path + ".resume"
+}
+
+func (e *deviceExport) saveCheckpoint(state *exportCheckpoint) error {
+	if e.file == nil {
+		return nil
+	}
+	offset, err := e.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	state.Offset = offset
+	buf, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	// A checkpoint must never be half written
+	tmp := e.checkpointPath() + ".tmp"
+	if err = os.WriteFile(tmp, buf, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.checkpointPath())
+}
+
+func (e *deviceExport) interrupted(err error) error {
+	if e.file != nil {
+		if _, statErr := os.Stat(e.checkpointPath()); statErr == nil {
+			fmt.Fprintf(os.Stderr, "Export interrupted after %d device(s). Run the same command with --resume to continue.\n", e.rows)
+		}
+	}
+	return err
+}
+
+func (e *deviceExport) writeHeader() error {
+	switch e.format {
+	case exportCsv:
+		header := make([]string, len(e.columns))
+		for i, col := range e.columns {
+			header[i] = strings.ToUpper(col)
+		}
+		w := csv.NewWriter(e.out)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	case exportJson:
+		if _, err := e.out.WriteString("["); err != nil {
+			return err
+		}
+	}
+	return e.out.Flush()
+}
+
+func (e *deviceExport) writeFooter() error {
+	if e.format == exportJson {
+		if e.rows > 0 {
+			_, _ = e.out.WriteString("\n")
+		}
+		if _, err := e.out.WriteString("]\n"); err != nil {
+			return err
+		}
+	}
+	return e.out.Flush()
+}
+
+// Writes a page of devices, and flushes it so that a checkpoint can point after it.
+func (e *deviceExport) writePage(devices []client.Device) error {
+	selected := make([]client.Device, 0, len(devices))
+	for _, d := range devices {
+		if matchesAll(&d, e.predicates) {
+			selected = append(selected, d)
+		}
+	}
+	if e.needDetails {
+		if err := lookupDevices(selected, nil); err != nil {
+			return err
+		}
+	} else if e.needHardware {
+		if err := lookupDevices(selected, missingHardware); err != nil {
+			return err
+		}
+	}
+
+	w := csv.NewWriter(e.out)
+	for i := range selected {
+		values := make([]string, len(e.columns))
+		for idx, col := range e.cols {
+			values[idx] = col.Formatter(&selected[i])
+		}
+		var err error
+		switch e.format {
+		case exportCsv:
+			err = w.Write(values)
+		case exportJson:
+			sep := ",\n"
+			if e.rows == 0 {
+				sep = "\n"
+			}
+			if _, err = e.out.WriteString(sep + "  "); err == nil {
+				err = e.writeObject(values)
+			}
+		case exportNdjson:
+			if err = e.writeObject(values); err == nil {
+				err = e.out.WriteByte('\n')
+			}
+		}
+		if err != nil {
+			return err
+		}
+		e.rows++
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return e.out.Flush()
+}
+
+// Writes a JSON object with the keys in the order of columns.
+func (e *deviceExport) writeObject(values []string) error {
+	e.out.WriteByte('{')
+	for i, col := range e.columns {
+		if i > 0 {
+			e.out.WriteByte(',')
+		}
+		key, _ := json.Marshal(col)
+		val, _ := json.Marshal(values[i])
+		e.out.Write(key)
+		e.out.WriteByte(':')
+		e.out.Write(val)
+	}
+	return e.out.WriteByte('}')
+}
+
+// Replaces devices with their full details, looking up at most --concurrency devices at a time.
+// If need is not nil, only the devices it returns true for are looked up.
+func lookupDevices(devices []client.Device, need devicePredicate) error {
+	errs := make([]error, len(devices))
+	forEachDevice(devices, func(i int, dev *client.Device) {
+		if need != nil && !need(dev) {
+			return
+		}
+		logrus.Debugf("Looking up device %s", dev.Uuid)
+		dapi := api.DeviceApiByUuid(dev.Factory, dev.Uuid)
+		d, err := dapi.Get()
+		if err != nil {
+			errs[i] = fmt.Errorf("Unable to look up device %s: %w", dev.Name, err)
+			return
+		}
+		if len(d.GroupName) == 0 && d.Group != nil {
+			d.GroupName = d.Group.Name
+		}
+		*dev = *d
+	})
+	return errors.Join(errs...)
+}
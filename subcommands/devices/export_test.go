@@ -0,0 +1,79 @@
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func TestExportResume(t *testing.T) {
+	srv := newTestServer(t)
+	var uuids []string
+	for i := 1; i <= 5; i++ {
+		d := srv.AddDevice(client.Device{Name: fmt.Sprintf("dev-%d", i), LmpVer: "95"})
+		uuids = append(uuids, d.Uuid)
+	}
+	dir := t.TempDir()
+
+	for _, format := range []string{exportCsv, exportJson, exportNdjson} {
+		// The lmp-ver column makes each device looked up, which is where the second page fails
+		args := []string{"export", "--format", format, "--columns", "name,lmp-ver", "--page-size", "2"}
+		expected := filepath.Join(dir, "expected."+format)
+		_, err := runCommand(t, "table", append(args, "--file", expected)...)
+		require.Nil(t, err, format)
+		assert.Contains(t, readFile(t, expected), "dev-5", format)
+
+		path := filepath.Join(dir, "devices."+format)
+		args = append(args, "--file", path)
+		srv.InjectFault(fake.Fault{Path: "/ota/devices/" + uuids[2] + "/", Status: 403})
+		_, err = runCommand(t, "table", args...)
+		require.ErrorIs(t, err, client.ErrForbidden, format)
+
+		buf, err := os.ReadFile(path + ".resume")
+		require.Nil(t, err, format)
+		var state exportCheckpoint
+		require.Nil(t, json.Unmarshal(buf, &state), format)
+		assert.Equal(t, 2, state.Rows, format)
+		assert.Contains(t, state.Next, "page=2", format)
+		info, err := os.Stat(path)
+		require.Nil(t, err, format)
+		assert.Equal(t, info.Size(), state.Offset, format)
+
+		// Anything after the last saved page is dropped
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		require.Nil(t, err)
+		_, err = f.WriteString("half a row")
+		require.Nil(t, err)
+		require.Nil(t, f.Close())
+
+		// A resumed export must use the same options
+		_, err = runCommand(t, "table", "export", "--format", format, "--columns", "name",
+			"--page-size", "2", "--file", path, "--resume")
+		assert.Equal(t, subcommands.ExitUsage, subcommands.ExitCode(err), format)
+
+		srv.ClearFaults()
+		_, err = runCommand(t, "table", append(args, "--resume")...)
+		require.Nil(t, err, format)
+		assert.Equal(t, readFile(t, expected), readFile(t, path), format)
+		assert.NoFileExists(t, path+".resume")
+
+		_, err = runCommand(t, "table", append(args, "--resume")...)
+		require.NotNil(t, err, format)
+		assert.Contains(t, err.Error(), "There is no interrupted export to resume")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	buf, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(buf)
+}
@@ -0,0 +1,395 @@
+package devices
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+const hwColumnPrefix = "hw:"
+
+const hwPathHelp = `A path into the hardware info of a device is similar to a jq path:
+  .key              A field of an object; use [key] or ["key"] for keys with dots
+  [N]               An N-th element of an array; negative N counts from the end
+  []                All elements of an array, or all values of an object
+  [key=value]       Only objects whose field has a given value
+  ..<step>          Apply the following step to a value and all values nested in it
+For example: .memory.size, .children[0].product, ..[class=processor].product`
+
+func init() {
+	reportCmd := &cobra.Command{
+		Use:   "hwinfo-report --path <path>",
+		Short: "Count devices per value of a field of their hardware info",
+		RunE:  doHwinfoReport,
+		Args:  cobra.NoArgs,
+		Long: `Count devices per value of a field of their hardware info, e.g. a kernel version, a RAM size,
+or a storage model. The hardware info of each device is looked up.
+
+A device is counted once for each distinct value found by a path.
+Devices without a value are counted as "-".
+
+` + hwPathHelp + `
+
+` + selectorConditions,
+		Example: `
+# Show RAM sizes of all devices:
+fioctl devices hwinfo-report --path ..[id=memory].size
+
+# Show CPU models in the "us-east" device group:
+fioctl devices hwinfo-report --path '..[class=processor].product' --selector group=us-east`,
+	}
+	cmd.AddCommand(reportCmd)
+	reportCmd.Flags().String("path", "", "A path to a field of the hardware info")
+	addDeviceQueryFlags(reportCmd, "Only include devices matching a selector",
+		"Number of devices looked up at the same time")
+	_ = reportCmd.MarkFlagRequired("path")
+}
+
+type hwStepKind int
+
+const (
+	hwField hwStepKind = iota
+	hwIndex
+	hwAll
+	hwFilter
+)
+
+type hwStep struct {
+	kind hwStepKind
+	// A step applies to a value and all values nested in it
+	recursive bool
+	key       string
+	value     string
+	index     int
+}
+
+type hwPath []hwStep
+
+// parseHwPath parses a path into hardware info; see hwPathHelp.
+func parseHwPath(expr string) (hwPath, error) {
+	invalid := func(reason string) (hwPath, error) {
+		return nil, fmt.Errorf("Invalid hardware info path %q: %s", expr, reason)
+	}
+	if len(expr) == 0 {
+		return invalid("empty path")
+	}
+	s := expr
+	if s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+	var path hwPath
+	for len(s) > 0 {
+		step := hwStep{}
+		if rest, ok := strings.CutPrefix(s, ".."); ok {
+			step.recursive = true
+			if s = rest; len(s) == 0 {
+				return invalid("nothing after ..")
+			}
+			if s[0] != '[' {
+				s = "." + s
+			}
+		}
+		switch {
+		case s == ".":
+			// An identity path
+			s = ""
+			continue
+		case strings.HasPrefix(s, ".["):
+			s = s[1:]
+			continue
+		case strings.HasPrefix(s, `."`):
+			key, rest, err := unquoteKey(s[1:])
+			if err != nil {
+				return invalid(err.Error())
+			}
+			step.kind, step.key, s = hwField, key, rest
+		case s[0] == '.':
+			end := strings.IndexAny(s[1:], ".[")
+			if end < 0 {
+				end = len(s) - 1
+			}
+			if end == 0 {
+				return invalid("missing a key after .")
+			}
+			step.kind, step.key, s = hwField, s[1:end+1], s[end+1:]
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if strings.HasPrefix(s, `["`) {
+				key, rest, err := unquoteKey(s[1:])
+				if err != nil {
+					return invalid(err.Error())
+				}
+				if !strings.HasPrefix(rest, "]") {
+					return invalid("missing ]")
+				}
+				step.kind, step.key, s = hwField, key, rest[1:]
+				break
+			}
+			if end < 0 {
+				return invalid("missing ]")
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			if len(inner) == 0 {
+				step.kind = hwAll
+			} else if key, val, ok := strings.Cut(inner, "="); ok {
+				step.kind, step.key, step.value = hwFilter, strings.TrimSpace(key), strings.TrimSpace(val)
+			} else if idx, err := strconv.Atoi(inner); err == nil {
+				step.kind, step.index = hwIndex, idx
+			} else {
+				step.kind, step.key = hwField, inner
+			}
+		default:
+			return invalid(fmt.Sprintf("unexpected %q", s))
+		}
+		path = append(path, step)
+	}
+	return path, nil
+}
+
+// Unquotes a leading JSON string, and returns it along with the rest of s
+func unquoteKey(s string) (string, string, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	var key string
+	if err := dec.Decode(&key); err != nil {
+		return "", "", errors.New("invalid quoted key")
+	}
+	return key, s[dec.InputOffset():], nil
+}
+
+// eval returns all values found by a path in hardware info.
+func (p hwPath) eval(hw *json.RawMessage) []any {
+	if hw == nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(*hw))
+	dec.UseNumber()
+	var root any
+	if err := dec.Decode(&root); err != nil {
+		logrus.Debugf("Unable to parse hardware info: %s", err)
+		return nil
+	}
+	values := []any{root}
+	for _, step := range p {
+		var next []any
+		for _, v := range values {
+			if !step.recursive {
+				next = step.apply(v, next)
+				continue
+			}
+			for _, nested := range descendants(v, nil) {
+				if _, isArray := nested.([]any); isArray && step.kind == hwFilter {
+					// Elements of an array are matched by themselves
+					continue
+				}
+				next = step.apply(nested, next)
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+func (s hwStep) apply(v any, res []any) []any {
+	switch s.kind {
+	case hwField:
+		if obj, ok := v.(map[string]any); ok {
+			if val, ok := obj[s.key]; ok {
+				res = append(res, val)
+			}
+		}
+	case hwIndex:
+		if arr, ok := v.([]any); ok {
+			idx := s.index
+			if idx < 0 {
+				idx += len(arr)
+			}
+			if idx >= 0 && idx < len(arr) {
+				res = append(res, arr[idx])
+			}
+		}
+	case hwAll:
+		res = append(res, children(v)...)
+	case hwFilter:
+		if arr, ok := v.([]any); ok {
+			for _, el := range arr {
+				if s.matches(el) {
+					res = append(res, el)
+				}
+			}
+		} else if s.matches(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (s hwStep) matches(v any) bool {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	val, ok := obj[s.key]
+	return ok && formatHwValue(val) == s.value
+}
+
+// Returns elements of an array, or values of an object sorted by their keys
+func children(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		res := make([]any, len(keys))
+		for i, k := range keys {
+			res[i] = v[k]
+		}
+		return res
+	}
+	return nil
+}
+
+// Returns a value followed by all values nested in it, depth first
+func descendants(v any, res []any) []any {
+	res = append(res, v)
+	for _, child := range children(v) {
+		res = descendants(child, res)
+	}
+	return res
+}
+
+func formatHwValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// Formats all values found by a path, each distinct value once
+func (p hwPath) values(d *client.Device) []string {
+	var res []string
+	for _, v := range p.eval(d.Hardware) {
+		if s := formatHwValue(v); len(s) > 0 && !slices.Contains(res, s) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+var (
+	hwColumnsLock sync.Mutex
+	hwColumns     = make(map[string]column)
+)
+
+// hwColumn returns a column showing values found by a path in hardware info of a device.
+func hwColumn(expr string) (column, error) {
+	hwColumnsLock.Lock()
+	defer hwColumnsLock.Unlock()
+	if c, ok := hwColumns[expr]; ok {
+		return c, nil
+	}
+	path, err := parseHwPath(expr)
+	if err != nil {
+		return column{}, err
+	}
+	c := column{func(d *client.Device) string { return strings.Join(path.values(d), ",") }}
+	hwColumns[expr] = c
+	return c, nil
+}
+
+func isHwColumn(name string) bool {
+	return strings.HasPrefix(name, hwColumnPrefix)
+}
+
+// A device list does not always include hardware info
+func missingHardware(d *client.Device) bool {
+	return d.Hardware == nil
+}
+
+type hwValueCount struct {
+	Value   string  `json:"value"`
+	Devices int     `json:"devices"`
+	Percent float64 `json:"percent"`
+}
+
+func doHwinfoReport(cmd *cobra.Command, args []string) error {
+	expr, _ := cmd.Flags().GetString("path")
+	path, err := parseHwPath(expr)
+	if err != nil {
+		return subcommands.UsageError(err)
+	}
+	filterBy, predicates, err := parseDeviceQuery()
+	if err != nil {
+		return err
+	}
+	devices, err := listDevices(filterBy, predicates)
+	if err != nil {
+		return err
+	}
+	if err = lookupDevices(devices, missingHardware); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for i := range devices {
+		values := path.values(&devices[i])
+		if len(values) == 0 {
+			values = []string{"-"}
+		}
+		for _, v := range values {
+			counts[v]++
+		}
+	}
+	report := make([]hwValueCount, 0, len(counts))
+	for value, n := range counts {
+		report = append(report, hwValueCount{
+			Value:   value,
+			Devices: n,
+			Percent: math.Round(float64(n)/float64(len(devices))*1000) / 10,
+		})
+	}
+	slices.SortFunc(report, func(a, b hwValueCount) int {
+		return cmp.Or(cmp.Compare(b.Devices, a.Devices), cmp.Compare(a.Value, b.Value))
+	})
+
+	out := subcommands.NewOutput(report, "VALUE", "DEVICES", "PERCENT")
+	for _, r := range report {
+		out.AddLine(r.Value, r.Devices, fmt.Sprintf("%.1f%%", r.Percent))
+	}
+	if err = out.Print(); err != nil {
+		return err
+	}
+	if subcommands.IsTableOutput() {
+		fmt.Printf("\nTotal: %d device(s)\n", len(devices))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package devices
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+const testHwinfo = `{
+	"id": "imx8mm",
+	"memory": {"size": 2048},
+	"config": {"kernel.version": "6.1.24"},
+	"children": [
+		{"id": "cpu:0", "class": "processor", "product": "cortex-a53"},
+		{"id": "core", "children": [
+			{"id": "cpu:1", "class": "processor", "product": "cortex-a53"},
+			{"id": "memory", "class": "memory", "size": 2147483648}
+		]},
+		{"id": "usb", "class": "bus", "product": "dwc3"}
+	]
+}`
+
+func TestParseHwPath(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		path hwPath
+	}{
+		{".", nil},
+		{"memory.size", hwPath{{kind: hwField, key: "memory"}, {kind: hwField, key: "size"}}},
+		{".children[-1]", hwPath{{kind: hwField, key: "children"}, {kind: hwIndex, index: -1}}},
+		{".children[].id", hwPath{{kind: hwField, key: "children"}, {kind: hwAll}, {kind: hwField, key: "id"}}},
+		{`.config["kernel.version"]`, hwPath{{kind: hwField, key: "config"}, {kind: hwField, key: "kernel.version"}}},
+		{`.config."kernel.version"`, hwPath{{kind: hwField, key: "config"}, {kind: hwField, key: "kernel.version"}}},
+		{".config[kernel]", hwPath{{kind: hwField, key: "config"}, {kind: hwField, key: "kernel"}}},
+		{"..[class = processor]", hwPath{{kind: hwFilter, recursive: true, key: "class", value: "processor"}}},
+		{"..product", hwPath{{kind: hwField, recursive: true, key: "product"}}},
+	} {
+		path, err := parseHwPath(tc.expr)
+		require.Nil(t, err, tc.expr)
+		assert.Equal(t, tc.path, path, tc.expr)
+	}
+
+	for _, expr := range []string{"", "..", ".children[0", `.["key]`, `.["key"`, ".memory..", ".memory.["} {
+		_, err := parseHwPath(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func TestHwPathValues(t *testing.T) {
+	hw := json.RawMessage(testHwinfo)
+	d := &client.Device{Hardware: &hw}
+	for _, tc := range []struct {
+		expr   string
+		values []string
+	}{
+		{".id", []string{"imx8mm"}},
+		{".memory.size", []string{"2048"}},
+		{".memory", []string{`{"size":2048}`}},
+		{`.config["kernel.version"]`, []string{"6.1.24"}},
+		{".children[0].product", []string{"cortex-a53"}},
+		{".children[-1].id", []string{"usb"}},
+		{".children[5].id", nil},
+		{".children[].id", []string{"cpu:0", "core", "usb"}},
+		{".children[class=bus].product", []string{"dwc3"}},
+		// Each distinct value is returned once
+		{"..[class=processor].product", []string{"cortex-a53"}},
+		{"..[class=processor].id", []string{"cpu:0", "cpu:1"}},
+		{"..[id=memory].size", []string{"2147483648"}},
+		{".missing", nil},
+		{".id.missing", nil},
+	} {
+		path, err := parseHwPath(tc.expr)
+		require.Nil(t, err, tc.expr)
+		assert.Equal(t, tc.values, path.values(d), tc.expr)
+	}
+
+	path, err := parseHwPath(".id")
+	require.Nil(t, err)
+	assert.Nil(t, path.values(&client.Device{}))
+	invalid := json.RawMessage("not json")
+	assert.Nil(t, path.values(&client.Device{Hardware: &invalid}))
+}
@@ -2,12 +2,12 @@ package devices
 
 import (
 	"fmt"
-	"os"
+	"iter"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/cheynewallace/tabby"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,6 +27,7 @@ var (
 	deviceUuid          string
 	showColumns         []string
 	showPage            uint64
+	showAllPages        bool
 	paginationLimit     uint64
 	paginationLimits    []uint64
 )
@@ -91,6 +92,17 @@ var Columns = map[string]column{
 	"is-wave":        {func(d *client.Device) string { return fmt.Sprintf("%v", d.IsWave) }},
 }
 
+// deviceColumn returns a column by its name, including columns of hardware info (hw:<path>).
+func deviceColumn(name string) (column, error) {
+	if c, ok := Columns[name]; ok {
+		return c, nil
+	}
+	if expr, ok := strings.CutPrefix(name, hwColumnPrefix); ok {
+		return hwColumn(expr)
+	}
+	return column{}, fmt.Errorf("Invalid column name: %s", name)
+}
+
 func addPaginationFlags(cmd *cobra.Command) {
 	paginationLimits = []uint64{10, 20, 30, 40, 50, 100, 200, 500, 1000}
 	limitsStr := ""
@@ -103,6 +115,8 @@ func addPaginationFlags(cmd *cobra.Command) {
 
 	cmd.Flags().Uint64VarP(&showPage, "page", "p", 1, "Page of devices to display when pagination is needed")
 	cmd.Flags().Uint64VarP(&paginationLimit, "limit", "n", 500, "Number of devices to paginate by. Allowed values: "+limitsStr)
+	cmd.Flags().BoolVarP(&showAllPages, "all", "", false, "Display devices from all pages")
+	cmd.MarkFlagsMutuallyExclusive("page", "all")
 }
 
 func addSortFlag(cmd *cobra.Command, flag, short, help string) {
@@ -111,7 +125,7 @@ func addSortFlag(cmd *cobra.Command, flag, short, help string) {
 	cmd.Flags().Lookup(flag).NoOptDefVal = "asc"
 }
 
-func appendSortFlagValue(sortBy []string, cmd *cobra.Command, flag string, field string) []string {
+func appendSortFlagValue(sortBy []string, cmd *cobra.Command, flag string, field string) ([]string, error) {
 	val, _ := cmd.Flags().GetString(flag)
 	switch val {
 	case "":
@@ -121,9 +135,10 @@ func appendSortFlagValue(sortBy []string, cmd *cobra.Command, flag string, field
 	case "desc":
 		sortBy = append(sortBy, "-"+field)
 	default:
-		subcommands.DieNotNil(fmt.Errorf("Only 'asc, desc' values are allowed for %s but received: %s", flag, val))
+		return nil, subcommands.UsageError(
+			fmt.Errorf("Only 'asc, desc' values are allowed for %s but received: %s", flag, val))
 	}
-	return sortBy
+	return sortBy, nil
 }
 
 func init() {
@@ -138,9 +153,10 @@ func init() {
 	listCmd := &cobra.Command{
 		Use:   "list [pattern]",
 		Short: "List devices registered to Factories. Optionally, include filepath style patterns to limit to device names. e.g. device-*",
-		Run:   doList,
+		RunE:  doList,
 		Args:  cobra.MaximumNArgs(1),
-		Long:  "Available columns for display:\n\n  * " + strings.Join(allCols, "\n  * "),
+		Long: "Available columns for display:\n\n  * " + strings.Join(allCols, "\n  * ") +
+			"\n\nColumns of hardware info are named hw:<path>, e.g. hw:.memory.size.\n" + hwPathHelp,
 	}
 	cmd.AddCommand(listCmd)
 	listCmd.Flags().BoolVarP(&deviceMine, "just-mine", "", false, "Only include devices owned by you")
@@ -149,6 +165,9 @@ func init() {
 	listCmd.Flags().StringVarP(&deviceByTag, "by-tag", "", "", "Only list devices configured with the given tag")
 	listCmd.Flags().StringVarP(&deviceByTarget, "by-target", "", "", "Only list devices updated to the given target name")
 	listCmd.Flags().StringVarP(&deviceByGroup, "by-group", "g", "", "Only list devices belonging to this group (Factory is mandatory)")
+	subcommands.RegisterFlagCompletion(listCmd, "by-tag", subcommands.CompleteTags)
+	subcommands.RegisterFlagCompletion(listCmd, "by-target", subcommands.CompleteTargetNames)
+	subcommands.RegisterFlagCompletion(listCmd, "by-group", subcommands.CompleteDeviceGroups)
 	listCmd.Flags().IntVarP(&deviceInactiveHours, "offline-threshold", "", 4, "List the device as 'OFFLINE' if not seen in the last X hours")
 	listCmd.Flags().StringVarP(&deviceUuid, "uuid", "", "", "Find device with the given UUID")
 	listCmd.Flags().StringSliceVarP(&showColumns, "columns", "", defCols, "Specify which columns to display")
@@ -159,50 +178,136 @@ func init() {
 	listCmd.MarkFlagsMutuallyExclusive("sort-by-name", "sort-by-last-seen")
 }
 
-func assertPagination() {
+func assertPagination() error {
 	// hack until: https://github.com/spf13/pflag/issues/236
 	for _, x := range paginationLimits {
 		if x == paginationLimit {
-			return
+			return nil
 		}
 	}
-	subcommands.DieNotNil(fmt.Errorf("Invalid limit: %d", paginationLimit))
+	return subcommands.UsageError(fmt.Errorf("Invalid limit: %d", paginationLimit))
 }
 
-func showDeviceList(dl *client.DeviceList, showColumns []string) {
-	t := tabby.New()
-	var cols = make([]interface{}, len(showColumns))
-	for idx, c := range showColumns {
-		if _, ok := Columns[c]; !ok {
-			fmt.Println("ERROR: Invalid column name:", c)
-			os.Exit(1)
+func showDeviceList(cmd *cobra.Command, dl *client.DeviceList, showColumns []string) error {
+	header, err := deviceListHeader(showColumns)
+	if err != nil {
+		return err
+	}
+	out := subcommands.NewOutput(dl.Devices, header...)
+	if err = lookupHardwareColumns(dl.Devices, showColumns); err != nil {
+		return err
+	}
+	if subcommands.IsTabularOutput() {
+		// Columns are not printed otherwise, and some of them (e.g. an owner) are expensive to compute
+		for _, device := range dl.Devices {
+			out.AddLine(deviceListRow(device, showColumns)...)
+		}
+	}
+	if err = out.Print(); err != nil {
+		return err
+	}
+	subcommands.ShowPages(cmd, showPage, dl.Next)
+	return nil
+}
+
+// Devices of all pages are printed as they are fetched
+func showDeviceListIter(devices iter.Seq2[client.Device, error], showColumns []string) error {
+	header, err := deviceListHeader(showColumns)
+	if err != nil {
+		return err
+	}
+	row := func(device client.Device) []any {
+		return deviceListRow(device, showColumns)
+	}
+	return subcommands.PrintIter(withHardwareColumns(devices, showColumns), row, header...)
+}
+
+// Devices are looked up for hardware info columns, unless the device list already includes it.
+func lookupHardwareColumns(devices []client.Device, showColumns []string) error {
+	if !needsHardwareLookup(showColumns) {
+		return nil
+	}
+	return lookupDevices(devices, missingHardware)
+}
+
+func needsHardwareLookup(showColumns []string) bool {
+	return subcommands.IsTabularOutput() && slices.ContainsFunc(showColumns, isHwColumn)
+}
+
+// Works like lookupHardwareColumns for devices produced by an iterator.
+// Devices are looked up in batches of a page size, so that they are still printed as they are fetched.
+func withHardwareColumns(devices iter.Seq2[client.Device, error], showColumns []string) iter.Seq2[client.Device, error] {
+	if !needsHardwareLookup(showColumns) {
+		return devices
+	}
+	return func(yield func(client.Device, error) bool) {
+		batch := make([]client.Device, 0, paginationLimit)
+		flush := func() bool {
+			if err := lookupHardwareColumns(batch, showColumns); err != nil {
+				yield(client.Device{}, err)
+				return false
+			}
+			for _, device := range batch {
+				if !yield(device, nil) {
+					return false
+				}
+			}
+			batch = batch[:0]
+			return true
 		}
-		cols[idx] = strings.ToUpper(c)
+		for device, err := range devices {
+			if err != nil {
+				yield(device, err)
+				return
+			}
+			if batch = append(batch, device); len(batch) == cap(batch) && !flush() {
+				return
+			}
+		}
+		flush()
 	}
-	t.AddHeader(cols...)
+}
 
-	row := make([]interface{}, len(showColumns))
-	for _, device := range dl.Devices {
-		if len(device.TargetName) == 0 {
-			device.TargetName = "???"
+func deviceListHeader(showColumns []string) ([]string, error) {
+	var cols = make([]string, len(showColumns))
+	for idx, c := range showColumns {
+		if _, err := deviceColumn(c); err != nil {
+			return nil, subcommands.UsageError(err)
 		}
-		for idx, col := range showColumns {
-			col := Columns[col]
-			row[idx] = col.Formatter(&device)
+		if isHwColumn(c) {
+			cols[idx] = c
+		} else {
+			cols[idx] = strings.ToUpper(c)
 		}
-		t.AddLine(row...)
 	}
-	t.Print()
-	subcommands.ShowPages(showPage, dl.Next)
+	return cols, nil
 }
 
-func doList(cmd *cobra.Command, args []string) {
+func deviceListRow(device client.Device, showColumns []string) []any {
+	if len(device.TargetName) == 0 {
+		device.TargetName = "???"
+	}
+	row := make([]any, len(showColumns))
+	for idx, col := range showColumns {
+		col, _ := deviceColumn(col)
+		row[idx] = col.Formatter(&device)
+	}
+	return row
+}
+
+func doList(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Listing registered devices for: %s", factory)
-	assertPagination()
-	var sortBy []string
-	sortBy = appendSortFlagValue(sortBy, cmd, "sort-by-last-seen", "last_seen")
-	sortBy = appendSortFlagValue(sortBy, cmd, "sort-by-name", "name")
+	if err := assertPagination(); err != nil {
+		return err
+	}
+	sortBy, err := appendSortFlagValue(nil, cmd, "sort-by-last-seen", "last_seen")
+	if err != nil {
+		return err
+	}
+	if sortBy, err = appendSortFlagValue(sortBy, cmd, "sort-by-name", "name"); err != nil {
+		return err
+	}
 
 	filterBy := map[string]string{
 		"factory":     factory,
@@ -223,7 +328,13 @@ func doList(cmd *cobra.Command, args []string) {
 		filterBy["prod"] = "0"
 	}
 
+	if showAllPages {
+		opts := client.PaginateOptions{Prefetch: true}
+		return showDeviceListIter(api.DeviceListIter(filterBy, strings.Join(sortBy, ","), paginationLimit, opts), showColumns)
+	}
 	dl, err := api.DeviceList(filterBy, strings.Join(sortBy, ","), showPage, paginationLimit)
-	subcommands.DieNotNil(err)
-	showDeviceList(dl, showColumns)
+	if err != nil {
+		return err
+	}
+	return showDeviceList(cmd, dl, showColumns)
 }
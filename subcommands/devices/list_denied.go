@@ -5,14 +5,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	"github.com/foundriesio/fioctl/subcommands"
+	"github.com/foundriesio/fioctl/client"
 )
 
 func init() {
 	listCmd := &cobra.Command{
 		Use:   "list-denied",
 		Short: "List device UUIDs that have been denied access to the device-gateway",
-		Run:   doListDenied,
+		RunE:  doListDenied,
 		Long: `Devices created using a factory-registration-reference server get created
 on-demand. Because of this, devices are placed into a deny-list when
 they are deleted, so that they can't continue to access the system by getting 
@@ -22,12 +22,21 @@ re-created.`,
 	addPaginationFlags(listCmd)
 }
 
-func doListDenied(cmd *cobra.Command, args []string) {
+func doListDenied(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Listing denied devices for: %s", factory)
-	assertPagination()
+	if err := assertPagination(); err != nil {
+		return err
+	}
 
+	columns := []string{"uuid", "name", "owner"}
+	if showAllPages {
+		opts := client.PaginateOptions{Prefetch: true}
+		return showDeviceListIter(api.DeviceListDeniedIter(factory, paginationLimit, opts), columns)
+	}
 	dl, err := api.DeviceListDenied(factory, showPage, paginationLimit)
-	subcommands.DieNotNil(err)
-	showDeviceList(dl, []string{"uuid", "name", "owner"})
+	if err != nil {
+		return err
+	}
+	return showDeviceList(cmd, dl, columns)
 }
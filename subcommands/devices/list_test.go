@@ -0,0 +1,39 @@
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestListAll(t *testing.T) {
+	srv := newTestServer(t)
+	for i := range 12 {
+		srv.AddDevice(client.Device{Name: fmt.Sprintf("dev-%02d", i)})
+	}
+
+	out, err := runCommand(t, "csv", "list", "--all", "-n10", "--columns", "name")
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 13)
+	assert.Equal(t, "NAME", lines[0])
+	assert.Equal(t, "dev-00", lines[1])
+	assert.Equal(t, "dev-11", lines[12])
+
+	out, err = runCommand(t, "json", "list", "--all", "-n10")
+	require.Nil(t, err)
+	var devices []client.Device
+	require.Nil(t, json.Unmarshal([]byte(out), &devices))
+	require.Len(t, devices, 12)
+	assert.Equal(t, "dev-11", devices[11].Name)
+
+	out, err = runCommand(t, "template={{range .}}{{.name}} {{end}}", "list", "--all", "-n10")
+	require.Nil(t, err)
+	assert.Equal(t, 12, len(strings.Fields(out)))
+}
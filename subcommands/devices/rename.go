@@ -1,28 +1,99 @@
 package devices
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"strings"
+	"text/template"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
-	cmd.AddCommand(&cobra.Command{
+	renameCmd := &cobra.Command{
 		Use:   "rename <current name> <new name>",
 		Short: "Rename a device",
-		Run:   doRename,
-		Args:  cobra.ExactArgs(2),
-	})
+		RunE:  doRename,
+		Args:  selectorArgs(cobra.ExactArgs(2)),
+		Long: `Rename a device.
+
+When used with a selector, the new name is a Go template rendered for each device,
+with the same fields as the "devices list -o json" output.`,
+		Example: `
+# Rename a device:
+fioctl devices rename device-1 lab-device-1
+
+# Add a prefix to the names of all devices in the "lab" device group:
+fioctl devices rename --selector group=lab 'lab-{{.name}}'`,
+	}
+	cmd.AddCommand(renameCmd)
+	addSelectorFlags(renameCmd)
 }
 
-func doRename(cmd *cobra.Command, args []string) {
+func doRename(cmd *cobra.Command, args []string) error {
+	if len(deviceSelector) > 0 {
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		names, err := renderNewNames(deviceArgs(args)[1], devices)
+		if err != nil {
+			return err
+		}
+		return runBulk(cmd, "Rename", devices, func(d *client.Device, out io.Writer) error {
+			fmt.Fprintf(out, "New name: %s\n", names[d.Uuid])
+			return d.Api.Rename(names[d.Uuid])
+		})
+	}
+
 	logrus.Debugf("Renaming %s -> %s", args[0], args[1])
 
 	d := getDeviceApi(cmd, args[0])
-	if err := d.Rename(args[1]); err != nil {
-		fmt.Printf("failed\n%s", err)
-		os.Exit(1)
+	return d.Rename(args[1])
+}
+
+// Returns new device names by device UUIDs; all new names must be unique.
+func renderNewNames(text string, devices []client.Device) (map[string]string, error) {
+	if !strings.Contains(text, "{{") {
+		return nil, subcommands.UsageError(errors.New(
+			"A new name must be a template when used with a selector, e.g. 'lab-{{.name}}'"))
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, subcommands.UsageError(fmt.Errorf("Invalid new name template: %w", err))
+	}
+	names := make(map[string]string, len(devices))
+	taken := make(map[string]string, len(devices))
+	for _, d := range devices {
+		// Let a template see the same field names as the json output
+		var fields map[string]any
+		b, err := json.Marshal(d)
+		if err == nil {
+			err = json.Unmarshal(b, &fields)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, fields); err != nil {
+			return nil, subcommands.UsageError(fmt.Errorf("Unable to render a new name of %s: %w", d.Name, err))
+		}
+		name := strings.TrimSpace(buf.String())
+		if len(name) == 0 {
+			return nil, fmt.Errorf("A new name of %s is empty", d.Name)
+		}
+		if other, ok := taken[name]; ok {
+			return nil, fmt.Errorf("Devices %s and %s would both be renamed to %s", other, d.Name, name)
+		}
+		taken[name] = d.Name
+		names[d.Uuid] = name
 	}
+	return names, nil
 }
@@ -0,0 +1,38 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func TestRenderNewNames(t *testing.T) {
+	devices := []client.Device{
+		{Name: "dev-1", Uuid: "uuid-1", GroupName: "lab"},
+		{Name: "dev-2", Uuid: "uuid-2", GroupName: "lab"},
+	}
+	names, err := renderNewNames(`{{index . "device-group"}}-{{.name}} `, devices)
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{"uuid-1": "lab-dev-1", "uuid-2": "lab-dev-2"}, names)
+
+	for _, tc := range []struct {
+		text  string
+		code  int
+		error string
+	}{
+		{"lab-device", subcommands.ExitUsage, "must be a template"},
+		{"{{.name", subcommands.ExitUsage, "Invalid new name template"},
+		{"{{.no_such_field}}", subcommands.ExitUsage, "Unable to render a new name of dev-1"},
+		{"{{if false}}x{{end}}", subcommands.ExitError, "A new name of dev-1 is empty"},
+		{`{{index . "device-group"}}`, subcommands.ExitError, "Devices dev-1 and dev-2 would both be renamed to lab"},
+	} {
+		_, err := renderNewNames(tc.text, devices)
+		require.NotNil(t, err, tc.text)
+		assert.Contains(t, err.Error(), tc.error, tc.text)
+		assert.Equal(t, tc.code, subcommands.ExitCode(err), tc.text)
+	}
+}
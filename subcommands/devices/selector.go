@@ -0,0 +1,341 @@
+package devices
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+const selectorConditions = `A selector is a comma separated list of conditions a device must match:
+  name=<pattern>        A filepath style pattern of a name, e.g. name=lab-*
+  group=<group>         A device group
+  tag=<tag>             A tag a device follows
+  target=<target>       A Target name a device is running
+  prod=<true|false>     A production or a non-production device
+  status=<status>       A status as shown by "devices list", e.g. status=OFFLINE
+  up-to-date=<bool>     Whether a device is running the latest Target of its tag
+  last-seen><age>       Not seen within an age, e.g. last-seen>30d or last-seen>12h
+  last-seen<<age>       Seen within an age`
+
+const selectorHelp = "Run for all devices matching a selector instead of a named device.\n" + selectorConditions
+
+var (
+	deviceSelector    string
+	deviceConcurrency int
+)
+
+// A device matching predicate which the API cannot filter by
+type devicePredicate func(d *client.Device) bool
+
+func matchesAll(d *client.Device, predicates []devicePredicate) bool {
+	for _, predicate := range predicates {
+		if !predicate(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// addSelectorFlags makes a command taking a device as its first argument also run for many devices.
+func addSelectorFlags(cmd *cobra.Command) {
+	addDeviceQueryFlags(cmd, selectorHelp, "Number of devices changed at the same time when using a selector")
+}
+
+// addDeviceQueryFlags adds the --selector and --concurrency flags of a command looking at many devices.
+func addDeviceQueryFlags(cmd *cobra.Command, selectorUsage, concurrencyUsage string) {
+	cmd.Flags().StringVarP(&deviceSelector, "selector", "", "", selectorUsage)
+	cmd.Flags().IntVarP(&deviceConcurrency, "concurrency", "", 5, concurrencyUsage)
+}
+
+// parseDeviceQuery validates the --selector and --concurrency flags,
+// and returns API filters and predicates selecting devices of the factory.
+func parseDeviceQuery() (map[string]string, []devicePredicate, error) {
+	if deviceConcurrency < 1 {
+		return nil, nil, subcommands.UsageError(fmt.Errorf("Invalid concurrency: %d", deviceConcurrency))
+	}
+	filterBy, predicates, err := parseSelector(viper.GetString("factory"), deviceSelector)
+	if err != nil {
+		return nil, nil, subcommands.UsageError(err)
+	}
+	return filterBy, predicates, nil
+}
+
+// forEachDevice calls fn for each device, at most --concurrency devices at a time, and waits for all calls.
+// The fn must only change the device it is called for, or the i-th element of its own results.
+func forEachDevice[T any](devices []T, fn func(i int, d *T)) {
+	// The --concurrency is validated by parseDeviceQuery; a single device may be changed without it
+	slots := make(chan struct{}, max(deviceConcurrency, 1))
+	var wg sync.WaitGroup
+	for i := range devices {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			fn(i, &devices[i])
+		}()
+	}
+	wg.Wait()
+}
+
+// deviceArgs returns arguments of a command taking a device as its first argument.
+// There is no device argument when a selector is given, so an empty placeholder is put in its place
+// to keep the other arguments where a command expects them.
+func deviceArgs(args []string) []string {
+	if len(deviceSelector) > 0 {
+		return append([]string{""}, args...)
+	}
+	return args
+}
+
+// selectorArgs validates arguments of a command taking a device as its first argument.
+func selectorArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return validate(cmd, deviceArgs(args))
+	}
+}
+
+// selectorCompletion completes arguments of a command taking a device as its first argument.
+func selectorCompletion(complete cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		return complete(cmd, deviceArgs(args), toComplete)
+	}
+}
+
+// parseSelector converts a selector into API filters and predicates for a client side filtering.
+func parseSelector(factory, selector string) (map[string]string, []devicePredicate, error) {
+	filterBy := map[string]string{"factory": factory}
+	var predicates []devicePredicate
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if len(term) == 0 {
+			continue
+		}
+		if age, ok := strings.CutPrefix(term, "last-seen>"); ok {
+			d, err := parseAge(age)
+			if err != nil {
+				return nil, nil, err
+			}
+			predicates = append(predicates, func(dev *client.Device) bool { return !seenWithin(dev, d) })
+			continue
+		}
+		if age, ok := strings.CutPrefix(term, "last-seen<"); ok {
+			d, err := parseAge(age)
+			if err != nil {
+				return nil, nil, err
+			}
+			predicates = append(predicates, func(dev *client.Device) bool { return seenWithin(dev, d) })
+			continue
+		}
+		key, val, ok := strings.Cut(term, "=")
+		if !ok || len(val) == 0 {
+			return nil, nil, fmt.Errorf("Invalid selector condition: %s", term)
+		}
+		switch key {
+		case "name":
+			filterBy["name"] = val
+		case "group":
+			filterBy["group"] = val
+		case "tag":
+			filterBy["match_tag"] = val
+		case "target":
+			filterBy["target_name"] = val
+		case "prod", "up-to-date":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid selector condition: %s. The value must be true or false", term)
+			}
+			if key == "prod" {
+				filterBy["prod"] = map[bool]string{true: "1", false: "0"}[b]
+			} else {
+				predicates = append(predicates, func(dev *client.Device) bool { return dev.UpToDate == b })
+			}
+		case "status":
+			predicates = append(predicates, func(dev *client.Device) bool {
+				return strings.EqualFold(statusFormatter(dev), val)
+			})
+		default:
+			return nil, nil, fmt.Errorf("Invalid selector condition: %s. Unknown key: %s", term, key)
+		}
+	}
+	return filterBy, predicates, nil
+}
+
+// Accepts Go durations (e.g. 12h) and a number of days (e.g. 30d).
+func parseAge(age string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(age); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("Invalid age: %s. Use a number of days (e.g. 30d) or a duration (e.g. 12h)", age)
+}
+
+func seenWithin(d *client.Device, age time.Duration) bool {
+	t, err := time.Parse(time.RFC3339, d.LastSeen)
+	return err == nil && time.Since(t) <= age
+}
+
+// selectDevices returns all devices matching the --selector, sorted by name.
+func selectDevices() ([]client.Device, error) {
+	filterBy, predicates, err := parseDeviceQuery()
+	if err != nil {
+		return nil, err
+	}
+	if len(filterBy) == 1 && len(predicates) == 0 {
+		// Most likely, a mistake which would change all devices
+		return nil, subcommands.UsageError(errors.New("A selector must have at least one condition"))
+	}
+	return listDevices(filterBy, predicates)
+}
+
+// listDevices returns all devices matching API filters and predicates, sorted by name.
+func listDevices(filterBy map[string]string, predicates []devicePredicate) ([]client.Device, error) {
+	var devices []client.Device
+	opts := client.PaginateOptions{Prefetch: true}
+	for device, err := range api.DeviceListIter(filterBy, "name", 1000, opts) {
+		if err != nil {
+			return nil, err
+		}
+		if matchesAll(&device, predicates) {
+			// A device is changed by its UUID, so that a concurrent rename cannot redirect a change
+			device.Api = api.DeviceApiByUuid(device.Factory, device.Uuid)
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+type bulkResult struct {
+	Device string `json:"device"`
+	Uuid   string `json:"uuid"`
+	Ok     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	// A request which a dry run printed instead of sending it
+	DryRun string `json:"dry-run,omitempty"`
+	err    error
+}
+
+// A change of a single device; anything it prints into out is shown along with its result.
+type bulkFunc func(d *client.Device, out io.Writer) error
+
+// runBulk previews the selected devices, asks to confirm an action (e.g. "Delete" or "Change the owner of"),
+// and then runs it for each device
+// with at most --concurrency devices at a time. It fails with a partial failure exit code
+// if the action failed for some of the devices.
+func runBulk(cmd *cobra.Command, action string, devices []client.Device, fn bulkFunc) error {
+	if len(devices) == 0 {
+		if !subcommands.IsTableOutput() {
+			return bulkReport([]bulkResult{})
+		}
+		fmt.Println("No devices match the selector")
+		return nil
+	}
+	names := make([]string, len(devices))
+	for i, d := range devices {
+		names[i] = d.Name
+	}
+	prompt := fmt.Sprintf("%s %d device(s) of the %s factory", action, len(devices), viper.GetString("factory"))
+	if err := subcommands.Confirm(prompt, names); err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	results := make([]bulkResult, len(devices))
+	var mu sync.Mutex
+	forEachDevice(devices, func(i int, d *client.Device) {
+		if ctx != nil && ctx.Err() != nil {
+			results[i] = bulkResult{Device: d.Name, Uuid: d.Uuid, Error: ctx.Err().Error(), err: ctx.Err()}
+			return
+		}
+		var out bytes.Buffer
+		err := fn(d, &out)
+		res := bulkResult{Device: d.Name, Uuid: d.Uuid, Ok: err == nil, err: err}
+		if errors.Is(err, client.ErrDryRun) {
+			// Each device previews the first request it would send
+			res = bulkResult{Device: d.Name, Uuid: d.Uuid, Ok: true, DryRun: err.Error()}
+		} else if err != nil {
+			res.Error = err.Error()
+		}
+		results[i] = res
+
+		if subcommands.IsTableOutput() {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(res.DryRun) > 0 {
+				fmt.Printf("%s .. dry run\n", d.Name)
+				fmt.Fprintln(&out, res.DryRun)
+			} else if res.Ok {
+				fmt.Printf("%s .. ok\n", d.Name)
+			} else {
+				fmt.Printf("%s .. failed\n", d.Name)
+				fmt.Fprintln(&out, res.Error)
+			}
+			if out.Len() > 0 {
+				fmt.Print(indent(out.String(), "  "))
+			}
+		}
+	})
+	return bulkReport(results)
+}
+
+func bulkReport(results []bulkResult) error {
+	var failed []bulkResult
+	previewed := 0
+	for _, res := range results {
+		if !res.Ok {
+			failed = append(failed, res)
+		} else if len(res.DryRun) > 0 {
+			previewed++
+		}
+	}
+	if subcommands.IsTableOutput() {
+		if previewed > 0 {
+			fmt.Printf("\n%d of %d device(s) previewed by a dry run\n", previewed, len(results))
+		} else {
+			fmt.Printf("\n%d of %d device(s) succeeded\n", len(results)-len(failed), len(results))
+		}
+		if len(failed) > 0 {
+			fmt.Println("Failed devices:")
+			sort.Slice(failed, func(i, j int) bool { return failed[i].Device < failed[j].Device })
+			for _, res := range failed {
+				fmt.Println(" ", res.Device)
+			}
+		}
+	} else {
+		out := subcommands.NewOutput(results, "DEVICE", "UUID", "OK", "ERROR")
+		for _, res := range results {
+			out.AddLine(res.Device, res.Uuid, res.Ok, res.Error)
+		}
+		if err := out.Print(); err != nil {
+			return err
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	} else if len(failed) == len(results) {
+		// Usually, all devices fail for the same reason (e.g. missing permissions)
+		return fmt.Errorf("Operation failed for all %d device(s): %w", len(results), failed[0].err)
+	}
+	return subcommands.PartialFailureError(len(failed), len(results))
+}
+
+func indent(text, prefix string) string {
+	lines := strings.SplitAfter(strings.TrimRight(text, "\n"), "\n")
+	return prefix + strings.Join(lines, prefix) + "\n"
+}
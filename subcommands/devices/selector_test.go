@@ -0,0 +1,128 @@
+package devices
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func TestParseSelector(t *testing.T) {
+	hoursAgo := func(h int) string { return time.Now().Add(-time.Duration(h) * time.Hour).Format(time.RFC3339) }
+	fresh := client.Device{LastSeen: hoursAgo(1), UpToDate: true}
+	stale := client.Device{LastSeen: hoursAgo(24 * 40), Status: "OK"}
+
+	for _, tc := range []struct {
+		selector string
+		filterBy map[string]string
+		// Whether the fresh and the stale devices match the predicates
+		fresh, stale bool
+	}{
+		{"", map[string]string{}, true, true},
+		{"name=dev-%, group=lab", map[string]string{"name": "dev-%", "group": "lab"}, true, true},
+		{"tag=main,target=lmp-42", map[string]string{"match_tag": "main", "target_name": "lmp-42"}, true, true},
+		{"prod=true", map[string]string{"prod": "1"}, true, true},
+		{"prod=0", map[string]string{"prod": "0"}, true, true},
+		{"up-to-date=true", map[string]string{}, true, false},
+		{"last-seen>30d", map[string]string{}, false, true},
+		{"last-seen<12h", map[string]string{}, true, false},
+		{"status=offline", map[string]string{}, false, true},
+		{"status=OK,up-to-date=false", map[string]string{}, false, false},
+	} {
+		filterBy, predicates, err := parseSelector(testFactory, tc.selector)
+		require.Nil(t, err, tc.selector)
+		tc.filterBy["factory"] = testFactory
+		assert.Equal(t, tc.filterBy, filterBy, tc.selector)
+		assert.Equal(t, tc.fresh, matchesAll(&fresh, predicates), tc.selector)
+		assert.Equal(t, tc.stale, matchesAll(&stale, predicates), tc.selector)
+	}
+
+	for _, selector := range []string{
+		"group", "group=", "owner=me", "prod=maybe", "last-seen>month", "last-seen<-1h",
+	} {
+		_, _, err := parseSelector(testFactory, selector)
+		assert.NotNil(t, err, selector)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for _, tc := range []struct {
+		age string
+		res time.Duration
+	}{
+		{"0d", 0},
+		{"30d", 30 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"1h30m", 90 * time.Minute},
+	} {
+		d, err := parseAge(tc.age)
+		require.Nil(t, err, tc.age)
+		assert.Equal(t, tc.res, d, tc.age)
+	}
+	for _, age := range []string{"", "d", "-1d", "1.5d", "-1h", "30", "a month"} {
+		_, err := parseAge(age)
+		assert.NotNil(t, err, age)
+	}
+}
+
+func TestSelectDevicesNeedsCondition(t *testing.T) {
+	newTestServer(t)
+	for _, selector := range []string{",", " "} {
+		deviceSelector = selector
+		_, err := selectDevices()
+		assert.Equal(t, subcommands.ExitUsage, subcommands.ExitCode(err), selector)
+	}
+	deviceSelector = ""
+}
+
+func TestRunBulkPartialFailure(t *testing.T) {
+	srv := newTestServer(t)
+	subcommands.AssumeYes = true
+	t.Cleanup(func() { subcommands.AssumeYes = false })
+	var uuids []string
+	for _, name := range []string{"dev-1", "dev-2", "dev-3"} {
+		d := srv.AddDevice(client.Device{Name: name, GroupName: "lab"})
+		uuids = append(uuids, d.Uuid)
+	}
+	srv.AddDevice(client.Device{Name: "other"})
+	srv.InjectFault(fake.Fault{Method: "DELETE", Path: "/ota/devices/" + uuids[1] + "/", Status: 403})
+
+	out, err := runCommand(t, "json", "delete", "--selector", "group=lab")
+	assert.Equal(t, subcommands.ExitPartialFailure, subcommands.ExitCode(err))
+	var results []bulkResult
+	require.Nil(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 3)
+	for i, res := range results {
+		assert.Equal(t, uuids[i], res.Uuid)
+		assert.Equal(t, i != 1, res.Ok, res.Device)
+	}
+	assert.NotEmpty(t, results[1].Error)
+	_, ok := srv.Device("dev-2")
+	assert.True(t, ok)
+	_, ok = srv.Device("other")
+	assert.True(t, ok)
+
+	// When all devices fail, a command fails with the exit code of the first error
+	out, err = runCommand(t, "table", "delete", "--selector", "group=lab")
+	assert.Equal(t, subcommands.ExitAuth, subcommands.ExitCode(err))
+	assert.Contains(t, out, "dev-2 .. failed")
+	assert.Contains(t, out, "0 of 1 device(s) succeeded")
+
+	srv.ClearFaults()
+	_, err = runCommand(t, "json", "delete", "--selector", "group=lab")
+	assert.Nil(t, err)
+	_, ok = srv.Device("dev-2")
+	assert.False(t, ok)
+
+	// Nothing matches now, which is not a failure
+	out, err = runCommand(t, "json", "delete", "--selector", "group=lab")
+	assert.Nil(t, err)
+	require.Nil(t, json.Unmarshal([]byte(out), &results))
+	assert.Empty(t, results)
+}
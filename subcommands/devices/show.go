@@ -20,7 +20,7 @@ func init() {
 	showCmd := &cobra.Command{
 		Use:   "show <name>",
 		Short: "Show details of a specific device",
-		Run:   doShow,
+		RunE:  doShow,
 		Args:  cobra.ExactArgs(1),
 	}
 	cmd.AddCommand(showCmd)
@@ -28,9 +28,12 @@ func init() {
 	showCmd.Flags().BoolVarP(&showAkToml, "aktoml", "", false, "Show aktualizr-lite toml config")
 }
 
-func doShow(cmd *cobra.Command, args []string) {
+func doShow(cmd *cobra.Command, args []string) error {
 	logrus.Debug("Showing device")
-	device := getDevice(cmd, args[0])
+	device, err := getDevice(cmd, args[0])
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("UUID:\t\t%s\n", device.Uuid)
 	fmt.Printf("Name:\t\t%s\n", device.Name)
@@ -141,4 +144,5 @@ func doShow(cmd *cobra.Command, args []string) {
 		fmt.Println()
 		fmt.Print(device.PublicKey)
 	}
+	return nil
 }
@@ -0,0 +1,206 @@
+package devices
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+// Columns derived from device fields, only available for summaries
+var summaryColumns = map[string]column{
+	"online": {func(d *client.Device) string {
+		if d.Online(deviceInactiveHours) {
+			return "online"
+		}
+		return "offline"
+	}},
+	"apps-set": {func(d *client.Device) string {
+		apps := slices.Clone(d.DockerApps)
+		slices.Sort(apps)
+		return strings.Join(apps, ",")
+	}},
+	"last-seen-age": {func(d *client.Device) string {
+		t, err := time.Parse(time.RFC3339, d.LastSeen)
+		if err != nil {
+			return "never"
+		}
+		age := time.Since(t)
+		for _, bucket := range []struct {
+			name string
+			age  time.Duration
+		}{{"<1h", time.Hour}, {"<1d", 24 * time.Hour}, {"<7d", 7 * 24 * time.Hour}, {"<30d", 30 * 24 * time.Hour}} {
+			if age < bucket.age {
+				return bucket.name
+			}
+		}
+		return ">=30d"
+	}},
+}
+
+type deviceSummary struct {
+	Values  map[string]string `json:"values"`
+	Devices int               `json:"devices"`
+	Percent float64           `json:"percent"`
+}
+
+func init() {
+	allCols := availableColumns(summaryColumns)
+	summarizeCmd := &cobra.Command{
+		Use:   "summarize --by <column>[,<column>]",
+		Short: "Count devices per value of one or more columns",
+		RunE:  doSummarize,
+		Args:  cobra.NoArgs,
+		Long: `Count devices of a Factory per combination of values of the given columns,
+walking through all pages of the device list. The most common combinations are shown first.
+
+Available columns:
+
+  * ` + strings.Join(allCols, "\n  * ") + `
+
+Where "online" tells if a device was seen within the --offline-threshold, "apps-set" is a sorted
+list of Apps a device runs, and "last-seen-age" is one of <1h, <1d, <7d, <30d, >=30d, or never.
+Columns of hardware info are named hw:<path>, see "fioctl devices hwinfo-report --help" for paths.
+
+` + selectorConditions,
+		Example: `
+# Show which Targets devices run per LmP version:
+fioctl devices summarize --by lmp-ver,target
+
+# Show how many devices of each group are online:
+fioctl devices summarize --by device-group,online --selector prod=true`,
+	}
+	cmd.AddCommand(summarizeCmd)
+	summarizeCmd.Flags().StringSlice("by", nil, "Columns to count devices by")
+	summarizeCmd.Flags().IntVarP(&deviceInactiveHours, "offline-threshold", "", 4,
+		"Consider a device as 'OFFLINE' if not seen in the last X hours")
+	addDeviceQueryFlags(summarizeCmd, "Only count devices matching a selector",
+		"Number of devices looked up at the same time, if some columns require it")
+	_ = summarizeCmd.MarkFlagRequired("by")
+	_ = summarizeCmd.RegisterFlagCompletionFunc("by", func(
+		cmd *cobra.Command, args []string, toComplete string,
+	) ([]cobra.Completion, cobra.ShellCompDirective) {
+		prefix := toComplete[:strings.LastIndex(toComplete, ",")+1]
+		var res []cobra.Completion
+		for _, col := range allCols {
+			res = append(res, prefix+strings.TrimSuffix(col, detailColumnNote))
+		}
+		return res, cobra.ShellCompDirectiveNoSpace
+	})
+}
+
+func summaryColumn(name string) (column, error) {
+	if c, ok := detailColumns[name]; ok {
+		return c, nil
+	} else if c, ok = summaryColumns[name]; ok {
+		return c, nil
+	}
+	return deviceColumn(name)
+}
+
+func doSummarize(cmd *cobra.Command, args []string) error {
+	by, _ := cmd.Flags().GetStringSlice("by")
+	if len(by) == 0 {
+		return subcommands.UsageError(fmt.Errorf("At least one column is required"))
+	}
+	needDetails, needHardware := false, false
+	cols := make([]column, len(by))
+	for i, name := range by {
+		c, err := summaryColumn(name)
+		if err != nil {
+			return subcommands.UsageError(err)
+		}
+		cols[i] = c
+		_, detail := detailColumns[name]
+		needDetails = needDetails || detail
+		needHardware = needHardware || isHwColumn(name)
+	}
+	filterBy, predicates, err := parseDeviceQuery()
+	if err != nil {
+		return err
+	}
+	devices, err := listDevices(filterBy, predicates)
+	if err != nil {
+		return err
+	}
+	if needDetails {
+		err = lookupDevices(devices, nil)
+	} else if needHardware {
+		err = lookupDevices(devices, missingHardware)
+	}
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string]*deviceSummary)
+	for i := range devices {
+		values := make([]string, len(cols))
+		for idx, c := range cols {
+			values[idx] = c.Formatter(&devices[i])
+		}
+		// Values may contain any characters, except for a NUL
+		key := strings.Join(values, "\x00")
+		g := groups[key]
+		if g == nil {
+			g = &deviceSummary{Values: make(map[string]string, len(by))}
+			for idx, name := range by {
+				g.Values[name] = values[idx]
+			}
+			groups[key] = g
+		}
+		g.Devices++
+	}
+	summary := make([]deviceSummary, 0, len(groups))
+	for _, g := range groups {
+		g.Percent = math.Round(float64(g.Devices)/float64(len(devices))*1000) / 10
+		summary = append(summary, *g)
+	}
+	slices.SortFunc(summary, func(a, b deviceSummary) int {
+		if c := cmp.Compare(b.Devices, a.Devices); c != 0 {
+			return c
+		}
+		for _, name := range by {
+			if c := cmp.Compare(a.Values[name], b.Values[name]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	header := make([]string, 0, len(by)+2)
+	for _, name := range by {
+		if isHwColumn(name) {
+			header = append(header, name)
+		} else {
+			header = append(header, strings.ToUpper(name))
+		}
+	}
+	header = append(header, "DEVICES", "PERCENT")
+	out := subcommands.NewOutput(summary, header...)
+	for _, g := range summary {
+		row := make([]any, 0, len(header))
+		for _, name := range by {
+			val := g.Values[name]
+			if len(val) == 0 && subcommands.IsTableOutput() {
+				val = "-"
+			}
+			row = append(row, val)
+		}
+		row = append(row, g.Devices, fmt.Sprintf("%.1f%%", g.Percent))
+		out.AddLine(row...)
+	}
+	if err = out.Print(); err != nil {
+		return err
+	}
+	if subcommands.IsTableOutput() {
+		fmt.Printf("\nTotal: %d device(s)\n", len(devices))
+	}
+	return nil
+}
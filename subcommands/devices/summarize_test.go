@@ -0,0 +1,70 @@
+package devices
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestSummarize(t *testing.T) {
+	srv := newTestServer(t)
+	seen := func(ago time.Duration) string { return time.Now().Add(-ago).UTC().Format(time.RFC3339) }
+	for _, d := range []client.Device{
+		{Name: "a", GroupName: "lab", Tag: "main", LastSeen: seen(time.Minute), DockerApps: []string{"b", "a"}},
+		{Name: "b", GroupName: "lab", Tag: "main", LastSeen: seen(time.Minute), DockerApps: []string{"a", "b"}},
+		{Name: "c", GroupName: "lab", Tag: "devel", LastSeen: seen(2 * time.Hour)},
+		{Name: "d", GroupName: "prod", Tag: "main", LastSeen: seen(10 * 24 * time.Hour), DockerApps: []string{"a"}},
+		{Name: "e", Tag: "main", LastSeen: seen(40 * 24 * time.Hour)},
+	} {
+		srv.AddDevice(d)
+	}
+
+	// The most common combinations go first, and others are sorted by their values
+	out, err := runCommand(t, "table", "summarize", "--by", "device-group,tag")
+	require.Nil(t, err)
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		rows = append(rows, strings.Fields(line))
+	}
+	assert.Equal(t, [][]string{
+		{"DEVICE-GROUP", "TAG", "DEVICES", "PERCENT"},
+		{"------------", "---", "-------", "-------"},
+		{"lab", "main", "2", "40.0%"},
+		{"-", "main", "1", "20.0%"},
+		{"lab", "devel", "1", "20.0%"},
+		{"prod", "main", "1", "20.0%"},
+		{},
+		{"Total:", "5", "device(s)"},
+	}, rows)
+
+	// Derived columns
+	out, err = runCommand(t, "json", "summarize", "--by", "online,apps-set,last-seen-age", "--offline-threshold", "1")
+	require.Nil(t, err)
+	var summary []deviceSummary
+	require.Nil(t, json.Unmarshal([]byte(out), &summary))
+	values := func(online, apps, age string) map[string]string {
+		return map[string]string{"online": online, "apps-set": apps, "last-seen-age": age}
+	}
+	assert.Equal(t, []deviceSummary{
+		{Values: values("online", "a,b", "<1h"), Devices: 2, Percent: 40},
+		{Values: values("offline", "", "<1d"), Devices: 1, Percent: 20},
+		{Values: values("offline", "", ">=30d"), Devices: 1, Percent: 20},
+		{Values: values("offline", "a", "<30d"), Devices: 1, Percent: 20},
+	}, summary)
+
+	// Devices are only counted if they match a selector
+	out, err = runCommand(t, "json", "summarize", "--by", "online", "--selector", "group=lab")
+	require.Nil(t, err)
+	summary = nil
+	require.Nil(t, json.Unmarshal([]byte(out), &summary))
+	assert.Equal(t, []deviceSummary{{Values: map[string]string{"online": "online"}, Devices: 3, Percent: 100}}, summary)
+
+	_, err = runCommand(t, "table", "summarize", "--by", "no-such-column")
+	assert.NotNil(t, err)
+}
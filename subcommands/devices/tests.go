@@ -8,14 +8,14 @@ import (
 	"github.com/cheynewallace/tabby"
 	"github.com/spf13/cobra"
 
-	"github.com/foundriesio/fioctl/subcommands"
+	"github.com/foundriesio/fioctl/client"
 )
 
 func init() {
 	logsCmd := &cobra.Command{
 		Use:   "tests <device> [<test id> [<artifact name>]]",
 		Short: "List tests results uploaded by a device",
-		Run:   doTests,
+		RunE:  doTests,
 		Args:  cobra.RangeArgs(1, 3),
 	}
 	cmd.AddCommand(logsCmd)
@@ -30,46 +30,52 @@ func timestamp(ts float32) string {
 	return time.Unix(secs, nsecs).UTC().String()
 }
 
-func doTests(cmd *cobra.Command, args []string) {
+func doTests(cmd *cobra.Command, args []string) error {
 	switch len(args) {
 	case 1:
-		doTestList(cmd, args)
+		return doTestList(cmd, args)
 	case 2:
-		doTestShow(cmd, args)
+		return doTestShow(cmd, args)
 	case 3:
-		doTestArtifact(cmd, args)
+		return doTestArtifact(cmd, args)
 	default:
 		panic("invalid number of args") // "impossible" because of cobra.RangeArgs
 	}
 }
 
-func doTestList(cmd *cobra.Command, args []string) {
+func doTestList(cmd *cobra.Command, args []string) error {
 	name := args[0]
-	d := getDevice(cmd, name)
+	d, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 
 	t := tabby.New()
 	t.AddHeader("NAME", "STATUS", "ID", "CREATED AT")
 
-	tl, err := d.Api.Tests()
-	subcommands.DieNotNil(err)
-	for tl != nil {
-		for _, test := range tl.Tests {
-			created := timestamp(test.CreatedOn)
-			t.AddLine(test.Name, test.Status, test.Id, created)
+	for test, err := range d.Api.TestsIter(client.PaginateOptions{Prefetch: true}) {
+		if err != nil {
+			return err
 		}
-		tl, err = tl.NextPage()
-		subcommands.DieNotNil(err)
+		created := timestamp(test.CreatedOn)
+		t.AddLine(test.Name, test.Status, test.Id, created)
 	}
 	t.Print()
+	return nil
 }
 
-func doTestShow(cmd *cobra.Command, args []string) {
+func doTestShow(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	testId := args[1]
-	d := getDevice(cmd, name)
+	d, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 
 	result, err := d.Api.TestGet(testId)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println("Name:     ", result.Name)
 	fmt.Println("Status:   ", result.Status)
@@ -96,15 +102,22 @@ func doTestShow(cmd *cobra.Command, args []string) {
 		}
 		t.Print()
 	}
+	return nil
 }
 
-func doTestArtifact(cmd *cobra.Command, args []string) {
+func doTestArtifact(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	testId := args[1]
 	artifact := args[2]
-	d := getDevice(cmd, name)
+	d, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 
 	content, err := d.Api.TestResultArtifact(testId, artifact)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	os.Stdout.Write(*content)
+	return nil
 }
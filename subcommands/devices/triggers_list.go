@@ -2,11 +2,9 @@ package devices
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/spf13/cobra"
 )
 
@@ -15,15 +13,17 @@ func init() {
 		Use:   "list-configured <device>",
 		Short: "List remote actions configured on a device",
 		Long:  "*NOTE*: Requires devices running LmP version 97 or later.",
-		Run:   doListTriggers,
+		RunE:  doListTriggers,
 		Args:  cobra.ExactArgs(1),
 	}
 	triggersCmd.AddCommand(cmd)
 }
 
-func loadRemoteActions(d client.DeviceApi) []string {
+func loadRemoteActions(d client.DeviceApi) ([]string, error) {
 	dcl, err := d.ListConfig()
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 	if len(dcl.Configs) > 0 {
 		for _, cfgFile := range dcl.Configs[0].Files {
 			if cfgFile.Name == "fio-remote-actions" {
@@ -37,28 +37,35 @@ func loadRemoteActions(d client.DeviceApi) []string {
 				if actions == nil {
 					break
 				}
-				return actions
+				return actions, nil
 			}
 		}
 	}
-	return nil
+	return nil, nil
 }
 
-func doListTriggers(cmd *cobra.Command, args []string) {
+func doListTriggers(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
 	// Quick sanity check for device
-	d := getDevice(cmd, name)
+	d, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 
 	// See what triggers are allowed
-	allowed := loadRemoteActions(d.Api)
+	allowed, err := loadRemoteActions(d.Api)
+	if err != nil {
+		return err
+	}
 	if len(allowed) == 0 {
 		fmt.Println("Remote actions are not configured for this device")
-		os.Exit(0)
+		return nil
 	}
 
 	fmt.Println("Available actions:")
 	for _, trigger := range allowed {
 		fmt.Println("*", trigger)
 	}
+	return nil
 }
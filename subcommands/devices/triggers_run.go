@@ -25,26 +25,32 @@ look up the results using the "fioctl devices tests" command.
 # Initiate a remote trigger:
 $ fioctl devices triggers run <device> <trigger>
 `,
-		Args: cobra.ExactArgs(2),
-		Run:  doRunTrigger,
+		Args:              cobra.ExactArgs(2),
+		RunE:              doRunTrigger,
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteDevices, completeTriggerActions),
 	}
 	cmd.Flags().StringP("reason", "r", "", "The reason for running this command")
 	triggersCmd.AddCommand(cmd)
 }
 
-func doRunTrigger(cmd *cobra.Command, args []string) {
+func doRunTrigger(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
 	// Quick sanity check for device
-	d := getDevice(cmd, name)
+	d, err := getDevice(cmd, name)
+	if err != nil {
+		return err
+	}
 
 	// See what triggers are allowed
-	allowed := loadRemoteActions(d.Api)
+	allowed, err := loadRemoteActions(d.Api)
+	if err != nil {
+		return err
+	}
 
 	action := args[1]
 	if !slices.Contains(allowed, action) {
-		err := fmt.Errorf("Invalid action: %s. Allowed actions are: %s", action, allowed)
-		subcommands.DieNotNil(err)
+		return fmt.Errorf("Invalid action: %s. Allowed actions are: %s", action, allowed)
 	}
 	reason, _ := cmd.Flags().GetString("reason")
 
@@ -53,7 +59,7 @@ func doRunTrigger(cmd *cobra.Command, args []string) {
 	idLen := 15
 	maxActionLen := 48 - idLen - 1  // 1 for the underscore in the CommandId below
 	if len(action) > maxActionLen { // Device Gateway has max len of 48
-		subcommands.DieNotNil(fmt.Errorf("Action name(%s) too long. Max length is %d", action, maxActionLen))
+		return fmt.Errorf("Action name(%s) too long. Max length is %d", action, maxActionLen)
 	}
 	id := rand.Text()[:idLen]
 
@@ -65,9 +71,12 @@ func doRunTrigger(cmd *cobra.Command, args []string) {
 	}
 
 	ccr := opts.AsConfig()
-	subcommands.DieNotNil(d.Api.PatchConfig(ccr, false))
+	if err := d.Api.PatchConfig(ccr, false); err != nil {
+		return err
+	}
 	fmt.Println("Config change submitted. Command ID is:", opts.CommandId)
 	fmt.Printf("Use 'fioctl devices tests %s %s' to check results.\n", name, opts.CommandId)
+	return nil
 }
 
 type triggerOptions struct {
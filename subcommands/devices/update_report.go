@@ -0,0 +1,380 @@
+package devices
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func init() {
+	reportCmd := &cobra.Command{
+		Use:   "update-report",
+		Short: "Summarize how updates went across many devices",
+		RunE:  doUpdateReport,
+		Args:  cobra.NoArgs,
+		Long: `Summarize how updates went across many devices.
+
+With --target, the most recent update of each device to a given Target is reported.
+With --wave, the most recent update of each production device following the wave's tag to the wave's
+version is reported. Otherwise, the most recent update of each device matching the --selector is reported.
+Only the latest page of each device's update history is looked at.
+
+Two API requests are made per device, so without a --selector a report on a large factory
+takes a while; use the --selector (e.g. a device group) and the --concurrency to speed it up.
+
+The report shows a success rate, failures grouped by the failed event and its message,
+and durations of update phases based on the device reported event times:
+
+  download                DownloadStarted -> DownloadCompleted
+  install                 InstallationStarted -> InstallationApplied,
+                          or InstallationCompleted if an update needs no reboot
+  reboot and apps start   InstallationApplied -> InstallationCompleted
+
+` + selectorConditions,
+		Example: `
+# Show how the rollout of a Target went in the "us-east" device group:
+fioctl devices update-report --target lmp-123 --selector group=us-east
+
+# Show how a wave went, as JSON for a dashboard:
+fioctl devices update-report --wave wave-42 -o json`,
+	}
+	cmd.AddCommand(reportCmd)
+	reportCmd.Flags().String("target", "", "Report updates to this Target")
+	reportCmd.Flags().String("wave", "", "Report updates of this wave")
+	addDeviceQueryFlags(reportCmd, "Only report devices matching a selector",
+		"Number of devices looked up at the same time")
+	reportCmd.MarkFlagsMutuallyExclusive("target", "wave")
+	subcommands.RegisterFlagCompletion(reportCmd, "target", subcommands.CompleteTargetNames)
+}
+
+const (
+	eventDownloadStarted   = "EcuDownloadStarted"
+	eventDownloadCompleted = "EcuDownloadCompleted"
+	eventInstallStarted    = "EcuInstallationStarted"
+	eventInstallApplied    = "EcuInstallationApplied"
+	eventInstallCompleted  = "EcuInstallationCompleted"
+)
+
+type updatePhase struct {
+	name string
+	from string
+	// The first of these events to happen after the from event ends a phase
+	to []string
+}
+
+var updatePhases = []updatePhase{
+	{"download", eventDownloadStarted, []string{eventDownloadCompleted}},
+	{"install", eventInstallStarted, []string{eventInstallApplied, eventInstallCompleted}},
+	{"reboot and apps start", eventInstallApplied, []string{eventInstallCompleted}},
+}
+
+type updateReport struct {
+	Target      string          `json:"target,omitempty"`
+	Wave        string          `json:"wave,omitempty"`
+	Devices     int             `json:"devices"`
+	NotStarted  int             `json:"not-started"`
+	InProgress  int             `json:"in-progress"`
+	Succeeded   int             `json:"succeeded"`
+	Failed      int             `json:"failed"`
+	SuccessRate float64         `json:"success-rate"`
+	Failures    []updateFailure `json:"failures"`
+	Phases      []phaseStats    `json:"phases"`
+	// Devices which could not be looked up
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type updateFailure struct {
+	Event   string   `json:"event"`
+	Message string   `json:"message"`
+	Count   int      `json:"count"`
+	Devices []string `json:"devices"`
+}
+
+type phaseStats struct {
+	Phase   string  `json:"phase"`
+	Updates int     `json:"updates"`
+	Median  float64 `json:"median-seconds"`
+	P90     float64 `json:"p90-seconds"`
+	Max     float64 `json:"max-seconds"`
+}
+
+// An update of a single device; a nil update means the device did not start a matching update
+type deviceUpdate struct {
+	device string
+	update *client.Update
+	events []client.UpdateEvent
+	err    error
+}
+
+func doUpdateReport(cmd *cobra.Command, args []string) error {
+	factory := viper.GetString("factory")
+	target, _ := cmd.Flags().GetString("target")
+	wave, _ := cmd.Flags().GetString("wave")
+	if len(target) == 0 && len(wave) == 0 && len(deviceSelector) == 0 {
+		return subcommands.UsageError(errors.New("One of --target, --wave, or --selector is required"))
+	}
+	filterBy, predicates, err := parseDeviceQuery()
+	if err != nil {
+		return err
+	}
+
+	report := updateReport{Target: target, Wave: wave, Failures: []updateFailure{}, Phases: []phaseStats{}}
+	// The most recent update is reported, unless it has to be for a given Target or version
+	matches := func(u *client.Update) bool { return true }
+	if len(target) > 0 {
+		matches = func(u *client.Update) bool { return u.Target == target }
+	} else if len(wave) > 0 {
+		w, err := api.FactoryGetWave(factory, wave, false)
+		if err != nil {
+			return err
+		}
+		if _, ok := filterBy["prod"]; !ok {
+			filterBy["prod"] = "1"
+		}
+		if _, ok := filterBy["match_tag"]; !ok {
+			filterBy["match_tag"] = w.Tag
+		}
+		matches = func(u *client.Update) bool { return u.Version == w.Version }
+	}
+
+	devices, err := listDevices(filterBy, predicates)
+	if err != nil {
+		return err
+	}
+	updates := lookupUpdates(devices, matches)
+	summarizeUpdates(&report, updates)
+
+	// A report is printed anyway, so that it shows why devices could not be looked up
+	var lookupErr error
+	if len(report.Errors) == len(devices) && len(devices) > 0 {
+		lookupErr = fmt.Errorf("Unable to look up updates of all %d device(s)", len(devices))
+	}
+	if !subcommands.IsTableOutput() {
+		if err = subcommands.PrintData(report); err != nil {
+			return err
+		}
+	} else {
+		printUpdateReport(&report)
+	}
+	return lookupErr
+}
+
+// Looks up the most recent matching update of each device and its events, at most --concurrency devices at a time.
+func lookupUpdates(devices []client.Device, matches func(u *client.Update) bool) []deviceUpdate {
+	updates := make([]deviceUpdate, len(devices))
+	forEachDevice(devices, func(i int, d *client.Device) {
+		res := deviceUpdate{device: d.Name}
+		defer func() { updates[i] = res }()
+		logrus.Debugf("Looking up updates of %s", d.Name)
+		ul, err := d.Api.ListUpdates()
+		if err != nil {
+			res.err = err
+			return
+		}
+		for _, u := range ul.Updates {
+			if matches(&u) {
+				res.update = &u
+				break
+			}
+		}
+		if res.update != nil {
+			res.events, res.err = d.Api.UpdateEvents(res.update.CorrelationId)
+		}
+	})
+	return updates
+}
+
+func summarizeUpdates(report *updateReport, updates []deviceUpdate) {
+	failures := make(map[[2]string]*updateFailure)
+	durations := make(map[string][]time.Duration)
+	report.Devices = len(updates)
+	for _, u := range updates {
+		if u.err != nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[u.device] = u.err.Error()
+			continue
+		}
+		if u.update == nil {
+			report.NotStarted++
+			continue
+		}
+		for phase, d := range phaseDurations(u.events) {
+			durations[phase] = append(durations[phase], d)
+		}
+		if failed := failedEvent(u.events); failed != nil {
+			report.Failed++
+			key := [2]string{failed.Type.Id, normalizeUpdateMessage(failed.Detail.Details)}
+			f := failures[key]
+			if f == nil {
+				f = &updateFailure{Event: key[0], Message: key[1]}
+				failures[key] = f
+			}
+			f.Count++
+			f.Devices = append(f.Devices, u.device)
+		} else if completed(u.events) {
+			report.Succeeded++
+		} else {
+			report.InProgress++
+		}
+	}
+
+	if finished := report.Succeeded + report.Failed; finished > 0 {
+		report.SuccessRate = math.Round(float64(report.Succeeded)/float64(finished)*1000) / 10
+	}
+	for _, f := range failures {
+		report.Failures = append(report.Failures, *f)
+	}
+	slices.SortFunc(report.Failures, func(a, b updateFailure) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Event, b.Event), cmp.Compare(a.Message, b.Message))
+	})
+	for _, phase := range updatePhases {
+		ds := durations[phase.name]
+		if len(ds) == 0 {
+			continue
+		}
+		slices.Sort(ds)
+		report.Phases = append(report.Phases, phaseStats{
+			Phase:   phase.name,
+			Updates: len(ds),
+			Median:  percentile(ds, 0.5).Seconds(),
+			P90:     percentile(ds, 0.9).Seconds(),
+			Max:     ds[len(ds)-1].Seconds(),
+		})
+	}
+}
+
+func failedEvent(events []client.UpdateEvent) *client.UpdateEvent {
+	for i, e := range events {
+		if e.Detail.Success != nil && !*e.Detail.Success {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+func completed(events []client.UpdateEvent) bool {
+	for _, e := range events {
+		if e.Type.Id == eventInstallCompleted && e.Detail.Success != nil && *e.Detail.Success {
+			return true
+		}
+	}
+	return false
+}
+
+func phaseDurations(events []client.UpdateEvent) map[string]time.Duration {
+	res := make(map[string]time.Duration)
+	for _, phase := range updatePhases {
+		var start time.Time
+		for _, e := range events {
+			t, err := time.Parse(time.RFC3339, e.Time)
+			if err != nil {
+				continue
+			}
+			if e.Type.Id == phase.from && start.IsZero() {
+				start = t
+			} else if !start.IsZero() && slices.Contains(phase.to, e.Type.Id) {
+				if t.After(start) {
+					res[phase.name] = t.Sub(start)
+				}
+				break
+			}
+		}
+	}
+	return res
+}
+
+// A nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(idx, 0)]
+}
+
+var (
+	reUuid   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	reHash   = regexp.MustCompile(`(?i)\b[0-9a-f]{12,}\b`)
+	reNumber = regexp.MustCompile(`\b\d+(\.\d+)*\b`)
+	reSpaces = regexp.MustCompile(`\s+`)
+)
+
+// Makes the same failure of different devices look the same, by dropping identifiers, hashes,
+// and numbers (e.g. sizes or timestamps) from its message.
+func normalizeUpdateMessage(msg string) string {
+	msg = reUuid.ReplaceAllString(msg, "<uuid>")
+	msg = reHash.ReplaceAllString(msg, "<hash>")
+	msg = reNumber.ReplaceAllString(msg, "<n>")
+	msg = strings.TrimSpace(reSpaces.ReplaceAllString(msg, " "))
+	if len(msg) > 200 {
+		msg = msg[:200] + "..."
+	}
+	return msg
+}
+
+func printUpdateReport(report *updateReport) {
+	if len(report.Target) > 0 {
+		fmt.Printf("Target:\t\t%s\n", report.Target)
+	}
+	if len(report.Wave) > 0 {
+		fmt.Printf("Wave:\t\t%s\n", report.Wave)
+	}
+	fmt.Printf("Devices:\t%d\n", report.Devices)
+	fmt.Printf("Succeeded:\t%d\n", report.Succeeded)
+	fmt.Printf("Failed:\t\t%d\n", report.Failed)
+	fmt.Printf("In progress:\t%d\n", report.InProgress)
+	fmt.Printf("Not started:\t%d\n", report.NotStarted)
+	if len(report.Errors) > 0 {
+		fmt.Printf("Lookup errors:\t%d\n", len(report.Errors))
+	}
+	if finished := report.Succeeded + report.Failed; finished > 0 {
+		fmt.Printf("Success rate:\t%.1f%% of %d finished update(s)\n", report.SuccessRate, finished)
+	}
+
+	if len(report.Failures) > 0 {
+		fmt.Println("\nFailures:")
+		t := subcommands.Tabby(1, "DEVICES", "EVENT", "MESSAGE", "EXAMPLES")
+		for _, f := range report.Failures {
+			examples := f.Devices
+			if len(examples) > 3 {
+				examples = append(slices.Clone(examples[:3]), fmt.Sprintf("(+%d)", len(f.Devices)-3))
+			}
+			t.AddLine(f.Count, f.Event, f.Message, strings.Join(examples, ", "))
+		}
+		t.Print()
+	}
+	if len(report.Phases) > 0 {
+		fmt.Println("\nPhase durations:")
+		t := subcommands.Tabby(1, "PHASE", "UPDATES", "MEDIAN", "P90", "MAX")
+		for _, p := range report.Phases {
+			t.AddLine(p.Phase, p.Updates, seconds(p.Median), seconds(p.P90), seconds(p.Max))
+		}
+		t.Print()
+	}
+	if len(report.Errors) > 0 {
+		fmt.Println("\nDevices which could not be looked up:")
+		names := make([]string, 0, len(report.Errors))
+		for name := range report.Errors {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, report.Errors[name])
+		}
+	}
+}
+
+func seconds(s float64) string {
+	return (time.Duration(s) * time.Second).String()
+}
@@ -0,0 +1,98 @@
+package devices
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+)
+
+func TestUpdateReportLookupErrors(t *testing.T) {
+	srv := newTestServer(t)
+	for _, name := range []string{"dev-1", "dev-2"} {
+		d := srv.AddDevice(client.Device{Name: name})
+		srv.InjectFault(fake.Fault{Path: "/ota/devices/" + d.Uuid + "/", Status: 403})
+	}
+
+	for _, output := range []string{"table", "json"} {
+		out, err := runCommand(t, output, "update-report", "--target", "lmp-2")
+		require.NotNil(t, err, output)
+		assert.Contains(t, err.Error(), "all 2 device(s)")
+		if output == "json" {
+			var report updateReport
+			require.Nil(t, json.Unmarshal([]byte(out), &report))
+			assert.Len(t, report.Errors, 2)
+		}
+	}
+}
+
+func TestPhaseDurations(t *testing.T) {
+	event := func(id, at string) client.UpdateEvent {
+		return client.UpdateEvent{Time: "2026-01-02T10:" + at + "Z", Type: client.EventType{Id: id}}
+	}
+	for _, tc := range []struct {
+		name   string
+		events []client.UpdateEvent
+		res    map[string]time.Duration
+	}{
+		{"no events", nil, map[string]time.Duration{}},
+		{"complete", []client.UpdateEvent{
+			event(eventDownloadStarted, "00:00"),
+			event(eventDownloadCompleted, "02:30"),
+			event(eventInstallStarted, "03:00"),
+			event(eventInstallApplied, "04:00"),
+			event(eventInstallCompleted, "10:00"),
+		}, map[string]time.Duration{
+			"download":              150 * time.Second,
+			"install":               time.Minute,
+			"reboot and apps start": 6 * time.Minute,
+		}},
+		{"install without a reboot", []client.UpdateEvent{
+			event(eventInstallStarted, "03:00"),
+			event(eventInstallCompleted, "05:00"),
+		}, map[string]time.Duration{"install": 2 * time.Minute}},
+		{"in progress", []client.UpdateEvent{
+			event(eventDownloadStarted, "00:00"),
+			event(eventDownloadStarted, "01:00"),
+		}, map[string]time.Duration{}},
+		{"clock skew", []client.UpdateEvent{
+			event(eventDownloadStarted, "05:00"),
+			event(eventDownloadCompleted, "01:00"),
+		}, map[string]time.Duration{}},
+		{"invalid time", []client.UpdateEvent{
+			event(eventDownloadStarted, "00:00"),
+			{Time: "yesterday", Type: client.EventType{Id: eventDownloadCompleted}},
+			event(eventDownloadCompleted, "00:10"),
+		}, map[string]time.Duration{"download": 10 * time.Second}},
+	} {
+		assert.Equal(t, tc.res, phaseDurations(tc.events), tc.name)
+	}
+}
+
+func TestNormalizeUpdateMessage(t *testing.T) {
+	for _, tc := range []struct {
+		msg string
+		res string
+	}{
+		{"", ""},
+		{"Download failed", "Download failed"},
+		{
+			"Failed to fetch ostree 5f8a9c0d1e2b3a4f5e6d7c8b9a0f1e2d3c4b5a6f:  timeout after 30 s",
+			"Failed to fetch ostree <hash>: timeout after <n> s",
+		},
+		{
+			"Device 0b8f3c2e-1d4a-4b6c-9e7f-a1b2c3d4e5f6 has no space left: 1.5 GB needed",
+			"Device <uuid> has no space left: <n> GB needed",
+		},
+		{"App\tshellhttpd\n failed v2", "App shellhttpd failed v2"},
+		{strings.Repeat("x ", 150), strings.Repeat("x ", 100) + "..."},
+	} {
+		assert.Equal(t, tc.res, normalizeUpdateMessage(tc.msg), tc.msg)
+	}
+}
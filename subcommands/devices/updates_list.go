@@ -1,7 +1,6 @@
 package devices
 
 import (
-	"github.com/cheynewallace/tabby"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
@@ -9,34 +8,13 @@ import (
 	"github.com/foundriesio/fioctl/subcommands"
 )
 
-func doListUpdates(cmd *cobra.Command, args []string) {
+func doListUpdates(cmd *cobra.Command, args []string) error {
 	logrus.Debug("Showing device updates")
-	t := tabby.New()
-	t.AddHeader("ID", "TIME", "VERSION", "TARGET")
 	d := getDeviceApi(cmd, args[0])
-	var ul *client.UpdateList
-	for {
-		var err error
-		if ul == nil {
-			ul, err = d.ListUpdates()
-		} else {
-			if ul.Next != nil {
-				ul, err = d.ListUpdatesCont(*ul.Next)
-			} else {
-				break
-			}
-		}
-		subcommands.DieNotNil(err)
-		for _, update := range ul.Updates {
-			t.AddLine(update.CorrelationId, update.Time, update.Version, update.Target)
-			listLimit -= 1
-			if listLimit == 0 {
-				break
-			}
-		}
-		if listLimit == 0 {
-			break
-		}
+	row := func(update client.Update) []any {
+		return []any{update.CorrelationId, update.Time, update.Version, update.Target}
 	}
-	t.Print()
+	// Updates of all pages are printed as they are fetched
+	updates := d.ListUpdatesIter(client.PaginateOptions{Limit: listLimit})
+	return subcommands.PrintIter(updates, row, "ID", "TIME", "VERSION", "TARGET")
 }
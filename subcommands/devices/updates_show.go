@@ -7,16 +7,16 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
-func doShowUpdate(_ *cobra.Command, args []string) {
+func doShowUpdate(_ *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debug("Showing device update")
 	d := api.DeviceApiByName(factory, args[0])
 	events, err := d.UpdateEvents(args[1])
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	for _, event := range events {
 		fmt.Printf("%s : %s(%s)", event.Time, event.Type.Id, event.Detail.TargetName)
 		if event.Detail.Success != nil {
@@ -34,4 +34,5 @@ func doShowUpdate(_ *cobra.Command, args []string) {
 			fmt.Println(indented)
 		}
 	}
+	return nil
 }
@@ -0,0 +1,421 @@
+package devices
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func init() {
+	waitCmd := &cobra.Command{
+		Use:   "wait <device> --for <condition>",
+		Short: "Wait until a device reaches a given state",
+		RunE:  doWait,
+		Args:  selectorArgs(cobra.ExactArgs(1)),
+		Long: `Wait until a device reaches a given state, showing its changes as they happen.
+
+Conditions:
+  up-to-date        A device runs the latest Target of its tag
+  target=<name>     A device runs a given Target
+  online            A device was seen within the --offline-threshold
+  status=<status>   A device has a status as shown by "devices list", e.g. status=OK
+  config-applied    A device applied its latest config
+
+While waiting for "up-to-date" or "target", the events of an update the device performs are shown,
+and the wait fails as soon as the update fails. Errors of looking up a device (e.g. network errors)
+are shown, and the device is checked again after the --interval, unless the device does not exist.
+
+The command exits with code 0 once the condition is met, with code 8 if it is not met within the
+--timeout, and with code 1 if an update fails.`,
+		Example: `
+# Wait until a device updates to the latest Target of its tag:
+fioctl devices wait device-1 --for up-to-date
+
+# Wait up to an hour until all devices of the "lab" device group run a given Target:
+fioctl devices wait --selector group=lab --for target=lmp-123 --timeout 1h`,
+	}
+	cmd.AddCommand(waitCmd)
+	addSelectorFlags(waitCmd)
+	waitCmd.Flags().Lookup("concurrency").Usage = "Number of devices checked at the same time when using a selector"
+	waitCmd.Flags().String("for", "", "A condition to wait for (see above)")
+	waitCmd.Flags().Duration("timeout", 30*time.Minute, "Stop waiting after this time")
+	waitCmd.Flags().Duration("interval", 15*time.Second, "Check devices this often")
+	waitCmd.Flags().IntVarP(&deviceInactiveHours, "offline-threshold", "", 4,
+		"Consider a device as 'OFFLINE' if not seen in the last X hours")
+	_ = waitCmd.MarkFlagRequired("for")
+	_ = waitCmd.RegisterFlagCompletionFunc("for", cobra.FixedCompletions(
+		[]string{"up-to-date", "target=", "online", "status=", "config-applied"}, cobra.ShellCompDirectiveNoSpace))
+}
+
+type waitCondition struct {
+	name string
+	// Whether a failed update of a device ends the wait
+	watchUpdates bool
+	needsConfig  bool
+	met          func(d *client.Device, cfg *client.DeviceConfig) bool
+}
+
+func parseWaitCondition(cond string) (*waitCondition, error) {
+	key, val, hasVal := strings.Cut(cond, "=")
+	if hasVal != (key == "target" || key == "status") || (hasVal && len(val) == 0) {
+		return nil, fmt.Errorf("Invalid condition: %s", cond)
+	}
+	c := &waitCondition{name: cond}
+	switch key {
+	case "up-to-date":
+		c.watchUpdates = true
+		c.met = func(d *client.Device, _ *client.DeviceConfig) bool { return d.UpToDate }
+	case "target":
+		c.watchUpdates = true
+		c.met = func(d *client.Device, _ *client.DeviceConfig) bool { return d.TargetName == val }
+	case "online":
+		c.met = func(d *client.Device, _ *client.DeviceConfig) bool { return d.Online(deviceInactiveHours) }
+	case "status":
+		c.met = func(d *client.Device, _ *client.DeviceConfig) bool {
+			return strings.EqualFold(statusFormatter(d), val)
+		}
+	case "config-applied":
+		c.needsConfig = true
+		c.met = func(_ *client.Device, cfg *client.DeviceConfig) bool { return cfg == nil || len(cfg.AppliedAt) > 0 }
+	default:
+		return nil, fmt.Errorf("Invalid condition: %s", cond)
+	}
+	return c, nil
+}
+
+const (
+	waitPending  = "waiting"
+	waitDone     = "done"
+	waitFailed   = "failed"
+	waitTimedOut = "timed out"
+)
+
+type waitResult struct {
+	Device string `json:"device"`
+	Uuid   string `json:"uuid"`
+	State  string `json:"state"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Tracks a state of a single device between its checks
+type deviceWaiter struct {
+	name  string
+	uuid  string
+	dapi  client.DeviceApi
+	cond  *waitCondition
+	state string
+	err   error
+
+	// Device properties seen by the last check
+	seen [][2]string
+	// A number of events seen for each update
+	seenEvents map[string]int
+}
+
+func doWait(cmd *cobra.Command, args []string) error {
+	condFlag, _ := cmd.Flags().GetString("for")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	cond, err := parseWaitCondition(condFlag)
+	if err != nil {
+		return subcommands.UsageError(err)
+	}
+	if timeout <= 0 {
+		return subcommands.UsageError(fmt.Errorf("Invalid timeout: %s", timeout))
+	}
+	if interval < time.Second {
+		return subcommands.UsageError(fmt.Errorf("Invalid interval: %s. It must be at least 1s", interval))
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var waiters []*deviceWaiter
+	if len(deviceSelector) > 0 {
+		devices, err := selectDevices()
+		if err != nil {
+			return err
+		}
+		if len(devices) == 0 {
+			fmt.Println("No devices match the selector")
+			return nil
+		}
+		for _, d := range devices {
+			waiters = append(waiters, newDeviceWaiter(d.Name, d.Uuid, d.Api, cond))
+		}
+	} else {
+		waiters = append(waiters, newDeviceWaiter(args[0], "", getDeviceApi(cmd, args[0]), cond))
+	}
+	for _, w := range waiters {
+		w.dapi = w.dapi.WithContext(ctx)
+	}
+
+	deadline := time.Now().Add(timeout)
+	progress := ""
+	for {
+		pending := checkDevices(waiters)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(waiters) > 1 && subcommands.IsTableOutput() {
+			if p := waitProgress(waiters); p != progress {
+				progress = p
+				fmt.Printf("%s %s\n", time.Now().Format(time.TimeOnly), progress)
+			}
+		}
+		if pending == 0 || !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(interval, time.Until(deadline))):
+		}
+	}
+
+	timedOut := 0
+	failed := 0
+	for _, w := range waiters {
+		if w.state == waitPending {
+			w.state = waitTimedOut
+			timedOut++
+		} else if w.state == waitFailed {
+			failed++
+		}
+	}
+	if len(waiters) > 1 || !subcommands.IsTableOutput() {
+		if err := waitReport(waiters); err != nil {
+			return err
+		}
+	}
+	if len(deviceSelector) == 0 {
+		w := waiters[0]
+		switch w.state {
+		case waitFailed:
+			return w.err
+		case waitTimedOut:
+			err := fmt.Errorf("Timed out after %s waiting for %s: %s", timeout, w.name, cond.name)
+			if w.err != nil {
+				err = fmt.Errorf("%w (the last check failed: %s)", err, w.err)
+			}
+			return subcommands.WithExitCode(err, subcommands.ExitTimeout)
+		}
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("Failed for %d of %d device(s)", failed, len(waiters))
+	} else if timedOut > 0 {
+		return subcommands.WithExitCode(
+			fmt.Errorf("Timed out after %s: %d of %d device(s) did not reach %s", timeout, timedOut, len(waiters), cond.name),
+			subcommands.ExitTimeout)
+	}
+	return nil
+}
+
+func newDeviceWaiter(name, uuid string, dapi client.DeviceApi, cond *waitCondition) *deviceWaiter {
+	return &deviceWaiter{
+		name:       name,
+		uuid:       uuid,
+		dapi:       dapi,
+		cond:       cond,
+		state:      waitPending,
+		seenEvents: make(map[string]int),
+	}
+}
+
+// Checks all pending devices, at most --concurrency devices at a time, and returns how many are still pending.
+func checkDevices(waiters []*deviceWaiter) int {
+	var mu sync.Mutex
+	pending := 0
+	forEachDevice(waiters, func(_ int, wp **deviceWaiter) {
+		w := *wp
+		if w.state != waitPending {
+			return
+		}
+		var out bytes.Buffer
+		w.check(&out)
+		mu.Lock()
+		defer mu.Unlock()
+		if w.state == waitPending {
+			pending++
+		}
+		if subcommands.IsTableOutput() {
+			fmt.Print(out.String())
+		}
+	})
+	return pending
+}
+
+// Checks a device once, and prints its changes since the previous check into out.
+func (w *deviceWaiter) check(out io.Writer) {
+	logf := func(format string, a ...any) {
+		fmt.Fprintf(out, "%s %s: %s\n", time.Now().Format(time.TimeOnly), w.name, fmt.Sprintf(format, a...))
+	}
+	fail := func(err error) {
+		w.state = waitFailed
+		w.err = err
+		logf("failed: %s", err)
+	}
+	// Only a missing device fails the wait; other errors (e.g. a 502 or a network timeout,
+	// which outlived the retries of a request) may go away by the next check.
+	lookupFailed := func(err error) {
+		if errors.Is(err, client.ErrNotFound) {
+			fail(err)
+		} else if !errors.Is(err, context.Canceled) {
+			w.err = err
+			logf("unable to check, will retry: %s", err)
+		}
+	}
+
+	d, err := w.dapi.Get()
+	if err != nil {
+		lookupFailed(err)
+		return
+	}
+	w.uuid = d.Uuid
+	var cfg *client.DeviceConfig
+	if w.cond.needsConfig {
+		configs, err := w.dapi.ListConfig()
+		if err != nil {
+			lookupFailed(err)
+			return
+		}
+		if len(configs.Configs) > 0 {
+			cfg = &configs.Configs[0]
+		}
+	}
+
+	w.err = nil
+
+	first := w.seen == nil
+	seen := [][2]string{
+		{"status", statusFormatter(d)},
+		{"target", d.TargetName},
+		{"up-to-date", strconv.FormatBool(d.UpToDate)},
+		{"update", d.CurrentUpdate},
+	}
+	if cfg != nil {
+		applied := "pending"
+		if len(cfg.AppliedAt) > 0 {
+			applied = "applied at " + cfg.AppliedAt
+		}
+		seen = append(seen, [2]string{"config", applied})
+	}
+	if first {
+		var props []string
+		for _, prop := range seen {
+			if len(prop[1]) > 0 {
+				props = append(props, prop[0]+"="+prop[1])
+			}
+		}
+		logf("%s", strings.Join(props, " "))
+	} else {
+		for i, prop := range seen {
+			if i < len(w.seen) && w.seen[i][1] != prop[1] {
+				logf("%s %q -> %q", prop[0], w.seen[i][1], prop[1])
+			} else if i >= len(w.seen) {
+				logf("%s %q", prop[0], prop[1])
+			}
+		}
+	}
+	w.seen = seen
+
+	if w.cond.watchUpdates && len(d.CurrentUpdate) > 0 {
+		if err := w.checkUpdate(d.CurrentUpdate, first, logf); err != nil {
+			if w.cond.met(d, cfg) {
+				// The device reached the state anyway, e.g. by a rollback to the awaited Target
+				logrus.Debugf("Ignoring a failed update of %s: %s", w.name, err)
+			} else {
+				fail(err)
+				return
+			}
+		}
+	}
+	if w.cond.met(d, cfg) {
+		w.state = waitDone
+		logf("done (%s)", w.cond.name)
+	}
+}
+
+// Shows new events of an update, and returns an error if the update has failed.
+// Events already present on the first check happened before the wait and are not shown.
+func (w *deviceWaiter) checkUpdate(correlationId string, first bool, logf func(string, ...any)) error {
+	events, err := w.dapi.UpdateEvents(correlationId)
+	if err != nil {
+		// Events of an update may be reported after the device starts it
+		logrus.Debugf("Unable to get events of the update %s of %s: %s", correlationId, w.name, err)
+		return nil
+	}
+	seen := w.seenEvents[correlationId]
+	w.seenEvents[correlationId] = len(events)
+	if first || seen >= len(events) {
+		return nil
+	}
+	for _, event := range events[seen:] {
+		result := ""
+		if event.Detail.Success != nil {
+			result = " -> Succeed"
+			if !*event.Detail.Success {
+				result = " -> Failed!"
+			}
+		}
+		logf("%s(%s)%s", event.Type.Id, event.Detail.TargetName, result)
+		if event.Detail.Success != nil && !*event.Detail.Success {
+			msg := fmt.Sprintf("Update %s failed: %s(%s)", correlationId, event.Type.Id, event.Detail.TargetName)
+			if len(event.Detail.Details) > 0 {
+				msg += "\n" + event.Detail.Details
+			}
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+func waitProgress(waiters []*deviceWaiter) string {
+	counts := make(map[string]int)
+	for _, w := range waiters {
+		counts[w.state]++
+	}
+	return fmt.Sprintf("%d of %d device(s) done, %d failed, %d waiting",
+		counts[waitDone], len(waiters), counts[waitFailed], counts[waitPending])
+}
+
+func waitReport(waiters []*deviceWaiter) error {
+	if subcommands.IsTableOutput() {
+		for _, state := range []string{waitFailed, waitTimedOut} {
+			var names []string
+			for _, w := range waiters {
+				if w.state == state {
+					names = append(names, w.name)
+				}
+			}
+			if len(names) > 0 {
+				fmt.Printf("Devices %s:\n  %s\n", state, strings.Join(names, "\n  "))
+			}
+		}
+		return nil
+	}
+	results := make([]waitResult, len(waiters))
+	out := subcommands.NewOutput(results, "DEVICE", "UUID", "STATE", "ERROR")
+	for i, w := range waiters {
+		results[i] = waitResult{Device: w.name, Uuid: w.uuid, State: w.state}
+		if w.err != nil {
+			results[i].Error = w.err.Error()
+		}
+		out.AddLine(w.name, w.uuid, w.state, results[i].Error)
+	}
+	return out.Print()
+}
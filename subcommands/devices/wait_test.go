@@ -0,0 +1,82 @@
+package devices
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/foundriesio/fioctl/client"
+	"github.com/foundriesio/fioctl/client/fake"
+)
+
+func TestWaitTransientErrors(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddDevice(client.Device{Name: "dev", TargetName: "lmp-1"})
+	cond, err := parseWaitCondition("target=lmp-2")
+	require.Nil(t, err)
+	w := newDeviceWaiter("dev", "", api.DeviceApiByName(testFactory, "dev"), cond)
+
+	// Errors which outlive the retries of a request keep a device pending
+	srv.InjectFault(fake.Fault{Path: "/ota/devices/dev/", Status: 502})
+	w.check(io.Discard)
+	assert.Equal(t, waitPending, w.state)
+	assert.ErrorIs(t, w.err, client.ErrServer)
+
+	srv.ClearFaults()
+	w.check(io.Discard)
+	assert.Equal(t, waitPending, w.state)
+	assert.Nil(t, w.err)
+
+	// A device which does not exist fails the wait
+	w = newDeviceWaiter("missing", "", api.DeviceApiByName(testFactory, "missing"), cond)
+	w.check(io.Discard)
+	assert.Equal(t, waitFailed, w.state)
+	assert.ErrorIs(t, w.err, client.ErrNotFound)
+}
+
+func TestParseWaitCondition(t *testing.T) {
+	now := time.Now().Format(time.RFC3339)
+	updated := &client.Device{TargetName: "lmp-2", UpToDate: true, LastSeen: now}
+	offline := &client.Device{TargetName: "lmp-1", Status: "OK"}
+	pending := &client.DeviceConfig{}
+	applied := &client.DeviceConfig{AppliedAt: now}
+
+	for _, tc := range []struct {
+		cond         string
+		watchUpdates bool
+		needsConfig  bool
+		// Whether the condition is met by the updated and offline devices
+		updated, offline bool
+	}{
+		{"up-to-date", true, false, true, false},
+		{"target=lmp-2", true, false, true, false},
+		{"target=lmp-1", true, false, false, true},
+		{"online", false, false, true, false},
+		{"status=offline", false, false, false, true},
+		{"status=OK", false, false, true, false},
+	} {
+		c, err := parseWaitCondition(tc.cond)
+		require.Nil(t, err, tc.cond)
+		assert.Equal(t, tc.cond, c.name)
+		assert.Equal(t, tc.watchUpdates, c.watchUpdates, tc.cond)
+		assert.Equal(t, tc.needsConfig, c.needsConfig, tc.cond)
+		assert.Equal(t, tc.updated, c.met(updated, nil), tc.cond)
+		assert.Equal(t, tc.offline, c.met(offline, nil), tc.cond)
+	}
+
+	c, err := parseWaitCondition("config-applied")
+	require.Nil(t, err)
+	assert.True(t, c.needsConfig)
+	assert.False(t, c.met(updated, pending))
+	assert.True(t, c.met(updated, applied))
+	// A device without a config has nothing to apply
+	assert.True(t, c.met(updated, nil))
+
+	for _, cond := range []string{"", "target", "target=", "status", "online=true", "up-to-date=1", "rebooted"} {
+		_, err := parseWaitCondition(cond)
+		assert.NotNil(t, err, cond)
+	}
+}
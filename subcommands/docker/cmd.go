@@ -33,7 +33,9 @@ func NewCommand() *cobra.Command {
 		helperPath = filepath.Dir(path)
 	}
 	helperPath = subcommands.FindWritableDirInPath(helperPath)
-	dockerConfigFile = dockerConfigPath()
+	if dockerConfigFile, err = dockerConfigPath(); err != nil {
+		logrus.Debugf("Unable to find a Docker config file: %s", err)
+	}
 	if !subcommands.IsWritable(dockerConfigFile) {
 		dockerConfigFile = ""
 	}
@@ -48,9 +50,12 @@ This command likely needs to be run as root. It creates a symlink,
 docker-credential-fio, in the same directory as the Docker client binary.
 
 NOTE: The credentials will need the "containers:read" scope to work with Docker`,
-		Run: doDockerCreds,
-		PreRun: func(cmd *cobra.Command, args []string) {
-			subcommands.DieNotNil(err, "Docker not found on system")
+		RunE: doDockerCreds,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := exec.LookPath("docker"); err != nil {
+				return fmt.Errorf("Docker not found on system: %w", err)
+			}
+			return nil
 		},
 	}
 	cmd.Flags().StringVarP(&helperPath, "creds-path", "", helperPath, "Path to install credential helper")
@@ -64,33 +69,38 @@ NOTE: The credentials will need the "containers:read" scope to work with Docker`
 	return cmd
 }
 
-func findSelf() string {
+func findSelf() (string, error) {
 	self := os.Args[0]
 	if !filepath.IsAbs(self) {
 		logrus.Debugf("Looking up path to %s", self)
 		var err error
-		self, err = exec.LookPath(self)
-		subcommands.DieNotNil(err)
-		self, err = filepath.Abs(self)
-		subcommands.DieNotNil(err)
+		if self, err = exec.LookPath(self); err != nil {
+			return "", err
+		}
+		if self, err = filepath.Abs(self); err != nil {
+			return "", err
+		}
 	}
-	return filepath.Clean(self)
+	return filepath.Clean(self), nil
 }
 
-func dockerConfigPath() string {
+func dockerConfigPath() (string, error) {
 	sudoer := os.Getenv("SUDO_USER")
 	if len(sudoer) > 0 {
 		u, err := user.Lookup(sudoer)
-		subcommands.DieNotNil(err)
-		return filepath.Join(u.HomeDir, ".docker/config.json")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(u.HomeDir, ".docker/config.json"), nil
 	}
-	path, err := homedir.Expand("~/.docker/config.json")
-	subcommands.DieNotNil(err)
-	return path
+	return homedir.Expand("~/.docker/config.json")
 }
 
-func doDockerCreds(cmd *cobra.Command, args []string) {
-	self := findSelf()
+func doDockerCreds(cmd *cobra.Command, args []string) error {
+	self, err := findSelf()
+	if err != nil {
+		return err
+	}
 
 	var config map[string]interface{}
 	bytes, err := os.ReadFile(dockerConfigFile)
@@ -98,16 +108,20 @@ func doDockerCreds(cmd *cobra.Command, args []string) {
 		dockerConfig := filepath.Dir(dockerConfigFile)
 		if _, err := os.Stat(dockerConfig); errors.Is(err, fs.ErrNotExist) {
 			fmt.Println("Creating Docker config directory:", dockerConfig)
-			subcommands.DieNotNil(os.Mkdir(dockerConfig, 0o755))
+			if err := os.Mkdir(dockerConfig, 0o755); err != nil {
+				return err
+			}
 		}
 		config = make(map[string]interface{})
-	} else {
-		subcommands.DieNotNil(json.Unmarshal(bytes, &config))
+	} else if err := json.Unmarshal(bytes, &config); err != nil {
+		return err
 	}
 
 	apiUrl := viper.GetString("server.url")
 	parts, err := url.Parse(apiUrl)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	hubUrl := strings.Replace(parts.Host, "api.", "hub.", 1)
 
 	helpers, ok := config["credHelpers"]
@@ -120,29 +134,38 @@ func doDockerCreds(cmd *cobra.Command, args []string) {
 	}
 
 	configBytes, err := subcommands.MarshalIndent(config, "", "  ")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if strings.Contains(helperPath, "~/") {
-		subcommands.DieNotNil(fmt.Errorf("~ character is not supported in --creds-path=. Try to run it as --creds-path %s", helperPath))
+		return subcommands.UsageError(fmt.Errorf("~ character is not supported in --creds-path=. Try to run it as --creds-path %s", helperPath))
 	}
 	dst := filepath.Join(helperPath, DOCKER_CREDS_HELPER)
 	if runtime.GOOS == "windows" {
 		dst += ".exe"
 	}
 	fmt.Println("Symlinking", self, "to", dst)
-	subcommands.DieNotNil(os.Symlink(self, dst))
+	if err := os.Symlink(self, dst); err != nil {
+		return err
+	}
 
 	fmt.Println("Adding", hubUrl, "helper to", dockerConfigFile)
-	subcommands.DieNotNil(os.WriteFile(dockerConfigFile, configBytes, 0o600))
+	return os.WriteFile(dockerConfigFile, configBytes, 0o600)
 }
 
-func RunCredsHelper() int {
+func RunCredsHelper() error {
+	if err := subcommands.LoadCredentials(); err != nil {
+		return err
+	}
 	if subcommands.Config.ClientCredentials.ClientSecret == "" {
-		msg := "ERROR: Your fioctl configuration does not appear to include oauth2 credentials. Please run `fioctl login` to configure and then try again.\n"
-		os.Stderr.WriteString(msg)
-		os.Exit(1)
+		return subcommands.AuthError(errors.New(
+			"Your fioctl configuration does not appear to include oauth2 credentials. Please run `fioctl login` to configure and then try again."))
+	}
+	// Ensure a fresh oauth2 access token
+	if _, err := subcommands.Login(NewCommand()); err != nil {
+		return err
 	}
-	subcommands.Login(NewCommand()) // Ensure a fresh oauth2 access token
 	creds := struct {
 		Username string
 		Secret   string
@@ -152,7 +175,9 @@ func RunCredsHelper() int {
 	}
 
 	bytes, err := json.Marshal(creds)
-	subcommands.DieNotNil(err)
-	os.Stdout.Write(bytes)
-	return 0
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(bytes)
+	return err
 }
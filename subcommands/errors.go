@@ -0,0 +1,154 @@
+package subcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+// Exit codes of fioctl, so that scripts and CI pipelines can react to a failure precisely.
+// These are a part of the fioctl interface: do not change existing values, only add new ones.
+const (
+	ExitOk = 0
+	// Any failure not covered by more specific codes below
+	ExitError = 1
+	// Invalid command line arguments or flags
+	ExitUsage = 2
+	// Not logged in, invalid credentials, or not enough permissions
+	ExitAuth = 3
+	// A requested resource (e.g. a device or a Target) does not exist
+	ExitNotFound = 4
+	// A resource is in a conflicting state (e.g. it already exists or is being changed)
+	ExitConflict = 5
+	// A bulk operation failed for some (but not all) of its items
+	ExitPartialFailure = 6
+	// The API server could not be reached, or a request timed out
+	ExitNetwork = 7
+	// A waited for condition was not met in time
+	ExitTimeout = 8
+	// A command was interrupted by Ctrl-C
+	ExitInterrupted = 130
+)
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// WithExitCode makes fioctl exit with a given code when a command fails with this error.
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// UsageError marks an error caused by invalid command line arguments or flags.
+func UsageError(err error) error {
+	return WithExitCode(err, ExitUsage)
+}
+
+// ErrInvalidConfig is returned for invalid settings of the config file (e.g. an unreadable CA bundle).
+var ErrInvalidConfig = errors.New("Invalid configuration")
+
+// ConfigError marks an error caused by invalid settings of the config file.
+// It exits with the same code as a usage error.
+func ConfigError(err error) error {
+	return UsageError(fmt.Errorf("%w: %w", ErrInvalidConfig, err))
+}
+
+// AuthError marks an error caused by missing or invalid credentials.
+func AuthError(err error) error {
+	return WithExitCode(err, ExitAuth)
+}
+
+// ErrSilentExit is returned by commands which have already reported their failure themselves.
+var ErrSilentExit = errors.New("Exit silently")
+
+// SilentExit makes fioctl exit with a given code without printing an error message,
+// e.g. when an external program run by fioctl fails.
+func SilentExit(code int) error {
+	return WithExitCode(ErrSilentExit, code)
+}
+
+// PartialFailureError is returned by bulk operations which failed for some of their items.
+func PartialFailureError(failed, total int) error {
+	return WithExitCode(fmt.Errorf("Operation failed for %d of %d items", failed, total), ExitPartialFailure)
+}
+
+// FailureStatus returns a word to print after a step of a command which returned an error,
+// e.g. after "Posting new Targets...". A dry run stops a command at its first change, which is not a failure.
+func FailureStatus(err error) string {
+	if errors.Is(err, client.ErrDryRun) {
+		return "dry run"
+	}
+	return "failed"
+}
+
+// PrintStepStatus prints an outcome of one of several independent steps of a command, e.g. after
+// "Deleting <device> .. ", and returns an error of a failed step.
+// A request previewed by a dry run is printed instead, and is not an error, so that a command goes on
+// to preview its next steps.
+func PrintStepStatus(err error) error {
+	switch {
+	case err == nil:
+		fmt.Println("ok")
+	case errors.Is(err, client.ErrDryRun):
+		fmt.Println("dry run")
+		fmt.Println(err)
+	default:
+		fmt.Println("failed")
+		return err
+	}
+	return nil
+}
+
+// ExitCode returns an exit code for an error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOk
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	switch {
+	case errors.Is(err, client.ErrUnauthorized), errors.Is(err, client.ErrForbidden):
+		return ExitAuth
+	case errors.Is(err, client.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, client.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitNetwork
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return ExitNetwork
+	}
+	return ExitError
+}
+
+// PrintError writes an error returned by a command to the stderr.
+func PrintError(err error) {
+	if errors.Is(err, ErrSilentExit) {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "ERROR:", err)
+}
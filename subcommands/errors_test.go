@@ -0,0 +1,39 @@
+package subcommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/foundriesio/fioctl/client"
+)
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOk},
+		{errors.New("boom"), ExitError},
+		{UsageError(errors.New("bad flag")), ExitUsage},
+		{fmt.Errorf("wrapped: %w", UsageError(errors.New("bad flag"))), ExitUsage},
+		{ConfigError(errors.New("bad CA bundle")), ExitUsage},
+		{AuthError(errors.New("no credentials")), ExitAuth},
+		{fmt.Errorf("get device: %w", client.ErrUnauthorized), ExitAuth},
+		{fmt.Errorf("get device: %w", client.ErrForbidden), ExitAuth},
+		{fmt.Errorf("get device: %w", client.ErrNotFound), ExitNotFound},
+		{fmt.Errorf("create wave: %w", client.ErrConflict), ExitConflict},
+		{PartialFailureError(2, 5), ExitPartialFailure},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, ExitNetwork},
+		{context.DeadlineExceeded, ExitNetwork},
+		{context.Canceled, ExitInterrupted},
+		{WithExitCode(client.ErrNotFound, ExitError), ExitError},
+	} {
+		assert.Equal(t, tc.code, ExitCode(tc.err), "%v", tc.err)
+	}
+	assert.Nil(t, UsageError(nil))
+}
@@ -14,8 +14,9 @@ var (
 var cmd = &cobra.Command{
 	Use:   "event-queues",
 	Short: "Manage event queues configured for a Factory",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 	Long: `Event queues provide a way to receive notifications about events
 happening in a Factory, such as when a device is first seen or an
@@ -4,7 +4,6 @@ import (
 	"os"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -16,7 +15,7 @@ func init() {
 		Aliases: []string{"mk-push"},
 		Short:   "Create an event queue that will ingest events at the URL",
 		Args:    cobra.ExactArgs(2),
-		Run:     doCreatePush,
+		RunE:    doCreatePush,
 	})
 
 	cmd.AddCommand(&cobra.Command{
@@ -24,7 +23,7 @@ func init() {
 		Short:      "Create a message queue that can be polled for events",
 		Deprecated: "and will be removed in a future release.",
 		Args:       cobra.ExactArgs(2),
-		Run:        doCreatePull,
+		RunE:       doCreatePull,
 		Long: `Create a message queue that can be polled for events via the Google PubSub API:
 
   https://cloud.google.com/pubsub/docs/reference/libraries 
@@ -34,7 +33,7 @@ polling the resulting PubSub subscription.`,
 	})
 }
 
-func doCreatePush(cmd *cobra.Command, args []string) {
+func doCreatePush(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Create a push queue for: %s", factory)
 
@@ -45,10 +44,10 @@ func doCreatePush(cmd *cobra.Command, args []string) {
 	}
 
 	_, err := api.EventQueuesCreate(factory, queue)
-	subcommands.DieNotNil(err)
+	return err
 }
 
-func doCreatePull(cmd *cobra.Command, args []string) {
+func doCreatePull(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Create a pull queue for: %s", factory)
 
@@ -58,7 +57,8 @@ func doCreatePull(cmd *cobra.Command, args []string) {
 	}
 
 	creds, err := api.EventQueuesCreate(factory, queue)
-	subcommands.DieNotNil(err)
-	err = os.WriteFile(args[1], creds, 0o700)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(args[1], creds, 0o700)
 }
@@ -1,7 +1,6 @@
 package events
 
 import (
-	"github.com/cheynewallace/tabby"
 	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,21 +12,22 @@ func init() {
 		Use:     "list",
 		Aliases: []string{"ls"},
 		Short:   "List configured event queues",
-		Run:     doList,
+		RunE:    doList,
 	})
 }
 
-func doList(cmd *cobra.Command, args []string) {
+func doList(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Listing event queues for: %s", factory)
 
 	queues, err := api.EventQueuesList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	t := tabby.New()
-	t.AddHeader("LABEL", "TYPE", "PUSH URL")
+	out := subcommands.NewOutput(queues, "LABEL", "TYPE", "PUSH URL")
 	for _, queue := range queues {
-		t.AddLine(queue.Label, queue.Type, queue.PushUrl)
+		out.AddLine(queue.Label, queue.Type, queue.PushUrl)
 	}
-	t.Print()
+	return out.Print()
 }
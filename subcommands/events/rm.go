@@ -1,25 +1,29 @@
 package events
 
 import (
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	cmd.AddCommand(&cobra.Command{
-		Use:   "rm <label>",
-		Short: "Remove an event queue",
-		Args:  cobra.ExactArgs(1),
-		Run:   doRemove,
+		Use:               "rm <label>",
+		Short:             "Remove an event queue",
+		Args:              cobra.ExactArgs(1),
+		RunE:              doRemove,
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteEventQueues),
 	})
 }
 
-func doRemove(cmd *cobra.Command, args []string) {
+func doRemove(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Removing event queue for: %s", factory)
+	if err := subcommands.Confirm("Remove an event queue", []string{args[0]}); err != nil {
+		return err
+	}
 
-	err := api.EventQueuesDelete(factory, args[0])
-	subcommands.DieNotNil(err)
+	return api.EventQueuesDelete(factory, args[0])
 }
@@ -1,7 +1,6 @@
 package factories
 
 import (
-	"github.com/cheynewallace/tabby"
 	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/spf13/cobra"
@@ -17,22 +16,24 @@ func NewCommand() *cobra.Command {
 		Use:    "factories",
 		Short:  "List Factories a user is a member of.",
 		Hidden: true, // Only useful support work
-		Run:    doFactories,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			api = subcommands.Login(cmd)
+		RunE:   doFactories,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			api, err = subcommands.Login(cmd)
+			return
 		},
 	}
 	cmd.Flags().BoolVarP(&admin, "admin", "", false, "Show all factories")
 	return cmd
 }
 
-func doFactories(cmd *cobra.Command, args []string) {
+func doFactories(cmd *cobra.Command, args []string) error {
 	factories, err := api.FactoriesList(admin)
-	subcommands.DieNotNil(err)
-	t := tabby.New()
-	t.AddHeader("NAME", "ID")
+	if err != nil {
+		return err
+	}
+	out := subcommands.NewOutput(factories, "NAME", "ID")
 	for _, f := range factories {
-		t.AddLine(f.Name, f.Id)
+		out.AddLine(f.Name, f.Id)
 	}
-	t.Print()
+	return out.Print()
 }
@@ -2,6 +2,7 @@ package git
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -29,10 +30,12 @@ This command likely needs to be run as root. It creates a symlink,
 git-credential-fio, in the same directory as the git client binary.
 
 NOTE: The credentials will need the "source:read-update" scope to work with Git`,
-		Run: doGitCreds,
-		PreRun: func(cmd *cobra.Command, args []string) {
-			_, err := exec.LookPath("git")
-			subcommands.DieNotNil(err, "Git not found on system")
+		RunE: doGitCreds,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := exec.LookPath("git"); err != nil {
+				return fmt.Errorf("Git not found on system: %w", err)
+			}
+			return nil
 		},
 	}
 	return cmd
@@ -43,42 +46,51 @@ func NewGetCredsCommand() *cobra.Command {
 		Use:    "git-credential-helper",
 		Hidden: true, // its used as a git-credential helper and is not user facing
 		Args:   cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if args[0] != "get" {
-				subcommands.DieNotNil(fmt.Errorf("This credential helper only supports 'get' and not '%s'", args[0]))
+				return subcommands.UsageError(fmt.Errorf("This credential helper only supports 'get' and not '%s'", args[0]))
 			}
-			os.Exit(RunCredsHelper())
+			return RunCredsHelper()
 		},
 	}
 	return cmd
 }
 
-func findSelf() string {
+func findSelf() (string, error) {
 	self := os.Args[0]
 	if !filepath.IsAbs(self) {
 		logrus.Debugf("Looking up path to %s", self)
 		var err error
-		self, err = exec.LookPath(self)
-		subcommands.DieNotNil(err)
-		self, err = filepath.Abs(self)
-		subcommands.DieNotNil(err)
+		if self, err = exec.LookPath(self); err != nil {
+			return "", err
+		}
+		if self, err = filepath.Abs(self); err != nil {
+			return "", err
+		}
 	}
-	return filepath.Clean(self)
+	return filepath.Clean(self), nil
 }
 
-func doGitCreds(cmd *cobra.Command, args []string) {
-	self := findSelf()
+func doGitCreds(cmd *cobra.Command, args []string) error {
+	self, err := findSelf()
+	if err != nil {
+		return err
+	}
 
 	apiUrl := viper.GetString("server.url")
 	if len(apiUrl) == 0 {
 		apiUrl = "https://api.foundries.io"
 	}
 	parts, err := url.Parse(apiUrl)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	sourceUrl := strings.Replace(parts.Host, "api.", "source.", 1)
 
 	cfgFile, err := filepath.Abs(viper.GetViper().ConfigFileUsed())
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if runtime.GOOS == "windows" {
 		// To get around edge cases with git on Windows we use the absolute path
@@ -96,31 +108,38 @@ func doGitCreds(cmd *cobra.Command, args []string) {
 	if len(out) > 0 {
 		fmt.Printf("%s\n", string(out))
 	}
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	c = exec.Command("git", gitHelperCommandArgs...)
 	out, err = c.CombinedOutput()
 	if len(out) > 0 {
 		fmt.Printf("%s\n", string(out))
 	}
-	subcommands.DieNotNil(err)
+	return err
 }
 
-func RunCredsHelper() int {
+func RunCredsHelper() error {
+	if err := subcommands.LoadCredentials(); err != nil {
+		return err
+	}
 	if subcommands.Config.ClientCredentials.ClientSecret == "" {
-		msg := "ERROR: Your fioctl configuration does not appear to include oauth2 credentials. Please run `fioctl login` to configure and then try again.\n"
-		os.Stderr.WriteString(msg)
-		os.Exit(1)
+		return subcommands.AuthError(errors.New(
+			"Your fioctl configuration does not appear to include oauth2 credentials. Please run `fioctl login` to configure and then try again."))
+	}
+	// Ensure a fresh oauth2 access token
+	if _, err := subcommands.Login(NewCommand()); err != nil {
+		return err
 	}
-	subcommands.Login(NewCommand()) // Ensure a fresh oauth2 access tokenA
 	var input string
 	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
 		input += scanner.Text() + "\n"
 	}
 	if err := scanner.Err(); err != nil {
-		subcommands.DieNotNil(err)
+		return err
 	}
 	input += fmt.Sprintf("password=%s\n", subcommands.Config.ClientCredentials.AccessToken)
-	os.Stdout.WriteString(input)
-	return 0
+	_, err := os.Stdout.WriteString(input)
+	return err
 }
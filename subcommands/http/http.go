@@ -21,41 +21,44 @@ func NewCommand() *cobra.Command {
 		Use:    "http",
 		Short:  "Run a direct authenticated HTTP command to the Foundries.io API",
 		Hidden: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			api = subcommands.Login(cmd)
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			if api, err = subcommands.Login(cmd); err != nil {
+				return
+			}
 			follow, _ := cmd.Flags().GetBool("follow-redirects")
 			if !follow {
 				api.GetHttpClient().CheckRedirect = func(req *http.Request, via []*http.Request) error {
 					return http.ErrUseLastResponse
 				}
 			}
+			return
 		},
 	}
 	httpCmd.PersistentFlags().StringP("token", "t", "", "API token from https://app.foundries.io/settings/tokens/")
 
 	getCmd := &cobra.Command{
-		Use: "get https://api.foundries.io/ota.... [header=val..]",
-		Run: doGet,
+		Use:  "get https://api.foundries.io/ota.... [header=val..]",
+		RunE: doGet,
 	}
 
 	postCmd := &cobra.Command{
-		Use: "post https://api.foundries.io/ota.... [header=val..]",
-		Run: doPost,
+		Use:  "post https://api.foundries.io/ota.... [header=val..]",
+		RunE: doPost,
 	}
 
 	putCmd := &cobra.Command{
-		Use: "put https://api.foundries.io/ota.... [header=val..]",
-		Run: doPut,
+		Use:  "put https://api.foundries.io/ota.... [header=val..]",
+		RunE: doPut,
 	}
 
 	patchCmd := &cobra.Command{
-		Use: "patch https://api.foundries.io/ota.... [header=val..]",
-		Run: doPatch,
+		Use:  "patch https://api.foundries.io/ota.... [header=val..]",
+		RunE: doPatch,
 	}
 
 	deleteCmd := &cobra.Command{
-		Use: "delete https://api.foundries.io/ota.... [header=val..]",
-		Run: doDelete,
+		Use:  "delete https://api.foundries.io/ota.... [header=val..]",
+		RunE: doDelete,
 	}
 
 	for _, cmd := range []*cobra.Command{getCmd, postCmd, putCmd, patchCmd, deleteCmd} {
@@ -81,24 +84,40 @@ echo '{"key": "value"}' | fioctl %s -d - https://...
 	return httpCmd
 }
 
-func doGet(cmd *cobra.Command, args []string) {
-	printResponse(api.RawGet(args[0], readHeaders(args[1:])))
+func doGet(cmd *cobra.Command, args []string) error {
+	return printResponse(api.RawGet(args[0], readHeaders(args[1:])))
 }
 
-func doPost(cmd *cobra.Command, args []string) {
-	printResponse(api.RawPost(args[0], readData(cmd), readHeaders(args[1:])))
+func doPost(cmd *cobra.Command, args []string) error {
+	data, err := readData(cmd)
+	if err != nil {
+		return err
+	}
+	return printResponse(api.RawPost(args[0], data, readHeaders(args[1:])))
 }
 
-func doPut(cmd *cobra.Command, args []string) {
-	printResponse(api.RawPut(args[0], readData(cmd), readHeaders(args[1:])))
+func doPut(cmd *cobra.Command, args []string) error {
+	data, err := readData(cmd)
+	if err != nil {
+		return err
+	}
+	return printResponse(api.RawPut(args[0], data, readHeaders(args[1:])))
 }
 
-func doPatch(cmd *cobra.Command, args []string) {
-	printResponse(api.RawPatch(args[0], readData(cmd), readHeaders(args[1:])))
+func doPatch(cmd *cobra.Command, args []string) error {
+	data, err := readData(cmd)
+	if err != nil {
+		return err
+	}
+	return printResponse(api.RawPatch(args[0], data, readHeaders(args[1:])))
 }
 
-func doDelete(cmd *cobra.Command, args []string) {
-	printResponse(api.RawDelete(args[0], readData(cmd), readHeaders(args[1:])))
+func doDelete(cmd *cobra.Command, args []string) error {
+	data, err := readData(cmd)
+	if err != nil {
+		return err
+	}
+	return printResponse(api.RawDelete(args[0], data, readHeaders(args[1:])))
 }
 
 func readHeaders(args []string) *map[string]string {
@@ -117,30 +136,29 @@ func readHeaders(args []string) *map[string]string {
 	return &headers
 }
 
-func readData(cmd *cobra.Command) []byte {
+func readData(cmd *cobra.Command) ([]byte, error) {
 	data, _ := cmd.Flags().GetString("data")
 	if data == "-" {
 		logrus.Debug("Reading post data from stdin")
-		dataBytes, err := io.ReadAll(os.Stdin)
-		subcommands.DieNotNil(err)
-		return dataBytes
+		return io.ReadAll(os.Stdin)
 	} else if len(data) > 0 && data[0] == '@' {
 		dataFile := data[1:]
 		logrus.Debugf("Reading post data from %s", dataFile)
-		dataBytes, err := os.ReadFile(dataFile)
-		subcommands.DieNotNil(err)
-		return dataBytes
+		return os.ReadFile(dataFile)
 	} else {
-		return []byte(data)
+		return []byte(data), nil
 	}
 }
 
-func printResponse(resp *http.Response, err error) {
-	subcommands.DieNotNil(err)
+func printResponse(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 	fmt.Fprintf(os.Stderr, "< Status: %s\n", resp.Status)
 	for k, v := range resp.Header {
 		fmt.Fprintf(os.Stderr, "< %s: %s\n", k, v)
 	}
 	_, err = io.Copy(os.Stdout, resp.Body)
-	subcommands.DieNotNil(err)
+	return err
 }
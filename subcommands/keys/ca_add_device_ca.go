@@ -10,7 +10,6 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/foundriesio/fioctl/x509"
 )
 
@@ -18,7 +17,7 @@ func init() {
 	cmd := &cobra.Command{
 		Use:   "add-device-ca <PKI Directory>",
 		Short: "Add device CA to the list of CAs allowed to issue device client certificates",
-		Run:   doAddDeviceCa,
+		RunE:  doAddDeviceCa,
 		Args:  cobra.ExactArgs(1),
 		Long: `Add device CA to the list of CAs allowed to issue device client certificates.
 
@@ -50,70 +49,94 @@ All such CAs will be added to the list of device CAs trusted by the device gatew
 	cmd.Flags().StringVarP(&hsmTokenLabel, "hsm-token-label", "", "", "The label of the HSM token containing the root CA key")
 }
 
-func assertFileName(flagName, value string) {
+func assertFileName(flagName, value string) error {
 	if len(value) > 0 {
 		if strings.ContainsRune(value, os.PathSeparator) {
-			subcommands.DieNotNil(fmt.Errorf("The `%s` argument must be filename and not a path: %s", flagName, value))
+			return fmt.Errorf("The `%s` argument must be filename and not a path: %s", flagName, value)
 		}
 	}
+	return nil
 }
 
-func doAddDeviceCa(cmd *cobra.Command, args []string) {
+func doAddDeviceCa(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	createLocalCA, _ := cmd.Flags().GetBool("local-ca")
 	createOnlineCA, _ := cmd.Flags().GetBool("online-ca")
 	localCaFilename, _ := cmd.Flags().GetString("local-ca-filename")
 
 	if !createLocalCA && !createOnlineCA {
-		subcommands.DieNotNil(errors.New("At least one of --online-ca or --local-ca must be true"))
+		return errors.New("At least one of --online-ca or --local-ca must be true")
 	}
 
-	assertFileName("--local-ca-filename", localCaFilename)
+	if err := assertFileName("--local-ca-filename", localCaFilename); err != nil {
+		return err
+	}
 
-	subcommands.DieNotNil(os.Chdir(args[0]))
+	if err := os.Chdir(args[0]); err != nil {
+		return err
+	}
 	hsm, err := x509.ValidateHsmArgs(
 		hsmModule, hsmPin, hsmTokenLabel, "--hsm-module", "--hsm-pin", "--hsm-token-label")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	x509.InitHsm(hsm)
 
 	fmt.Println("Fetching a list of existing device CAs")
 	resp, err := api.FactoryGetCA(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	certs := client.CaCerts{CaCrt: resp.CaCrt}
 	if len(certs.CaCrt) == 0 {
-		subcommands.DieNotNil(errors.New("Factory PKI not initialized. Set it up using 'fioctl keys ca create'."))
+		return errors.New("Factory PKI not initialized. Set it up using 'fioctl keys ca create'.")
 	}
 
 	if createLocalCA {
 		localCaKeyFilename := strings.TrimSuffix(localCaFilename, ".pem") + ".key"
 		if _, err := os.Stat(localCaFilename); !os.IsNotExist(err) {
-			subcommands.DieNotNil(fmt.Errorf(`A local device CA file %s already exists.
-Please specify a different name with --local-ca-filename.`, localCaFilename))
+			return fmt.Errorf(`A local device CA file %s already exists.
+Please specify a different name with --local-ca-filename.`, localCaFilename)
 		}
 		if _, err := os.Stat(localCaKeyFilename); !os.IsNotExist(err) {
-			subcommands.DieNotNil(fmt.Errorf(`A local device CA key file %s already exists.
-Please specify a different name with --local-ca-filename.`, localCaKeyFilename))
+			return fmt.Errorf(`A local device CA key file %s already exists.
+Please specify a different name with --local-ca-filename.`, localCaKeyFilename)
 		}
 
 		fmt.Println("Creating local device CA")
-		commonName := getDeviceCaCommonName(factory)
-		certs.CaCrt += "\n" + x509.CreateDeviceCaExt(commonName, factory, localCaKeyFilename, localCaFilename)
+		commonName, err := getDeviceCaCommonName(factory)
+		if err != nil {
+			return err
+		}
+		crtPem, err := x509.CreateDeviceCaExt(commonName, factory, localCaKeyFilename, localCaFilename)
+		if err != nil {
+			return err
+		}
+		certs.CaCrt += "\n" + crtPem
 	}
 
 	if createOnlineCA {
 		fmt.Println("Requesting new Foundries.io Online Device CA CSR")
 		csrs, err := api.FactoryCreateCA(factory, client.CaCreateOptions{CreateOnlineCa: true})
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 
 		if _, err := os.Stat(x509.OnlineCaCertFile); !os.IsNotExist(err) {
 			fmt.Printf("Moving existing online device CA file from %s to %s.bak", x509.OnlineCaCertFile, x509.OnlineCaCertFile)
-			subcommands.DieNotNil(os.Rename(x509.OnlineCaCertFile, x509.OnlineCaCertFile+".bak"))
+			if err := os.Rename(x509.OnlineCaCertFile, x509.OnlineCaCertFile+".bak"); err != nil {
+				return err
+			}
 		}
 
 		fmt.Println("Signing Foundries.io CSR for online use")
-		certs.CaCrt += "\n" + x509.SignCaCsr(csrs.CaCsr)
+		crtPem, err := x509.SignCaCsr(csrs.CaCsr)
+		if err != nil {
+			return err
+		}
+		certs.CaCrt += "\n" + crtPem
 	}
 
 	fmt.Println("Uploading signed certs to Foundries.io")
-	subcommands.DieNotNil(api.FactoryPatchCA(factory, certs))
+	return api.FactoryPatchCA(factory, certs)
 }
@@ -9,7 +9,6 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/foundriesio/fioctl/x509"
 )
 
@@ -25,7 +24,7 @@ func init() {
 	cmd := &cobra.Command{
 		Use:   "create <PKI Directory>",
 		Short: "Create PKI infrastructure to manage mutual TLS for the device gateway",
-		Run:   doCreateCA,
+		RunE:  doCreateCA,
 		Args:  cobra.ExactArgs(1),
 		Long: `Perform a one-time operation to set up PKI infrastructure for managing
 the device gateway. Caution: this can only be done once.
@@ -65,20 +64,26 @@ This is optional.`,
 	cmd.Flags().StringVarP(&hsmTokenLabel, "hsm-token-label", "", "", "The label of the HSM token created for the root CA key")
 }
 
-func getDeviceCaCommonName(factory string) string {
+func getDeviceCaCommonName(factory string) (string, error) {
 	user, err := api.UserAccessDetails(factory, "self")
-	subcommands.DieNotNil(err)
-	return "fio-" + user.PolisId
+	if err != nil {
+		return "", err
+	}
+	return "fio-" + user.PolisId, nil
 }
 
-func doCreateCA(cmd *cobra.Command, args []string) {
+func doCreateCA(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	certsDir := args[0]
 
-	subcommands.DieNotNil(os.Chdir(certsDir))
+	if err := os.Chdir(certsDir); err != nil {
+		return err
+	}
 	hsm, err := x509.ValidateHsmArgs(
 		hsmModule, hsmPin, hsmTokenLabel, "--hsm-module", "--hsm-pin", "--hsm-token-label")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	x509.InitHsm(hsm)
 
 	logrus.Debugf("Create CA for %s under %s", factory, certsDir)
@@ -88,18 +93,26 @@ func doCreateCA(cmd *cobra.Command, args []string) {
 		CreateTlsCert:  true,
 	}
 	csrs, err := api.FactoryCreateCA(factory, opts)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	var certs client.CaCerts
 	fmt.Println("Creating offline root CA for Factory")
-	certs.RootCrt = x509.CreateFactoryCa(factory)
+	if certs.RootCrt, err = x509.CreateFactoryCa(factory); err != nil {
+		return err
+	}
 
 	fmt.Println("Signing Foundries.io TLS CSR")
-	certs.TlsCrt = x509.SignTlsCsr(csrs.TlsCsr)
+	if certs.TlsCrt, err = x509.SignTlsCsr(csrs.TlsCsr); err != nil {
+		return err
+	}
 
 	if createOnlineCA {
 		fmt.Println("Signing Foundries.io CSR for online use")
-		certs.CaCrt = x509.SignCaCsr(csrs.CaCsr)
+		if certs.CaCrt, err = x509.SignCaCsr(csrs.CaCsr); err != nil {
+			return err
+		}
 	}
 
 	if createLocalCA {
@@ -107,10 +120,17 @@ func doCreateCA(cmd *cobra.Command, args []string) {
 		if len(certs.CaCrt) > 0 {
 			certs.CaCrt += "\n"
 		}
-		commonName := getDeviceCaCommonName(factory)
-		certs.CaCrt += x509.CreateDeviceCa(commonName, factory)
+		commonName, err := getDeviceCaCommonName(factory)
+		if err != nil {
+			return err
+		}
+		crtPem, err := x509.CreateDeviceCa(commonName, factory)
+		if err != nil {
+			return err
+		}
+		certs.CaCrt += crtPem
 	}
 
 	fmt.Println("Uploading signed certs to Foundries.io")
-	subcommands.DieNotNil(api.FactoryPatchCA(factory, certs))
+	return api.FactoryPatchCA(factory, certs)
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,7 +29,7 @@ func init() {
 	revokeCmd := &cobra.Command{
 		Use:   "revoke-device-ca <PKI Directory>",
 		Short: "Revoke device CA, so that devices with client certificates it issued can no longer connect to your Factory",
-		Run:   doRevokeDeviceCa,
+		RunE:  doRevokeDeviceCa,
 		Args:  cobra.ExactArgs(1),
 		Long: `Revoke device CA, so that devices with client certificates it issued can no longer connect to your Factory.
 
@@ -62,7 +63,7 @@ fioctl keys ca revoke-device-ca /path/to/pki/dir --ca-file local-ca --dry-run --
 	disableCmd := &cobra.Command{
 		Use:   "disable-device-ca <PKI Directory>",
 		Short: "Disable device CA, so that new devices with client certificates it issued can no longer be registered",
-		Run:   doRevokeDeviceCa,
+		RunE:  doRevokeDeviceCa,
 		Args:  cobra.ExactArgs(1),
 		Long: `Disable device CA, so that new devices with client certificates it issued can no longer be registered.
 
@@ -106,7 +107,7 @@ func addRevokeCmdFlags(cmd *cobra.Command, op string) {
 	cmd.Flags().StringVarP(&hsmTokenLabel, "hsm-token-label", "", "", "The label of the HSM token containing the root CA key")
 }
 
-func doRevokeDeviceCa(cmd *cobra.Command, args []string) {
+func doRevokeDeviceCa(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	pretty, _ := cmd.Flags().GetBool("pretty")
@@ -118,16 +119,22 @@ func doRevokeDeviceCa(cmd *cobra.Command, args []string) {
 	}[cmd.Annotations[crlCmdAnnotation]]
 
 	if len(caFiles)+len(caSerials) == 0 {
-		subcommands.DieNotNil(errors.New("At least one of --ca-file or --ca-serial must be provided"))
+		return errors.New("At least one of --ca-file or --ca-serial must be provided")
 	}
 	for _, v := range caFiles {
-		assertFileName("--ca-file", v)
+		if err := assertFileName("--ca-file", v); err != nil {
+			return err
+		}
 	}
 
-	subcommands.DieNotNil(os.Chdir(args[0]))
+	if err := os.Chdir(args[0]); err != nil {
+		return err
+	}
 	hsm, err := x509.ValidateHsmArgs(
 		hsmModule, hsmPin, hsmTokenLabel, "--hsm-module", "--hsm-pin", "--hsm-token-label")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	x509.InitHsm(hsm)
 
 	fmt.Println("Generating Certificate Revocation List")
@@ -135,50 +142,79 @@ func doRevokeDeviceCa(cmd *cobra.Command, args []string) {
 	for _, serial := range caSerials {
 		num := new(big.Int)
 		if _, ok := num.SetString(serial, 10); !ok {
-			subcommands.DieNotNil(fmt.Errorf("Value %s is not a valid base 10 serial", serial))
+			return fmt.Errorf("Value %s is not a valid base 10 serial", serial)
 		}
 		toRevoke[serial] = crlReason
 	}
 	for _, filename := range caFiles {
-		ca := x509.LoadCertFromFile(filename)
+		ca, err := x509.LoadCertFromFile(filename)
+		if err != nil {
+			return err
+		}
 		toRevoke[ca.SerialNumber.Text(10)] = crlReason
 	}
 
 	caList, err := api.FactoryGetCA(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	validSerials := make(map[string]bool, 0)
 	for _, c := range parseCertList(caList.CaCrt) {
 		validSerials[c.SerialNumber.Text(10)] = true
 	}
 	for serial := range toRevoke {
 		if _, ok := validSerials[serial]; !ok {
-			subcommands.DieNotNil(fmt.Errorf("There is no active device CA with serial %s", serial))
+			return fmt.Errorf("There is no active device CA with serial %s", serial)
 		}
 	}
 
 	fmt.Println("Signing CRL by Factory root CA")
-	certs := client.CaCerts{CaRevokeCrl: x509.CreateCrl(toRevoke)}
+	crl, err := x509.CreateCrl(toRevoke)
+	if err != nil {
+		return err
+	}
+	certs := client.CaCerts{CaRevokeCrl: crl}
 
 	if dryRun {
 		fmt.Println(certs.CaRevokeCrl)
 		if pretty {
-			prettyPrintCrl(certs.CaRevokeCrl)
+			if err := prettyPrintCrl(certs.CaRevokeCrl); err != nil {
+				return err
+			}
 		}
-		return
+		return nil
+	}
+
+	serials := make([]string, 0, len(toRevoke))
+	for serial := range toRevoke {
+		serials = append(serials, serial)
+	}
+	sort.Strings(serials)
+	if crlReason == x509.CrlCaRevoke {
+		// Devices with certificates issued by these CAs are cut off from the factory
+		action := fmt.Sprintf("Revoke %d device CA(s) of the %s factory with serials", len(serials), factory)
+		err = subcommands.ConfirmFactory(factory, action, serials)
+	} else {
+		action := fmt.Sprintf("Disable %d device CA(s) of the %s factory with serials", len(serials), factory)
+		err = subcommands.Confirm(action, serials)
+	}
+	if err != nil {
+		return err
 	}
 
 	fmt.Println("Uploading CRL to Foundries.io")
-	subcommands.DieNotNil(api.FactoryPatchCA(factory, certs))
+	return api.FactoryPatchCA(factory, certs)
 }
 
-func prettyPrintCrl(crlPem string) {
+func prettyPrintCrl(crlPem string) error {
 	block, remaining := pem.Decode([]byte(crlPem))
 	if block == nil || len(remaining) > 0 {
-		subcommands.DieNotNil(errors.New("Invalid PEM block"), "Failed to parse generated CRL:")
-		return // linter
+		return errors.New("Failed to parse generated CRL: Invalid PEM block")
 	}
 	c, err := x509Lib.ParseRevocationList(block.Bytes)
-	subcommands.DieNotNil(err, "Failed to parse generated CRL:")
+	if err != nil {
+		return fmt.Errorf("Failed to parse generated CRL: %w", err)
+	}
 	fmt.Println("Certificate Revocation List:")
 	fmt.Println("\tIssuer:", c.Issuer)
 	fmt.Println("\tValidity:")
@@ -235,4 +271,5 @@ func prettyPrintCrl(crlPem string) {
 			}
 		}
 	}
+	return nil
 }
@@ -8,7 +8,6 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/foundriesio/fioctl/x509"
 )
 
@@ -16,7 +15,7 @@ func init() {
 	cmd := &cobra.Command{
 		Use:   "rotate-tls <PKI Directory>",
 		Short: "Rotate the TLS certificate used by Device Gateway and OSTree Server",
-		Run:   doRotateTls,
+		RunE:  doRotateTls,
 		Args:  cobra.ExactArgs(1),
 	}
 	caCmd.AddCommand(cmd)
@@ -26,26 +25,38 @@ func init() {
 	cmd.Flags().StringVarP(&hsmTokenLabel, "hsm-token-label", "", "", "The label of the HSM token containing the root CA key")
 }
 
-func doRotateTls(cmd *cobra.Command, args []string) {
+func doRotateTls(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 
-	subcommands.DieNotNil(os.Chdir(args[0]))
+	if err := os.Chdir(args[0]); err != nil {
+		return err
+	}
 	hsm, err := x509.ValidateHsmArgs(
 		hsmModule, hsmPin, hsmTokenLabel, "--hsm-module", "--hsm-pin", "--hsm-token-label")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	x509.InitHsm(hsm)
 
 	fmt.Println("Requesting new Foundries.io TLS CSR")
 	csrs, err := api.FactoryCreateCA(factory, client.CaCreateOptions{CreateTlsCert: true})
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if _, err := os.Stat(x509.TlsCertFile); !os.IsNotExist(err) {
 		fmt.Printf("Moving existing TLS cert file from %s to %s.bak", x509.TlsCertFile, x509.TlsCertFile)
-		subcommands.DieNotNil(os.Rename(x509.TlsCertFile, x509.TlsCertFile+".bak"))
+		if err := os.Rename(x509.TlsCertFile, x509.TlsCertFile+".bak"); err != nil {
+			return err
+		}
 	}
 	fmt.Println("Signing Foundries.io TLS CSR")
-	certs := client.CaCerts{TlsCrt: x509.SignTlsCsr(csrs.TlsCsr)}
+	tlsCrt, err := x509.SignTlsCsr(csrs.TlsCsr)
+	if err != nil {
+		return err
+	}
+	certs := client.CaCerts{TlsCrt: tlsCrt}
 
 	fmt.Println("Uploading signed certs to Foundries.io")
-	subcommands.DieNotNil(api.FactoryPatchCA(factory, certs))
+	return api.FactoryPatchCA(factory, certs)
 }
@@ -31,7 +31,7 @@ func init() {
 	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Show certificates known to the Factory",
-		Run:   doShowCA,
+		RunE:  doShowCA,
 	}
 	caCmd.AddCommand(cmd)
 	cmd.Flags().BoolVarP(&prettyFormat, "pretty", "", false, "Display human readable output of each certificate")
@@ -40,15 +40,22 @@ func init() {
 	justShowFlags.Add(cmd, justShowCas, "Only show device authenticate certificates trusted by the device-gateway")
 }
 
-func doShowCA(cmd *cobra.Command, args []string) {
+func doShowCA(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Showing certs for %s", factory)
 
 	resp, err := api.FactoryGetCA(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	flag, err := justShowFlags.GetFlag()
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
+	if !subcommands.IsTableOutput() {
+		return subcommands.PrintData(resp)
+	}
 	if len(flag) > 0 {
 		switch flag {
 		case justShowRoot:
@@ -61,7 +68,7 @@ func doShowCA(cmd *cobra.Command, args []string) {
 		default:
 			panic("Unknown flag: " + flag)
 		}
-		return
+		return nil
 	}
 
 	fmt.Println("## Change Metadata")
@@ -83,6 +90,7 @@ func doShowCA(cmd *cobra.Command, args []string) {
 	fmt.Println("\n## Device Authentication Certificate(s)")
 	printOneCert(resp.CaCrt)
 	printDisabledCas(resp.CaDisabled)
+	return nil
 }
 
 func printOneCert(crt string) {
@@ -190,7 +198,10 @@ func prettyPrint(cert string) {
 			fmt.Println("\t\tNIST CURVE:", pub.Curve.Params().Name)
 			fmt.Print("\t\t\t")
 			ecdh, err := pub.ECDH()
-			subcommands.DieNotNil(err)
+			if err != nil {
+				fmt.Println("Failed to read public key:", err)
+				break
+			}
 			for idx, b := range ecdh.Bytes() {
 				fmt.Printf("%02x:", b)
 				if (idx+1)%15 == 0 {
@@ -6,7 +6,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/foundriesio/fioctl/x509"
 )
 
@@ -24,7 +23,7 @@ from the specified PKI directory. The signed certificate is printed to stdout.
 The certificate type is determined automatically from the CSR's requested
 extensions. If the CSR contains a Basic Constraints extension with CA:TRUE,
 a CA certificate is produced. Otherwise, a TLS server certificate is produced.`,
-		Run:  doSignCa,
+		RunE: doSignCa,
 		Args: cobra.ExactArgs(1),
 	}
 	caCmd.AddCommand(cmd)
@@ -33,14 +32,22 @@ a CA certificate is produced. Otherwise, a TLS server certificate is produced.`,
 	_ = cmd.MarkFlagDirname("pki-dir")
 }
 
-func doSignCa(cmd *cobra.Command, args []string) {
+func doSignCa(cmd *cobra.Command, args []string) error {
 	csrFile := args[0]
 
 	csrPem, err := os.ReadFile(csrFile)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	subcommands.DieNotNil(os.Chdir(pkiDir))
+	if err := os.Chdir(pkiDir); err != nil {
+		return err
+	}
 
-	crtPem := x509.SignCsr(string(csrPem))
+	crtPem, err := x509.SignCsr(string(csrPem))
+	if err != nil {
+		return err
+	}
 	fmt.Print(crtPem)
+	return nil
 }
@@ -8,26 +8,27 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	cmd := &cobra.Command{
 		Use:   "update <ca-crts file>",
 		Short: "Update the list of CAs that can create client certificates for devices",
-		Run:   doUpdateCA,
+		RunE:  doUpdateCA,
 		Args:  cobra.ExactArgs(1),
 	}
 	caCmd.AddCommand(cmd)
 }
 
-func doUpdateCA(cmd *cobra.Command, args []string) {
+func doUpdateCA(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Updating certs for %s", factory)
 
 	buf, err := os.ReadFile(args[0])
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	certs := client.CaCerts{CaCrt: string(buf)}
-	subcommands.DieNotNil(api.FactoryPatchCA(factory, certs))
+	return api.FactoryPatchCA(factory, certs)
 }
@@ -14,8 +14,9 @@ var (
 var cmd = &cobra.Command{
 	Use:   "keys",
 	Short: "Manage keys in use by your Factory fleet",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 }
 
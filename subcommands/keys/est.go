@@ -5,7 +5,6 @@ import (
 	"os"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/foundriesio/fioctl/x509"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -16,7 +15,7 @@ func init() {
 	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Show the EST TLS certificate authorized for this Factory",
-		Run:   doShowEst,
+		RunE:  doShowEst,
 	}
 	estCmd.AddCommand(cmd)
 	cmd.Flags().BoolVarP(&prettyFormat, "pretty", "", false, "Display human readable output of each certificate")
@@ -24,7 +23,7 @@ func init() {
 	cmd = &cobra.Command{
 		Use:   "authorize <PKI directory>",
 		Short: "Authorize Foundries.io to run an EST server at <repoid>.est.foundries.io",
-		Run:   doAuthorizeEst,
+		RunE:  doAuthorizeEst,
 		Args:  cobra.ExactArgs(1),
 		Long: `This command will initiate a transaction with api.foundries.io that:
 
@@ -39,12 +38,14 @@ func init() {
 	cmd.Flags().StringVarP(&hsmTokenLabel, "hsm-token-label", "", "", "The label of the HSM token containing the root CA key")
 }
 
-func doShowEst(cmd *cobra.Command, args []string) {
+func doShowEst(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Showing EST cert for %s", factory)
 
 	cert, err := api.FactoryGetCA(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if len(cert.EstCrt) == 0 {
 		fmt.Println("EST TLS certificate has not been configured for this Factory.")
 	} else if prettyFormat {
@@ -52,23 +53,34 @@ func doShowEst(cmd *cobra.Command, args []string) {
 	} else {
 		fmt.Println(cert.EstCrt)
 	}
+	return nil
 }
 
-func doAuthorizeEst(cmd *cobra.Command, args []string) {
+func doAuthorizeEst(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 
-	subcommands.DieNotNil(os.Chdir(args[0]))
+	if err := os.Chdir(args[0]); err != nil {
+		return err
+	}
 	hsm, err := x509.ValidateHsmArgs(
 		hsmModule, hsmPin, hsmTokenLabel, "--hsm-module", "--hsm-pin", "--hsm-token-label")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	x509.InitHsm(hsm)
 
 	logrus.Debugf("Authorizing EST for %s", factory)
 	csrs, err := api.FactoryCreateCA(factory, client.CaCreateOptions{CreateEstCert: true})
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	certs := client.CaCerts{EstCrt: x509.SignEstCsr(csrs.EstCsr)}
+	estCrt, err := x509.SignEstCsr(csrs.EstCsr)
+	if err != nil {
+		return err
+	}
+	certs := client.CaCerts{EstCrt: estCrt}
 	fmt.Println("Uploading new EST certificate:")
 	fmt.Println(certs.EstCrt)
-	subcommands.DieNotNil(api.FactoryPatchCA(factory, certs))
+	return api.FactoryPatchCA(factory, certs)
 }
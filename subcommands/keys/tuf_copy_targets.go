@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -16,7 +15,7 @@ func init() {
 		Use:    "copy-targets <offline key archive> <new targets archive>",
 		Short:  "Copy the Target signing credentials from the offline key archive",
 		Hidden: true,
-		Run:    doCopyTargets,
+		RunE:   doCopyTargets,
 		Args:   cobra.ExactArgs(2),
 		Long: `This command extracts the Target signing credentials required for initializing 
 waves into a new tarball so that the offline key archive is not required for
@@ -31,24 +30,32 @@ Please, use a new approach to rotate the targets key into a separate file:
 	cmd.AddCommand(copy)
 }
 
-func doCopyTargets(cmd *cobra.Command, args []string) {
+func doCopyTargets(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	credsFile := args[0]
 	creds, err := GetOfflineCreds(credsFile)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	root, err := api.TufRootGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	targetsCreds, err := createTargetsCreds(factory, *root, creds)
-	subcommands.DieNotNil(err)
-	saveTufCreds(args[1], targetsCreds)
+	if err != nil {
+		return err
+	}
+	return saveTufCreds(args[1], targetsCreds)
 }
 
 func createTargetsCreds(factory string, root client.AtsTufRoot, creds OfflineCreds) (OfflineCreds, error) {
 	targets := make(OfflineCreds)
 	onlinePub, err := api.TufTargetsOnlineKey(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 	for _, keyid := range root.Signed.Roles["targets"].KeyIDs {
 		pubkey := root.Signed.Keys[keyid].KeyValue.Public
 		if pubkey != onlinePub.KeyValue.Public {
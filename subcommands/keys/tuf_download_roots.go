@@ -3,6 +3,7 @@ package keys
 import (
 	"archive/tar"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"os"
 
@@ -11,21 +12,20 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	downloadRoots := &cobra.Command{
 		Use:   "download-roots <archive path>",
 		Short: "Download all versions of the Factory's TUF root metadata into a tarball",
-		Run:   doDownloadRoots,
+		RunE:  doDownloadRoots,
 		Args:  cobra.ExactArgs(1),
 	}
 	downloadRoots.Flags().BoolP("prod", "", false, "Download the production versions")
 	tufCmd.AddCommand(downloadRoots)
 }
 
-func doDownloadRoots(cmd *cobra.Command, args []string) {
+func doDownloadRoots(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	prod, _ := cmd.Flags().GetBool("prod")
 	dstPath := args[0]
@@ -38,7 +38,9 @@ func doDownloadRoots(cmd *cobra.Command, args []string) {
 	}
 
 	file, err := os.Create(dstPath)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
 	gzipWriter := gzip.NewWriter(file)
@@ -51,14 +53,18 @@ func doDownloadRoots(cmd *cobra.Command, args []string) {
 		var root *client.AtsTufRoot
 		root, err = getRoot(factory, ver)
 		if err != nil {
-			if herr := client.AsHttpError(err); herr != nil && herr.Response.StatusCode == 404 {
+			if errors.Is(err, client.ErrNotFound) {
 				break
 			}
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 		}
 
 		bytes, err := canonical.MarshalCanonical(root)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 
 		fmt.Printf("= Adding %d.root.json\n", ver)
 		header := &tar.Header{
@@ -66,8 +72,13 @@ func doDownloadRoots(cmd *cobra.Command, args []string) {
 			Size: int64(len(bytes)),
 			Mode: 0644,
 		}
-		subcommands.DieNotNil(tarWriter.WriteHeader(header))
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
 		_, err = tarWriter.Write(bytes)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/foundriesio/fioctl/subcommands"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,7 +13,7 @@ func init() {
 		Use:    "resign-root <offline key archive>",
 		Short:  "Re-sign the Factory's TUF root metadata",
 		Hidden: true,
-		Run:    doResignRoot,
+		RunE:   tufUpdatesShortcut(doResignRoot),
 		Args:   cobra.ExactArgs(1),
 		Deprecated: `it will be removed in the future.
 Please, use a more secure way to keep your TUF root role fresh:
@@ -26,14 +25,15 @@ Please, use a more secure way to keep your TUF root role fresh:
 	cmd.AddCommand(resign)
 }
 
-func doResignRoot(cmd *cobra.Command, args []string) {
-	isTufUpdatesShortcut = true
+func doResignRoot(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	changelog, _ := cmd.Flags().GetString("changelog")
 	keysFile := args[0]
 
 	creds, err := GetOfflineCreds(keysFile)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	// Below the `tuf updates` subcommands are chained in a correct order.
 	// Detach from the parent, so that command calls below use correct args.
@@ -41,21 +41,34 @@ func doResignRoot(cmd *cobra.Command, args []string) {
 
 	fmt.Println("= Creating new TUF updates transaction")
 	tufUpdatesCmd.SetArgs([]string{"init", "-m", changelog})
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	fmt.Println("= Extending TUF root expiration")
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	curCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	curCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 	newCiRoot.Signed.Expires = time.Now().AddDate(1, 0, 0).UTC().Round(time.Second) // 1 year validity
-	newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
-	signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds)
+	if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+		return err
+	}
+	if err := signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds); err != nil {
+		return err
+	}
 
 	fmt.Println("= Uploading new TUF root")
-	subcommands.DieNotNil(api.TufRootUpdatesPut(factory, "", newCiRoot, newProdRoot, nil, nil))
+	if err := api.TufRootUpdatesPut(factory, "", newCiRoot, newProdRoot, nil, nil); err != nil {
+		return err
+	}
 
 	fmt.Println("= Applying staged TUF root changes")
 	tufUpdatesCmd.SetArgs([]string{"apply"})
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	return tufUpdatesCmd.Execute()
 }
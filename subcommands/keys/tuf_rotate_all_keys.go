@@ -4,8 +4,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
@@ -29,7 +27,7 @@ When you rotate all TUF signing keys:
 Migrate a Factory to use Ed25519 key type for all TUF signing keys (online and offline):
   fioctl keys tuf rotate-all-keys --key-type=ed25519 \
     --keys=offline-tuf-root-keys.tgz --targets-keys=offline-tuf-targets-keys.tgz`,
-		Run: doRotateAllKeys,
+		RunE: tufUpdatesShortcut(doRotateAllKeys),
 	}
 	rotate.Flags().StringP("keys", "k", "", "Path to <offline-creds.tgz> used to sign TUF root.")
 	_ = rotate.MarkFlagRequired("keys")
@@ -42,10 +40,11 @@ Migrate a Factory to use Ed25519 key type for all TUF signing keys (online and o
 	tufCmd.AddCommand(rotate)
 }
 
-func doRotateAllKeys(cmd *cobra.Command, unusedArgs []string) {
-	isTufUpdatesShortcut = true
+func doRotateAllKeys(cmd *cobra.Command, unusedArgs []string) error {
 	keyType, _ := cmd.Flags().GetString("key-type")
-	ParseTufKeyType(keyType) // fails on error
+	if _, err := ParseTufKeyType(keyType); err != nil {
+		return err
+	}
 	changelog, _ := cmd.Flags().GetString("changelog")
 	if changelog == "" {
 		changelog = "Rotate all TUF root signing keys"
@@ -67,25 +66,35 @@ func doRotateAllKeys(cmd *cobra.Command, unusedArgs []string) {
 		args = append(args, "--first-time", "-k", credsFile)
 	}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	args = []string{"rotate-offline-key", "-r", "root", "-k", credsFile, "-y", keyType}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	args = []string{"rotate-offline-key", "-r", "targets", "-k", targetsCredsFile, "-y", keyType}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	args = []string{"rotate-online-key", "-r", "targets,snapshot,timestamp", "-y", keyType}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	args = []string{"sign", "-k", credsFile}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	fmt.Println("= Applying staged TUF root changes")
 	tufUpdatesCmd.SetArgs([]string{"apply"})
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	return tufUpdatesCmd.Execute()
 }
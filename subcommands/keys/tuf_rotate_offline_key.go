@@ -5,8 +5,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 const (
@@ -37,7 +35,7 @@ When you rotate the TUF targets offline signing key:
   # Rotate offline TUF targets key using the Ed25519 elliptic curve to generate a new key pair:
   fioctl keys tuf rotate-offline-key --role=targets --key-type=ed25519 \
     --keys=offline-tuf-root-keys.tgz --targets-keys=offline-tuf-targets-keys.tgz`,
-		Run:         doRotateOfflineKey,
+		RunE:        tufUpdatesShortcut(doRotateOfflineKey),
 		Annotations: map[string]string{tufCmdAnnotation: tufCmdRotateOfflineKey},
 	}
 	rotate.Flags().StringP("role", "r", "", "TUF role name, supported: Root, Targets.")
@@ -61,7 +59,7 @@ Instead, please, use a new approach to rotate TUF root key:
   fioctl keys tuf rotate-offline-key --role=root --keys=<offline-creds.tgz>
 `,
 		Hidden:      true,
-		Run:         doRotateOfflineKey,
+		RunE:        tufUpdatesShortcut(doRotateOfflineKey),
 		Annotations: map[string]string{tufCmdAnnotation: tufCmdRotateRootLegacy},
 		Args:        cobra.ExactArgs(1),
 	}
@@ -83,7 +81,7 @@ Instead, please, use a new approach to rotate TUF targets key:
     --keys=<offline-creds.tgz> [--targets-keys=<offline-targets-creds.tgz>]
 `,
 		Hidden:      true,
-		Run:         doRotateOfflineKey,
+		RunE:        tufUpdatesShortcut(doRotateOfflineKey),
 		Annotations: map[string]string{tufCmdAnnotation: tufCmdRotateTargetsLegacy},
 		Args:        cobra.ExactArgs(1),
 	}
@@ -92,29 +90,33 @@ Instead, please, use a new approach to rotate TUF targets key:
 	cmd.AddCommand(legacyRotateTargets)
 }
 
-func doRotateOfflineKey(cmd *cobra.Command, args []string) {
-	isTufUpdatesShortcut = true
+func doRotateOfflineKey(cmd *cobra.Command, args []string) error {
 	var (
 		roleName, credsFile string
 		targetsCredsFile    string
 		firstTime           bool
 	)
 	keyType, _ := cmd.Flags().GetString("key-type")
-	ParseTufKeyType(keyType) // fails on error
+	if _, err := ParseTufKeyType(keyType); err != nil {
+		return err
+	}
 	changelog, _ := cmd.Flags().GetString("changelog")
 	cmdName := cmd.Annotations[tufCmdAnnotation]
 	switch cmdName {
 	case tufCmdRotateOfflineKey:
+		var err error
 		roleName, _ = cmd.Flags().GetString("role")
-		roleName = ParseTufRoleNameOffline(roleName)
+		if roleName, err = ParseTufRoleNameOffline(roleName); err != nil {
+			return err
+		}
 		credsFile, _ = cmd.Flags().GetString("keys")
 		targetsCredsFile, _ = cmd.Flags().GetString("targets-keys")
 		firstTime, _ = cmd.Flags().GetBool("first-time")
 		if firstTime && roleName != tufRoleNameRoot {
-			subcommands.DieNotNil(errors.New("The --first-time option is only valid for the first TUF root key rotation."))
+			return errors.New("The --first-time option is only valid for the first TUF root key rotation.")
 		}
 		if targetsCredsFile != "" && roleName != tufRoleNameTargets {
-			subcommands.DieNotNil(errors.New("The --targets-keys option is only valid for the TUF targets key rotation."))
+			return errors.New("The --targets-keys option is only valid for the TUF targets key rotation.")
 		}
 	case tufCmdRotateRootLegacy:
 		roleName = tufRoleNameRoot
@@ -149,16 +151,20 @@ func doRotateOfflineKey(cmd *cobra.Command, args []string) {
 		args = append(args, "--first-time", "-k", credsFile)
 	}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	args = []string{"rotate-offline-key", "-r", roleName, "-k", credsFile, "-y", keyType, "-s"}
 	if targetsCredsFile != "" {
 		args = append(args, "-K", targetsCredsFile)
 	}
 	tufUpdatesCmd.SetArgs(args)
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	if err := tufUpdatesCmd.Execute(); err != nil {
+		return err
+	}
 
 	fmt.Println("= Applying staged TUF root changes")
 	tufUpdatesCmd.SetArgs([]string{"apply"})
-	subcommands.DieNotNil(tufUpdatesCmd.Execute())
+	return tufUpdatesCmd.Execute()
 }
@@ -17,7 +17,7 @@ func init() {
 	show := &cobra.Command{
 		Use:   "show-root",
 		Short: "Show the Factory's TUF root metadata",
-		Run:   doShowRoot,
+		RunE:  doShowRoot,
 	}
 	show.Flags().BoolVarP(&showProd, "prod", "", false, "Show the production version")
 	tufCmd.AddCommand(show)
@@ -29,13 +29,13 @@ func init() {
 Instead, use "fioctl keys tuf show-root".
 `,
 		Hidden: true,
-		Run:    doShowRoot,
+		RunE:   doShowRoot,
 	}
 	legacyShow.Flags().BoolVarP(&showProd, "prod", "", false, "Show the production version")
 	cmd.AddCommand(legacyShow)
 }
 
-func doShowRoot(cmd *cobra.Command, args []string) {
+func doShowRoot(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 
 	var err error
@@ -45,8 +45,13 @@ func doShowRoot(cmd *cobra.Command, args []string) {
 	} else {
 		root, err = api.TufRootGet(factory)
 	}
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	bytes, err := subcommands.MarshalIndent(root, "", "  ")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Println(string(bytes))
+	return nil
 }
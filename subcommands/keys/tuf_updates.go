@@ -4,8 +4,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 // This allows to chain several TUF updates subcommands into a single "shortcut" worklfow.
@@ -42,25 +40,37 @@ For increased safety there can be only one active TUF updates transaction at a t
 	    --role=root --txid=abcdef42 --keys=tuf-root-keys.tgz --sign
   5. On TUF root admin's shell:
      fioctl keys tuf updates apply --txid=abcdef42`,
+	// Shortcut commands run this command directly; errors are returned to them instead
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func init() {
 	tufCmd.AddCommand(tufUpdatesCmd)
+}
 
-	subcommands.AddLastWill(func() {
-		if isTufUpdatesShortcut {
+// tufUpdatesShortcut wraps a command which chains several TUF updates subcommands.
+// If such a command fails, a user is told what to do with a possibly staged TUF root update.
+func tufUpdatesShortcut(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		isTufUpdatesShortcut = true
+		err := run(cmd, args)
+		if err != nil && isTufUpdatesShortcut {
 			if isTufUpdatesInitialized {
 				// Tuf updates initialized; but the shortcut failed before trying to apply it.
-				fmt.Println(`
+				err = fmt.Errorf(`%w
+
 No changes were made to your Factory.
 Please, cancel the staged TUF root updates
-using the "fioctl keys tuf updates cancel" command, and try again later.`)
+using the "fioctl keys tuf updates cancel" command, and try again later.`, err)
 			} else {
 				// The init phase failed itself, so there is no active transaction.
-				fmt.Println(`
+				err = fmt.Errorf(`%w
+
 No changes were made to your Factory.
-Please, fix an error above and try again.`)
+Please, fix an error above and try again.`, err)
 			}
 		}
-	})
+		return err
+	}
 }
@@ -30,7 +30,7 @@ Use the sign-prod-targets subcommand if you want to sign existing production Tar
 - Add offline TUF targets key, explicitly specifying new key type (and signing algorithm):
   fioctl keys tuf updates add-offline-key \
     --txid=abc --role=targets --keys=tuf-targets-keys.tgz --key-type=ed25519`,
-		Run: doTufUpdatesAddOfflineKey,
+		RunE: doTufUpdatesAddOfflineKey,
 	}
 	add.Flags().StringP("role", "r", "", "TUF role name, supported: Root, Targets.")
 	_ = add.MarkFlagRequired("role")
@@ -43,68 +43,109 @@ For security reasons, it is disallowed to add several actual keys for the same T
 	tufUpdatesCmd.AddCommand(add)
 }
 
-func doTufUpdatesAddOfflineKey(cmd *cobra.Command, args []string) {
+func doTufUpdatesAddOfflineKey(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	keyTypeStr, _ := cmd.Flags().GetString("key-type")
-	keyType := ParseTufKeyType(keyTypeStr)
+	keyType, err := ParseTufKeyType(keyTypeStr)
+	if err != nil {
+		return err
+	}
 	keysFile, _ := cmd.Flags().GetString("keys")
 	roleName, _ := cmd.Flags().GetString("role")
-	roleName = ParseTufRoleNameOffline(roleName)
+	if roleName, err = ParseTufRoleNameOffline(roleName); err != nil {
+		return err
+	}
 
 	var creds OfflineCreds
 	if _, err := os.Stat(keysFile); err == nil {
 		creds, err = GetOfflineCreds(keysFile)
-		subcommands.DieNotNil(err)
-		subcommands.AssertWritable(keysFile)
+		if err != nil {
+			return err
+		}
+		if err := subcommands.AssertWritable(keysFile); err != nil {
+			return err
+		}
 	} else if errors.Is(err, fs.ErrNotExist) {
 		creds = make(OfflineCreds, 0)
-		saveTufCreds(keysFile, creds)
+		if err := saveTufCreds(keysFile, creds); err != nil {
+			return err
+		}
 	} else {
-		subcommands.DieNotNil(err)
+		return err
 	}
 
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	_, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	_, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 
 	switch roleName {
 	case tufRoleNameRoot:
-		addOfflineRootKey(newCiRoot, creds, keyType)
+		if err := addOfflineRootKey(newCiRoot, creds, keyType); err != nil {
+			return err
+		}
 	case tufRoleNameTargets:
 		onlineTargetsId := updates.Updated.OnlineKeys["targets"]
 		if onlineTargetsId == "" {
-			subcommands.DieNotNil(errors.New("Unable to find online target key for Factory"))
+			return errors.New("Unable to find online target key for Factory")
+		}
+		if err := addOfflineTargetsKey(newCiRoot, creds, keyType, onlineTargetsId); err != nil {
+			return err
 		}
-		addOfflineTargetsKey(newCiRoot, creds, keyType, onlineTargetsId)
 	default:
 		panic(fmt.Errorf("Unexpected role name: %s", roleName))
 	}
-	newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
+	if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+		return err
+	}
 
 	fmt.Println("= Uploading new TUF root")
-	tmpFile := saveTempTufCreds(keysFile, creds)
+	tmpFile, err := saveTempTufCreds(keysFile, creds)
+	if err != nil {
+		return err
+	}
 	err = api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil)
-	handleTufRootUpdatesUpload(tmpFile, keysFile, err)
+	return handleTufRootUpdatesUpload(tmpFile, keysFile, err)
 }
 
-func addOfflineRootKey(root *client.AtsTufRoot, creds OfflineCreds, keyType TufKeyType) {
+func addOfflineRootKey(root *client.AtsTufRoot, creds OfflineCreds, keyType TufKeyType) error {
 	oldKids := root.Signed.Roles["root"].KeyIDs
-	subcommands.DieNotNil(checkNoTufSigner(root, creds, oldKids))
+	if err := checkNoTufSigner(root, creds, oldKids); err != nil {
+		return err
+	}
 
-	kp := genTufKeyPair(keyType)
-	addOfflineTufKey(root, "root", kp, oldKids, creds)
+	kp, err := genTufKeyPair(keyType)
+	if err != nil {
+		return err
+	}
+	if err := addOfflineTufKey(root, "root", kp, oldKids, creds); err != nil {
+		return err
+	}
 	fmt.Println("= New root keyid:", kp.signer.Id)
+	return nil
 }
 
-func addOfflineTargetsKey(root *client.AtsTufRoot, creds OfflineCreds, keyType TufKeyType, onlineTargetsId string) {
+func addOfflineTargetsKey(root *client.AtsTufRoot, creds OfflineCreds, keyType TufKeyType, onlineTargetsId string) error {
 	oldKids := root.Signed.Roles["targets"].KeyIDs
 	if len(oldKids) > 1 {
-		subcommands.DieNotNil(checkNoTufSigner(root, creds, subcommands.SliceRemove(oldKids, onlineTargetsId)))
+		if err := checkNoTufSigner(root, creds, subcommands.SliceRemove(oldKids, onlineTargetsId)); err != nil {
+			return err
+		}
 	}
 
-	kp := genTufKeyPair(keyType)
-	addOfflineTufKey(root, "targets", kp, oldKids, creds)
+	kp, err := genTufKeyPair(keyType)
+	if err != nil {
+		return err
+	}
+	if err := addOfflineTufKey(root, "targets", kp, oldKids, creds); err != nil {
+		return err
+	}
 	fmt.Println("= New targets keyid:", kp.signer.Id)
+	return nil
 }
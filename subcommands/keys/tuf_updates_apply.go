@@ -10,20 +10,19 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/foundriesio/fioctl/client"
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	applyCmd := &cobra.Command{
 		Use:   "apply",
 		Short: "Apply staged TUF root updates for the Factory",
-		Run:   doTufUpdatesApply,
+		RunE:  doTufUpdatesApply,
 	}
 	applyCmd.Flags().StringP("txid", "x", "", "TUF root updates transaction ID.")
 	tufUpdatesCmd.AddCommand(applyCmd)
 }
 
-func doTufUpdatesApply(cmd *cobra.Command, args []string) {
+func doTufUpdatesApply(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 
@@ -34,15 +33,15 @@ func doTufUpdatesApply(cmd *cobra.Command, args []string) {
 	if err != nil {
 		msg := "Failed to apply staged TUF root updates:\n%w\n"
 		var isNonFatal bool
-		if herr := client.AsHttpError(err); herr != nil {
-			if herr.Response.StatusCode == 404 {
+		if herr := client.AsAPIError(err); herr != nil {
+			if errors.Is(herr, client.ErrNotFound) {
 				// Double check: if there are no TUF updates - fail clean; otherwise, fatal error.
 				updates, err1 := api.TufRootUpdatesGet(factory)
 				if err1 == nil && updates.Status == client.TufRootUpdatesStatusNone {
-					subcommands.DieNotNil(errors.New("There are no TUF root updates in progress."))
+					return errors.New("There are no TUF root updates in progress.")
 				}
 			}
-			isNonFatal = slices.Contains([]int{400, 401, 403, 422, 423}, herr.Response.StatusCode)
+			isNonFatal = slices.Contains([]int{400, 401, 403, 422, 423}, herr.StatusCode)
 		}
 		if isNonFatal {
 			msg += `No changes were made to your Factory.
@@ -57,8 +56,11 @@ If the error persists, please contact customer support.`
 		}
 		err = fmt.Errorf(msg, err)
 	}
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println(`The staged TUF root updates were applied to your Factory.
 Please, make sure that the updated TUF keys file(s) are stored in a safe place.`)
+	return nil
 }
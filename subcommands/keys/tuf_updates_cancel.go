@@ -13,14 +13,21 @@ func init() {
 	cancelCmd := &cobra.Command{
 		Use:   "cancel",
 		Short: "Cancel staged TUF root updates for the Factory",
-		Run:   doTufUpdatesCancel,
+		RunE:  doTufUpdatesCancel,
 	}
 	tufUpdatesCmd.AddCommand(cancelCmd)
 }
 
-func doTufUpdatesCancel(cmd *cobra.Command, args []string) {
+func doTufUpdatesCancel(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
-	subcommands.DieNotNil(api.TufRootUpdatesCancel(factory))
+	action := fmt.Sprintf("Abandon all changes to the TUF root of the %s factory staged by the current transaction", factory)
+	if err := subcommands.ConfirmFactory(factory, action, nil); err != nil {
+		return err
+	}
+	if err := api.TufRootUpdatesCancel(factory); err != nil {
+		return err
+	}
 	fmt.Println(`The staged TUF root updates were canceled.
 No other changes were made to your Factory.`)
+	return nil
 }
@@ -38,7 +38,7 @@ When you delete the TUF targets offline signing key:
   fioctl keys tuf updates delete-offline-key \
     --txid=abc --role=targets
 	 --key-id=15bbb6e79c9ac73b2db7df73c96f3a4937a25d948c048ba0208e49e426e5888a`,
-		Run: doTufUpdatesDeleteOfflineKey,
+		RunE: doTufUpdatesDeleteOfflineKey,
 	}
 	del.Flags().StringP("role", "r", "", "TUF role name, supported: Root, Targets.")
 	_ = del.MarkFlagRequired("role")
@@ -50,31 +50,38 @@ When you delete the TUF targets offline signing key:
 	tufUpdatesCmd.AddCommand(del)
 }
 
-func doTufUpdatesDeleteOfflineKey(cmd *cobra.Command, args []string) {
+func doTufUpdatesDeleteOfflineKey(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	keysFile, _ := cmd.Flags().GetString("keys")
 	keyId, _ := cmd.Flags().GetString("key-id")
 	roleName, _ := cmd.Flags().GetString("role")
-	roleName = ParseTufRoleNameOffline(roleName)
+	roleName, err := ParseTufRoleNameOffline(roleName)
+	if err != nil {
+		return err
+	}
 
-	var (
-		creds OfflineCreds
-		err   error
-	)
+	var creds OfflineCreds
 
 	if keysFile != "" {
 		creds, err = GetOfflineCreds(keysFile)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 	} else if keyId == "" {
-		subcommands.DieNotNil(errors.New(
+		return errors.New(
 			"Either --keys or --key-id option is required to delete the offline TUF key.",
-		))
+		)
 	}
 
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
-	_, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
+	_, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 
 	var (
 		roleToUpdate *tuf.RootRole
@@ -89,12 +96,12 @@ func doTufUpdatesDeleteOfflineKey(cmd *cobra.Command, args []string) {
 		roleToUpdate = newCiRoot.Signed.Roles["targets"]
 		onlineTargetsId := updates.Updated.OnlineKeys["targets"]
 		if onlineTargetsId == "" {
-			subcommands.DieNotNil(errors.New("Unable to find online Target key for Factory"))
+			return errors.New("Unable to find online Target key for Factory")
 		}
 		if keyId != "" && keyId == onlineTargetsId {
-			subcommands.DieNotNil(fmt.Errorf(
+			return fmt.Errorf(
 				"It is not allowed to delete an online TUF Targets key: %s", onlineTargetsId,
-			))
+			)
 		}
 		validKeyIds = subcommands.SliceRemove(roleToUpdate.KeyIDs, onlineTargetsId)
 	default:
@@ -104,16 +111,20 @@ func doTufUpdatesDeleteOfflineKey(cmd *cobra.Command, args []string) {
 	fmt.Println("= Delete keyid:", keyId)
 	if keyId == "" {
 		oldKey, err := FindOneTufSigner(newCiRoot, creds, validKeyIds)
-		subcommands.DieNotNil(err, ErrMsgReadingTufKey(roleName, "current"))
+		if err != nil {
+			return fmt.Errorf("%s%w", ErrMsgReadingTufKey(roleName, "current"), err)
+		}
 		keyId = oldKey.Id
 	} else if !slices.Contains(validKeyIds, keyId) {
-		subcommands.DieNotNil(fmt.Errorf(
+		return fmt.Errorf(
 			"Key ID %s not found in TUF %s role keys: %v", keyId, roleName, validKeyIds,
-		))
+		)
 	}
 	roleToUpdate.KeyIDs = subcommands.SliceRemove(roleToUpdate.KeyIDs, keyId)
-	newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
+	if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+		return err
+	}
 
 	fmt.Println("= Uploading new TUF root")
-	subcommands.DieNotNil(api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil))
+	return api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil)
 }
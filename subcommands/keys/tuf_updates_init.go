@@ -7,15 +7,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	initCmd := &cobra.Command{
 		Use:   "init",
 		Short: "Start a new transaction to update TUF root keys",
-		Run:   doTufUpdatesInit,
+		RunE:  doTufUpdatesInit,
 	}
 	initCmd.Flags().StringP("changelog", "m", "", "Reason for doing this operation. Saved in root metadata to track change history.")
 	_ = initCmd.MarkFlagRequired("changelog")
@@ -26,7 +24,7 @@ func init() {
 	tufUpdatesCmd.AddCommand(initCmd)
 }
 
-func doTufUpdatesInit(cmd *cobra.Command, args []string) {
+func doTufUpdatesInit(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	changelog, _ := cmd.Flags().GetString("changelog")
 	firstTime, _ := cmd.Flags().GetBool("first-time")
@@ -34,13 +32,15 @@ func doTufUpdatesInit(cmd *cobra.Command, args []string) {
 
 	if firstTime {
 		if _, err := os.Stat(keysFile); err == nil {
-			subcommands.DieNotNil(errors.New(`Destination file exists.
-Please make sure you aren't accidentally overwriting another Factory's keys`))
+			return errors.New(`Destination file exists.
+Please make sure you aren't accidentally overwriting another Factory's keys`)
 		}
 	}
 
 	res, err := api.TufRootUpdatesInit(factory, changelog, firstTime, isTufUpdatesShortcut)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	isTufUpdatesInitialized = true
 	if !isTufUpdatesShortcut {
@@ -57,6 +57,9 @@ Other users are required to supply this transaction ID for all commands except r
 		creds := make(OfflineCreds)
 		creds["tufrepo/keys/first-root.sec"] = []byte(res.FirstRootKeyPriv)
 		creds["tufrepo/keys/first-root.pub"] = []byte(res.FirstRootKeyPub)
-		saveTufCreds(keysFile, creds)
+		if err := saveTufCreds(keysFile, creds); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -19,7 +19,7 @@ func init() {
 	review := &cobra.Command{
 		Use:   "review",
 		Short: "Show the Factory's TUF root metadata",
-		Run:   doTufUpdatesReview,
+		RunE:  doTufUpdatesReview,
 	}
 	review.Flags().BoolP("raw", "", false, "Show the raw root.json")
 	review.Flags().BoolP("diff", "", false, "Show the unified diff between current and staged root.json")
@@ -28,30 +28,37 @@ func init() {
 	tufUpdatesCmd.AddCommand(review)
 }
 
-func doTufUpdatesReview(cmd *cobra.Command, args []string) {
+func doTufUpdatesReview(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	showRaw, _ := cmd.Flags().GetBool("raw")
 	showDiff, _ := cmd.Flags().GetBool("diff")
 	showProd, _ := cmd.Flags().GetBool("prod")
 	if showProd && !showRaw && !showDiff {
-		subcommands.DieNotNil(errors.New(
+		return errors.New(
 			"If the flag 'prod' is set then one of the flags [raw diff] must also be set",
-		))
+		)
 	}
 
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	oldCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, false)
+	oldCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, false)
+	if err != nil {
+		return err
+	}
 
 	if showRaw || showDiff {
 		if updates.Status == client.TufRootUpdatesStatusNone {
-			subcommands.DieNotNil(errors.New("There are no TUF root updates in progress."))
+			return errors.New("There are no TUF root updates in progress.")
 		}
 		var rootToShow *client.AtsTufRoot
 		if newProdRoot == nil {
 			// No effective changes yet, but we know how the prod root would look like
-			newProdRoot = genProdTufRoot(newCiRoot)
+			if newProdRoot, err = genProdTufRoot(newCiRoot); err != nil {
+				return err
+			}
 		}
 
 		if showProd {
@@ -62,16 +69,17 @@ func doTufUpdatesReview(cmd *cobra.Command, args []string) {
 
 		if showRaw {
 			bytes, err := subcommands.MarshalIndent(rootToShow, "", "  ")
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			fmt.Println(string(bytes))
 		} else {
 			var baseRootToShow *client.AtsTufRoot
 			if showProd {
 				if updates.Current.ProdRoot != "" {
-					subcommands.DieNotNil(
-						json.Unmarshal([]byte(updates.Current.ProdRoot), &baseRootToShow),
-						"Current prod root",
-					)
+					if err := json.Unmarshal([]byte(updates.Current.ProdRoot), &baseRootToShow); err != nil {
+						return fmt.Errorf("Current prod root: %w", err)
+					}
 				} else {
 					// First rotation, old prod root equals old CI root
 					baseRootToShow = oldCiRoot
@@ -81,9 +89,13 @@ func doTufUpdatesReview(cmd *cobra.Command, args []string) {
 			}
 
 			before, err := subcommands.MarshalIndent(baseRootToShow, "", "  ")
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			after, err := subcommands.MarshalIndent(rootToShow, "", "  ")
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			diff := godiff.Strings(
 				strings.Split(string(before), "\n"),
 				strings.Split(string(after), "\n"),
@@ -135,4 +147,5 @@ Once your are satisfied with your TUF updates, please, run 'fioctl keys tuf upda
 If you want to cancel staged TUF updates, please, run 'fioctl keys tuf updates cancel'.`)
 		}
 	}
+	return nil
 }
@@ -40,7 +40,7 @@ When you rotate the TUF Targets offline signing key:
 - Rotate offline TUF targets key and store the new key in a separate file (and re-sign TUF root):
   fioctl keys tuf updates rotate-offline-key \
     --txid=abc --role=targets --keys=tuf-root-keys.tgz --targets-keys=tuf-targets-keys.tgz --sign`,
-		Run: doTufUpdatesRotateOfflineKey,
+		RunE: doTufUpdatesRotateOfflineKey,
 	}
 	rotate.Flags().StringP("role", "r", "", "TUF role name, supported: Root, Targets.")
 	_ = rotate.MarkFlagRequired("role")
@@ -54,71 +54,104 @@ When you rotate the TUF Targets offline signing key:
 	tufUpdatesCmd.AddCommand(rotate)
 }
 
-func doTufUpdatesRotateOfflineKey(cmd *cobra.Command, args []string) {
+func doTufUpdatesRotateOfflineKey(cmd *cobra.Command, args []string) error {
 	roleName, _ := cmd.Flags().GetString("role")
-	roleName = ParseTufRoleNameOffline(roleName)
+	roleName, err := ParseTufRoleNameOffline(roleName)
+	if err != nil {
+		return err
+	}
 	switch roleName {
 	case tufRoleNameRoot:
-		doTufUpdatesRotateOfflineRootKey(cmd)
+		if err := doTufUpdatesRotateOfflineRootKey(cmd); err != nil {
+			return err
+		}
 	case tufRoleNameTargets:
-		doTufUpdatesRotateOfflineTargetsKey(cmd)
+		if err := doTufUpdatesRotateOfflineTargetsKey(cmd); err != nil {
+			return err
+		}
 	default:
 		panic(fmt.Errorf("Unexpected role name: %s", roleName))
 	}
+	return nil
 }
 
-func doTufUpdatesRotateOfflineRootKey(cmd *cobra.Command) {
+func doTufUpdatesRotateOfflineRootKey(cmd *cobra.Command) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	keyTypeStr, _ := cmd.Flags().GetString("key-type")
-	keyType := ParseTufKeyType(keyTypeStr)
+	keyType, err := ParseTufKeyType(keyTypeStr)
+	if err != nil {
+		return err
+	}
 	keysFile, _ := cmd.Flags().GetString("keys")
 	targetsKeysFile, _ := cmd.Flags().GetString("targets-keys")
 	shouldSign, _ := cmd.Flags().GetBool("sign")
 
 	if keysFile == "" {
-		subcommands.DieNotNil(errors.New(
+		return errors.New(
 			"The --keys option is required to rotate the offline TUF root key.",
-		))
+		)
 	}
 	if targetsKeysFile != "" {
-		subcommands.DieNotNil(errors.New(
+		return errors.New(
 			"The --targets-keys option is only valid to rotate the offline TUF targets key.",
-		))
+		)
 	}
 
 	creds, err := GetOfflineCreds(keysFile)
-	subcommands.DieNotNil(err)
-	subcommands.AssertWritable(keysFile)
+	if err != nil {
+		return err
+	}
+	if err := subcommands.AssertWritable(keysFile); err != nil {
+		return err
+	}
 
 	var updates client.TufRootUpdates
 	updates, err = api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	curCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	curCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 
 	// A rotation is pretty easy:
 	// 1. change the who's listed as the root key
 	// 2. sign the new root.json with both the old and new root
-	newKey, newCreds := replaceOfflineRootKey(newCiRoot, creds, keyType)
+	newKey, newCreds, err := replaceOfflineRootKey(newCiRoot, creds, keyType)
+	if err != nil {
+		return err
+	}
 	fmt.Println("= New root keyid:", newKey.Id)
-	newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
+	if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+		return err
+	}
 
 	if shouldSign {
-		signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, newCreds)
+		if err := signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, newCreds); err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("= Uploading new TUF root")
-	tmpFile := saveTempTufCreds(keysFile, newCreds)
+	tmpFile, err := saveTempTufCreds(keysFile, newCreds)
+	if err != nil {
+		return err
+	}
 	err = api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil)
-	handleTufRootUpdatesUpload(tmpFile, keysFile, err)
+	return handleTufRootUpdatesUpload(tmpFile, keysFile, err)
 }
 
-func doTufUpdatesRotateOfflineTargetsKey(cmd *cobra.Command) {
+func doTufUpdatesRotateOfflineTargetsKey(cmd *cobra.Command) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	keyTypeStr, _ := cmd.Flags().GetString("key-type")
-	keyType := ParseTufKeyType(keyTypeStr)
+	keyType, err := ParseTufKeyType(keyTypeStr)
+	if err != nil {
+		return err
+	}
 	keysFile, _ := cmd.Flags().GetString("keys")
 	targetsKeysFile, _ := cmd.Flags().GetString("targets-keys")
 	shouldSign, _ := cmd.Flags().GetBool("sign")
@@ -127,24 +160,30 @@ func doTufUpdatesRotateOfflineTargetsKey(cmd *cobra.Command) {
 		targetsKeysFile = keysFile
 	}
 	if targetsKeysFile == "" {
-		subcommands.DieNotNil(errors.New(
+		return errors.New(
 			"The --keys or --targets-keys option is required to rotate the offline TUF Targets key.",
-		))
+		)
 	}
 	if shouldSign && keysFile == "" {
-		subcommands.DieNotNil(errors.New("The --keys option is required to sign the new TUF root."))
+		return errors.New("The --keys option is required to sign the new TUF root.")
 	}
 
 	var creds, targetsCreds OfflineCreds
 	if _, err := os.Stat(targetsKeysFile); err == nil {
 		targetsCreds, err = GetOfflineCreds(targetsKeysFile)
-		subcommands.DieNotNil(err)
-		subcommands.AssertWritable(targetsKeysFile)
+		if err != nil {
+			return err
+		}
+		if err := subcommands.AssertWritable(targetsKeysFile); err != nil {
+			return err
+		}
 	} else if errors.Is(err, fs.ErrNotExist) {
 		targetsCreds = make(OfflineCreds, 0)
-		saveTufCreds(targetsKeysFile, targetsCreds)
+		if err := saveTufCreds(targetsKeysFile, targetsCreds); err != nil {
+			return err
+		}
 	} else {
-		subcommands.DieNotNil(err)
+		return err
 	}
 
 	if shouldSign {
@@ -153,14 +192,21 @@ func doTufUpdatesRotateOfflineTargetsKey(cmd *cobra.Command) {
 		} else {
 			var err error
 			creds, err = GetOfflineCreds(keysFile)
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	curCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	curCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 
 	// Target "rotation" works like this:
 	// 1. Find the "online target key" - this the key used by CI, so we don't
@@ -170,12 +216,19 @@ func doTufUpdatesRotateOfflineTargetsKey(cmd *cobra.Command) {
 	// 4. Re-sign existing production targets.
 	onlineTargetsId := updates.Updated.OnlineKeys["targets"]
 	if onlineTargetsId == "" {
-		subcommands.DieNotNil(errors.New("Unable to find online Target key for Factory"))
+		return errors.New("Unable to find online Target key for Factory")
+	}
+	if err != nil {
+		return err
+	}
+	newKey, newCreds, err := replaceOfflineTargetsKey(newCiRoot, onlineTargetsId, targetsCreds, keyType)
+	if err != nil {
+		return err
 	}
-	subcommands.DieNotNil(err)
-	newKey, newCreds := replaceOfflineTargetsKey(newCiRoot, onlineTargetsId, targetsCreds, keyType)
 	fmt.Println("= New Target keyid:", newKey.Id)
-	newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
+	if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+		return err
+	}
 
 	fmt.Println("= Re-signing prod Targets")
 	var oldestKey TufSigner
@@ -183,29 +236,44 @@ func doTufUpdatesRotateOfflineTargetsKey(cmd *cobra.Command) {
 		// Seaching for old key in curCiRoot supports several rotations in one transaction.
 		oldestKey, err = FindOneTufSigner(curCiRoot, targetsCreds,
 			subcommands.SliceRemove(curCiRoot.Signed.Roles["targets"].KeyIDs, onlineTargetsId))
-		subcommands.DieNotNil(err, ErrMsgReadingTufKey(tufRoleNameTargets, "current"))
+		if err != nil {
+			return fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameTargets, "current"), err)
+		}
 	}
 
 	targetsProdMap, err := api.ProdTargetsList(factory, false)
-	subcommands.DieNotNil(err, "Failed to fetch production Targets:")
+	if err != nil {
+		return fmt.Errorf("Failed to fetch production Targets: %w", err)
+	}
 	excludeTargetsWithoutKeySigInplace(targetsProdMap, oldestKey.Id)
 	newTargetsProdSigs, err := signProdTargets(newKey, targetsProdMap)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	targetsWaveMap, err := api.WaveTargetsList(factory, false)
-	subcommands.DieNotNil(err, "Failed to fetch production Wave Targets:")
+	if err != nil {
+		return fmt.Errorf("Failed to fetch production Wave Targets: %w", err)
+	}
 	excludeTargetsWithoutKeySigInplace(targetsWaveMap, oldestKey.Id)
 	newTargetsWaveSigs, err := signProdTargets(newKey, targetsWaveMap)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if shouldSign {
-		signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds)
+		if err := signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds); err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("= Uploading new TUF root")
-	tmpFile := saveTempTufCreds(targetsKeysFile, newCreds)
+	tmpFile, err := saveTempTufCreds(targetsKeysFile, newCreds)
+	if err != nil {
+		return err
+	}
 	err = api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, newTargetsProdSigs, newTargetsWaveSigs)
-	handleTufRootUpdatesUpload(tmpFile, targetsKeysFile, err)
+	return handleTufRootUpdatesUpload(tmpFile, targetsKeysFile, err)
 }
 
 func excludeTargetsWithoutKeySigInplace(targetsMap map[string]client.AtsTufTargets, mustHaveSigKeyId string) {
@@ -223,44 +291,59 @@ outerLoop:
 
 func replaceOfflineRootKey(
 	root *client.AtsTufRoot, creds OfflineCreds, keyType TufKeyType,
-) (TufSigner, OfflineCreds) {
+) (TufSigner, OfflineCreds, error) {
 	oldKids := root.Signed.Roles["root"].KeyIDs
 	oldKey, err := FindOneTufSigner(root, creds, oldKids)
-	subcommands.DieNotNil(err, ErrMsgReadingTufKey(tufRoleNameRoot, "current"))
+	if err != nil {
+		return TufSigner{}, nil, fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameRoot, "current"), err)
+	}
 	oldKids = subcommands.SliceRemove(oldKids, oldKey.Id)
 
-	kp := genTufKeyPair(keyType)
-	addOfflineTufKey(root, "root", kp, oldKids, creds)
+	kp, err := genTufKeyPair(keyType)
+	if err != nil {
+		return TufSigner{}, nil, err
+	}
+	if err := addOfflineTufKey(root, "root", kp, oldKids, creds); err != nil {
+		return TufSigner{}, nil, err
+	}
 	root.Signed.Expires = time.Now().AddDate(1, 0, 0).UTC().Round(time.Second) // 1 year validity
-	return kp.signer, creds
+	return kp.signer, creds, nil
 }
 
 func replaceOfflineTargetsKey(
 	root *client.AtsTufRoot, onlineTargetsId string, creds OfflineCreds, keyType TufKeyType,
-) (TufSigner, OfflineCreds) {
+) (TufSigner, OfflineCreds, error) {
 	// Support first key rotation (no offline targets key yet) for backward-compatibility.
 	oldKids := root.Signed.Roles["targets"].KeyIDs
 	if len(oldKids) > 1 {
 		oldKey, err := FindOneTufSigner(root, creds, subcommands.SliceRemove(oldKids, onlineTargetsId))
-		subcommands.DieNotNil(err, ErrMsgReadingTufKey(tufRoleNameTargets, "current"))
+		if err != nil {
+			return TufSigner{}, nil, fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameTargets, "current"), err)
+		}
 		oldKids = subcommands.SliceRemove(oldKids, oldKey.Id)
 	}
 
-	kp := genTufKeyPair(keyType)
-	addOfflineTufKey(root, "targets", kp, oldKids, creds)
-	return kp.signer, creds
+	kp, err := genTufKeyPair(keyType)
+	if err != nil {
+		return TufSigner{}, nil, err
+	}
+	if err := addOfflineTufKey(root, "targets", kp, oldKids, creds); err != nil {
+		return TufSigner{}, nil, err
+	}
+	return kp.signer, creds, nil
 }
 
-func handleTufRootUpdatesUpload(tmpKeysFile, keysFile string, err error) {
+func handleTufRootUpdatesUpload(tmpKeysFile, keysFile string, err error) error {
 	if err != nil {
 		if omg := os.Remove(tmpKeysFile); omg != nil {
 			fmt.Printf("Failed to remove a temporary keys file %s: %v.\n", tmpKeysFile, omg)
 		}
-		subcommands.DieNotNil(err)
+		return err
 	}
 	if err = os.Rename(tmpKeysFile, keysFile); err != nil {
-		fmt.Println("\nERROR: Unable to update offline keys file.", err)
-		fmt.Println("Temp copy still available at:", tmpKeysFile)
-		fmt.Println("This temp file contains your new Factory private key. You must copy this file.")
+		return fmt.Errorf(`Unable to update offline keys file: %w
+Temp copy still available at: %s
+This temp file contains your new Factory private key. You must copy this file.`, err, tmpKeysFile)
 	}
+	return nil
 }
@@ -7,8 +7,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/exp/slices"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
@@ -34,7 +32,7 @@ When you apply the online key rotation, these features are temporarily disabled
 - Rotate all online TUF keys explicitly specifying new key type (and signing algorithm):
   fioctl keys tuf updates rotate-online-key \
     --txid=abc --role=targets,snapshot,timestamp --key-type=ed25519`,
-		Run: doTufUpdatesRotateOnlineKey,
+		RunE: doTufUpdatesRotateOnlineKey,
 	}
 	rotate.Flags().StringSliceP("role", "r", nil, "TUF role name, supported: Targets, Snapshot, Timestamp.")
 	_ = rotate.MarkFlagRequired("role")
@@ -47,30 +45,45 @@ When you apply the online key rotation, these features are temporarily disabled
 	tufUpdatesCmd.AddCommand(rotate)
 }
 
-func doTufUpdatesRotateOnlineKey(cmd *cobra.Command, args []string) {
+func doTufUpdatesRotateOnlineKey(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	roleNames, _ := cmd.Flags().GetStringSlice("role")
 	for idx, roleName := range roleNames {
-		roleNames[idx] = strings.ToLower(ParseTufRoleNameOnline(roleName))
+		roleName, err := ParseTufRoleNameOnline(roleName)
+		if err != nil {
+			return err
+		}
+		roleNames[idx] = strings.ToLower(roleName)
 	}
 	keyTypeStr, _ := cmd.Flags().GetString("key-type")
-	keyType := ParseTufKeyType(keyTypeStr)
+	keyType, err := ParseTufKeyType(keyTypeStr)
+	if err != nil {
+		return err
+	}
 	keysFile, _ := cmd.Flags().GetString("keys")
 	shouldSign, _ := cmd.Flags().GetBool("sign")
 
 	// Preliminary check to give a more verbose error message before requesting to generate new keys
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
-	_, _, _ = checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
+	if _, _, _, err := checkTufRootUpdatesStatus(updates, true); err != nil {
+		return err
+	}
 
 	fmt.Println("= Generating new online TUF keys")
-	subcommands.DieNotNil(api.TufRootUpdatesGenerateOnlineKeys(
+	if err := api.TufRootUpdatesGenerateOnlineKeys(
 		factory, txid, keyType.Name(), roleNames,
-	))
+	); err != nil {
+		return err
+	}
 
 	updates, err = api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err, "Failed to fetch new online TUF keys")
+	if err != nil {
+		return fmt.Errorf("Failed to fetch new online TUF keys: %w", err)
+	}
 	for _, roleName := range []string{tufRoleNameTargets, tufRoleNameSnapshot, tufRoleNameTimestamp} {
 		roleName = strings.ToLower(roleName)
 		if slices.Contains(roleNames, roleName) {
@@ -80,13 +93,25 @@ func doTufUpdatesRotateOnlineKey(cmd *cobra.Command, args []string) {
 
 	if shouldSign {
 		creds, err := GetOfflineCreds(keysFile)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 
-		curCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
-		newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
-		signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds)
+		curCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+		if err != nil {
+			return err
+		}
+		if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+			return err
+		}
+		if err := signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds); err != nil {
+			return err
+		}
 
 		fmt.Println("= Uploading new TUF root")
-		subcommands.DieNotNil(api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil))
+		if err := api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil); err != nil {
+			return err
+		}
 	}
+	return nil
 }
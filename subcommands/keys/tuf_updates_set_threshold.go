@@ -24,7 +24,7 @@ Signature threshold for the CI TUF Targets is always set to 1.
 Make sure to add enough offline signing keys, and add enough signatures to satisfy the new signature threshold.
 `,
 		Args: cobra.ExactArgs(1),
-		Run:  doTufUpdatesSetThreshold,
+		RunE: doTufUpdatesSetThreshold,
 	}
 	setCmd.Flags().StringP("role", "r", "", "TUF role name, supported: Root, Targets.")
 	_ = setCmd.MarkFlagRequired("role")
@@ -36,27 +36,37 @@ Make sure to add enough offline signing keys, and add enough signatures to satis
 	tufUpdatesCmd.AddCommand(setCmd)
 }
 
-func doTufUpdatesSetThreshold(cmd *cobra.Command, args []string) {
+func doTufUpdatesSetThreshold(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	shouldSign, _ := cmd.Flags().GetBool("sign")
 	keysFile, _ := cmd.Flags().GetString("keys")
 	txid, _ := cmd.Flags().GetString("txid")
 	roleName, _ := cmd.Flags().GetString("role")
-	roleName = ParseTufRoleNameOffline(roleName)
+	roleName, err := ParseTufRoleNameOffline(roleName)
+	if err != nil {
+		return err
+	}
 	threshold, err := strconv.Atoi(args[0])
-	subcommands.DieNotNil(err, fmt.Sprintf("Threshold argument must be an integer: %s", args[0]))
+	if err != nil {
+		return subcommands.UsageError(fmt.Errorf("Threshold argument must be an integer: %s: %w", args[0], err))
+	}
 
 	var minThreshold = map[string]int{tufRoleNameRoot: 1, tufRoleNameTargets: 2}
 	if threshold < minThreshold[roleName] {
-		subcommands.DieNotNil(fmt.Errorf(
-			"Threshold for TUF %s must be greater than %d: %d", roleName, minThreshold[roleName], threshold))
+		return fmt.Errorf(
+			"Threshold for TUF %s must be greater than %d: %d", roleName, minThreshold[roleName], threshold)
 	}
 
 	var updates client.TufRootUpdates
 	updates, err = api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	curCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	curCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 
 	switch roleName {
 	case tufRoleNameRoot:
@@ -64,20 +74,28 @@ func doTufUpdatesSetThreshold(cmd *cobra.Command, args []string) {
 	case tufRoleNameTargets:
 		// For targets, only the production role should be modified
 		if newProdRoot == nil {
-			newProdRoot = genProdTufRoot(newCiRoot)
+			if newProdRoot, err = genProdTufRoot(newCiRoot); err != nil {
+				return err
+			}
 		}
 		newProdRoot.Signed.Roles["targets"].Threshold = threshold
 	default:
 		panic(fmt.Errorf("Unexpected role name: %s", roleName))
 	}
-	newCiRoot, newProdRoot = finalizeTufRootChanges(newCiRoot, newProdRoot)
+	if newCiRoot, newProdRoot, err = finalizeTufRootChanges(newCiRoot, newProdRoot); err != nil {
+		return err
+	}
 
 	if shouldSign {
 		creds, err := GetOfflineCreds(keysFile)
-		subcommands.DieNotNil(err)
-		signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds)
+		if err != nil {
+			return err
+		}
+		if err := signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds); err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("= Uploading new TUF root")
-	subcommands.DieNotNil(api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil))
+	return api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil)
 }
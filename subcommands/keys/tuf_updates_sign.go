@@ -5,8 +5,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
@@ -14,7 +12,7 @@ func init() {
 		Use:   "sign --txid=<txid> --keys=<tuf-root-keys.tgz>",
 		Short: "Sign the staged TUF root for your Factory with the offline root key",
 		Long:  "Sign the staged TUF root for your Factory with the offline root key",
-		Run:   doTufUpdatesSign,
+		RunE:  doTufUpdatesSign,
 	}
 	signCmd.Flags().StringP("txid", "x", "", "TUF root updates transaction ID.")
 	signCmd.Flags().StringP("keys", "k", "", "Path to <tuf-root-keys.tgz> used to sign TUF root.")
@@ -23,25 +21,36 @@ func init() {
 	tufUpdatesCmd.AddCommand(signCmd)
 }
 
-func doTufUpdatesSign(cmd *cobra.Command, args []string) {
+func doTufUpdatesSign(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	keysFile, _ := cmd.Flags().GetString("keys")
 
 	creds, err := GetOfflineCreds(keysFile)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	curCiRoot, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	curCiRoot, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 	if newProdRoot == nil {
 		// User might still want to re-sign and apply updates even if there are no changes.
 		// E.g. this way the user can optimize the latest root.json size after the root key rotation
-		newProdRoot = genProdTufRoot(newCiRoot)
+		if newProdRoot, err = genProdTufRoot(newCiRoot); err != nil {
+			return err
+		}
+	}
+	if err := signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds); err != nil {
+		return err
 	}
-	signNewTufRoot(curCiRoot, newCiRoot, newProdRoot, creds)
 
 	fmt.Println("= Uploading new TUF root")
-	subcommands.DieNotNil(api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil))
+	return api.TufRootUpdatesPut(factory, txid, newCiRoot, newProdRoot, nil, nil)
 }
@@ -26,7 +26,7 @@ There are 3 use cases when this command comes handy:
   and need to sign your production Targets with an additional key.
 - You remove an offline TUF Targets keys
   and need to replace its signatures on production Targets with signatures by another key.`,
-		Run: doTufUpdatesSignProdTargets,
+		RunE: doTufUpdatesSignProdTargets,
 	}
 	signCmd.Flags().StringP("txid", "x", "", "TUF root updates transaction ID.")
 	signCmd.Flags().StringP("keys", "k", "", "Path to <tuf-targets-keys.tgz> used to sign TUF Targets.")
@@ -37,7 +37,7 @@ There are 3 use cases when this command comes handy:
 	tufUpdatesCmd.AddCommand(signCmd)
 }
 
-func doTufUpdatesSignProdTargets(cmd *cobra.Command, args []string) {
+func doTufUpdatesSignProdTargets(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	txid, _ := cmd.Flags().GetString("txid")
 	keysFile, _ := cmd.Flags().GetString("keys")
@@ -52,24 +52,33 @@ func doTufUpdatesSignProdTargets(cmd *cobra.Command, args []string) {
 	}
 
 	creds, err := GetOfflineCreds(keysFile)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	updates, err := api.TufRootUpdatesGet(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	_, newCiRoot, newProdRoot := checkTufRootUpdatesStatus(updates, true)
+	_, newCiRoot, newProdRoot, err := checkTufRootUpdatesStatus(updates, true)
+	if err != nil {
+		return err
+	}
 	if newProdRoot == nil {
-		subcommands.DieNotNil(errors.New(`Please make changes to your Factory TUF root.
-For example, add a new offline TUF Targets key before signing production Targets with it.`))
+		return errors.New(`Please make changes to your Factory TUF root.
+For example, add a new offline TUF Targets key before signing production Targets with it.`)
 	}
 
 	onlineTargetsId := updates.Updated.OnlineKeys["targets"]
 	if onlineTargetsId == "" {
-		subcommands.DieNotNil(errors.New("Unable to find online Target key for Factory"))
+		return errors.New("Unable to find online Target key for Factory")
 	}
 	signer, err := FindOneTufSigner(newCiRoot, creds,
 		subcommands.SliceRemove(newCiRoot.Signed.Roles["targets"].KeyIDs, onlineTargetsId))
-	subcommands.DieNotNil(err, ErrMsgReadingTufKey(tufRoleNameTargets, "current"))
+	if err != nil {
+		return fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameTargets, "current"), err)
+	}
 
 	var newTargetsProdSigs, newTargetsWaveSigs map[string][]tuf.Signature
 
@@ -78,18 +87,29 @@ For example, add a new offline TUF Targets key before signing production Targets
 	// If only wave names or only tags specified - re-sign only what was specified (either wave names or tags).
 	if len(tags) > 0 || len(waveNames) == 0 {
 		targetsProdMap, err := api.ProdTargetsList(factory, true, tags...)
-		subcommands.DieNotNil(err, "Failed to fetch production Targets:")
+		if err != nil {
+			return fmt.Errorf("Failed to fetch production Targets: %w", err)
+		}
 		newTargetsProdSigs, err = signProdTargets(signer, targetsProdMap)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 	}
 	if len(waveNames) > 0 || len(tags) == 0 {
 		targetsWaveMap, err := api.WaveTargetsList(factory, true, waveNames...)
-		subcommands.DieNotNil(err, "Failed to fetch production Wave Targets:")
+		if err != nil {
+			return fmt.Errorf("Failed to fetch production Wave Targets: %w", err)
+		}
 		newTargetsWaveSigs, err = signProdTargets(signer, targetsWaveMap)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 	}
 
 	fmt.Println("= Uploading new signatures")
-	subcommands.DieNotNil(api.TufRootUpdatesPut(
-		factory, txid, newCiRoot, newProdRoot, newTargetsProdSigs, newTargetsWaveSigs))
+	if err := api.TufRootUpdatesPut(
+		factory, txid, newCiRoot, newProdRoot, newTargetsProdSigs, newTargetsWaveSigs); err != nil {
+		return err
+	}
+	return nil
 }
@@ -46,56 +46,66 @@ func ErrMsgReadingTufKey(role, treat string) string {
 	return fmt.Sprintf("Error reading %s TUF %s private key from specified file:\n", treat, role)
 }
 
-func ParseTufKeyType(s string) TufKeyType {
+func ParseTufKeyType(s string) (TufKeyType, error) {
 	t, err := parseTufKeyType(s)
-	subcommands.DieNotNil(err)
-	return t
+	return t, subcommands.UsageError(err)
 }
 
-func ParseTufRoleNameOffline(s string) string {
+func ParseTufRoleNameOffline(s string) (string, error) {
 	r, err := parseTufRoleName(s, tufRoleNameRoot, tufRoleNameTargets)
-	subcommands.DieNotNil(err)
-	return r
+	return r, subcommands.UsageError(err)
 }
 
-func ParseTufRoleNameOnline(s string) string {
+func ParseTufRoleNameOnline(s string) (string, error) {
 	r, err := parseTufRoleName(s, tufRoleNameTargets, tufRoleNameSnapshot, tufRoleNameTimestamp)
-	subcommands.DieNotNil(err)
-	return r
+	return r, subcommands.UsageError(err)
 }
 
-func genTufKeyId(key crypto.Signer) string {
+func genTufKeyId(key crypto.Signer) (string, error) {
 	// # This has to match the exact logic used by ota-tuf (required by garage-sign):
 	// https://github.com/foundriesio/ota-tuf/blob/fio-changes/libtuf/src/main/scala/com/advancedtelematic/libtuf/crypt/TufCrypto.scala#L66-L71
 	// It sets a keyid to a signature of the key's canonical DER encoding (same logic for all keys).
 	// Note: this differs from the TUF spec, need to change once we deprecate the garage-sign.
 	pubBytes, err := x509.MarshalPKIXPublicKey(key.Public())
-	subcommands.DieNotNil(err)
-	return fmt.Sprintf("%x", sha256.Sum256(pubBytes))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(pubBytes)), nil
 }
 
-func genTufKeyPair(keyType TufKeyType) TufKeyPair {
+func genTufKeyPair(keyType TufKeyType) (TufKeyPair, error) {
 	keyTypeName := keyType.Name()
 	pk, err := keyType.GenerateKey()
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return TufKeyPair{}, err
+	}
 	privKey, pubKey, err := keyType.SaveKeyPair(pk)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return TufKeyPair{}, err
+	}
 
 	priv := client.AtsKey{
 		KeyType:  keyTypeName,
 		KeyValue: client.AtsKeyVal{Private: privKey},
 	}
 	atsPrivBytes, err := json.Marshal(priv)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return TufKeyPair{}, err
+	}
 
 	pub := client.AtsKey{
 		KeyType:  keyTypeName,
 		KeyValue: client.AtsKeyVal{Public: pubKey},
 	}
 	atsPubBytes, err := json.Marshal(pub)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return TufKeyPair{}, err
+	}
 
-	id := genTufKeyId(pk)
+	id, err := genTufKeyId(pk)
+	if err != nil {
+		return TufKeyPair{}, err
+	}
 
 	return TufKeyPair{
 		atsPriv:      priv,
@@ -107,7 +117,7 @@ func genTufKeyPair(keyType TufKeyType) TufKeyPair {
 			Type: keyType,
 			Key:  pk,
 		},
-	}
+	}, nil
 }
 
 func SignTufMeta(metaBytes []byte, signers ...TufSigner) ([]tuf.Signature, error) {
@@ -161,39 +171,45 @@ func signTufRoot(root *client.AtsTufRoot, signers ...TufSigner) error {
 	return nil
 }
 
-func saveTufCreds(path string, creds OfflineCreds) {
+func saveTufCreds(path string, creds OfflineCreds) error {
 	file, err := os.Create(path)
-	subcommands.DieNotNil(err)
-	defer file.Close()
+	if err != nil {
+		return err
+	}
 
 	gzipWriter := gzip.NewWriter(file)
-	defer gzipWriter.Close()
-
 	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
-
 	for name, val := range creds {
 		header := &tar.Header{
 			Name: name,
 			Size: int64(len(val)),
 		}
-		subcommands.DieNotNil(tarWriter.WriteHeader(header))
-		_, err := tarWriter.Write(val)
-		subcommands.DieNotNil(err)
+		if err = tarWriter.WriteHeader(header); err != nil {
+			break
+		}
+		if _, err = tarWriter.Write(val); err != nil {
+			break
+		}
 	}
+	// Each writer flushes its data on close, so all close errors matter
+	for _, closer := range []io.Closer{tarWriter, gzipWriter, file} {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
 
-func saveTempTufCreds(credsFile string, creds OfflineCreds) string {
+func saveTempTufCreds(credsFile string, creds OfflineCreds) (string, error) {
 	path := credsFile + ".tmp"
 	if _, err := os.Stat(path); err == nil {
-		subcommands.DieNotNil(fmt.Errorf(`Backup file exists: %s
+		return "", fmt.Errorf(`Backup file exists: %s
 This file may be from a previous failed key rotation and include critical data.
 Please move this file somewhere safe before re-running this command.`,
 			path,
-		))
+		)
 	}
-	saveTufCreds(path, creds)
-	return path
+	return path, saveTufCreds(path, creds)
 }
 
 func GetOfflineCreds(credsFile string) (OfflineCreds, error) {
@@ -321,7 +337,7 @@ func findTufSigners(root *client.AtsTufRoot, creds OfflineCreds, keyids []string
 
 func addOfflineTufKey(
 	root *client.AtsTufRoot, role tuf.RoleName, key TufKeyPair, oldKids []string, creds OfflineCreds,
-) {
+) error {
 	base := fmt.Sprintf("tufrepo/keys/fioctl-%s-%s", role, key.signer.Id)
 	creds[base+".pub"] = key.atsPubBytes
 	creds[base+".sec"] = key.atsPrivBytes
@@ -330,7 +346,9 @@ func addOfflineTufKey(
 
 	factory := viper.GetString("factory")
 	user, err := api.UserAccessDetails(factory, "self")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if root.Signed.KeyOwners == nil {
 		root.Signed.KeyOwners = make(map[string]client.RootKeyOwner)
 	}
@@ -338,6 +356,7 @@ func addOfflineTufKey(
 		PolisId:   user.PolisId,
 		CreatedAt: time.Unix(time.Now().Unix(), 0).UTC(), // Strip millis
 	}
+	return nil
 }
 
 func removeUnusedTufKeys(root *client.AtsTufRoot) {
@@ -364,84 +383,101 @@ func removeUnusedTufKeys(root *client.AtsTufRoot) {
 }
 
 func checkTufRootUpdatesStatus(updates client.TufRootUpdates, forUpdate bool) (
-	curCiRoot, newCiRoot, newProdRoot *client.AtsTufRoot,
+	curCiRoot, newCiRoot, newProdRoot *client.AtsTufRoot, err error,
 ) {
 	switch updates.Status {
 	case client.TufRootUpdatesStatusNone:
 		if forUpdate {
-			subcommands.DieNotNil(errors.New(`There are no TUF root updates in progress.
-Please, run 'fioctl keys tuf updates init' to start over.`))
+			err = errors.New(`There are no TUF root updates in progress.
+Please, run 'fioctl keys tuf updates init' to start over.`)
+			return
 		}
 	case client.TufRootUpdatesStatusStarted:
 		break
 	case client.TufRootUpdatesStatusApplying:
 		if forUpdate {
-			subcommands.DieNotNil(errors.New(
+			err = errors.New(
 				"No modifications to TUF root updates allowed while they are being applied.",
-			))
+			)
+			return
 		}
 	default:
-		subcommands.DieNotNil(fmt.Errorf("Unexpected TUF root updates status: %s", updates.Status))
+		err = fmt.Errorf("Unexpected TUF root updates status: %s", updates.Status)
+		return
 	}
 
 	if updates.Current != nil && updates.Current.CiRoot != "" {
-		subcommands.DieNotNil(
-			json.Unmarshal([]byte(updates.Current.CiRoot), &curCiRoot), "Current CI root",
-		)
+		if err = json.Unmarshal([]byte(updates.Current.CiRoot), &curCiRoot); err != nil {
+			err = fmt.Errorf("Current CI root: %w", err)
+			return
+		}
 	}
 	if curCiRoot == nil {
-		subcommands.DieNotNil(errors.New("Current TUF CI root not set. Please, report a bug."))
+		err = errors.New("Current TUF CI root not set. Please, report a bug.")
+		return
 	}
 	if updates.Updated != nil {
 		if updates.Updated.CiRoot != "" {
-			subcommands.DieNotNil(
-				json.Unmarshal([]byte(updates.Updated.CiRoot), &newCiRoot), "Updated CI root",
-			)
+			if err = json.Unmarshal([]byte(updates.Updated.CiRoot), &newCiRoot); err != nil {
+				err = fmt.Errorf("Updated CI root: %w", err)
+				return
+			}
 		}
 		if updates.Updated.ProdRoot != "" {
-			subcommands.DieNotNil(
-				json.Unmarshal([]byte(updates.Updated.ProdRoot), &newProdRoot), "Updated prod root",
-			)
+			if err = json.Unmarshal([]byte(updates.Updated.ProdRoot), &newProdRoot); err != nil {
+				err = fmt.Errorf("Updated prod root: %w", err)
+				return
+			}
 		}
 	}
 	if newCiRoot == nil && updates.Status != client.TufRootUpdatesStatusNone {
-		subcommands.DieNotNil(errors.New("Updated TUF CI root not set. Please, report a bug."))
+		err = errors.New("Updated TUF CI root not set. Please, report a bug.")
 	}
 	return
 }
 
-func genProdTufRoot(ciRoot *client.AtsTufRoot) (prodRoot *client.AtsTufRoot) {
+func genProdTufRoot(ciRoot *client.AtsTufRoot) (prodRoot *client.AtsTufRoot, err error) {
 	// Deep copy in Golang is hard; use the marshal-unmarshal trick
 	body, err := json.Marshal(ciRoot)
-	subcommands.DieNotNil(err)
-	subcommands.DieNotNil(json.Unmarshal(body, &prodRoot))
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(body, &prodRoot); err != nil {
+		return nil, err
+	}
 	prodRoot.Signed.Roles["targets"].Threshold = 2
 	return
 }
 
-func finalizeTufRootChanges(ciRoot, prodRoot *client.AtsTufRoot) (newCiRoot, newProdRoot *client.AtsTufRoot) {
+func finalizeTufRootChanges(ciRoot, prodRoot *client.AtsTufRoot) (newCiRoot, newProdRoot *client.AtsTufRoot, err error) {
 	// This function must be called after any changes to the TUF root signed body
 	newCiRoot = ciRoot
 	newCiRoot.Signatures = make([]tuf.Signature, 0)
 	removeUnusedTufKeys(newCiRoot)
-	newProdRoot = genProdTufRoot(newCiRoot)
+	if newProdRoot, err = genProdTufRoot(newCiRoot); err != nil {
+		return
+	}
 	if prodRoot != nil {
 		newProdRoot.Signed.Roles["targets"].Threshold = prodRoot.Signed.Roles["targets"].Threshold
 	}
 	return
 }
 
-func signNewTufRoot(curCiRoot, newCiRoot, newProdRoot *client.AtsTufRoot, creds OfflineCreds) {
+func signNewTufRoot(curCiRoot, newCiRoot, newProdRoot *client.AtsTufRoot, creds OfflineCreds) error {
 	// Find new and old keys that match; at least one of them must be found.
 	signers := make([]TufSigner, 0, 2)
 	newKey, newErr := FindOneTufSigner(newCiRoot, creds, newCiRoot.Signed.Roles["root"].KeyIDs)
 	if !errors.Is(newErr, errFoundNoKey) {
-		subcommands.DieNotNil(newErr, ErrMsgReadingTufKey(tufRoleNameRoot, "new"))
+		if newErr != nil {
+			return fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameRoot, "new"), newErr)
+		}
 		signers = append(signers, newKey)
 	}
 	oldKey, oldErr := FindOneTufSigner(curCiRoot, creds, curCiRoot.Signed.Roles["root"].KeyIDs)
 	if !errors.Is(oldErr, errFoundNoKey) {
-		subcommands.DieNotNil(oldErr, ErrMsgReadingTufKey(tufRoleNameRoot, "current"))
+		if oldErr != nil {
+			return fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameRoot, "current"), oldErr)
+		}
 		if len(signers) == 0 || oldKey.Id != newKey.Id {
 			signers = append(signers, oldKey)
 		}
@@ -450,16 +486,18 @@ func signNewTufRoot(curCiRoot, newCiRoot, newProdRoot *client.AtsTufRoot, creds
 	// At this point either oldKey or newKey was found, or both newErr and oldErr are errFoundNoKey
 	if len(signers) == 0 {
 		if oldErr.Error() == newErr.Error() { // TUF root key is not being rotated
-			subcommands.DieNotNil(oldErr, ErrMsgReadingTufKey(tufRoleNameRoot, "current"))
+			return fmt.Errorf("%s%w", ErrMsgReadingTufKey(tufRoleNameRoot, "current"), oldErr)
 		} else { // TUF root key is being rotated
-			subcommands.DieNotNil(fmt.Errorf(
-				"%s %s\n %s", ErrMsgReadingTufKey(tufRoleNameRoot, "current and new"), oldErr, newErr))
+			return fmt.Errorf(
+				"%s%s\n %s", ErrMsgReadingTufKey(tufRoleNameRoot, "current and new"), oldErr, newErr)
 		}
 	}
 
 	fmt.Println("= Signing new TUF root")
-	subcommands.DieNotNil(signTufRoot(newCiRoot, signers...))
-	subcommands.DieNotNil(signTufRoot(newProdRoot, signers...))
+	if err := signTufRoot(newCiRoot, signers...); err != nil {
+		return err
+	}
+	return signTufRoot(newProdRoot, signers...)
 }
 
 func signProdTargets(
@@ -2,6 +2,7 @@ package login
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -19,31 +20,40 @@ var (
 	refreshToken bool
 	authURL      string
 	insecure     bool
+	credsStore   string
 )
 
 func NewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Access Foundries.io services with your client credentials",
-		Run:   doLogin,
+		RunE:  doLogin,
 	}
 	cmd.Flags().BoolVarP(&refreshToken, "refresh-access-token", "", false, "Refresh your current oauth2 access token. This is used when a token's scopes have been updated in app.foundries.io")
 	cmd.Flags().StringVarP(&authURL, "oauth-url", "", client.OauthURL, "OAuth URL to authenticate with")
 	cmd.Flags().BoolVarP(&insecure, "insecure-ssl", "", false, "Ignore TLS certificates from API servers.")
 	_ = cmd.Flags().MarkHidden("insecure-ssl")
+	cmd.Flags().StringVarP(&credsStore, "credentials-store", "", "",
+		fmt.Sprintf("Where to keep client secrets and tokens: %s, %s, or %s. Default is the Secret Service where available, and an encrypted file otherwise.",
+			subcommands.CredentialStoreSecretService, subcommands.CredentialStoreFile, subcommands.CredentialStorePlaintext))
 	return cmd
 }
 
-func doLogin(cmd *cobra.Command, args []string) {
+func doLogin(cmd *cobra.Command, args []string) error {
 	logrus.Debug("Executing login command")
+	if len(credsStore) > 0 {
+		viper.Set("credentials.store", credsStore)
+	}
+	if err := subcommands.LoadCredentials(); err != nil {
+		return err
+	}
 
 	if refreshToken {
 		creds := client.NewClientCredentials(subcommands.Config.ClientCredentials)
 		// Change ExpiresIn to basically "now". This will cause fioctl to
 		// get a new token with fresh scopes
 		creds.Config.ExpiresIn = 1
-		subcommands.SaveOauthConfig(creds.Config)
-		return
+		return subcommands.SaveOauthConfig(creds.Config)
 	}
 	if !cmd.Flag("oauth-url").Changed {
 		authURL = subcommands.Config.ClientCredentials.URL
@@ -52,7 +62,9 @@ func doLogin(cmd *cobra.Command, args []string) {
 		authURL = client.OauthURL
 	}
 	u, err := url.Parse(authURL)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return subcommands.UsageError(err)
+	}
 	subcommands.Config.ClientCredentials.URL = authURL
 
 	if authURL != client.OauthURL {
@@ -62,9 +74,13 @@ func doLogin(cmd *cobra.Command, args []string) {
 	}
 
 	creds := client.NewClientCredentials(subcommands.Config.ClientCredentials)
+	creds.SetContext(cmd.Context())
+	creds.SetTracer(subcommands.Config.Tracer)
 	if creds.Config.ClientId == "" || creds.Config.ClientSecret == "" {
 		credsUrl := fmt.Sprintf("https://%s/settings/credentials/", u.Host)
-		creds.Config.ClientId, creds.Config.ClientSecret = promptForCreds(credsUrl)
+		if creds.Config.ClientId, creds.Config.ClientSecret, err = promptForCreds(credsUrl); err != nil {
+			return err
+		}
 	}
 
 	if insecure {
@@ -74,27 +90,41 @@ func doLogin(cmd *cobra.Command, args []string) {
 	}
 
 	if creds.Config.ClientId == "" || creds.Config.ClientSecret == "" {
-		fmt.Println("Cannot execute login without client ID or client secret.")
-		os.Exit(1)
+		return subcommands.AuthError(errors.New("Cannot execute login without client ID or client secret."))
 	}
 
 	expired, err := creds.IsExpired()
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if expired && creds.HasRefreshToken() {
-		subcommands.DieNotNil(creds.Refresh())
+		err = creds.Refresh()
 	} else if creds.Config.AccessToken == "" {
-		subcommands.DieNotNil(creds.Get())
+		err = creds.Get()
 	} else {
 		fmt.Println("You are already logged in to Foundries.io services.")
-		os.Exit(0)
+		if !subcommands.HasPlaintextCredentials() && len(credsStore) == 0 {
+			return nil
+		}
+		if err = subcommands.MigrateOauthConfig(creds.Config); err != nil {
+			return err
+		}
+		fmt.Printf("Your credentials are now kept in the %s credential store.\n", viper.GetString("credentials.store"))
+		return nil
+	}
+	if err != nil {
+		return subcommands.AuthError(err)
 	}
 
-	subcommands.SaveOauthConfig(creds.Config)
+	if err = subcommands.MigrateOauthConfig(creds.Config); err != nil {
+		return err
+	}
 	fmt.Println("You are now logged in to Foundries.io services.")
+	return nil
 }
 
-func promptForCreds(credsUrl string) (string, string) {
+func promptForCreds(credsUrl string) (string, string, error) {
 	logrus.Debug("Reading client ID/secret from stdin")
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -111,9 +141,8 @@ func promptForCreds(credsUrl string) (string, string) {
 	clientSecret := strings.Trim(scanner.Text(), " ")
 
 	if clientId == "" || clientSecret == "" {
-		fmt.Println("Client ID and client credentials are both required.")
-		os.Exit(1)
+		return "", "", subcommands.AuthError(errors.New("Client ID and client credentials are both required."))
 	}
 
-	return clientId, clientSecret
+	return clientId, clientSecret, nil
 }
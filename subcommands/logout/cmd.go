@@ -12,17 +12,20 @@ func NewCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "logout",
 		Short: "Remove Foundries.io client credentials from system",
-		Run:   doLogout,
+		RunE:  doLogout,
 	}
 }
 
-func doLogout(cmd *cobra.Command, args []string) {
+func doLogout(cmd *cobra.Command, args []string) error {
 	logrus.Debug("Executing logout command")
+	if err := subcommands.WipeCredentials(); err != nil {
+		return err
+	}
 
 	creds := client.NewClientCredentials(subcommands.Config.ClientCredentials)
 	creds.Config.ClientId = ""
 	creds.Config.ClientSecret = ""
 	creds.Config.RefreshToken = ""
 	creds.Config.AccessToken = ""
-	subcommands.SaveOauthConfig(creds.Config)
+	return subcommands.SaveOauthConfig(creds.Config)
 }
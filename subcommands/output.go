@@ -0,0 +1,321 @@
+package subcommands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted by the global --output flag
+const (
+	OutputTable    = "table"
+	OutputJson     = "json"
+	OutputYaml     = "yaml"
+	OutputCsv      = "csv"
+	OutputNdjson   = "ndjson"
+	OutputTemplate = "template="
+)
+
+// OutputFormat is set by the global --output flag
+var OutputFormat = OutputTable
+
+var outputTemplate *template.Template
+
+var outputFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join": strings.Join,
+}
+
+// ValidateOutputFormat checks the global --output flag, and prepares a template if one is given.
+func ValidateOutputFormat() error {
+	switch OutputFormat {
+	case OutputTable, OutputJson, OutputYaml, OutputCsv, OutputNdjson:
+		return nil
+	}
+	if text, ok := strings.CutPrefix(OutputFormat, OutputTemplate); ok {
+		var err error
+		outputTemplate, err = template.New("output").Funcs(outputFuncs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("Invalid output template: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("Invalid output format: %s. Allowed values: table, json, yaml, csv, ndjson, template=<go-template>",
+		OutputFormat)
+}
+
+// IsTableOutput returns true if a command should print its usual human readable output.
+// Otherwise, a command should print its result using an Output.
+func IsTableOutput() bool {
+	return OutputFormat == OutputTable
+}
+
+// IsTabularOutput returns true if the rows added to an Output are printed (the table and csv formats).
+func IsTabularOutput() bool {
+	return OutputFormat == OutputTable || OutputFormat == OutputCsv
+}
+
+// Output prints a result of a command in the format selected by the global --output flag.
+// The table and csv formats print the rows added by AddLine, while the json, yaml, ndjson, and template formats
+// print the data - usually a client struct or a list of them, so that scripts can rely on its fields.
+type Output struct {
+	header []string
+	rows   [][]string
+	data   any
+}
+
+func NewOutput(data any, header ...string) *Output {
+	return &Output{header: header, data: data}
+}
+
+func (o *Output) AddLine(columns ...any) {
+	o.rows = append(o.rows, formatRow(columns))
+}
+
+func formatRow(columns []any) []string {
+	row := make([]string, len(columns))
+	for idx, col := range columns {
+		row[idx] = fmt.Sprint(col)
+	}
+	return row
+}
+
+// SetData replaces the data printed by the structured formats, e.g. once all pages are collected.
+func (o *Output) SetData(data any) {
+	o.data = data
+}
+
+func (o *Output) Print() error {
+	switch {
+	case OutputFormat == OutputTable:
+		var header []any
+		for _, col := range o.header {
+			header = append(header, col)
+		}
+		t := Tabby(0, header...)
+		for _, row := range o.rows {
+			line := make([]any, len(row))
+			for idx, col := range row {
+				line[idx] = col
+			}
+			t.AddLine(line...)
+		}
+		t.Print()
+		return nil
+	case OutputFormat == OutputCsv:
+		if len(o.header) == 0 {
+			return errors.New("The csv output format is not supported by this command, use json or yaml")
+		}
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(o.header)
+		_ = w.WriteAll(o.rows)
+		return w.Error()
+	case OutputFormat == OutputJson:
+		b, err := json.MarshalIndent(o.data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Println(string(b))
+		return err
+	case OutputFormat == OutputYaml:
+		b, err := marshalYaml(o.data)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(b)
+		return err
+	case OutputFormat == OutputNdjson:
+		// A JSON document per line: each item of a list, or the data itself
+		enc := json.NewEncoder(os.Stdout)
+		if v := reflect.ValueOf(o.data); v.Kind() == reflect.Slice {
+			for i := range v.Len() {
+				if err := enc.Encode(v.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return enc.Encode(o.data)
+	case outputTemplate != nil:
+		v, err := templateData(o.data)
+		if err != nil {
+			return err
+		}
+		return outputTemplate.Execute(os.Stdout, v)
+	}
+	return fmt.Errorf("Invalid output format: %s", OutputFormat)
+}
+
+// PrintIter prints a list produced by an iterator (e.g. all pages of an API list) in the output format.
+// Items are printed as they are produced, so that long lists are never held in memory; only the table
+// format collects all rows first, as it needs the width of each column.
+// A row function returns the columns of an item for the table and csv formats.
+func PrintIter[T any](items iter.Seq2[T, error], row func(T) []any, header ...string) error {
+	switch {
+	case OutputFormat == OutputTable:
+		out := NewOutput(nil, header...)
+		for item, err := range items {
+			if err != nil {
+				return err
+			}
+			out.AddLine(row(item)...)
+		}
+		return out.Print()
+	case OutputFormat == OutputCsv:
+		if len(header) == 0 {
+			return errors.New("The csv output format is not supported by this command, use json or yaml")
+		}
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(header)
+		for item, err := range items {
+			if err != nil {
+				w.Flush()
+				return err
+			}
+			_ = w.Write(formatRow(row(item)))
+			// Let a reader see each row as soon as it is fetched
+			w.Flush()
+		}
+		w.Flush()
+		return w.Error()
+	case OutputFormat == OutputJson:
+		// The same document as json.MarshalIndent produces for a whole list
+		count := 0
+		for item, err := range items {
+			if err != nil {
+				return err
+			}
+			b, err := json.MarshalIndent(item, "  ", "  ")
+			if err != nil {
+				return err
+			}
+			sep := ",\n  "
+			if count == 0 {
+				sep = "[\n  "
+			}
+			if _, err = fmt.Print(sep, string(b)); err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 {
+			_, err := fmt.Println("[]")
+			return err
+		}
+		_, err := fmt.Print("\n]\n")
+		return err
+	case OutputFormat == OutputYaml:
+		// Sequences of one item each add up to a sequence of all items
+		count := 0
+		for item, err := range items {
+			if err != nil {
+				return err
+			}
+			b, err := marshalYaml([]T{item})
+			if err != nil {
+				return err
+			}
+			if _, err = os.Stdout.Write(b); err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 {
+			_, err := fmt.Println("[]")
+			return err
+		}
+		return nil
+	case OutputFormat == OutputNdjson:
+		enc := json.NewEncoder(os.Stdout)
+		for item, err := range items {
+			if err != nil {
+				return err
+			}
+			if err = enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputTemplate != nil:
+		// A template ranges over the items as they are produced, rather than over a list
+		var iterErr error
+		seq := func(yield func(any) bool) {
+			for item, err := range items {
+				if err != nil {
+					iterErr = err
+					return
+				}
+				v, err := templateData(item)
+				if err != nil {
+					iterErr = err
+					return
+				}
+				if !yield(v) {
+					return
+				}
+			}
+		}
+		err := outputTemplate.Execute(os.Stdout, iter.Seq[any](seq))
+		if iterErr != nil {
+			return iterErr
+		}
+		return err
+	}
+	return fmt.Errorf("Invalid output format: %s", OutputFormat)
+}
+
+// Let a template see the same field names as the json and yaml formats
+func templateData(data any) (any, error) {
+	var v any
+	b, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(b, &v)
+	}
+	return v, err
+}
+
+// PrintData prints the data of a command having no tabular form in a structured format.
+func PrintData(data any) error {
+	return NewOutput(data).Print()
+}
+
+// Client structs only have json tags, so a yaml is produced from their json representation.
+// A json document is a valid yaml document, and a yaml.Node keeps the order of its fields.
+func marshalYaml(data any) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	resetYamlStyle(&node)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	err = enc.Close()
+	return buf.Bytes(), err
+}
+
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
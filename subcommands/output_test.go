@@ -0,0 +1,97 @@
+package subcommands
+
+import (
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Yields items, and then an error if it is given
+func testItems(items []testItem, err error) iter.Seq2[testItem, error] {
+	return func(yield func(testItem, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(testItem{}, err)
+		}
+	}
+}
+
+func TestPrintIter(t *testing.T) {
+	origFormat := OutputFormat
+	defer func() { OutputFormat = origFormat }()
+	row := func(item testItem) []any { return []any{item.Name, item.Count} }
+
+	for _, items := range [][]testItem{
+		{},
+		{{"a", 1}},
+		{{"a", 1}, {"b", 2}, {"c", 3}},
+	} {
+		for _, format := range []string{
+			OutputTable, OutputJson, OutputYaml, OutputCsv, OutputNdjson,
+			`template={{range .}}{{.name}}={{.count}};{{end}}`,
+		} {
+			OutputFormat = format
+			require.Nil(t, ValidateOutputFormat(), format)
+			// Items are printed the same way as a list of them
+			out := NewOutput(items, "NAME", "COUNT")
+			for _, item := range items {
+				out.AddLine(row(item)...)
+			}
+			expected := captureStdout(t, func() { require.Nil(t, out.Print(), format) })
+			var err error
+			printed := captureStdout(t, func() { err = PrintIter(testItems(items, nil), row, "NAME", "COUNT") })
+			require.Nil(t, err, format)
+			assert.Equal(t, expected, printed, format)
+		}
+	}
+
+	// Items printed before an error are kept, and the error is returned
+	failure := errors.New("page 2 failed")
+	for _, tc := range []struct {
+		format  string
+		printed string
+	}{
+		{OutputCsv, "NAME,COUNT\na,1\n"},
+		{OutputNdjson, `{"name":"a","count":1}` + "\n"},
+		{`template={{range .}}{{.name}};{{end}}`, "a;"},
+	} {
+		OutputFormat = tc.format
+		require.Nil(t, ValidateOutputFormat(), tc.format)
+		var err error
+		printed := captureStdout(t, func() {
+			err = PrintIter(testItems([]testItem{{"a", 1}}, failure), row, "NAME", "COUNT")
+		})
+		assert.Equal(t, failure, err, tc.format)
+		assert.Equal(t, tc.printed, printed, tc.format)
+	}
+
+	// A template may stop early, e.g. to print the first item only
+	OutputFormat = `template={{range .}}{{.name}}{{break}}{{end}}`
+	require.Nil(t, ValidateOutputFormat())
+	var consumed []string
+	items := func(yield func(testItem, error) bool) {
+		for _, name := range []string{"a", "b", "c"} {
+			consumed = append(consumed, name)
+			if !yield(testItem{Name: name}, nil) {
+				return
+			}
+		}
+	}
+	var err error
+	printed := captureStdout(t, func() { err = PrintIter(items, row) })
+	require.Nil(t, err)
+	assert.Equal(t, "a", printed)
+	assert.Equal(t, []string{"a"}, consumed)
+}
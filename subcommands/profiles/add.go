@@ -0,0 +1,86 @@
+package profiles
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+var (
+	addFactory   string
+	addServerUrl string
+	addCaCert    string
+	addUse       bool
+)
+
+// Config file keys are case insensitive, so only lower case names are allowed
+var validProfileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+func init() {
+	addCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a new profile",
+		Long: `Add a new profile to the config file.
+
+Settings not given to this command are inherited from the top level of the config file.
+Run "fioctl --profile <name> login" afterwards to configure credentials of the new profile.`,
+		Example: `
+  # Add a profile for an on-premise API server, and make it the current one
+  fioctl profiles add onprem --factory my-factory --server-url https://api.example.com \
+    --ca-cert /etc/ssl/example-ca.pem --use
+  fioctl login`,
+		Args: cobra.ExactArgs(1),
+		RunE: doAdd,
+	}
+	cmd.AddCommand(addCmd)
+	addCmd.Flags().StringVarP(&addFactory, "factory", "f", "", "A default factory of this profile")
+	addCmd.Flags().StringVarP(&addServerUrl, "server-url", "", "", "An API server URL of this profile")
+	addCmd.Flags().StringVarP(&addCaCert, "ca-cert", "", "",
+		"A PEM bundle of CA certificates trusted by this profile in addition to the system ones")
+	addCmd.Flags().BoolVarP(&addUse, "use", "", false, "Make the new profile the current one")
+}
+
+func doAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !validProfileName.MatchString(name) {
+		return subcommands.UsageError(fmt.Errorf(
+			"Invalid profile name: %s. It must only contain lower case letters, digits, and '.-_' characters", name))
+	}
+	cfg, err := subcommands.LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	if cfg.Profile(name) != nil {
+		return subcommands.WithExitCode(fmt.Errorf("Profile already exists: %s", name), subcommands.ExitConflict)
+	}
+
+	settings := make(map[string]interface{})
+	if len(addFactory) > 0 {
+		settings["factory"] = addFactory
+	}
+	server := make(map[string]interface{})
+	if len(addServerUrl) > 0 {
+		server["url"] = addServerUrl
+	}
+	if len(addCaCert) > 0 {
+		if server["ca_cert"], err = filepath.Abs(addCaCert); err != nil {
+			return err
+		}
+	}
+	if len(server) > 0 {
+		settings["server"] = server
+	}
+	cfg.SetProfile(name, settings)
+	if addUse {
+		cfg.Values[subcommands.CurrentProfileKey] = name
+	}
+	if err = cfg.Save(); err != nil {
+		return err
+	}
+	fmt.Println("Added profile:", name)
+	return nil
+}
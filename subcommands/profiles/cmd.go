@@ -0,0 +1,32 @@
+package profiles
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage named profiles of the fioctl config file",
+	Long: `Manage named profiles of the fioctl config file.
+
+A profile keeps its own settings, such as a factory, a server URL, a CA bundle, and client credentials.
+The settings of the active profile override the top level settings of the config file:
+
+  current_profile: dev
+  profiles:
+    dev:
+      factory: my-dev-factory
+    onprem:
+      factory: my-factory
+      server:
+        url: https://api.example.com
+        ca_cert: /etc/ssl/example-ca.pem
+
+A profile is selected by the --profile flag, the FIOCTL_PROFILE environment variable,
+or the current_profile of the config file, in this order.
+A login or a token refresh only updates the credentials of the active profile.`,
+}
+
+func NewCommand() *cobra.Command {
+	return cmd
+}
@@ -0,0 +1,50 @@
+package profiles
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+type profileInfo struct {
+	Name    string `json:"name"`
+	Active  bool   `json:"active"`
+	Factory string `json:"factory,omitempty"`
+	Server  string `json:"server,omitempty"`
+}
+
+func init() {
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List profiles of the config file",
+		Args:  cobra.NoArgs,
+		RunE:  doList,
+	})
+}
+
+func doList(cmd *cobra.Command, args []string) error {
+	cfg, err := subcommands.LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	profiles := []profileInfo{}
+	for _, name := range cfg.Profiles() {
+		settings := cfg.Profile(name)
+		info := profileInfo{Name: name, Active: name == subcommands.ProfileName}
+		info.Factory, _ = settings["factory"].(string)
+		if server, ok := settings["server"].(map[string]interface{}); ok {
+			info.Server, _ = server["url"].(string)
+		}
+		profiles = append(profiles, info)
+	}
+
+	out := subcommands.NewOutput(profiles, "ACTIVE", "NAME", "FACTORY", "SERVER")
+	for _, p := range profiles {
+		active := ""
+		if p.Active {
+			active = "*"
+		}
+		out.AddLine(active, p.Name, p.Factory, p.Server)
+	}
+	return out.Print()
+}
@@ -0,0 +1,35 @@
+package profiles
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func init() {
+	cmd.AddCommand(&cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove a profile including its credentials",
+		Args:  cobra.ExactArgs(1),
+		RunE:  doRemove,
+	})
+}
+
+func doRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := subcommands.LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	if cfg.Profile(name) == nil {
+		return subcommands.ProfileNotFoundError(name)
+	}
+	cfg.DeleteProfile(name)
+	if err = cfg.Save(); err != nil {
+		return err
+	}
+	fmt.Println("Removed profile:", name)
+	return nil
+}
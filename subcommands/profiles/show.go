@@ -0,0 +1,64 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+// Credentials are never shown, only whether they are set
+var secretKeys = []string{"client_secret", "access_token", "refresh_token"}
+
+func init() {
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show [<name>]",
+		Short: "Show settings of a profile",
+		Long:  "Show settings of a profile, or of the active profile if no name is given. Secrets are redacted.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  doShow,
+	})
+}
+
+func doShow(cmd *cobra.Command, args []string) error {
+	name := subcommands.ProfileName
+	if len(args) == 1 {
+		name = args[0]
+	}
+	if len(name) == 0 {
+		return errors.New("No profile is active. Specify a profile name.")
+	}
+	cfg, err := subcommands.LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Profile(name)
+	if settings == nil {
+		return subcommands.ProfileNotFoundError(name)
+	}
+	if creds, ok := settings["clientcredentials"].(map[string]interface{}); ok {
+		for _, key := range secretKeys {
+			if val, ok := creds[key].(string); ok && len(val) > 0 {
+				creds[key] = "<redacted>"
+			}
+		}
+	}
+
+	if !subcommands.IsTableOutput() {
+		return subcommands.PrintData(settings)
+	}
+	fmt.Println("Name:", name)
+	if len(settings) == 0 {
+		return nil
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err = enc.Encode(settings); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,36 @@
+package profiles
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/foundriesio/fioctl/subcommands"
+)
+
+func init() {
+	cmd.AddCommand(&cobra.Command{
+		Use:   "use <name>",
+		Short: "Make a profile the current one",
+		Long:  "Make a profile the current one, so that it is used when neither --profile nor FIOCTL_PROFILE is set.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  doUse,
+	})
+}
+
+func doUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := subcommands.LoadConfigFile()
+	if err != nil {
+		return err
+	}
+	if cfg.Profile(name) == nil {
+		return subcommands.ProfileNotFoundError(name)
+	}
+	cfg.Values[subcommands.CurrentProfileKey] = name
+	if err = cfg.Save(); err != nil {
+		return err
+	}
+	fmt.Println("Switched to profile:", name)
+	return nil
+}
@@ -12,8 +12,9 @@ var api *client.Api
 var cmd = &cobra.Command{
 	Use:   "secrets",
 	Short: "Manage secret credentials configured in a Factory",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 }
 
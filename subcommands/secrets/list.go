@@ -1,14 +1,13 @@
 package secrets
 
 import (
-	"fmt"
-	"os"
+	"errors"
 
-	"github.com/cheynewallace/tabby"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
 )
 
@@ -16,26 +15,28 @@ func init() {
 	cmd.AddCommand(&cobra.Command{
 		Use:   "list",
 		Short: "List secret credentials configured in the Factory",
-		Run:   doList,
+		RunE:  doList,
 	})
 }
 
-func doList(cmd *cobra.Command, args []string) {
+func doList(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Listing Factory secrets for: %s", factory)
 
 	triggers, err := api.FactoryTriggers(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	t := tabby.New()
-	t.AddHeader("SECRETS")
+	secrets := []client.ProjectSecret{}
 	if len(triggers) == 1 {
-		for _, secret := range triggers[0].Secrets {
-			t.AddLine(secret.Name)
-		}
+		secrets = triggers[0].Secrets
 	} else if len(triggers) != 0 {
-		fmt.Println("ERROR: Factory configuration issue. Factory has unexpected number of triggers.")
-		os.Exit(1)
+		return errors.New("Factory configuration issue. Factory has unexpected number of triggers.")
+	}
+	out := subcommands.NewOutput(secrets, "SECRETS")
+	for _, secret := range secrets {
+		out.AddLine(secret.Name)
 	}
-	t.Print()
+	return out.Print()
 }
@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -26,24 +27,25 @@ func init() {
 
   # Delete a secret by setting it to an empty value. eg:
   fioctl secrets update secret_name=`,
-		Run:  doUpdate,
+		RunE: doUpdate,
 		Args: cobra.MinimumNArgs(1),
 	})
 }
 
-func doUpdate(cmd *cobra.Command, args []string) {
+func doUpdate(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Updating Factory secrets for: %s", factory)
 
 	triggers, err := api.FactoryTriggers(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	secrets := make([]client.ProjectSecret, len(args))
 	for i, arg := range args {
 		parts := strings.SplitN(arg, "=", 2)
 		if len(parts) != 2 {
-			fmt.Println("ERROR: Invalid key=value argument: ", arg)
-			os.Exit(1)
+			return subcommands.UsageError(fmt.Errorf("Invalid key=value argument: %s", arg))
 		}
 		secrets[i].Name = parts[0]
 		value := parts[1]
@@ -51,7 +53,9 @@ func doUpdate(cmd *cobra.Command, args []string) {
 			secrets[i].Value = nil
 		} else if value[0] == '=' {
 			bytes, err := os.ReadFile(value[1:])
-			subcommands.DieNotNil(err, "Unable to read secret:")
+			if err != nil {
+				return fmt.Errorf("Unable to read secret: %w", err)
+			}
 			content := string(bytes)
 			secrets[i].Value = &content
 		} else {
@@ -67,10 +71,9 @@ func doUpdate(cmd *cobra.Command, args []string) {
 	} else if len(triggers) == 1 {
 		pt = triggers[0]
 	} else {
-		fmt.Println("ERROR: Factory configuration issue. Factory has unexpected number of triggers.")
-		os.Exit(1)
+		return errors.New("Factory configuration issue. Factory has unexpected number of triggers.")
 	}
 
 	pt.Secrets = secrets
-	subcommands.DieNotNil(api.FactoryUpdateTrigger(factory, pt))
+	return api.FactoryUpdateTrigger(factory, pt)
 }
@@ -19,9 +19,10 @@ func NewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Get dashboard view of a Factory and its devices",
-		Run:   showStatus,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			api = subcommands.Login(cmd)
+		RunE:  showStatus,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			api, err = subcommands.Login(cmd)
+			return
 		},
 	}
 	subcommands.RequireFactory(cmd)
@@ -29,12 +30,29 @@ func NewCommand() *cobra.Command {
 	return cmd
 }
 
-func showStatus(cmd *cobra.Command, args []string) {
+func showStatus(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Showing status of %s", factory)
 
 	status, err := api.FactoryStatus(factory, inactiveThreshold)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
+
+	if !subcommands.IsTableOutput() {
+		out := subcommands.NewOutput(status,
+			"KIND", "TAG", "LATEST TARGET", "DEVICES", "ON LATEST", "ON ORPHAN", "ONLINE")
+		for _, tags := range []struct {
+			kind string
+			tags []client.TagStatus
+		}{{"wave", status.ProdWaveTags}, {"production", status.ProdTags}, {"test", status.Tags}} {
+			for _, tag := range tags.tags {
+				out.AddLine(tags.kind, tag.Name, tag.LatestTarget, tag.DevicesTotal, tag.DevicesOnLatest,
+					tag.DevicesOnOrphan, tag.DevicesOnline)
+			}
+		}
+		return out.Print()
+	}
 
 	fmt.Println("Total number of devices:", status.TotalDevices)
 
@@ -73,6 +91,7 @@ func showStatus(cmd *cobra.Command, args []string) {
 	printTargetStatus("Active Wave", status.ProdWaveTags)
 	printTargetStatus("Production", status.ProdTags)
 	printTargetStatus("CI", status.Tags)
+	return nil
 }
 
 func printTargetStatus(tagPrefix string, tagStatus []client.TagStatus) {
@@ -27,7 +27,7 @@ func init() {
 	addCmd := &cobra.Command{
 		Use:   "add",
 		Short: "Compose and add Targets to Factory's TUF Targets metadata",
-		Run:   doAdd,
+		RunE:  doAdd,
 		Long: `
 Compose new Targets out of the latest ones with the specified source tag via the command arguments — either OSTree commit hashes or App URIs.
 
@@ -51,42 +51,44 @@ Add new App Targets:
 	addCmd.Flags().StringVarP(&addTargetsCreator, "targets-creator", "", "fioctl", "optional name/comment/context about Targets origination")
 }
 
-func doAdd(cmd *cobra.Command, args []string) {
+func doAdd(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	supportedTargetTypes := map[string]func(factory string, tags []string, srcTag string, args []string) (Targets, error){
 		"app":    createAppTargets,
 		"ostree": createOstreeTarget,
 	}
 	if len(addTargetType) == 0 {
-		subcommands.DieNotNil(errors.New("missing mandatory flag `--type`"))
+		return errors.New("missing mandatory flag `--type`")
 	}
 	deriveTargets, ok := supportedTargetTypes[addTargetType]
 	if !ok {
-		subcommands.DieNotNil(errors.New("unsupported type of Target: " + addTargetType))
+		return errors.New("unsupported type of Target: " + addTargetType)
 	}
 	if len(addTags) == 0 {
-		subcommands.DieNotNil(errors.New("missing mandatory flag `--tags`"))
+		return errors.New("missing mandatory flag `--tags`")
 	}
 	tags := strings.Split(addTags, ",")
 	if len(addSrcTag) == 0 {
-		subcommands.DieNotNil(errors.New("missing mandatory flag `--src-tag`"))
+		return errors.New("missing mandatory flag `--src-tag`")
 	}
 
 	newTargets, err := deriveTargets(factory, tags, addSrcTag, args)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if !addQuiet {
 		newTargets.print()
 	}
 	if !addDryRun {
 		fmt.Printf("Posting new Targets...")
 		err = newTargets.post(factory, addTargetsCreator)
-		if err == nil {
-			fmt.Println("OK")
-		} else {
-			fmt.Println("Failed")
+		if err != nil {
+			fmt.Println(subcommands.FailureStatus(err))
+			return err
 		}
-		subcommands.DieNotNil(err)
+		fmt.Println("OK")
 	}
+	return nil
 }
 
 func createAppTargets(factory string, tags []string, srcTag string, appUris []string) (Targets, error) {
@@ -150,10 +152,14 @@ func createOstreeTarget(factory string, tags []string, srcTag string, hwIdToHash
 
 func deriveTargets(factory string, hwIds map[string]interface{}, srcTag string, customizeFunc func(target *client.Target) error) (Targets, error) {
 	latestBuild, err := api.JobservLatestBuild(factory, false)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 
 	targets, err := api.TargetsList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 
 	latestTargetsPerHwId := make(map[string]*client.Target) // latest Targets per hardware ID that have  `srcTag`
 	for _, meta := range targets {
@@ -17,10 +17,11 @@ import (
 
 func init() {
 	cmd.AddCommand(&cobra.Command{
-		Use:   "artifacts <target> [<artifact name>]",
-		Short: "Show artifacts created in CI for a Target",
-		Run:   doArtifacts,
-		Args:  cobra.RangeArgs(1, 2),
+		Use:               "artifacts <target> [<artifact name>]",
+		Short:             "Show artifacts created in CI for a Target",
+		RunE:              doArtifacts,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetVersions),
 		Example: `
   # List all artifacts for Target 12
   fioctl targets artifacts 12
@@ -36,18 +37,23 @@ func init() {
 	})
 }
 
-func listArtifacts(factory string, target int) {
+func listArtifacts(factory string, target int) error {
 	runs, err := api.JobservRuns(factory, target)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	for _, run := range runs {
 		fmt.Println(run.Name, run.Url)
 		run, err := api.JobservRun(run.Url)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 		stripLen := len(run.Url) - len(run.Name) - 1
 		for _, a := range run.Artifacts {
 			fmt.Println(a[stripLen:])
 		}
 	}
+	return nil
 }
 
 type DlStatus struct {
@@ -78,56 +84,55 @@ func (d *DlStatus) Write(p []byte) (int, error) {
 	return n, nil
 }
 
-func downloadArtifact(factory string, target int, artifact string) {
+func downloadArtifact(factory string, target int, artifact string) error {
 	firstSlash := strings.Index(artifact, "/")
 	if firstSlash < 1 {
-		fmt.Println("ERROR: Invalid artifact path:", artifact)
-		os.Exit(1)
+		return subcommands.UsageError(fmt.Errorf("Invalid artifact path: %v", artifact))
 	}
 	run := artifact[0:firstSlash]
 	artifact = artifact[firstSlash+1:]
 
 	if strings.HasSuffix(artifact, "console.log") {
-		api.JobservTailRun(factory, target, run, artifact)
-		return
+		return api.JobservTailRun(factory, target, run, artifact)
 	}
 
 	resp, err := api.JobservRunArtifact(factory, target, run, artifact)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	status := DlStatus{resp.ContentLength, 0, 20, time.Now()}
 	written, err := io.Copy(os.Stdout, io.TeeReader(resp.Body, &status))
 	fmt.Fprintln(os.Stderr)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if written != resp.ContentLength {
-		fmt.Fprintf(os.Stderr, "ERROR: read %d bytes, expected %d bytes\n", written, resp.ContentLength)
-		os.Exit(1)
+		return fmt.Errorf("Read %d bytes, expected %d bytes", written, resp.ContentLength)
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		fmt.Fprintf(os.Stderr, "ERROR: HTTP response code %d\n", resp.StatusCode)
-		os.Exit(1)
+		return fmt.Errorf("HTTP response code %d", resp.StatusCode)
 	}
+	return nil
 }
 
-func doArtifacts(cmd *cobra.Command, args []string) {
+func doArtifacts(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 
 	if len(args) == 0 {
 		logrus.Debugf("Showing all testing done for Factory: %s", factory)
-		listAll(factory)
-		os.Exit(0)
+		return listAll(factory)
 	}
 
 	target, err := strconv.Atoi(args[0])
 	if err != nil {
-		subcommands.DieNotNil(fmt.Errorf("Invalid Target number: %s", args[0]))
+		return fmt.Errorf("Invalid Target number: %s", args[0])
 	}
 	if len(args) == 1 {
 		logrus.Debugf("Showing Target artifacts for %s %d", factory, target)
-		listArtifacts(factory, target)
-	} else {
-		artifact := args[1]
-		logrus.Debugf("Downloading artifact %s %d %s", factory, target, artifact)
-		downloadArtifact(factory, target, artifact)
+		return listArtifacts(factory, target)
 	}
+	artifact := args[1]
+	logrus.Debugf("Downloading artifact %s %d %s", factory, target, artifact)
+	return downloadArtifact(factory, target, artifact)
 }
@@ -12,8 +12,9 @@ var api *client.Api
 var cmd = &cobra.Command{
 	Use:   "targets",
 	Short: "Manage Factory TUF targets",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 }
 
@@ -20,10 +20,11 @@ var (
 
 func init() {
 	var deltas = &cobra.Command{
-		Use:   "static-deltas <target-version> [<from-version>...]",
-		Short: "Generate static deltas to the given Target version to make OTAs faster",
-		Run:   doDeltas,
-		Args:  cobra.MinimumNArgs(1),
+		Use:               "static-deltas <target-version> [<from-version>...]",
+		Short:             "Generate static deltas to the given Target version to make OTAs faster",
+		RunE:              doDeltas,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: subcommands.CompleteTargetVersions,
 		Long: `In many cases OTA updates will have multiple OSTree changes. These updates
 can be downloaded faster by generating OSTree static
 deltas. Static deltas are generated using "from(sha) -> to(sha)" type
@@ -41,6 +42,7 @@ number of static deltas to ensure devices are updated efficiently.`,
 	}
 	cmd.AddCommand(deltas)
 	deltas.Flags().StringVarP(&byTag, "by-tag", "", "", "Find from-versions devices on the given tag")
+	subcommands.RegisterFlagCompletion(deltas, "by-tag", subcommands.CompleteTags)
 	deltas.Flags().BoolVarP(&noTail, "no-tail", "", false, "Don't tail output of CI Job")
 	deltas.Flags().BoolVarP(&dryRun, "dryrun", "", false, "Only show what deltas would be produced")
 	deltas.Flags().StringVarP(&hwId, "hw-id", "", "", "Filter from and to Targets by the given hardware ID")
@@ -61,10 +63,12 @@ func findVersions(maxVer int, forTag string, tags []client.TagStatus) (bool, []i
 	return false, nil
 }
 
-func doDeltas(cmd *cobra.Command, args []string) {
+func doDeltas(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	toVer, err := strconv.Atoi(args[0])
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if len(hwId) > 0 {
 		logrus.Debugf("Generating static deltas to Target %d with hardware ID %s in Factory %s", toVer, hwId, factory)
 	} else {
@@ -74,20 +78,24 @@ func doDeltas(cmd *cobra.Command, args []string) {
 	var froms []int
 	for _, fromStr := range args[1:] {
 		fromI, err := strconv.Atoi(fromStr)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 		if fromI >= toVer {
-			subcommands.DieNotNil(fmt.Errorf("from-version %d is newer than to-version %d", fromI, toVer))
+			return fmt.Errorf("from-version %d is newer than to-version %d", fromI, toVer)
 		}
 		froms = append(froms, fromI)
 	}
 	if len(byTag) > 0 {
 		status, err := api.FactoryStatus(factory, 4)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 
 		foundCi, versionsCi := findVersions(toVer, byTag, status.Tags)
 		foundProd, versionsProd := findVersions(toVer, byTag, status.ProdTags)
 		if !foundCi && !foundProd {
-			subcommands.DieNotNil(fmt.Errorf("No tags named '%s' found", byTag))
+			return fmt.Errorf("No tags named '%s' found", byTag)
 		}
 		if foundCi {
 			froms = append(froms, versionsCi...)
@@ -97,7 +105,7 @@ func doDeltas(cmd *cobra.Command, args []string) {
 		}
 	}
 	if len(froms) == 0 {
-		subcommands.DieNotNil(errors.New("No Targets found to generate deltas for."))
+		return errors.New("No Targets found to generate deltas for.")
 	}
 	if dryRun {
 		if len(hwId) > 0 {
@@ -108,14 +116,17 @@ func doDeltas(cmd *cobra.Command, args []string) {
 		for _, v := range froms {
 			fmt.Println("  ", v, "->", toVer)
 		}
-		return
+		return nil
 	}
 	logrus.Debugf("Froms: %v", froms)
 
 	jobServUrl, webUrl, err := api.TargetDeltasCreate(factory, toVer, froms, hwId)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("CI URL: %s\n", webUrl)
 	if !noTail {
-		api.JobservTail(jobServUrl)
+		return api.JobservTail(jobServUrl)
 	}
+	return nil
 }
@@ -21,37 +21,38 @@ func init() {
 	editCmd := &cobra.Command{
 		Use:    "edit",
 		Short:  "Edit targets.json directly - proceed with caution!",
-		Run:    doEdit,
+		RunE:   doEdit,
 		Hidden: true,
 	}
 	cmd.AddCommand(editCmd)
 	editCmd.Flags().BoolVarP(&editNoTail, "no-tail", "", false, "Don't tail output of CI Job")
 }
 
-func doEdit(cmd *cobra.Command, args []string) {
+func doEdit(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Editing Targets for %s", factory)
 
 	// Get raw json
 	targets, err := api.TargetsList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	orig, err := subcommands.MarshalIndent(targets, "", "  ")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	// Create temp file to edit with
 	tmpfile, err := os.CreateTemp("", "targets.*.json")
 	if err != nil {
-		fmt.Println("Unable to create tempfile: ", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to create tempfile: %w", err)
 	}
 	defer os.Remove(tmpfile.Name())
 	if _, err := tmpfile.Write(orig); err != nil {
-		fmt.Println("Unable to write tempfile: ", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to write tempfile: %w", err)
 	}
 	if err := tmpfile.Close(); err != nil {
-		fmt.Println("Unable to close tempfile: ", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to close tempfile: %w", err)
 	}
 
 	// Let user edit the file
@@ -66,25 +67,24 @@ func doEdit(cmd *cobra.Command, args []string) {
 	logrus.Debug("Running editor and waiting for it to finish...")
 	if err := edit.Run(); err != nil {
 		fmt.Println("Editing cancelled: ", err)
-		os.Exit(0)
+		return nil
 	}
 
 	// Read it and see if its changed
 	content, err := os.ReadFile(tmpfile.Name())
 	if err != nil {
-		fmt.Println("ERROR: Unable to re-read tempfile:", err)
+		return fmt.Errorf("Unable to re-read tempfile: %w", err)
 	}
 	if bytes.Equal(content, orig) {
 		fmt.Println("No changes found, exiting.")
-		os.Exit(0)
+		return nil
 	}
 
 	// Push changes
 	var newTargets tuf.Files
 	err = json.Unmarshal(content, &newTargets)
 	if err != nil {
-		fmt.Println("Unable to parse new Targets: ", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to parse new Targets: %w", err)
 	}
 	type TargetsUp struct {
 		Targets tuf.Files `json:"targets"`
@@ -92,15 +92,17 @@ func doEdit(cmd *cobra.Command, args []string) {
 	upload := TargetsUp{newTargets}
 	content, err = json.Marshal(upload)
 	if err != nil {
-		fmt.Println("Unable to marshal Targets data: ", err)
-		os.Exit(1)
+		return fmt.Errorf("Unable to marshal Targets data: %w", err)
 	}
 
 	logrus.Debugf("Pushing to server: %s", string(content))
 	jobservUrl, webUrl, err := api.TargetsPut(factory, content)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("CI URL: %s\n", webUrl)
 	if !editNoTail {
-		api.JobservTail(jobservUrl)
+		return api.JobservTail(jobservUrl)
 	}
+	return nil
 }
@@ -2,7 +2,6 @@ package targets
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 
 	"github.com/sirupsen/logrus"
@@ -25,8 +24,9 @@ func init() {
 		Short: "Generate a system image with pre-loaded container images",
 		Example: "fioctl targets image raspberrypi4-64-lmp-464 // preload all Target apps\n" +
 			"fioctl targets image raspberrypi4-64-lmp-464 --apps app-00,app-01 // preload app-00 and app-01",
-		Run:  doImage,
-		Args: cobra.ExactArgs(1),
+		RunE:              doImage,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetNames),
 	}
 	cmd.AddCommand(imageCmd)
 	imageCmd.Flags().StringVarP(&appsShortlist, "apps", "", "",
@@ -37,26 +37,30 @@ func init() {
 	imageCmd.Flags().StringVarP(&ciScriptsRef, "ci-scripts-ref", "", "master", "Override to a specific git-ref of ci-scripts")
 }
 
-func validateAppShortlist() {
+func validateAppShortlist() error {
 	pattern := `^[a-zA-Z0-9-_,]+$`
 	re := regexp.MustCompile(pattern)
 	if len(appsShortlist) > 0 && !re.MatchString(appsShortlist) {
-		fmt.Println("ERROR: Invalid value for apps:", appsShortlist)
-		fmt.Println("       apps must be ", pattern)
-		os.Exit(1)
+		return subcommands.UsageError(fmt.Errorf("Invalid value for apps: %s\n       apps must be %s", appsShortlist, pattern))
 	}
+	return nil
 }
 
-func doImage(cmd *cobra.Command, args []string) {
-	validateAppShortlist()
+func doImage(cmd *cobra.Command, args []string) error {
+	if err := validateAppShortlist(); err != nil {
+		return err
+	}
 	factory := viper.GetString("factory")
 	inputTarget := args[0]
 	logrus.Debugf("Generating image of Target %s in Factory %s", inputTarget, factory)
 
 	jobServUrl, webUrl, err := api.TargetImageCreate(factory, inputTarget, appsShortlist, ciScriptsRepo, ciScriptsRef)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("CI URL: %s\n", webUrl)
 	if !noTail {
-		api.JobservTail(jobServUrl)
+		return api.JobservTail(jobServUrl)
 	}
+	return nil
 }
@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/cheynewallace/tabby"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -81,56 +80,68 @@ func init() {
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List targets.",
-		Run:   doList,
+		RunE:  doList,
 		Long:  "Available columns for display:\n\n  * " + strings.Join(allCols, "\n  * "),
 	}
 	cmd.AddCommand(listCmd)
 	listCmd.Flags().BoolVarP(&listRaw, "raw", "r", false, "Print raw targets.json")
 	listCmd.Flags().BoolVarP(&listProd, "production", "", false, "Show the production version targets.json")
 	listCmd.Flags().StringVarP(&listByTag, "by-tag", "", "", "Only list Targets that match the given tag")
+	subcommands.RegisterFlagCompletion(listCmd, "by-tag", subcommands.CompleteTags)
 	listCmd.Flags().StringSliceVarP(&showColumns, "columns", "", defCols, "Specify which columns to display")
 }
 
-func doList(cmd *cobra.Command, args []string) {
+func doList(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	logrus.Debugf("Listing Targets for %s tag(%s)", factory, listByTag)
 
 	if listProd && len(listByTag) == 0 {
-		subcommands.DieNotNil(errors.New("--production flag requires --by-tag flag"))
+		return errors.New("--production flag requires --by-tag flag")
 	}
 
 	if listRaw {
 		if listProd {
 			meta, err := api.ProdTargetsGet(factory, listByTag, true)
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			bytes, err := json.Marshal(meta)
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			os.Stdout.Write(bytes)
 		} else {
 			body, err := api.TargetsListRaw(factory)
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			os.Stdout.Write(*body)
 		}
-		return
+		return nil
 	}
 
 	var targets data.Files
 	if listProd {
 		meta, err := api.ProdTargetsGet(factory, listByTag, true)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 		targets = meta.Signed.Targets
 	} else {
 		var err error
 		targets, err = api.TargetsList(factory)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 	}
 
 	var keys []string
 	listing := make(map[string]*targetListing)
-	for _, target := range targets {
+	listed := make(data.Files)
+	for name, target := range targets {
 		custom, err := api.TargetCustom(target)
 		if err != nil {
-			fmt.Printf("ERROR: %s\n", err)
+			fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
 			continue
 		}
 		if custom.TargetFormat != "OSTREE" {
@@ -154,6 +165,7 @@ func doList(cmd *cobra.Command, args []string) {
 		if err != nil {
 			panic(fmt.Sprintf("Invalid version: %v. Error: %s", target, err))
 		}
+		listed[name] = target
 		key := fmt.Sprintf("%d-%s", ver, strings.Join(custom.Tags, ","))
 		build, ok := listing[key]
 		if ok {
@@ -187,16 +199,14 @@ func doList(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	t := tabby.New()
-	var cols = make([]interface{}, len(showColumns))
+	var cols = make([]string, len(showColumns))
 	for idx, c := range showColumns {
 		if _, ok := Columns[c]; !ok {
-			fmt.Println("ERROR: Invalid column name:", c)
-			os.Exit(1)
+			return fmt.Errorf("Invalid column name: %v", c)
 		}
 		cols[idx] = strings.ToUpper(c)
 	}
-	t.AddHeader(cols...)
+	out := subcommands.NewOutput(listed, cols...)
 	row := make([]interface{}, len(showColumns))
 
 	sort.Sort(byTargetKey(keys))
@@ -206,7 +216,7 @@ func doList(cmd *cobra.Command, args []string) {
 			col := Columns[col]
 			row[idx] = col.Formatter(l)
 		}
-		t.AddLine(row...)
+		out.AddLine(row...)
 	}
-	t.Print()
+	return out.Print()
 }
@@ -60,10 +60,11 @@ var (
 
 func init() {
 	offlineUpdateCmd := &cobra.Command{
-		Use:   "offline-update <target-name> <dst> --tag <tag> [--prod | --wave <wave-name>] [--expires-in-days <days>] [--tuf-only]",
-		Short: "Download Target content for an offline update",
-		Run:   doOfflineUpdate,
-		Args:  cobra.ExactArgs(2),
+		Use:               "offline-update <target-name> <dst> --tag <tag> [--prod | --wave <wave-name>] [--expires-in-days <days>] [--tuf-only]",
+		Short:             "Download Target content for an offline update",
+		RunE:              doOfflineUpdate,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetNames),
 		Example: `
 	# Download update content of the Wave Target #1451 for "intel-corei7-64" hardware type
 	fioctl targets offline-update intel-corei7-64-lmp-1451 /mnt/flash-drive/offline-update-content --wave wave-deployment-001
@@ -94,6 +95,8 @@ func init() {
 	offlineUpdateCmd.Flags().StringVarP(&ouOstreeRepoSrc, "ostree-repo-source", "", "",
 		"Path to the local ostree repo to be added to the offline bundle")
 	offlineUpdateCmd.MarkFlagsMutuallyExclusive("tag", "wave")
+	subcommands.RegisterFlagCompletion(offlineUpdateCmd, "tag", subcommands.CompleteTags)
+	subcommands.RegisterFlagCompletion(offlineUpdateCmd, "wave", subcommands.CompleteWaves)
 	offlineUpdateCmd.MarkFlagsMutuallyExclusive("prod", "wave")
 	initSignCmd(offlineUpdateCmd)
 	initShowCmd(offlineUpdateCmd)
@@ -109,7 +112,7 @@ Run this command if your offline update bundle contains production/Wave targets.
 In this case, the bundle has to be signed by one or more Targets role offline key.
 The number of required signatures depends on the threshold number set in the current TUF root role metadata,
 and is printed by this command.`,
-		Run:  doSignBundle,
+		RunE: doSignBundle,
 		Args: cobra.ExactArgs(1),
 	}
 	signCmd.Flags().StringP("keys", "k", "",
@@ -128,19 +131,19 @@ func initShowCmd(parentCmd *cobra.Command) {
 Run this command if you would like to get information about an offline bundle.
 Specifically, what Targets it includes, what the type of the Targets (CI or production),
 a bundle's expiration time', etc.`,
-		Run:  doShowBundle,
+		RunE: doShowBundle,
 		Args: cobra.ExactArgs(1),
 	}
 	parentCmd.AddCommand(showCmd)
 }
 
-func doOfflineUpdate(cmd *cobra.Command, args []string) {
+func doOfflineUpdate(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	targetName := args[0]
 	dstDir := args[1]
 
 	if len(ouTag) == 0 && len(ouWave) == 0 {
-		subcommands.DieNotNil(errors.New("Either `--tag` or `--wave` should be specified"))
+		return errors.New("Either `--tag` or `--wave` should be specified")
 	}
 
 	var targetCustomData *tuf.FileMeta
@@ -156,38 +159,47 @@ func doOfflineUpdate(cmd *cobra.Command, args []string) {
 		fmt.Printf("Getting CI Target details; target: %s, tag: %s...\n", targetName, ouTag)
 		targetCustomData, targetGetErr = getCiTargetMeta(factory, targetName, ouTag)
 	}
-	subcommands.DieNotNil(targetGetErr)
+	if err := targetGetErr; err != nil {
+		return err
+	}
 
 	fmt.Printf("Refreshing and downloading TUF metadata for Target %s to %s...\n", targetName, path.Join(dstDir, "tuf"))
-	subcommands.DieNotNil(downloadTufRepo(factory, targetName, ouTag, ouProd, ouWave, ouExpiresIn, path.Join(dstDir, "tuf")), "Failed to download TUF metadata:")
+	if err := downloadTufRepo(factory, targetName, ouTag, ouProd, ouWave, ouExpiresIn, path.Join(dstDir, "tuf")); err != nil {
+		return fmt.Errorf("Failed to download TUF metadata: %w", err)
+	}
 	fmt.Println("Successfully refreshed and downloaded TUF metadata")
 
 	if !ouTufOnly {
 		if !isDstDirClean(dstDir) {
 			if !ouAllowMultipleTargets {
-				subcommands.DieNotNil(errors.New(`Destination directory already has update data.
+				return errors.New(`Destination directory already has update data.
 Provide a clean destination directory or re-run with --allow-multiple-targets to add a new Target to a directory which already has update data.
-Notice that multiple Targets in the same directory is only supported in LmP >= v92.`))
+Notice that multiple Targets in the same directory is only supported in LmP >= v92.`)
 			}
 		}
 
 		// Get the target info in order to deduce the ostree and app download URLs
 		ti, err := getTargetInfo(targetCustomData)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 
 		if ouOstreeRepoSrc != "" {
 			fmt.Printf("Copying local ostree repo from %s...\n", ouOstreeRepoSrc)
-			subcommands.DieNotNil(copyOstree(ouOstreeRepoSrc, dstDir+"/ostree_repo/"), "Failed to copy local ostree repo:")
+			if err := copyOstree(ouOstreeRepoSrc, dstDir+"/ostree_repo/"); err != nil {
+				return fmt.Errorf("Failed to copy local ostree repo: %w", err)
+			}
 		} else {
 			fmt.Printf("Downloading an ostree repo from the Target's OE build %d...\n", ti.ostreeVersion)
-			subcommands.DieNotNil(downloadOstree(factory, ti.ostreeVersion, ti.hardwareID, dstDir), "Failed to download Target's ostree repo:")
+			if err := downloadOstree(factory, ti.ostreeVersion, ti.hardwareID, dstDir); err != nil {
+				return fmt.Errorf("Failed to download Target's ostree repo: %w", err)
+			}
 		}
 
 		sha256String := base64.StdEncoding.EncodeToString(ti.sha256)
 		expectedCommit := path.Join(dstDir, "ostree_repo", "objects", sha256String[0:2], sha256String[2:]+".commit")
 		if _, err := os.Stat(expectedCommit); errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("ERROR: ostree repo does not contain target's hash %s. If the target references a custom ostree repo, re-run specifying --ostree-repo-source.\n", sha256String)
-			os.Exit(1)
+			return fmt.Errorf("ostree repo does not contain target's hash %s. If the target references a custom ostree repo, re-run specifying --ostree-repo-source.", sha256String)
 		}
 
 		if !ouNoApps {
@@ -195,11 +207,15 @@ Notice that multiple Targets in the same directory is only supported in LmP >= v
 				// Get the specified target from the list of factory targets to obtain the "original" tag/branch that produced
 				// the target, so we can find out the correct app bundle fetch URL.
 				targetCustomData, targetGetErr = api.TargetGet(factory, targetName)
-				subcommands.DieNotNil(targetGetErr)
+				if err := targetGetErr; err != nil {
+					return err
+				}
 
 				// Get the target info again in order to extract the "original" tag/branch and deduce the app download URLs
 				ti, err = getTargetInfo(targetCustomData)
-				subcommands.DieNotNil(err)
+				if err != nil {
+					return err
+				}
 			}
 
 			if ti.fetchedApps == nil {
@@ -214,15 +230,18 @@ Notice that multiple Targets in the same directory is only supported in LmP >= v
 						"The bundle will only update rootfs/ostree. Check your Factory configuration if this is not your intention.")
 				}
 			}
-			if herr := client.AsHttpError(err); herr != nil && herr.Response.StatusCode == 404 {
+			if errors.Is(err, client.ErrNotFound) {
 				fmt.Println("WARNING: The Target Apps were not fetched by the `assemble` run, make sure that App preloading is enabled if needed. The update won't include any Apps!")
 			} else {
-				subcommands.DieNotNil(err, "Failed to download Target's Apps:")
+				if err != nil {
+					return fmt.Errorf("Failed to download Target's Apps: %w", err)
+				}
 			}
 		}
 		fmt.Println("Successfully downloaded offline update content")
 	}
 	doShowBundle(cmd, []string{dstDir})
+	return nil
 }
 
 func getTargetInfo(targetFile *tuf.FileMeta) (*ouTargetInfo, error) {
@@ -247,8 +266,7 @@ func getTargetInfo(targetFile *tuf.FileMeta) (*ouTargetInfo, error) {
 	if ok {
 		info.sha256 = sha256
 	} else {
-		fmt.Printf("ERROR: Target has no sha256 hash set")
-		os.Exit(1)
+		return nil, errors.New("Target has no sha256 hash set")
 	}
 	if info.fetchedApps == nil {
 		info.buildTag = custom.Tags[0] // See the assemble.py script in ci-scripts https://github.com/foundriesio/ci-scripts/blob/18b4fb154c37b6ad1bc6e7b7903a540b7a758f5d/assemble.py#L300
@@ -271,7 +289,7 @@ func getTargetInfo(targetFile *tuf.FileMeta) (*ouTargetInfo, error) {
 func getWaveTargetMeta(factory string, targetName string, wave string) (*tuf.FileMeta, error) {
 	waveTargets, err := api.WaveTargetsList(factory, true, wave)
 	if err != nil {
-		if herr := client.AsHttpError(err); herr != nil && herr.Response.StatusCode == 404 {
+		if errors.Is(err, client.ErrNotFound) {
 			return nil, fmt.Errorf("No active Wave with the specified name was found; wave: %s", wave)
 		}
 		return nil, fmt.Errorf("Failed to get Wave Target metadata: %s", err.Error())
@@ -287,7 +305,7 @@ func getWaveTargetMeta(factory string, targetName string, wave string) (*tuf.Fil
 func getProdTargetMeta(factory string, targetName string, tag string) (*tuf.FileMeta, error) {
 	targets, err := api.ProdTargetsGet(factory, tag, true)
 	if err != nil {
-		if herr := client.AsHttpError(err); herr != nil && herr.Response.StatusCode == 404 {
+		if errors.Is(err, client.ErrNotFound) {
 			return nil, fmt.Errorf("No production Targets were found for the specified tag `%s`", tag)
 		}
 		return nil, fmt.Errorf("Failed to get production Target metadata: %s", err.Error())
@@ -303,7 +321,7 @@ func getProdTargetMeta(factory string, targetName string, tag string) (*tuf.File
 func getCiTargetMeta(factory string, targetName string, tag string) (*tuf.FileMeta, error) {
 	data, err := api.TufMetadataGet(factory, "targets.json", tag, false)
 	if err != nil {
-		if herr := client.AsHttpError(err); herr != nil && herr.Response.StatusCode == 404 {
+		if errors.Is(err, client.ErrNotFound) {
 			return nil, fmt.Errorf("No CI Targets found for the specified tag `%s`", tag)
 		}
 		return nil, fmt.Errorf("Failed to get CI Target metadata: %s", err.Error())
@@ -359,7 +377,7 @@ func downloadTufRepo(factory string, target string, tag string, prod bool, wave
 		metadataFileName := fmt.Sprintf("%d.root.json", ver)
 		err := downloadMetadataFile(metadataFileName)
 		if err != nil {
-			if httpErr := client.AsHttpError(err); httpErr != nil && httpErr.Response.StatusCode == 404 {
+			if errors.Is(err, client.ErrNotFound) {
 				// if 404 received for N.root.json, then stop downloading root metadata versions
 				break
 			}
@@ -491,9 +509,13 @@ func processDownloadResponse(artifactPath string, resp *http.Response, err error
 		return err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return &client.HttpError{
-			Message:  fmt.Sprintf("failed to download a CI artifact; status code: %d, artifact: %s", resp.StatusCode, artifactPath),
-			Response: resp,
+		return &client.APIError{
+			Method:     resp.Request.Method,
+			URL:        resp.Request.URL.String(),
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Message:    fmt.Sprintf("failed to download a CI artifact; status code: %d, artifact: %s", resp.StatusCode, artifactPath),
+			Response:   resp,
 		}
 	}
 
@@ -552,23 +574,33 @@ func getBundleTargetsMeta(bundleTufPath string, errIfNotExist bool) (bundleTarge
 	return
 }
 
-func doSignBundle(cmd *cobra.Command, args []string) {
+func doSignBundle(cmd *cobra.Command, args []string) error {
 	offlineKeysFile, _ := cmd.Flags().GetString("keys")
 	offlineKeys, err := keys.GetOfflineCreds(offlineKeysFile)
-	subcommands.DieNotNil(err, "Failed to open offline keys file")
+	if err != nil {
+		return fmt.Errorf("Failed to open offline keys file: %w", err)
+	}
 
 	bundleTufPath := path.Join(args[0], "tuf")
 	bundleTargets, err := getBundleTargetsMeta(bundleTufPath, true)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	rootMeta, err := getLatestRoot(bundleTufPath)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	err = signBundleTargets(rootMeta, bundleTargets, offlineKeys)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if b, err := canonical.MarshalCanonical(bundleTargets); err == nil {
-		subcommands.DieNotNil(os.WriteFile(path.Join(bundleTufPath, "bundle-targets.json"), b, 0666))
+		if err := os.WriteFile(path.Join(bundleTufPath, "bundle-targets.json"), b, 0666); err != nil {
+			return err
+		}
 		numberOfMoreRequiredSignatures := rootMeta.Signed.Roles["targets"].Threshold - len(bundleTargets.Signatures)
 		if numberOfMoreRequiredSignatures > 0 {
 			fmt.Printf("%d more signature(s) is/are required to meet the required threshold (%d)\n",
@@ -578,9 +610,10 @@ func doSignBundle(cmd *cobra.Command, args []string) {
 				len(bundleTargets.Signatures), rootMeta.Signed.Roles["targets"].Threshold)
 		}
 	} else {
-		subcommands.DieNotNil(err)
+		return err
 	}
 	doShowBundle(cmd, args)
+	return nil
 }
 
 func getLatestRoot(bundleTufPath string) (*client.AtsTufRoot, error) {
@@ -616,7 +649,7 @@ func getLatestRoot(bundleTufPath string) (*client.AtsTufRoot, error) {
 func signBundleTargets(rootMeta *client.AtsTufRoot, bundleTargetsMeta *tuf.Signed, offlineKeys keys.OfflineCreds) error {
 	signer, err := keys.FindOneTufSigner(rootMeta, offlineKeys, rootMeta.Signed.Roles["targets"].KeyIDs)
 	if err != nil {
-		return fmt.Errorf("%s %w", keys.ErrMsgReadingTufKey("targets", "current"), err)
+		return fmt.Errorf("%s%w", keys.ErrMsgReadingTufKey("targets", "current"), err)
 	}
 	for _, signature := range bundleTargetsMeta.Signatures {
 		if signature.KeyID == signer.Id {
@@ -635,12 +668,16 @@ func signBundleTargets(rootMeta *client.AtsTufRoot, bundleTargetsMeta *tuf.Signe
 	return nil
 }
 
-func doShowBundle(cmd *cobra.Command, args []string) {
+func doShowBundle(cmd *cobra.Command, args []string) error {
 	tufMetaPath := path.Join(args[0], "tuf")
 	bundleTufMeta, err := getBundleTargetsMeta(tufMetaPath, true)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	bundleMeta := ouBundleTufMeta{}
-	subcommands.DieNotNil(json.Unmarshal(*bundleTufMeta.Signed, &bundleMeta))
+	if err := json.Unmarshal(*bundleTufMeta.Signed, &bundleMeta); err != nil {
+		return err
+	}
 	fmt.Println("Bundle Targets info:")
 	fmt.Printf("\tType:\t\t%s\n", bundleMeta.ouBundleMeta.Type)
 	fmt.Printf("\tTag:\t\t%s\n", bundleMeta.Tag)
@@ -661,7 +698,9 @@ func doShowBundle(cmd *cobra.Command, args []string) {
 		// Therefore, instead of failing the command fetches the root meta from the backend.
 		rootMeta, err = api.TufRootGet(viper.GetString("factory"))
 	}
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Println("\tAllowed keys:")
 	for _, key := range rootMeta.Signed.Roles["targets"].KeyIDs {
 		fmt.Printf("\t\t\t- %s\n", key)
@@ -672,4 +711,5 @@ func doShowBundle(cmd *cobra.Command, args []string) {
 		fmt.Printf("\tMissing:\t%d (the number of required additional signatures;"+
 			" run the `sign` sub-command to sign the bundle)\n", numberOfMissingSignatures)
 	}
+	return nil
 }
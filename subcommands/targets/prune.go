@@ -2,7 +2,6 @@ package targets
 
 import (
 	"fmt"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,8 +23,14 @@ func init() {
 	pruneCmd := &cobra.Command{
 		Use:   "prune <target> [<target>...]",
 		Short: "Prune Target(s)",
-		Run:   doPrune,
+		RunE:  doPrune,
 		Args:  cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if pruneByTag {
+				return subcommands.CompleteTags(cmd, args, toComplete)
+			}
+			return subcommands.CompleteTargetNames(cmd, args, toComplete)
+		},
 		Example: `
   # prune a single Target by name:
   fioctl targets prune intel-corei7-64-lmp-123
@@ -72,11 +77,13 @@ func sortedListsMatch(a, b []string) bool {
 	return true
 }
 
-func doPrune(cmd *cobra.Command, args []string) {
+func doPrune(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 
 	targets, err := api.TargetsList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	var target_names []string
 	if pruneByTag {
@@ -98,12 +105,13 @@ func doPrune(cmd *cobra.Command, args []string) {
 			for _, name := range target_names {
 				parts := strings.SplitN(name, "lmp-", 2)
 				if len(parts) != 2 {
-					fmt.Printf("Unable to decode Target name: %s\n", name)
-					os.Exit(1)
+					return fmt.Errorf("Unable to decode Target name: %s", name)
 				}
 				custom, _ := api.TargetCustom(targets[name])
 				verI, err := strconv.Atoi(custom.Version)
-				subcommands.DieNotNil(err)
+				if err != nil {
+					return err
+				}
 				vals, ok := versions[verI]
 				if ok {
 					versions[verI] = append(vals, name)
@@ -126,29 +134,42 @@ func doPrune(cmd *cobra.Command, args []string) {
 				}
 			} else {
 				fmt.Println("Nothing to prune")
-				os.Exit(0)
+				return nil
 			}
 		}
 	} else {
 		for _, name := range args {
 			if _, ok := targets[name]; !ok {
-				fmt.Printf("Target(%s) not found in targets.json\n", name)
-				os.Exit(1)
+				return fmt.Errorf("Target(%s) not found in targets.json", name)
 			}
 		}
 		target_names = args
 	}
 
-	fmt.Printf("Deleting Targets:\n %s\n", strings.Join(target_names, "\n "))
 	if pruneDryRun {
+		fmt.Printf("Deleting Targets:\n %s\n", strings.Join(target_names, "\n "))
 		fmt.Println("Dry run, exiting")
-		return
+		return nil
+	}
+	sort.Strings(target_names)
+	action := fmt.Sprintf("Delete %d Target(s) from the %s factory", len(target_names), factory)
+	if pruneByTag {
+		// A tag may match many more Targets than a user expects
+		err = subcommands.ConfirmFactory(factory, action, target_names)
+	} else {
+		err = subcommands.Confirm(action, target_names)
+	}
+	if err != nil {
+		return err
 	}
 
 	jobservUrl, webUrl, err := api.TargetDeleteTargets(factory, target_names)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("CI URL: %s\n", webUrl)
 	if !pruneNoTail {
-		api.JobservTail(jobservUrl)
+		return api.JobservTail(jobservUrl)
 	}
+	return nil
 }
@@ -3,6 +3,7 @@ package targets
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,10 +28,11 @@ var sbomFormats formats
 
 func init() {
 	showCmd := &cobra.Command{
-		Use:   "show <version>",
-		Short: "Show details of a specific Target.",
-		Run:   doShow,
-		Args:  cobra.ExactArgs(1),
+		Use:               "show <version>",
+		Short:             "Show details of a specific Target.",
+		RunE:              doShow,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetVersions),
 		Example: `
   # Show details of all Targets with version 42:
   fioctl targets show 42
@@ -43,19 +45,21 @@ func init() {
 	showCmd.PersistentFlags().BoolP("raw", "r", false, "Print raw target custom json")
 
 	showAppCmd := &cobra.Command{
-		Use:   "compose-app <version> <app>",
-		Short: "Show details of a specific compose app.",
-		Run:   doShowComposeApp,
-		Args:  cobra.ExactArgs(2),
+		Use:               "compose-app <version> <app>",
+		Short:             "Show details of a specific compose app.",
+		RunE:              doShowComposeApp,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetVersions),
 	}
 	showCmd.AddCommand(showAppCmd)
 	showAppCmd.Flags().Bool("manifest", false, "Show an app docker manifest")
 
 	sbomCmd := &cobra.Command{
-		Use:   "sboms <version> [<build/run> [<artifact>]] ",
-		Short: "Show SBOMs for a specific target.",
-		Run:   doShowSboms,
-		Args:  cobra.RangeArgs(1, 3),
+		Use:               "sboms <version> [<build/run> [<artifact>]] ",
+		Short:             "Show SBOMs for a specific target.",
+		RunE:              doShowSboms,
+		Args:              cobra.RangeArgs(1, 3),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetVersions),
 		Example: `
   # Show all SBOM files for Target version 42:
   fioctl targets show sboms 42
@@ -104,7 +108,7 @@ func sortedAppsNames(target client.TufCustom) []string {
 	return keys
 }
 
-func doShow(cmd *cobra.Command, args []string) {
+func doShow(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	version := args[0]
 	logrus.Debugf("Showing Targets for %s %s", factory, version)
@@ -113,7 +117,10 @@ func doShow(cmd *cobra.Command, args []string) {
 	raw, _ := cmd.Flags().GetBool("raw")
 
 	shownCiUrl := false
-	sortedTargetNames, hashes, targets := getTargets(factory, prodTag, version)
+	sortedTargetNames, hashes, targets, err := getTargets(factory, prodTag, version)
+	if err != nil {
+		return err
+	}
 	for _, targetName := range sortedTargetNames {
 		target := targets[targetName]
 		hash := hashes[targetName]
@@ -154,15 +161,18 @@ func doShow(cmd *cobra.Command, args []string) {
 			t.Print()
 		} else {
 			jsonBytes, err := json.MarshalIndent(target, "\t", "\t")
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			fmt.Println("\n## Target:", targetName)
 			fmt.Printf("   Hash:   %s\n", hash)
 			fmt.Printf("\t%s\n", string(jsonBytes))
 		}
 	}
+	return nil
 }
 
-func doShowComposeApp(cmd *cobra.Command, args []string) {
+func doShowComposeApp(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	version := args[0]
 	appName := args[1]
@@ -170,15 +180,19 @@ func doShowComposeApp(cmd *cobra.Command, args []string) {
 
 	prodTag, _ := cmd.Flags().GetString("production-tag")
 
-	_, _, targets := getTargets(factory, prodTag, version)
+	_, _, targets, err := getTargets(factory, prodTag, version)
+	if err != nil {
+		return err
+	}
 	for name, custom := range targets {
 		_, ok := custom.ComposeApps[appName]
 		if !ok {
-			fmt.Println("ERROR: App not found in Target")
-			os.Exit(1)
+			return errors.New("App not found in Target")
 		}
 		appInfo, err := api.TargetComposeApp(factory, name, appName)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 
 		fmt.Println("Version:\n\t", appInfo.Uri)
 		if len(appInfo.Error) > 0 {
@@ -217,9 +231,10 @@ func doShowComposeApp(cmd *cobra.Command, args []string) {
 		// all targets contain the same compose apps, so only print once
 		break
 	}
+	return nil
 }
 
-func getTargets(factory string, prodTag string, version string) ([]string, map[string]string, map[string]client.TufCustom) {
+func getTargets(factory string, prodTag string, version string) ([]string, map[string]string, map[string]client.TufCustom, error) {
 	var targets tuf.Files
 	var prodMeta *client.AtsTufTargets
 
@@ -231,12 +246,16 @@ func getTargets(factory string, prodTag string, version string) ([]string, map[s
 	if len(prodTag) > 0 {
 		var err error
 		prodMeta, err = api.ProdTargetsGet(factory, prodTag, true)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 		targets = make(tuf.Files)
 		if !byName {
 			for name, target := range prodMeta.Signed.Targets {
 				custom, err := api.TargetCustom(target)
-				subcommands.DieNotNil(err)
+				if err != nil {
+					return nil, nil, nil, err
+				}
 				if custom.Version == version {
 					targets[name] = target
 				}
@@ -248,11 +267,15 @@ func getTargets(factory string, prodTag string, version string) ([]string, map[s
 		var err error
 		logrus.Debug("Looking up Targets by version")
 		targets, err = api.TargetsList(factory, version)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 	} else {
 		logrus.Debug("Looking up Target by name")
 		target, err := api.TargetGet(factory, version)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return nil, nil, nil, err
+		}
 		targets = make(tuf.Files)
 		targets[version] = *target
 	}
@@ -275,27 +298,26 @@ func getTargets(factory string, prodTag string, version string) ([]string, map[s
 		names = append(names, name)
 	}
 	if len(matches) == 0 {
-		fmt.Println("ERROR: no Target found for version")
-		os.Exit(1)
+		return nil, nil, nil, errors.New("no Target found for version")
 	}
 	sort.Strings(names)
-	return names, hashes, matches
+	return names, hashes, matches, nil
 }
 
 func indent(input string, prefix string) string {
 	return prefix + strings.Join(strings.SplitAfter(input, "\n"), prefix)
 }
 
-func getSbomFormat(formatStr string) string {
+func getSbomFormat(formatStr string) (string, error) {
 	format, ok := sbomFormats[formatStr]
 	if !ok {
 		fmt.Println(sbomFormats)
-		subcommands.DieNotNil(fmt.Errorf("Unknown --format: %s", formatStr))
+		return "", subcommands.UsageError(fmt.Errorf("Unknown --format: %s", formatStr))
 	}
-	return format
+	return format, nil
 }
 
-func doShowSboms(cmd *cobra.Command, args []string) {
+func doShowSboms(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	version := args[0]
 	logrus.Debugf("Showing sboms for %s %s", factory, version)
@@ -309,25 +331,30 @@ func doShowSboms(cmd *cobra.Command, args []string) {
 	formatStr, _ := cmd.Flags().GetString("format")
 	downloadPath, _ := cmd.Flags().GetString("download")
 
-	format := getSbomFormat(formatStr)
+	format, err := getSbomFormat(formatStr)
+	if err != nil {
+		return err
+	}
 	name := version
 	if factory != "lmp" {
-		name = getSbomTargetName(factory, prodTag, version)
+		if name, err = getSbomTargetName(factory, prodTag, version); err != nil {
+			return err
+		}
 	}
 
 	if len(downloadPath) > 0 {
-		doDownloadSboms(factory, name, downloadPath, format, args)
-		return
+		return doDownloadSboms(factory, name, downloadPath, format, args)
 	}
 
 	if len(args) == 3 {
 		path := fmt.Sprintf("%s/%s", args[1], args[2])
-		displaySbom(factory, name, path, format)
-		return
+		return displaySbom(factory, name, path, format)
 	}
 
 	sboms, err := api.TargetSboms(factory, name)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	t := tabby.New()
 	t.AddHeader("BUILD/RUN", "BOM ARTIFACT")
 	for _, sbom := range sboms {
@@ -337,44 +364,54 @@ func doShowSboms(cmd *cobra.Command, args []string) {
 		}
 	}
 	t.Print()
+	return nil
 }
 
-func getSbomTargetName(factory, prodTag, version string) string {
-	_, _, targets := getTargets(factory, prodTag, version)
+func getSbomTargetName(factory, prodTag, version string) (string, error) {
+	_, _, targets, err := getTargets(factory, prodTag, version)
+	if err != nil {
+		return "", err
+	}
 	for name := range targets {
-		return name
+		return name, nil
 	}
-	subcommands.DieNotNil(fmt.Errorf("Unable to find Target for version: %s", version))
-	return "" // Make compiler happy
+	return "", fmt.Errorf("Unable to find Target for version: %s", version)
 }
 
-func displaySbom(factory, targetName, path, format string) {
+func displaySbom(factory, targetName, path, format string) error {
 	contentType := format
 	if format == "table" {
 		contentType = "application/spdx.json"
 	}
 	data, err := api.SbomDownload(factory, targetName, path, contentType)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if format == "table" {
 		// special handling for default
 		var doc client.SpdxDocument
-		subcommands.DieNotNil(json.Unmarshal(data, &doc))
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
 		t := tabby.New()
 		t.AddHeader("PACKAGE", "VERSION", "LICENSE")
 		for _, pkg := range doc.Packages {
 			t.AddLine(pkg.Name, pkg.VersionInfo, pkg.License())
 		}
 		t.Print()
-	} else {
-		os.Stdout.Write(data)
+		return nil
 	}
+	_, err = os.Stdout.Write(data)
+	return err
 }
 
-func doDownloadSboms(factory, targetName, downloadPath, format string, args []string) {
+func doDownloadSboms(factory, targetName, downloadPath, format string, args []string) error {
 	st, err := os.Stat(downloadPath)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if !st.IsDir() {
-		subcommands.DieNotNil(fmt.Errorf("download path is not a directory: %s", downloadPath))
+		return subcommands.UsageError(fmt.Errorf("download path is not a directory: %s", downloadPath))
 	}
 
 	if format == "table" {
@@ -394,7 +431,9 @@ func doDownloadSboms(factory, targetName, downloadPath, format string, args []st
 	}
 
 	sboms, err := api.TargetSboms(factory, targetName)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	for _, sbom := range sboms {
 		buildRun := sbom.CiBuild + "/" + sbom.CiRun + "/" + sbom.Artifact
 		if len(filter) == 0 || strings.HasPrefix(buildRun, filter) {
@@ -404,12 +443,19 @@ func doDownloadSboms(factory, targetName, downloadPath, format string, args []st
 			extension := "." + parts[1]
 			dst = strings.Replace(dst, ".spdx.json", extension, 1)
 			dst = strings.Replace(dst, ".spdx.tar.zst", extension, 1)
-			subcommands.DieNotNil(os.MkdirAll(filepath.Dir(dst), st.Mode()))
+			if err := os.MkdirAll(filepath.Dir(dst), st.Mode()); err != nil {
+				return err
+			}
 			fmt.Printf("%s %s/%s/%s\n |-> %s...", prefixMsg, sbom.CiBuild, sbom.CiRun, sbom.Artifact, dst)
 			bytes, err := api.SbomDownload(factory, targetName, buildRun, format)
 			fmt.Println()
-			subcommands.DieNotNil(err)
-			subcommands.DieNotNil(os.WriteFile(dst, bytes, 0o744))
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(dst, bytes, 0o744); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
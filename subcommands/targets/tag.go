@@ -1,8 +1,8 @@
 package targets
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -29,8 +29,14 @@ func init() {
 
   # Tag a specific Target by name
   fioctl targets tag --tags master,testing intel-corei7-64-lmp-42`,
-		Run:  doTag,
+		RunE: doTag,
 		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if tagByVersion {
+				return subcommands.CompleteTargetVersions(cmd, args, toComplete)
+			}
+			return subcommands.CompleteTargetNames(cmd, args, toComplete)
+		},
 	}
 	cmd.AddCommand(tagCmd)
 	tagCmd.Flags().StringVarP(&tagTags, "tags", "T", "", "comma,separate,list")
@@ -54,20 +60,22 @@ func Set(a, b []string) []string {
 	return unique
 }
 
-func doTag(cmd *cobra.Command, args []string) {
+func doTag(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	tags := strings.Split(tagTags, ",")
 	if !tagAppend && len(tags) == 0 {
-		subcommands.DieNotNil(fmt.Errorf("Tags list cannot be empty when setting tags"))
+		return fmt.Errorf("Tags list cannot be empty when setting tags")
 	}
 
 	// Make sure all tags are unique, no accidental repeats/typos
 	if len(tags) != len(Set(tags, nil)) {
-		subcommands.DieNotNil(fmt.Errorf("Invalid list of tags. An item was repeated in %s", tags))
+		return fmt.Errorf("Invalid list of tags. An item was repeated in %s", tags)
 	}
 
 	targets, err := api.TargetsList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	updates := make(client.UpdateTargets)
 
@@ -90,14 +98,15 @@ func doTag(cmd *cobra.Command, args []string) {
 			}
 		}
 		if len(updates) == 0 {
-			fmt.Println("ERROR: no Targets found matching the given versions")
-			os.Exit(1)
+			return errors.New("no Targets found matching the given versions")
 		}
 	} else {
 		for _, name := range args {
 			if target, ok := targets[name]; ok {
 				custom, err := api.TargetCustom(target)
-				subcommands.DieNotNil(err)
+				if err != nil {
+					return err
+				}
 				targetTags := tags
 				if tagAppend {
 					targetTags = Set(custom.Tags, tags)
@@ -107,23 +116,27 @@ func doTag(cmd *cobra.Command, args []string) {
 				}
 				fmt.Printf("Changing tags of %s from %s -> %s\n", name, custom.Tags, targetTags)
 			} else {
-				fmt.Printf("Target(%s) not found in targets.json\n", name)
-				os.Exit(1)
+				return fmt.Errorf("Target(%s) not found in targets.json", name)
 			}
 		}
 	}
 
 	if dryRun {
 		data, err := subcommands.MarshalIndent(updates, "  ", "  ")
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return err
+		}
 		fmt.Println(string(data))
-		return
+		return nil
 	}
 
 	jobServUrl, webUrl, err := api.TargetUpdateTags(factory, updates)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Printf("CI URL: %s\n", webUrl)
 	if !tagNoTail {
-		api.JobservTail(jobServUrl)
+		return api.JobservTail(jobServUrl)
 	}
+	return nil
 }
@@ -11,21 +11,24 @@ import (
 
 func init() {
 	cmd.AddCommand(&cobra.Command{
-		Use:   "tail <target> <run>",
-		Short: "Tail the console output of a live CI Run",
-		Run:   doTail,
-		Args:  cobra.ExactArgs(2),
+		Use:               "tail <target> <run>",
+		Short:             "Tail the console output of a live CI Run",
+		RunE:              doTail,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetVersions),
 		Example: `
   fioctl targets tail 12 build-amd64
 `,
 	})
 }
 
-func doTail(cmd *cobra.Command, args []string) {
+func doTail(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	build, err := strconv.Atoi(args[0])
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	run := args[1]
 
-	api.JobservTailRun(factory, build, run, "console.log")
+	return api.JobservTailRun(factory, build, run, "console.log")
 }
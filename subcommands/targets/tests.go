@@ -11,15 +11,17 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
 )
 
 func init() {
 	cmd.AddCommand(&cobra.Command{
-		Use:   "tests [<target> [<test-id> [<artifact name>]]]",
-		Short: "Show testing done against a Target",
-		Run:   doShowTests,
-		Args:  cobra.RangeArgs(0, 3),
+		Use:               "tests [<target> [<test-id> [<artifact name>]]]",
+		Short:             "Show testing done against a Target",
+		RunE:              doShowTests,
+		Args:              cobra.RangeArgs(0, 3),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteTargetVersions),
 		Example: `
   # List all testing performed in the Factory
   fioctl targets tests
@@ -45,40 +47,43 @@ func timestamp(ts float32) string {
 	return time.Unix(secs, nsecs).UTC().String()
 }
 
-func listAll(factory string) {
+func listAll(factory string) error {
 	versions, err := api.TargetTestingApi(factory).Versions()
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Println("Tested Targets:")
 	for _, ver := range versions {
 		fmt.Println(" ", ver)
 	}
+	return nil
 }
 
-func list(factory string, target int) {
+func list(factory string, target int) error {
 	tapi := api.TargetTestingApi(factory)
 	t := tabby.New()
 	t.AddHeader("NAME", "STATUS", "ID", "CREATED AT", "DEVICE")
 
-	tl, err := tapi.Tests(target)
-	subcommands.DieNotNil(err)
-	for tl != nil {
-		for _, test := range tl.Tests {
-			created := timestamp(test.CreatedOn)
-			name := test.DeviceUUID
-			if len(test.DeviceName) > 0 {
-				name = test.DeviceName
-			}
-			t.AddLine(test.Name, test.Status, test.Id, created, name)
+	for test, err := range tapi.TestsIter(target, client.PaginateOptions{Prefetch: true}) {
+		if err != nil {
+			return err
+		}
+		created := timestamp(test.CreatedOn)
+		name := test.DeviceUUID
+		if len(test.DeviceName) > 0 {
+			name = test.DeviceName
 		}
-		tl, err = tl.NextPage()
-		subcommands.DieNotNil(err)
+		t.AddLine(test.Name, test.Status, test.Id, created, name)
 	}
 	t.Print()
+	return nil
 }
 
-func show(factory string, target int, testId string) {
+func show(factory string, target int, testId string) error {
 	test, err := api.TargetTestingApi(factory).TestResults(target, testId)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	fmt.Println("Name:     ", test.Name)
 	fmt.Println("Status:   ", test.Status)
 	fmt.Println("Created:  ", timestamp(test.CreatedOn))
@@ -108,32 +113,36 @@ func show(factory string, target int, testId string) {
 		}
 		t.Print()
 	}
+	return nil
 }
 
-func doShowTests(cmd *cobra.Command, args []string) {
+func doShowTests(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 
 	if len(args) == 0 {
 		logrus.Debugf("Showing all testing done for Factory: %s", factory)
-		listAll(factory)
-		os.Exit(0)
+		return listAll(factory)
 	}
 
 	target, err := strconv.Atoi(args[0])
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if len(args) == 1 {
 		logrus.Debugf("Showing Target testing for %s %d", factory, target)
-		list(factory, target)
+		return list(factory, target)
 	} else if len(args) == 2 {
 		testId := args[1]
 		logrus.Debugf("Showing Target test results for %s %d - %s", factory, target, testId)
-		show(factory, target, testId)
-	} else {
-		testId := args[1]
-		artifact := args[2]
-		logrus.Debugf("Showing Target test artifacts for %s %d - %s / %s", factory, target, testId, artifact)
-		content, err := api.TargetTestingApi(factory).TestArtifact(target, testId, artifact)
-		subcommands.DieNotNil(err)
-		os.Stdout.Write(*content)
+		return show(factory, target, testId)
+	}
+	testId := args[1]
+	artifact := args[2]
+	logrus.Debugf("Showing Target test artifacts for %s %d - %s / %s", factory, target, testId, artifact)
+	content, err := api.TargetTestingApi(factory).TestArtifact(target, testId, artifact)
+	if err != nil {
+		return err
 	}
+	_, err = os.Stdout.Write(*content)
+	return err
 }
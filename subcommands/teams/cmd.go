@@ -15,38 +15,50 @@ func NewCommand() *cobra.Command {
 		Use:   "teams [<team_name>]",
 		Short: "List teams belonging to a Factory",
 		Args:  cobra.RangeArgs(0, 1),
-		Run:   doTeamsCommand,
+		RunE:  doTeamsCommand,
 	}
 	subcommands.RequireFactory(cmd)
 	return cmd
 }
 
-func doTeamsCommand(cmd *cobra.Command, args []string) {
+func doTeamsCommand(cmd *cobra.Command, args []string) error {
+	api, err := subcommands.Login(cmd)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
-		doList(subcommands.Login(cmd), viper.GetString("factory"))
-	} else {
-		doGetTeam(subcommands.Login(cmd), viper.GetString("factory"), args[0])
+		return doList(api, viper.GetString("factory"))
 	}
-
+	return doGetTeam(api, viper.GetString("factory"), args[0])
 }
 
-func doList(api *client.Api, factory string) {
+func doList(api *client.Api, factory string) error {
 	logrus.Debugf("Listing teams for %s", factory)
 
 	teams, err := api.TeamsList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	t := tabby.New()
-	t.AddHeader("NAME", "DESCRIPTION")
+	out := subcommands.NewOutput(teams, "NAME", "DESCRIPTION")
 	for _, team := range teams {
-		t.AddLine(team.Name, team.Description)
+		out.AddLine(team.Name, team.Description)
 	}
-	t.Print()
+	return out.Print()
 }
 
-func doGetTeam(api *client.Api, factory, team_name string) {
+func doGetTeam(api *client.Api, factory, team_name string) error {
 	team, err := api.TeamDetails(factory, team_name)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
+	if !subcommands.IsTableOutput() {
+		out := subcommands.NewOutput(team, "ID", "NAME")
+		for _, member := range team.Members {
+			out.AddLine(member.PolisId, member.Name)
+		}
+		return out.Print()
+	}
 
 	t := tabby.New()
 	t.AddHeader("NAME", "DESCRIPTION")
@@ -70,4 +82,5 @@ func doGetTeam(api *client.Api, factory, team_name string) {
 		t.AddLine(member.PolisId, member.Name)
 	}
 	t.Print()
+	return nil
 }
@@ -16,38 +16,49 @@ func NewCommand() *cobra.Command {
 		Use:   "users [<user_id>]",
 		Short: "List users with access to a Factory",
 		Args:  cobra.RangeArgs(0, 1),
-		Run:   doUserCommand,
+		RunE:  doUserCommand,
 	}
 	subcommands.RequireFactory(cmd)
 	return cmd
 }
 
-func doUserCommand(cmd *cobra.Command, args []string) {
+func doUserCommand(cmd *cobra.Command, args []string) error {
+	api, err := subcommands.Login(cmd)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
-		doList(subcommands.Login(cmd), viper.GetString("factory"))
-	} else {
-		doGetUser(subcommands.Login(cmd), viper.GetString("factory"), args[0])
+		return doList(api, viper.GetString("factory"))
 	}
-
+	return doGetUser(api, viper.GetString("factory"), args[0])
 }
 
-func doList(api *client.Api, factory string) {
+func doList(api *client.Api, factory string) error {
 	logrus.Debugf("Listing users for %s", factory)
 
 	users, err := api.UsersList(factory)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	t := tabby.New()
-	t.AddHeader("ID", "NAME", "ROLE")
+	out := subcommands.NewOutput(users, "ID", "NAME", "ROLE")
 	for _, user := range users {
-		t.AddLine(user.PolisId, user.Name, user.Role)
+		out.AddLine(user.PolisId, user.Name, user.Role)
 	}
-	t.Print()
+	return out.Print()
 }
 
-func doGetUser(api *client.Api, factory, user_id string) {
+func doGetUser(api *client.Api, factory, user_id string) error {
 	user, err := api.UserAccessDetails(factory, user_id)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
+	if !subcommands.IsTableOutput() {
+		out := subcommands.NewOutput(user, "ID", "NAME", "ROLE")
+		out.AddLine(user.PolisId, user.Name, user.Role)
+		return out.Print()
+	}
+
 	t := tabby.New()
 	t.AddHeader("ID", "NAME", "ROLE")
 	t.AddLine(user.PolisId, user.Name, user.Role)
@@ -74,4 +85,5 @@ func doGetUser(api *client.Api, factory, user_id string) {
 		t.AddLine(scope[strings.Index(scope, ":")+1:])
 	}
 	t.Print()
+	return nil
 }
@@ -16,67 +16,62 @@ func NewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Show version information of this tool.",
-		Run:   doVersion,
+		RunE:  doVersion,
 	}
 	cmd.Flags().StringVarP(&updateTo, "update-to", "", "", "Update to the given version of Fioctl")
 	return cmd
 }
 
-func doVersion(cmd *cobra.Command, args []string) {
+func doVersion(cmd *cobra.Command, args []string) error {
 	fmt.Println(Commit)
-	updateFinder := newUpdateFinder(cmd)
+	updateFinder, err := newUpdateFinder(cmd)
+	if err != nil {
+		return err
+	}
 	update, err := updateFinder.FindLatest()
-	DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if len(updateTo) != 0 {
 		if update == nil {
-			DieNotNil(fmt.Errorf("no updates found for platform: %s", updateFinder.platform))
-			return // required to make compiler happy
+			return fmt.Errorf("no updates found for platform: %s", updateFinder.platform)
 		}
 		if updateTo != update.Version.String() {
-			DieNotNil(fmt.Errorf("invalid version %s != %s", updateTo, update.Version))
+			return fmt.Errorf("invalid version %s != %s", updateTo, update.Version)
 		}
 		if err := update.Do(); err != nil {
 			if os.IsPermission(err) {
-				DieNotNil(fmt.Errorf("There was a permission error while updating Fioctl: %w. Please run again as an admin or root.", err))
-			} else {
-				DieNotNil(err)
+				return fmt.Errorf("There was a permission error while updating Fioctl: %w. Please run again as an admin or root.", err)
 			}
+			return err
 		}
-		return
+		return nil
 	}
 
 	if update != nil {
 		fmt.Println("Update available:", update.Version)
 		fmt.Println("\t", update.Uri)
 		exe, err := os.Executable()
-		DieNotNil(err)
+		if err != nil {
+			return err
+		}
 		fmt.Println()
 		fmt.Println("You can update by running:")
 		fmt.Println("\t", exe, "version", "--update-to", update.Version)
 	} else {
 		fmt.Println("This is the latest version.")
 	}
+	return nil
 }
 
-func newUpdateFinder(cmd *cobra.Command) *FioctlUpdateFinder {
-	DieNotNil(viper.BindPFlags(cmd.Flags()))
+func newUpdateFinder(cmd *cobra.Command) (*FioctlUpdateFinder, error) {
+	if err := viper.BindPFlags(cmd.Flags()); err != nil {
+		return nil, err
+	}
 	path, err := homedir.Expand("~/.config/fioctl-tuf.json")
-	DieNotNil(err)
-	client, err := NewFioctlUpdateFinder(path)
-	DieNotNil(err)
-	return client
-}
-
-// We must define this here are face circular import issues
-func DieNotNil(err error, message ...string) {
 	if err != nil {
-		parts := []interface{}{"ERROR:"}
-		for _, p := range message {
-			parts = append(parts, p)
-		}
-		parts = append(parts, err)
-		fmt.Println(parts...)
-		os.Exit(1)
+		return nil, err
 	}
+	return NewFioctlUpdateFinder(path)
 }
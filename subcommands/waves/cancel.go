@@ -15,15 +15,19 @@ func init() {
 		Long: `Cancel a given Wave by name.
 Once canceled, a Wave is no longer available as an update source for production devices.
 However, those already updated will remain on that version until a new version is rolled out.`,
-		Run:  doCancelWave,
-		Args: cobra.ExactArgs(1),
+		RunE:              doCancelWave,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteWaves),
 	})
 }
 
-func doCancelWave(cmd *cobra.Command, args []string) {
+func doCancelWave(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name := args[0]
 	logrus.Debugf("Canceling a Wave %s for %s", name, factory)
+	if err := subcommands.Confirm("Cancel a Wave", []string{name}); err != nil {
+		return err
+	}
 
-	subcommands.DieNotNil(api.FactoryCancelWave(factory, name))
+	return api.FactoryCancelWave(factory, name)
 }
@@ -13,8 +13,9 @@ var cmd = &cobra.Command{
 	Use:     "waves",
 	Aliases: []string{"wave"},
 	Short:   "Manage Factory Waves",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		api = subcommands.Login(cmd)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		api, err = subcommands.Login(cmd)
+		return
 	},
 }
 
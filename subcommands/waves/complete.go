@@ -15,15 +15,16 @@ func init() {
 		Long: `Complete a given Wave by name.
 Once complete, a Wave generally becomes available as an update source for all production devices.
 A subsequent Wave might become a new source for a part of production devices again.`,
-		Run:  doCompleteWave,
-		Args: cobra.ExactArgs(1),
+		RunE:              doCompleteWave,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteWaves),
 	})
 }
 
-func doCompleteWave(cmd *cobra.Command, args []string) {
+func doCompleteWave(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name := args[0]
 	logrus.Debugf("Completing a Wave %s for %s", name, factory)
 
-	subcommands.DieNotNil(api.FactoryCompleteWave(factory, name))
+	return api.FactoryCompleteWave(factory, name)
 }
@@ -1,6 +1,7 @@
 package waves
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -33,8 +34,9 @@ We recommend that you generate static deltas for your production Targets to opti
 Consider generating a static delta for Targets using:
 $ fioctl targets static-deltas
 `,
-		Run:  doInitWave,
-		Args: cobra.ExactArgs(3),
+		RunE:              doInitWave,
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: subcommands.CompleteArgs(nil, subcommands.CompleteTargetVersions, subcommands.CompleteTags),
 		Example: `
 Start a new Wave for the Target version 4 and the 'production' device tag:
 $ fioctl wave init -k ~/path/to/keys/targets.only.key.tgz wave-name 4 production
@@ -56,31 +58,44 @@ Example: 2020-01-01T00:00:00Z`)
 Example: 1,2,3`)
 	initCmd.Flags().StringP("keys", "k", "", "Path to <offline-creds.tgz> used to sign Wave Targets.")
 	initCmd.Flags().StringP("source-tag", "", "", "Match this tag when looking for Target versions. Certain advanced tagging configurations may require this argument.")
+	subcommands.RegisterFlagCompletion(initCmd, "source-tag", subcommands.CompleteTags)
 	_ = initCmd.MarkFlagRequired("keys")
 }
 
-func doInitWave(cmd *cobra.Command, args []string) {
+func doInitWave(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name, version, tag := args[0], args[1], args[2]
 	intVersion, err := strconv.ParseInt(version, 10, 32)
-	subcommands.DieNotNil(err, "Version must be an integer")
-	expires := readExpiration(cmd)
+	if err != nil {
+		return fmt.Errorf("Version must be an integer: %w", err)
+	}
+	expires, err := readExpiration(cmd)
+	if err != nil {
+		return err
+	}
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	prune, _ := cmd.Flags().GetStringSlice("prune")
 	sourceTag, _ := cmd.Flags().GetString("source-tag")
-	offlineKeys := readOfflineKeys(cmd)
+	offlineKeys, err := readOfflineKeys(cmd)
+	if err != nil {
+		return err
+	}
 
 	logrus.Debugf("Creating a Wave %s for Tactory %s Targets version %s and new tag %s expires %s",
 		name, factory, version, tag, expires.Format(time.RFC3339))
 
 	new_targets, err := api.TargetsList(factory, version)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 	if len(new_targets) == 0 {
-		subcommands.DieNotNil(fmt.Errorf("No targets found for version %s", version))
+		return fmt.Errorf("No targets found for version %s", version)
 	}
 
 	current_targets, err := api.ProdTargetsGet(factory, tag, false)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	targets := client.AtsTargetsMeta{}
 	targets.Type = tuf.TUFTypes["targets"]
@@ -91,19 +106,21 @@ func doInitWave(cmd *cobra.Command, args []string) {
 	} else {
 		targets.Targets = current_targets.Signed.Targets
 		if targets.Version <= current_targets.Signed.Version {
-			subcommands.DieNotNil(fmt.Errorf(
-				"Cannot create a Wave for a version lower than or equal to production Targets for the same tag"))
+			return errors.New(
+				"Cannot create a Wave for a version lower than or equal to production Targets for the same tag")
 		}
 	}
 
 	for name, file := range new_targets {
 		if _, exists := targets.Targets[name]; exists {
-			subcommands.DieNotNil(fmt.Errorf("Target %s already exists in production Targets for tag %s", name, tag))
+			return fmt.Errorf("Target %s already exists in production Targets for tag %s", name, tag)
 		}
 
 		if len(sourceTag) > 0 {
 			custom, err := api.TargetCustom(file)
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return err
+			}
 			found := false
 			for _, tag := range custom.Tags {
 				if tag == sourceTag {
@@ -115,20 +132,29 @@ func doInitWave(cmd *cobra.Command, args []string) {
 				continue
 			}
 		}
-		subcommands.DieNotNil(replaceTags(&file, tag), fmt.Sprintf("Malformed CI Target custom field %s", name))
+		if err := replaceTags(&file, tag); err != nil {
+			return fmt.Errorf("Malformed CI Target custom field %s: %w", name, err)
+		}
 		targets.Targets[name] = file
 	}
 
 	if len(prune) > 0 {
 		if slices.Contains(prune, version) {
-			subcommands.DieNotNil(fmt.Errorf("Cannot prune current version"))
+			return errors.New("Cannot prune current version")
+		}
+		if targets, err = pruneTargets(&targets, prune); err != nil {
+			return err
 		}
-		targets = pruneTargets(&targets, prune)
 	}
 
 	meta, err := json.MarshalCanonical(targets)
-	subcommands.DieNotNil(err, "Failed to serialize new Targets")
-	signatures := signTargets(meta, factory, offlineKeys)
+	if err != nil {
+		return fmt.Errorf("Failed to serialize new Targets: %w", err)
+	}
+	signatures, err := signTargets(meta, factory, offlineKeys)
+	if err != nil {
+		return err
+	}
 
 	signed := tuf.Signed{
 		// Existing signatures are invalidated by new targets, throw them away.
@@ -145,13 +171,19 @@ func doInitWave(cmd *cobra.Command, args []string) {
 	}
 	if dryRun {
 		payload, err := subcommands.MarshalIndent(&wave, "", "  ")
-		subcommands.DieNotNil(err, "Failed to marshal a Wave")
+		if err != nil {
+			return fmt.Errorf("Failed to marshal a Wave: %w", err)
+		}
 		fmt.Println(string(payload))
 	} else {
-		subcommands.DieNotNil(api.FactoryCreateWave(factory, &wave), "Failed to create a Wave")
+		if err := api.FactoryCreateWave(factory, &wave); err != nil {
+			return fmt.Errorf("Failed to create a Wave: %w", err)
+		}
 	}
 
-	if !hasStaticDelta(new_targets) {
+	if hasDelta, err := hasStaticDelta(new_targets); err != nil {
+		return err
+	} else if !hasDelta {
 
 		fmt.Print(`
 WARNING: You created a Wave for a Target version without static deltas.
@@ -164,26 +196,31 @@ You can then cancel this Wave and create a new one for Target with a static delt
 `)
 
 	}
+	return nil
 }
 
-func hasStaticDelta(new_targets tuf.Files) bool {
+func hasStaticDelta(new_targets tuf.Files) (bool, error) {
 	for _, file := range new_targets {
 		custom, err := api.TargetCustom(file)
-		subcommands.DieNotNil(err)
+		if err != nil {
+			return false, err
+		}
 		if custom.DeltaStats == nil {
-			return false
+			return false, nil
 		}
 	}
-	return true
+	return true, nil
 }
 
-func pruneTargets(currentTargets *client.AtsTargetsMeta, versions []string) client.AtsTargetsMeta {
+func pruneTargets(currentTargets *client.AtsTargetsMeta, versions []string) (client.AtsTargetsMeta, error) {
 	var missing []string
 	for _, version := range versions {
 		found := false
 		for name, file := range currentTargets.Targets {
 			custom, err := api.TargetCustom(file)
-			subcommands.DieNotNil(err)
+			if err != nil {
+				return *currentTargets, err
+			}
 			if custom.Version == version {
 				delete(currentTargets.Targets, name)
 				found = true
@@ -194,17 +231,21 @@ func pruneTargets(currentTargets *client.AtsTargetsMeta, versions []string) clie
 		}
 	}
 	if len(missing) > 0 {
-		subcommands.DieNotNil(fmt.Errorf(""), fmt.Sprintf("Unable to prune the following versions: %s", strings.Join(missing, ",")))
+		return *currentTargets, fmt.Errorf("Unable to prune the following versions: %s", strings.Join(missing, ","))
 	}
 
-	return *currentTargets
+	return *currentTargets, nil
 }
 
-func signTargets(meta []byte, factory string, offlineKeys keys.OfflineCreds) []tuf.Signature {
+func signTargets(meta []byte, factory string, offlineKeys keys.OfflineCreds) ([]tuf.Signature, error) {
 	root, err := api.TufRootGet(factory)
-	subcommands.DieNotNil(err, "Failed to fetch root role")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch root role: %w", err)
+	}
 	onlinePub, err := api.TufTargetsOnlineKey(factory)
-	subcommands.DieNotNil(err, "Failed to fetch online Targets public key")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch online Targets public key: %w", err)
+	}
 
 	targetsKids := root.Signed.Roles["targets"].KeyIDs
 	signerKids := make([]string, 0, len(targetsKids)-1)
@@ -217,23 +258,27 @@ func signTargets(meta []byte, factory string, offlineKeys keys.OfflineCreds) []t
 	}
 
 	if len(signerKids) == 0 {
-		subcommands.DieNotNil(fmt.Errorf(`Root role is not configured to sign Targets offline.
-Please, run "fioctl keys tuf rotate-offline-key --role=targets" in order to create offline Targets keys.`))
+		return nil, errors.New(`Root role is not configured to sign Targets offline.
+Please, run "fioctl keys tuf rotate-offline-key --role=targets" in order to create offline Targets keys.`)
 	}
 
 	signer, err := keys.FindOneTufSigner(root, offlineKeys, signerKids)
-	subcommands.DieNotNil(err, keys.ErrMsgReadingTufKey("targets", "current"))
+	if err != nil {
+		return nil, fmt.Errorf("%s%w", keys.ErrMsgReadingTufKey("targets", "current"), err)
+	}
 	signatures, err := keys.SignTufMeta(meta, signer)
-	subcommands.DieNotNil(err, "Failed to sign new targets")
-	return signatures
+	if err != nil {
+		return nil, fmt.Errorf("Failed to sign new targets: %w", err)
+	}
+	return signatures, nil
 }
 
-func readExpiration(cmd *cobra.Command) (expires time.Time) {
-	var err error
+func readExpiration(cmd *cobra.Command) (expires time.Time, err error) {
 	if cmd.Flags().Changed("expires-at") {
 		at, _ := cmd.Flags().GetString("expires-at")
-		expires, err = time.Parse(time.RFC3339, at)
-		subcommands.DieNotNil(err, "Invalid expires-at value:")
+		if expires, err = time.Parse(time.RFC3339, at); err != nil {
+			return expires, subcommands.UsageError(fmt.Errorf("Invalid expires-at value: %w", err))
+		}
 		expires = expires.UTC()
 	} else {
 		days := 365
@@ -244,14 +289,16 @@ func readExpiration(cmd *cobra.Command) (expires time.Time) {
 	}
 	// This forces a JSON marshaller to use an RFC3339 instead of the default RFC3339Nano format.
 	// An aktualizr we use on devices to update targets doesn't understand the latter one.
-	return expires.Round(time.Second)
+	return expires.Round(time.Second), nil
 }
 
-func readOfflineKeys(cmd *cobra.Command) keys.OfflineCreds {
+func readOfflineKeys(cmd *cobra.Command) (keys.OfflineCreds, error) {
 	offlineKeysFile, _ := cmd.Flags().GetString("keys")
 	offlineKeys, err := keys.GetOfflineCreds(offlineKeysFile)
-	subcommands.DieNotNil(err, "Failed to open offline keys file")
-	return offlineKeys
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open offline keys file: %w", err)
+	}
+	return offlineKeys, nil
 }
 
 func replaceTags(target *tuf.FileMeta, tag string) error {
@@ -1,11 +1,11 @@
 package waves
 
 import (
-	"github.com/cheynewallace/tabby"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/foundriesio/fioctl/client"
 	"github.com/foundriesio/fioctl/subcommands"
 )
 
@@ -13,38 +13,57 @@ func init() {
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "Show available waves",
-		Run:   doListWaves,
+		RunE:  doListWaves,
 	}
 	cmd.AddCommand(listCmd)
 	listCmd.Flags().Uint64P("limit", "n", 20, "Limit the number of results displayed.")
 	listCmd.Flags().Uint64P("page", "p", 1, "Page of Waves to display when pagination is needed")
 	listCmd.Flags().StringP("status", "S", "", "Only show Waves with a given status; one of (active, complete, canceled)")
 	listCmd.Flags().StringP("tag", "T", "", "Only show Waves with a given tag")
+	subcommands.RegisterFlagCompletion(listCmd, "tag", subcommands.CompleteTags)
+	listCmd.Flags().Bool("all", false, "Show Waves from all pages")
+	listCmd.MarkFlagsMutuallyExclusive("page", "all")
 }
 
-func doListWaves(cmd *cobra.Command, args []string) {
+func doListWaves(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	limit, _ := cmd.Flags().GetUint64("limit")
 	showPage, _ := cmd.Flags().GetUint64("page")
 	status, _ := cmd.Flags().GetString("status")
 	tag, _ := cmd.Flags().GetString("tag")
-	logrus.Debugf("Showing a list of Waves for %s", factory)
 
-	lst, err := api.FactoryListWaves(factory, limit, showPage, status, tag)
-	subcommands.DieNotNil(err)
+	all, _ := cmd.Flags().GetBool("all")
+	logrus.Debugf("Showing a list of Waves for %s", factory)
 
-	t := tabby.New()
-	t.AddHeader("NAME", "VERSION", "TAG", "STATUS", "CREATED AT", "FINISHED AT")
-	for _, wave := range lst.Waves {
-		t.AddLine(
+	header := []string{"NAME", "VERSION", "TAG", "STATUS", "CREATED AT", "FINISHED AT"}
+	row := func(wave client.Wave) []any {
+		return []any{
 			wave.Name,
 			wave.Version,
 			wave.Tag,
 			wave.Status,
 			wave.ChangeMeta.CreatedAt,
 			wave.ChangeMeta.UpdatedAt,
-		)
+		}
+	}
+
+	if all {
+		// Waves of all pages are printed as they are fetched
+		opts := client.PaginateOptions{Prefetch: true}
+		return subcommands.PrintIter(api.FactoryListWavesIter(factory, limit, status, tag, opts), row, header...)
+	}
+
+	lst, err := api.FactoryListWaves(factory, limit, showPage, status, tag)
+	if err != nil {
+		return err
+	}
+	out := subcommands.NewOutput(lst.Waves, header...)
+	for _, wave := range lst.Waves {
+		out.AddLine(row(wave)...)
+	}
+	if err := out.Print(); err != nil {
+		return err
 	}
-	t.Print()
-	subcommands.ShowPages(showPage, lst.Next)
+	subcommands.ShowPages(cmd, showPage, lst.Next)
+	return nil
 }
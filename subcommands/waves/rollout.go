@@ -27,8 +27,9 @@ The exact update time is determined by many factors:
 device up and down lifecycle, update schedule, networking between a device and update servers, etc.
 At least one command flag is required to limit the subset of devices to roll out to.
 If you want to roll out to all matching devices in a Factory, please, use the "complete" command.`,
-		Run:  doRolloutWave,
-		Args: cobra.RangeArgs(1, 2),
+		RunE:              doRolloutWave,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteWaves, subcommands.CompleteDeviceGroups),
 		Example: `
 Rollout a Wave to all devices in the "us-east" device group:
 $ fioctl waves rollout --group us-east
@@ -63,6 +64,7 @@ In all of the above examples:
 `,
 	}
 	rollout.Flags().StringP("group", "g", "", "A device group to roll out a wave to")
+	subcommands.RegisterFlagCompletion(rollout, "group", subcommands.CompleteDeviceGroups)
 	rollout.Flags().StringP("limit", "l", "",
 		`A number of devices to roll out a wave to.
 It can be an exact number (e.g. 10), or as a percentage of all matching devices (e.g. 10%).
@@ -83,12 +85,21 @@ A maximum number of devices rolled out using this flag cannot exceed 10000.`,
 	cmd.AddCommand(rollout)
 }
 
-func doRolloutWave(cmd *cobra.Command, args []string) {
+func doRolloutWave(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	wave := args[0]
-	group := readGroup(cmd, args)
-	limit, percentage := readLimit(cmd)
-	uuids := readUuids(cmd)
+	group, err := readGroup(cmd, args)
+	if err != nil {
+		return err
+	}
+	limit, percentage, err := readLimit(cmd)
+	if err != nil {
+		return err
+	}
+	uuids, err := readUuids(cmd)
+	if err != nil {
+		return err
+	}
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	printUuids, _ := cmd.Flags().GetBool("print-uuids")
 	printNames, _ := cmd.Flags().GetBool("print-names")
@@ -96,8 +107,8 @@ func doRolloutWave(cmd *cobra.Command, args []string) {
 	isFullRollout := len(uuids) == 0 && limit == 0 && percentage == 0
 
 	if len(group) == 0 && isFullRollout {
-		subcommands.DieNotNil(errors.New(
-			"One of the following flags must be set: group, limit, percentage, uuids\n" + cmd.UsageString(),
+		return subcommands.UsageError(errors.New(
+			"One of the following flags must be set: group, limit, percentage, uuids",
 		))
 	}
 
@@ -114,7 +125,9 @@ func doRolloutWave(cmd *cobra.Command, args []string) {
 		PrintNames: printNames,
 	}
 	res, err := api.FactoryRolloutWave(factory, wave, options)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	if len(group) == 0 {
 		if dryRun {
@@ -155,31 +168,32 @@ func doRolloutWave(cmd *cobra.Command, args []string) {
 		}
 		fmt.Println("")
 	}
+	return nil
 }
 
-func readGroup(cmd *cobra.Command, args []string) string {
+func readGroup(cmd *cobra.Command, args []string) (string, error) {
 	// Backward-compatible reader: new way - named flag, old way - positional flag
 	group, _ := cmd.Flags().GetString("group")
 	if len(args) > 1 {
 		if len(group) > 0 {
-			subcommands.DieNotNil(errors.New(
-				"Flag \"group\" cannot be both positional and named\n" + cmd.UsageString(),
+			return "", subcommands.UsageError(errors.New(
+				"Flag \"group\" cannot be both positional and named",
 			))
 		}
 		group = args[1]
 	}
-	return group
+	return group, nil
 }
 
-func readUuids(cmd *cobra.Command) []string {
+func readUuids(cmd *cobra.Command) ([]string, error) {
 	uuids, _ := cmd.Flags().GetString("uuids")
 	if len(uuids) == 0 {
-		return nil
+		return nil, nil
 	} else if uuids[0] == '@' {
 		if content, err := os.ReadFile(uuids[1:]); err != nil {
-			subcommands.DieNotNil(err, "Failed to read the devices UUIDs from a file:")
+			return nil, fmt.Errorf("Failed to read the devices UUIDs from a file: %w", err)
 		} else if len(content) == 0 {
-			subcommands.DieNotNil(errors.New("Devices UUIDs file cannot be empty"))
+			return nil, subcommands.UsageError(errors.New("Devices UUIDs file cannot be empty"))
 		} else {
 			uuids = string(content)
 		}
@@ -197,7 +211,7 @@ func readUuids(cmd *cobra.Command) []string {
 				if len(r) > 100 {
 					r = r[:100] + "... (cropped)"
 				}
-				subcommands.DieNotNil(fmt.Errorf("Device uuid value is too long, limit is 60: %s", r))
+				return nil, subcommands.UsageError(fmt.Errorf("Device uuid value is too long, limit is 60: %s", r))
 			}
 			res[i] = r
 			i += 1
@@ -207,29 +221,25 @@ func readUuids(cmd *cobra.Command) []string {
 
 	if len(res) > 10000 {
 		// API will not accept more UUIDs anyway, so why should we try
-		subcommands.DieNotNil(fmt.Errorf(
+		return nil, subcommands.UsageError(fmt.Errorf(
 			"Device uuids list contains %d items, limit is 10000", len(res),
 		))
 	}
-	return res
+	return res, nil
 }
 
-func readLimit(cmd *cobra.Command) (limit int, percentage int) {
+func readLimit(cmd *cobra.Command) (limit int, percentage int, err error) {
 	value, _ := cmd.Flags().GetString("limit")
 	if len(value) > 0 {
-		var err error
 		if value[len(value)-1] == '%' {
-			if percentage, err = strconv.Atoi(value[0 : len(value)-1]); err != nil {
-				subcommands.DieNotNil(fmt.Errorf(
-					`A "limit" must be either a number or a percentage: %s`, value,
-				))
-			}
+			percentage, err = strconv.Atoi(value[0 : len(value)-1])
 		} else {
-			if limit, err = strconv.Atoi(value); err != nil {
-				subcommands.DieNotNil(fmt.Errorf(
-					`A "limit" must be either a number or a percentage: %s`, value,
-				))
-			}
+			limit, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			err = subcommands.UsageError(fmt.Errorf(
+				`A "limit" must be either a number or a percentage: %s`, value,
+			))
 		}
 	}
 	return
@@ -12,23 +12,26 @@ import (
 
 func init() {
 	showCmd := &cobra.Command{
-		Use:   "show <wave>",
-		Short: "Show a given wave by name",
-		Run:   doShowWave,
-		Args:  cobra.ExactArgs(1),
+		Use:               "show <wave>",
+		Short:             "Show a given wave by name",
+		RunE:              doShowWave,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteWaves),
 	}
 	cmd.AddCommand(showCmd)
 	showCmd.Flags().BoolP("show-targets", "s", false, "Show Wave Targets")
 }
 
-func doShowWave(cmd *cobra.Command, args []string) {
+func doShowWave(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name := args[0]
 	showTargets, _ := cmd.Flags().GetBool("show-targets")
 	logrus.Debugf("Showing Wave %s for %s", name, factory)
 
 	wave, err := api.FactoryGetWave(factory, name, showTargets)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	fmt.Printf("Name: \t\t%s\n", wave.Name)
 	fmt.Printf("Version: \t%s\n", wave.Version)
@@ -76,4 +79,5 @@ func doShowWave(cmd *cobra.Command, args []string) {
 		data, _ := subcommands.MarshalIndent(wave.Targets, "  ", "  ")
 		fmt.Println("  " + string(data))
 	}
+	return nil
 }
@@ -17,27 +17,40 @@ func init() {
 
 This command is only needed when your TUF root requires more than 1 signature for production Targets.
 In this case, you cannot roll out or complete a wave before it has enough signatures.`,
-		Run:  doSignWave,
-		Args: cobra.ExactArgs(1),
+		RunE:              doSignWave,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteWaves),
 	}
 	cmd.AddCommand(signCmd)
 	signCmd.Flags().StringP("keys", "k", "", "Path to <offline-creds.tgz> used to sign wave Targets.")
 	_ = signCmd.MarkFlagRequired("keys")
 }
 
-func doSignWave(cmd *cobra.Command, args []string) {
+func doSignWave(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	name := args[0]
-	offlineKeys := readOfflineKeys(cmd)
+	offlineKeys, err := readOfflineKeys(cmd)
+	if err != nil {
+		return err
+	}
 
 	wave, err := api.FactoryGetWave(factory, name, true)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
 	var targets tuf.Signed
-	subcommands.DieNotNil(json.Unmarshal(*wave.Targets, &targets))
+	if err := json.Unmarshal(*wave.Targets, &targets); err != nil {
+		return err
+	}
 	meta, err := json.MarshalCanonical(targets.Signed)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
 
-	signatures := signTargets(meta, factory, offlineKeys)
-	subcommands.DieNotNil(api.FactorySignWave(factory, name, signatures))
+	signatures, err := signTargets(meta, factory, offlineKeys)
+	if err != nil {
+		return err
+	}
+	return api.FactorySignWave(factory, name, signatures)
 }
@@ -49,14 +49,15 @@ For a canceled Wave, all devices with Target version < Wave version are unschedu
 For finished Waves, all numbers are calculated for a current date (not a date of a Wave finishing).
 This can be used to monitor how an update progresses after a Wave completes.
 `,
-		Run:  doShowWaveStatus,
-		Args: cobra.RangeArgs(0, 1),
+		RunE:              doShowWaveStatus,
+		Args:              cobra.RangeArgs(0, 1),
+		ValidArgsFunction: subcommands.CompleteArgs(subcommands.CompleteWaves),
 	}
 	cmd.AddCommand(showCmd)
 	showCmd.Flags().Int("offline-threshold", 4, "Consider device 'OFFLINE' if not seen in the last X hours")
 }
 
-func doShowWaveStatus(cmd *cobra.Command, args []string) {
+func doShowWaveStatus(cmd *cobra.Command, args []string) error {
 	factory := viper.GetString("factory")
 	offlineThreshold, _ := cmd.Flags().GetInt("offline-threshold")
 	name := "$active$"
@@ -68,7 +69,14 @@ func doShowWaveStatus(cmd *cobra.Command, args []string) {
 	}
 
 	status, err := api.FactoryWaveStatus(factory, name, offlineThreshold)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return err
+	}
+
+	groups := waveGroupsOutput(status)
+	if !subcommands.IsTableOutput() {
+		return groups.Print()
+	}
 
 	fmt.Printf("Wave '%s' for tag '%s' version %d is %s\n",
 		status.Name, status.Tag, status.Version, status.Status)
@@ -95,31 +103,14 @@ func doShowWaveStatus(cmd *cobra.Command, args []string) {
 
 	hasTargets := len(status.RolloutGroups) > 0
 	if len(status.RolloutGroups) > 0 || len(status.OtherGroups) > 0 {
-		unscheduledMessage := "At Wave Completion"
-		if status.Status == "canceled" {
-			unscheduledMessage = "Never (Wave Canceled)"
-		} else if status.Status == "complete" {
-			unscheduledMessage += " (Done)"
-		}
-
-		t = subcommands.Tabby(0, "GROUP", "TOTAL", "UPDATED", "NEED UPDATE", "SCHEDULED", "ONLINE", "ROLLOUT AT")
-		for _, group := range status.RolloutGroups {
-			t.AddLine(
-				group.Name, group.DevicesTotal, group.DevicesOnWave+group.DevicesOnNewer,
-				group.DevicesOnOlder, group.DevicesScheduled, group.DevicesOnline, group.RolloutAt)
-		}
 		for _, group := range status.OtherGroups {
-			if group.Name == "" {
-				group.Name = "(No Group)"
-			}
-			t.AddLine(
-				group.Name, group.DevicesTotal, group.DevicesOnWave+group.DevicesOnNewer,
-				group.DevicesOnOlder, group.DevicesScheduled, group.DevicesOnline, unscheduledMessage)
 			if len(group.Targets) > 0 {
 				hasTargets = true
 			}
 		}
-		t.Print()
+		if err := groups.Print(); err != nil {
+			return err
+		}
 	}
 
 	if hasTargets && status.Status == "active" {
@@ -134,6 +125,34 @@ func doShowWaveStatus(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
+	return nil
+}
+
+// Rows of a device group overview; a wave status as a whole is printed in structured formats
+func waveGroupsOutput(status *client.WaveStatus) *subcommands.Output {
+	unscheduledMessage := "At Wave Completion"
+	if status.Status == "canceled" {
+		unscheduledMessage = "Never (Wave Canceled)"
+	} else if status.Status == "complete" {
+		unscheduledMessage += " (Done)"
+	}
+
+	out := subcommands.NewOutput(status,
+		"GROUP", "TOTAL", "UPDATED", "NEED UPDATE", "SCHEDULED", "ONLINE", "ROLLOUT AT")
+	for _, group := range status.RolloutGroups {
+		out.AddLine(
+			group.Name, group.DevicesTotal, group.DevicesOnWave+group.DevicesOnNewer,
+			group.DevicesOnOlder, group.DevicesScheduled, group.DevicesOnline, group.RolloutAt)
+	}
+	for _, group := range status.OtherGroups {
+		if group.Name == "" {
+			group.Name = "(No Group)"
+		}
+		out.AddLine(
+			group.Name, group.DevicesTotal, group.DevicesOnWave+group.DevicesOnNewer,
+			group.DevicesOnOlder, group.DevicesScheduled, group.DevicesOnline, unscheduledMessage)
+	}
+	return out
 }
 
 func showGroupStatusTargets(group client.RolloutGroupStatus, status *client.WaveStatus) {
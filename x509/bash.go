@@ -9,8 +9,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-
-	"github.com/foundriesio/fioctl/subcommands"
+	"strings"
 )
 
 type KeyStorage interface {
@@ -25,26 +24,20 @@ func (s *hsmStorage) configure() {
 	os.Setenv("HSM_TOKEN_LABEL", s.TokenLabel)
 }
 
-func run(script string, arg ...string) string {
+func run(script string, arg ...string) (string, error) {
 	factoryCaKeyStorage.configure()
 	arg = append([]string{"-s"}, arg...)
 	cmd := exec.Command("/bin/sh", arg...)
 	cmd.Stderr = os.Stderr
-	in, err := cmd.StdinPipe()
-	subcommands.DieNotNil(err, "Failed to start the shell")
-
-	go func() {
-		defer in.Close()
-		_, err := in.Write([]byte(script))
-		subcommands.DieNotNil(err, "Failed to pass the script to the shell")
-	}()
-
+	cmd.Stdin = strings.NewReader(script)
 	out, err := cmd.Output()
-	subcommands.DieNotNil(err, "Failed to execute the shell script")
-	return string(out)
+	if err != nil {
+		return "", fmt.Errorf("Failed to execute the shell script: %w", err)
+	}
+	return string(out), nil
 }
 
-func CreateFactoryCa(ou string) string {
+func CreateFactoryCa(ou string) (string, error) {
 	const script = `#!/bin/sh -e
 ## This script creates the offline private key, factory_ca.key, and x509 certficate, factory_ca.pem,
 ## owned by the user that provides a chain of trust for all other certficates used by this Factory.
@@ -92,15 +85,17 @@ openssl req $extra -new -x509 -days 7300 -config ca.cnf -key "$key" -out factory
 chmod 400 factory_ca.pem
 rm ca.cnf
 `
-	run(script, ou)
+	if _, err := run(script, ou); err != nil {
+		return "", err
+	}
 	return readFile(FactoryCaCertFile)
 }
 
-func CreateDeviceCa(cn, ou string) string {
+func CreateDeviceCa(cn, ou string) (string, error) {
 	return CreateDeviceCaExt(cn, ou, DeviceCaKeyFile, DeviceCaCertFile)
 }
 
-func CreateDeviceCaExt(cn, ou, keyFile, certFile string) string {
+func CreateDeviceCaExt(cn, ou, keyFile, certFile string) (string, error) {
 	const script = `#!/bin/sh -e
 ## This is an optional script used to create a certificate
 ## capable of signing a certicate signing request from an LmP device.
@@ -134,40 +129,46 @@ openssl ecparam -genkey -name prime256v1 | openssl ec -out $key
 openssl req -new -config ca.cnf -key $key
 chmod 400 $key
 rm ca.cnf`
-	csrPem := run(script, keyFile, cn, ou)
-	crtPem := signCaCsr("device-ca-*", csrPem)
-	writeFile(certFile, crtPem)
-	return crtPem
+	csrPem, err := run(script, keyFile, cn, ou)
+	if err != nil {
+		return "", err
+	}
+	crtPem, err := signCaCsr("device-ca-*", csrPem)
+	if err != nil {
+		return "", err
+	}
+	return crtPem, writeFile(certFile, crtPem)
 }
 
-func SignTlsCsr(csrPem string) string {
-	crtPem := signTlsCsr("tls-*", csrPem)
-	writeFile(TlsCertFile, crtPem)
-	return crtPem
+func SignTlsCsr(csrPem string) (string, error) {
+	crtPem, err := signTlsCsr("tls-*", csrPem)
+	if err != nil {
+		return "", err
+	}
+	return crtPem, writeFile(TlsCertFile, crtPem)
 }
 
-func SignEstCsr(csrPem string) string {
+func SignEstCsr(csrPem string) (string, error) {
 	return signTlsCsr("est-*", csrPem)
 }
 
-func SignCaCsr(csrPem string) string {
-	crtPem := signCaCsr("online-ca-*", csrPem)
-	writeFile(OnlineCaCertFile, crtPem)
-	return crtPem
+func SignCaCsr(csrPem string) (string, error) {
+	crtPem, err := signCaCsr("online-ca-*", csrPem)
+	if err != nil {
+		return "", err
+	}
+	return crtPem, writeFile(OnlineCaCertFile, crtPem)
 }
 
-func SignEl2GoCsr(csrPem string) string {
+func SignEl2GoCsr(csrPem string) (string, error) {
 	return signCaCsr("el2g-*", csrPem)
 }
 
-func CreateCrl(serials map[string]int) string {
-	if true {
-		panic("This function is not implemented in Bash implementation")
-	}
-	return "Neverland"
+func CreateCrl(serials map[string]int) (string, error) {
+	return "", ErrNotImplemented
 }
 
-func signTlsCsr(tmpFileMask, csrPem string) string {
+func signTlsCsr(tmpFileMask, csrPem string) (string, error) {
 	const script = `#!/bin/sh -e
 ## This script signs the "tls-csr" returned when creating Factory certificates.
 ## These certificates are signed so that the devices trust the TLS connection with the Foundries.io device gateway.
@@ -202,7 +203,7 @@ rm server.ext factory_ca.srl || true`
 	return signCsr(script, tmpFileMask, csrPem)
 }
 
-func signCaCsr(tmpFileMask, csrPem string) string {
+func signCaCsr(tmpFileMask, csrPem string) (string, error) {
 	const script = `#!/bin/sh -e
 ## This script signs "device ca" signing requests.
 ## The request may come from either the Factory (so that lmp-device-register will work)
@@ -234,25 +235,31 @@ rm ca.ext`
 	return signCsr(script, tmpFileMask, csrPem)
 }
 
-func signCsr(script, tmpFileMask, csrPem string) string {
+func signCsr(script, tmpFileMask, csrPem string) (string, error) {
 	csrFile, err := os.CreateTemp("", tmpFileMask+".csr")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return "", err
+	}
 	defer os.Remove(csrFile.Name())
 	defer csrFile.Close()
-	_, err = csrFile.Write([]byte(csrPem))
-	subcommands.DieNotNil(err)
+	if _, err = csrFile.Write([]byte(csrPem)); err != nil {
+		return "", err
+	}
 
 	crtFile, err := os.CreateTemp("", tmpFileMask+".crt")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return "", err
+	}
 	defer os.Remove(crtFile.Name())
 	defer crtFile.Close()
 
-	run(script, csrFile.Name(), crtFile.Name())
+	if _, err = run(script, csrFile.Name(), crtFile.Name()); err != nil {
+		return "", err
+	}
 	return readFile(crtFile.Name())
 }
 
-func SignCsr(csrPem string) string {
+func SignCsr(csrPem string) (string, error) {
 	fmt.Println("---- The Bash implementation of SignCsr only works for TLS CSRs and not for CAs. ----")
-	crtPem := signTlsCsr("tls-*", csrPem)
-	return crtPem
+	return signTlsCsr("tls-*", csrPem)
 }
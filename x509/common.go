@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 const (
@@ -40,15 +38,16 @@ const (
 	CrlCaRenew = 8 // RFC 5280 - removeFromCRL
 )
 
-func readFile(filename string) string {
+// ErrNotImplemented is returned by functions which are not supported by the current PKI implementation.
+var ErrNotImplemented = errors.New("This function is not implemented in this PKI implementation")
+
+func readFile(filename string) (string, error) {
 	data, err := os.ReadFile(filename)
-	subcommands.DieNotNil(err)
-	return string(data)
+	return string(data), err
 }
 
-func writeFile(filename, contents string) {
-	err := os.WriteFile(filename, []byte(contents), 0400)
-	subcommands.DieNotNil(err)
+func writeFile(filename, contents string) error {
+	return os.WriteFile(filename, []byte(contents), 0400)
 }
 
 type HsmInfo struct {
@@ -87,17 +86,22 @@ func ValidateHsmArgs(hsmModule, hsmPin, hsmTokenLabel, moduleArg, pinArg, tokenA
 	return nil, nil
 }
 
-func parseOnePemBlock(pemBlock string) *pem.Block {
+func parseOnePemBlock(pemBlock string) (*pem.Block, error) {
 	first, rest := pem.Decode([]byte(pemBlock))
 	if first == nil || len(rest) > 0 {
-		subcommands.DieNotNil(errors.New("Malformed PEM data"))
+		return nil, errors.New("Malformed PEM data")
 	}
-	return first
+	return first, nil
 }
 
-func LoadCertFromFile(fn string) *x509.Certificate {
-	crtPem := parseOnePemBlock(readFile(fn))
-	crt, err := x509.ParseCertificate(crtPem.Bytes)
-	subcommands.DieNotNil(err)
-	return crt
+func LoadCertFromFile(fn string) (*x509.Certificate, error) {
+	data, err := readFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	crtPem, err := parseOnePemBlock(data)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse %s: %w", fn, err)
+	}
+	return x509.ParseCertificate(crtPem.Bytes)
 }
@@ -9,6 +9,7 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/pem"
+	"errors"
 	"os"
 	"testing"
 
@@ -42,24 +43,24 @@ func TestNoHsm(t *testing.T) {
 			assert.Equal(t, os.FileMode(0400), stat.Mode())
 		}
 
-		factoryCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(FactoryCaCertFile)))
+		factoryCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, FactoryCaCertFile)))
 		require.Nil(t, err)
-		factoryCaKeyOnDisk, err := x509.ParseECPrivateKey(pemToDer(t, readFile(FactoryCaKeyFile)))
+		factoryCaKeyOnDisk, err := x509.ParseECPrivateKey(pemToDer(t, readTestFile(t, FactoryCaKeyFile)))
 		require.Nil(t, err)
 		assert.Equal(t, factoryCa, factoryCaOnDisk)
 		assert.Equal(t, factoryCa.PublicKey, factoryCaKeyOnDisk.Public())
 
-		tlsCertOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(TlsCertFile)))
+		tlsCertOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, TlsCertFile)))
 		require.Nil(t, err)
 		assert.Equal(t, tlsCert, tlsCertOnDisk)
 
-		onlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(OnlineCaCertFile)))
+		onlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, OnlineCaCertFile)))
 		require.Nil(t, err)
 		assert.Equal(t, onlineCa, onlineCaOnDisk)
 
-		offlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(DeviceCaCertFile)))
+		offlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, DeviceCaCertFile)))
 		require.Nil(t, err)
-		offlineCaKeyOnDisk, err := x509.ParseECPrivateKey(pemToDer(t, readFile(DeviceCaKeyFile)))
+		offlineCaKeyOnDisk, err := x509.ParseECPrivateKey(pemToDer(t, readTestFile(t, DeviceCaKeyFile)))
 		require.Nil(t, err)
 		assert.Equal(t, offlineCa, offlineCaOnDisk)
 		assert.Equal(t, offlineCa.PublicKey, offlineCaKeyOnDisk.Public())
@@ -78,7 +79,8 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	onlineCaKey, onlineCaCsr := genTestOnlineCaCsr(t)
 	el2goCaKey, el2goCaCsr := genTestEl2GoCaCsr(t)
 
-	factoryCaPem := CreateFactoryCa(testFactory)
+	factoryCaPem, err := CreateFactoryCa(testFactory)
+	require.Nil(t, err)
 	factoryCa, err := x509.ParseCertificate(pemToDer(t, factoryCaPem))
 	require.Nil(t, err)
 	factoryCaPool.AddCert(factoryCa)
@@ -94,7 +96,8 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	assert.Equal(t, []string{testFactory}, factoryCa.Subject.OrganizationalUnit)
 	assert.Equal(t, [][]*x509.Certificate{{factoryCa}}, factoryCaChain)
 
-	tlsCertPem := SignTlsCsr(tlsCsr)
+	tlsCertPem, err := SignTlsCsr(tlsCsr)
+	require.Nil(t, err)
 	tlsCert, err := x509.ParseCertificate(pemToDer(t, tlsCertPem))
 	require.Nil(t, err)
 	tlsCertChain, err := tlsCert.Verify(x509.VerifyOptions{
@@ -118,7 +121,8 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	assert.Equal(t, [][]*x509.Certificate{{tlsCert, factoryCa}}, tlsCertChain)
 	assert.Equal(t, tlsCert.PublicKey, tlsKey.Public())
 
-	estCertPem := SignEstCsr(estCsr)
+	estCertPem, err := SignEstCsr(estCsr)
+	require.Nil(t, err)
 	estCert, err := x509.ParseCertificate(pemToDer(t, estCertPem))
 	require.Nil(t, err)
 	estCertChain, err := estCert.Verify(x509.VerifyOptions{
@@ -135,7 +139,8 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	assert.Equal(t, [][]*x509.Certificate{{estCert, factoryCa}}, estCertChain)
 	assert.Equal(t, estCert.PublicKey, estKey.Public())
 
-	onlineCaPem := SignCaCsr(onlineCaCsr)
+	onlineCaPem, err := SignCaCsr(onlineCaCsr)
+	require.Nil(t, err)
 	onlineCa, err := x509.ParseCertificate(pemToDer(t, onlineCaPem))
 	require.Nil(t, err)
 	onlineCaChain, err := onlineCa.Verify(x509.VerifyOptions{
@@ -151,7 +156,8 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	assert.Equal(t, [][]*x509.Certificate{{onlineCa, factoryCa}}, onlineCaChain)
 	assert.Equal(t, onlineCa.PublicKey, onlineCaKey.Public())
 
-	offlineCaPem := CreateDeviceCa(testUser, testFactory)
+	offlineCaPem, err := CreateDeviceCa(testUser, testFactory)
+	require.Nil(t, err)
 	offlineCa, err := x509.ParseCertificate(pemToDer(t, offlineCaPem))
 	require.Nil(t, err)
 	offlineCaChain, err := offlineCa.Verify(x509.VerifyOptions{
@@ -166,7 +172,8 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	assert.Equal(t, []string{testFactory}, offlineCa.Subject.OrganizationalUnit)
 	assert.Equal(t, [][]*x509.Certificate{{offlineCa, factoryCa}}, offlineCaChain)
 
-	el2goCaPem := SignEl2GoCsr(el2goCaCsr)
+	el2goCaPem, err := SignEl2GoCsr(el2goCaCsr)
+	require.Nil(t, err)
 	el2goCa, err := x509.ParseCertificate(pemToDer(t, el2goCaPem))
 	require.Nil(t, err)
 	el2goCaChain, err := el2goCa.Verify(x509.VerifyOptions{
@@ -183,21 +190,13 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	assert.Equal(t, [][]*x509.Certificate{{el2goCa, factoryCa}}, el2goCaChain)
 	assert.Equal(t, el2goCa.PublicKey, el2goCaKey.Public())
 
-	if crlSupported, crlPem := func() (bool, string) {
-		// Recover from "unsupported" panic in bash implementation
-		crlSupported := true
-		defer func() {
-			if r := recover(); r != nil {
-				require.Equal(t, r, "This function is not implemented in Bash implementation")
-				crlSupported = false
-			}
-		}()
-		crlPem := CreateCrl(map[string]int{
-			onlineCa.SerialNumber.Text(10):  CrlCaRevoke,
-			offlineCa.SerialNumber.Text(10): CrlCaDisable,
-		})
-		return crlSupported, crlPem
-	}(); crlSupported {
+	// The bash implementation does not support CRLs
+	crlPem, err := CreateCrl(map[string]int{
+		onlineCa.SerialNumber.Text(10):  CrlCaRevoke,
+		offlineCa.SerialNumber.Text(10): CrlCaDisable,
+	})
+	if !errors.Is(err, ErrNotImplemented) {
+		require.Nil(t, err)
 		crl, err := x509.ParseRevocationList(pemToDer(t, crlPem))
 		require.Nil(t, err)
 		require.NotNil(t, crl)
@@ -227,6 +226,12 @@ func runTest(t *testing.T, verifyFiles func(factoryCa, tlsCert, onlineCa, offlin
 	verifyFiles(factoryCa, tlsCert, onlineCa, offlineCa)
 }
 
+func readTestFile(t *testing.T, filename string) string {
+	data, err := readFile(filename)
+	require.Nil(t, err)
+	return data
+}
+
 func pemToDer(t *testing.T, block string) []byte {
 	der, rest := pem.Decode([]byte(block))
 	require.NotNil(t, der)
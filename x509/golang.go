@@ -10,49 +10,72 @@ import (
 	"crypto/x509/pkix"
 	"encoding/asn1"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"time"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 type KeyStorage interface {
-	genAndSaveKey() crypto.Signer
-	loadKey() crypto.Signer
+	genAndSaveKey() (crypto.Signer, error)
+	loadKey() (crypto.Signer, error)
 }
 
-func genRandomSerialNumber() *big.Int {
+func genRandomSerialNumber() (*big.Int, error) {
 	// Generate a 160 bits serial number (20 octets)
 	max := big.NewInt(0).Exp(big.NewInt(2), big.NewInt(160), nil)
-	serial, err := rand.Int(rand.Reader, max)
-	subcommands.DieNotNil(err)
-	return serial
+	return rand.Int(rand.Reader, max)
 }
 
 func genCertificate(
 	crtTemplate *x509.Certificate, caCrt *x509.Certificate, pub crypto.PublicKey, signerKey crypto.Signer,
-) string {
+) (string, error) {
+	serial, err := genRandomSerialNumber()
+	if err != nil {
+		return "", err
+	}
+	crtTemplate.SerialNumber = serial
 	certRaw, err := x509.CreateCertificate(rand.Reader, crtTemplate, caCrt, pub, signerKey)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return "", err
+	}
 
 	certPemBlock := pem.Block{Type: "CERTIFICATE", Bytes: certRaw}
 	var certRow bytes.Buffer
-	err = pem.Encode(&certRow, &certPemBlock)
-	subcommands.DieNotNil(err)
-
-	return certRow.String()
+	if err = pem.Encode(&certRow, &certPemBlock); err != nil {
+		return "", err
+	}
+	return certRow.String(), nil
 }
 
-func parsePemCertificateRequest(csrPem string) *x509.CertificateRequest {
-	pemBlock := parseOnePemBlock(csrPem)
+func parsePemCertificateRequest(csrPem string) (*x509.CertificateRequest, error) {
+	pemBlock, err := parseOnePemBlock(csrPem)
+	if err != nil {
+		return nil, err
+	}
 	clientCSR, err := x509.ParseCertificateRequest(pemBlock.Bytes)
-	subcommands.DieNotNil(err)
-	err = clientCSR.CheckSignature()
-	subcommands.DieNotNil(err)
-	return clientCSR
+	if err != nil {
+		return nil, err
+	}
+	if err = clientCSR.CheckSignature(); err != nil {
+		return nil, err
+	}
+	return clientCSR, nil
 }
 
-func marshalSubject(cn string, ou string) pkix.Name {
+// loadFactoryCa returns a Factory root CA and its private key, which are used to sign other certificates.
+func loadFactoryCa() (*x509.Certificate, crypto.Signer, error) {
+	factoryKey, err := factoryCaKeyStorage.loadKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	factoryCa, err := LoadCertFromFile(FactoryCaCertFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return factoryCa, factoryKey, nil
+}
+
+func marshalSubject(cn string, ou string) (pkix.Name, error) {
 	// In it's simpler form, this function would be replaced by
 	// pkix.Name{CommonName: cn, OrganizationalUnit: []string{ou}}
 	// However, x509 library uses PrintableString instead of UTF8String
@@ -63,9 +86,13 @@ func marshalSubject(cn string, ou string) pkix.Name {
 	// to OpenSSL, which is less of an issue, but still worth to adjust
 	// while we are at it.
 	cnBytes, err := asn1.MarshalWithParams(cn, "utf8")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return pkix.Name{}, err
+	}
 	ouBytes, err := asn1.MarshalWithParams(ou, "utf8")
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return pkix.Name{}, err
+	}
 	var (
 		oidCommonName         = []int{2, 5, 4, 3}
 		oidOrganizationalUnit = []int{2, 5, 4, 11}
@@ -80,65 +107,100 @@ func marshalSubject(cn string, ou string) pkix.Name {
 			Value: asn1.RawValue{FullBytes: ouBytes},
 		},
 	}
-	return pkix.Name{ExtraNames: pkixAttrTypeValue}
+	return pkix.Name{ExtraNames: pkixAttrTypeValue}, nil
 }
 
-func CreateFactoryCa(ou string) string {
-	priv := factoryCaKeyStorage.genAndSaveKey()
+func CreateFactoryCa(ou string) (string, error) {
+	subject, err := marshalSubject(factoryCaName, ou)
+	if err != nil {
+		return "", err
+	}
+	priv, err := factoryCaKeyStorage.genAndSaveKey()
+	if err != nil {
+		return "", err
+	}
 	crtTemplate := x509.Certificate{
-		SerialNumber: genRandomSerialNumber(),
-		Subject:      marshalSubject(factoryCaName, ou),
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().AddDate(20, 0, 0),
+		Subject:   subject,
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().AddDate(20, 0, 0),
 
 		BasicConstraintsValid: true,
 		IsCA:                  true,
 		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
 	}
-	factoryCaString := genCertificate(&crtTemplate, &crtTemplate, priv.Public(), priv)
-	writeFile(FactoryCaCertFile, factoryCaString)
-	return factoryCaString
+	factoryCaString, err := genCertificate(&crtTemplate, &crtTemplate, priv.Public(), priv)
+	if err != nil {
+		return "", err
+	}
+	return factoryCaString, writeFile(FactoryCaCertFile, factoryCaString)
 }
 
-func CreateDeviceCa(cn, ou string) string {
+func CreateDeviceCa(cn, ou string) (string, error) {
 	return CreateDeviceCaExt(cn, ou, DeviceCaKeyFile, DeviceCaCertFile)
 }
 
-func CreateDeviceCaExt(cn, ou, keyFile, certFile string) string {
-	priv := genAndSaveKeyToFile(keyFile)
-	crtPem := genCaCert(marshalSubject(cn, ou), priv.Public())
-	writeFile(certFile, crtPem)
-	return crtPem
+func CreateDeviceCaExt(cn, ou, keyFile, certFile string) (string, error) {
+	subject, err := marshalSubject(cn, ou)
+	if err != nil {
+		return "", err
+	}
+	priv, err := genAndSaveKeyToFile(keyFile)
+	if err != nil {
+		return "", err
+	}
+	crtPem, err := genCaCert(subject, priv.Public())
+	if err != nil {
+		return "", err
+	}
+	return crtPem, writeFile(certFile, crtPem)
 }
 
-func SignCaCsr(csrPem string) string {
-	csr := parsePemCertificateRequest(csrPem)
-	crtPem := genCaCert(csr.Subject, csr.PublicKey)
-	writeFile(OnlineCaCertFile, crtPem)
-	return crtPem
+func SignCaCsr(csrPem string) (string, error) {
+	csr, err := parsePemCertificateRequest(csrPem)
+	if err != nil {
+		return "", err
+	}
+	crtPem, err := genCaCert(csr.Subject, csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return crtPem, writeFile(OnlineCaCertFile, crtPem)
 }
 
-func SignEl2GoCsr(csrPem string) string {
-	csr := parsePemCertificateRequest(csrPem)
+func SignEl2GoCsr(csrPem string) (string, error) {
+	csr, err := parsePemCertificateRequest(csrPem)
+	if err != nil {
+		return "", err
+	}
 	return genCaCert(csr.Subject, csr.PublicKey)
 }
 
-func SignTlsCsr(csrPem string) string {
-	csr := parsePemCertificateRequest(csrPem)
-	crtPem := genTlsCert(csr.Subject, csr.DNSNames, csr.PublicKey)
-	writeFile(TlsCertFile, crtPem)
-	return crtPem
+func SignTlsCsr(csrPem string) (string, error) {
+	csr, err := parsePemCertificateRequest(csrPem)
+	if err != nil {
+		return "", err
+	}
+	crtPem, err := genTlsCert(csr.Subject, csr.DNSNames, csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	return crtPem, writeFile(TlsCertFile, crtPem)
 }
 
-func SignEstCsr(csrPem string) string {
-	csr := parsePemCertificateRequest(csrPem)
+func SignEstCsr(csrPem string) (string, error) {
+	csr, err := parsePemCertificateRequest(csrPem)
+	if err != nil {
+		return "", err
+	}
 	return genTlsCert(csr.Subject, csr.DNSNames, csr.PublicKey)
 }
 
-func CreateCrl(serials map[string]int) string {
-	factoryKey := factoryCaKeyStorage.loadKey()
-	factoryCa := LoadCertFromFile(FactoryCaCertFile)
+func CreateCrl(serials map[string]int) (string, error) {
+	factoryCa, factoryKey, err := loadFactoryCa()
+	if err != nil {
+		return "", err
+	}
 	now := time.Now()
 	crl := &x509.RevocationList{
 		Number:     big.NewInt(1),
@@ -149,7 +211,7 @@ func CreateCrl(serials map[string]int) string {
 		num := new(big.Int)
 		if _, ok := num.SetString(serial, 10); !ok {
 			// We expect a valid input here
-			panic("Value is not a valid base 10 serial:" + serial)
+			return "", fmt.Errorf("Value is not a valid base 10 serial: %s", serial)
 		}
 		crl.RevokedCertificateEntries = append(crl.RevokedCertificateEntries, x509.RevocationListEntry{
 			SerialNumber:   num,
@@ -160,23 +222,27 @@ func CreateCrl(serials map[string]int) string {
 	// Our old root CAs are missing this bit; it's OK to add this here, and the API will accept it.
 	factoryCa.KeyUsage |= x509.KeyUsageCRLSign
 	derBytes, err := x509.CreateRevocationList(rand.Reader, crl, factoryCa, factoryKey)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return "", err
+	}
 
 	var pemBuffer bytes.Buffer
-	err = pem.Encode(&pemBuffer, &pem.Block{Type: "X509 CRL", Bytes: derBytes})
-	subcommands.DieNotNil(err)
-	return pemBuffer.String()
+	if err = pem.Encode(&pemBuffer, &pem.Block{Type: "X509 CRL", Bytes: derBytes}); err != nil {
+		return "", err
+	}
+	return pemBuffer.String(), nil
 }
 
-func genTlsCert(subject pkix.Name, dnsNames []string, pubkey crypto.PublicKey) string {
-	factoryKey := factoryCaKeyStorage.loadKey()
-	factoryCa := LoadCertFromFile(FactoryCaCertFile)
+func genTlsCert(subject pkix.Name, dnsNames []string, pubkey crypto.PublicKey) (string, error) {
+	factoryCa, factoryKey, err := loadFactoryCa()
+	if err != nil {
+		return "", err
+	}
 	crtTemplate := x509.Certificate{
-		SerialNumber: genRandomSerialNumber(),
-		Subject:      subject,
-		Issuer:       factoryCa.Subject,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().AddDate(10, 0, 0),
+		Subject:   subject,
+		Issuer:    factoryCa.Subject,
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().AddDate(10, 0, 0),
 
 		IsCA:        false,
 		KeyUsage:    x509.KeyUsageDigitalSignature,
@@ -186,15 +252,16 @@ func genTlsCert(subject pkix.Name, dnsNames []string, pubkey crypto.PublicKey) s
 	return genCertificate(&crtTemplate, factoryCa, pubkey, factoryKey)
 }
 
-func genCaCert(subject pkix.Name, pubkey crypto.PublicKey) string {
-	factoryKey := factoryCaKeyStorage.loadKey()
-	factoryCa := LoadCertFromFile(FactoryCaCertFile)
+func genCaCert(subject pkix.Name, pubkey crypto.PublicKey) (string, error) {
+	factoryCa, factoryKey, err := loadFactoryCa()
+	if err != nil {
+		return "", err
+	}
 	crtTemplate := x509.Certificate{
-		SerialNumber: genRandomSerialNumber(),
-		Subject:      subject,
-		Issuer:       factoryCa.Subject,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().AddDate(10, 0, 0),
+		Subject:   subject,
+		Issuer:    factoryCa.Subject,
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().AddDate(10, 0, 0),
 
 		BasicConstraintsValid: true,
 		IsCA:                  true,
@@ -208,17 +275,21 @@ func genCaCert(subject pkix.Name, pubkey crypto.PublicKey) string {
 // certificate type. If the CSR contains a Basic Constraints extension with
 // CA:TRUE, a CA certificate is produced. Otherwise, a TLS certificate is
 // produced, honoring SANs from the CSR.
-func SignCsr(csrPem string) string {
-	csr := parsePemCertificateRequest(csrPem)
-	factoryKey := factoryCaKeyStorage.loadKey()
-	factoryCa := LoadCertFromFile(FactoryCaCertFile)
+func SignCsr(csrPem string) (string, error) {
+	csr, err := parsePemCertificateRequest(csrPem)
+	if err != nil {
+		return "", err
+	}
+	factoryCa, factoryKey, err := loadFactoryCa()
+	if err != nil {
+		return "", err
+	}
 
 	crtTemplate := x509.Certificate{
-		SerialNumber: genRandomSerialNumber(),
-		Subject:      csr.Subject,
-		Issuer:       factoryCa.Subject,
-		NotBefore:    time.Now(),
-		NotAfter:     time.Now().AddDate(10, 0, 0),
+		Subject:   csr.Subject,
+		Issuer:    factoryCa.Subject,
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().AddDate(10, 0, 0),
 	}
 
 	if csrRequestsCA(csr) {
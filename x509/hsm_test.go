@@ -64,24 +64,24 @@ func TestHsm(t *testing.T) {
 			require.Equal(t, true, os.IsNotExist(err))
 		}
 
-		factoryCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(FactoryCaCertFile)))
+		factoryCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, FactoryCaCertFile)))
 		require.Nil(t, err)
 		factoryCaPubeyOnHsm, err := x509.ParsePKIXPublicKey(readPubkeyFromHsm(t, FactoryCaKeyLabel))
 		require.Nil(t, err)
 		assert.Equal(t, factoryCa, factoryCaOnDisk)
 		assert.Equal(t, factoryCa.PublicKey, factoryCaPubeyOnHsm)
 
-		tlsCertOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(TlsCertFile)))
+		tlsCertOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, TlsCertFile)))
 		require.Nil(t, err)
 		assert.Equal(t, tlsCert, tlsCertOnDisk)
 
-		onlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(OnlineCaCertFile)))
+		onlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, OnlineCaCertFile)))
 		require.Nil(t, err)
 		assert.Equal(t, onlineCa, onlineCaOnDisk)
 
-		offlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readFile(DeviceCaCertFile)))
+		offlineCaOnDisk, err := x509.ParseCertificate(pemToDer(t, readTestFile(t, DeviceCaCertFile)))
 		require.Nil(t, err)
-		offlineCaKeyOnDisk, err := x509.ParseECPrivateKey(pemToDer(t, readFile(DeviceCaKeyFile)))
+		offlineCaKeyOnDisk, err := x509.ParseECPrivateKey(pemToDer(t, readTestFile(t, DeviceCaKeyFile)))
 		require.Nil(t, err)
 		assert.Equal(t, offlineCa, offlineCaOnDisk)
 		assert.Equal(t, offlineCa.PublicKey, offlineCaKeyOnDisk.Public())
@@ -9,34 +9,47 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
-func genAndSaveKeyToFile(fn string) crypto.Signer {
+func genAndSaveKeyToFile(fn string) (crypto.Signer, error) {
 	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 
 	keyRaw, err := x509.MarshalECPrivateKey(priv)
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 
 	keyBlock := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyRaw}
 	keyBytes := pem.EncodeToMemory(keyBlock)
-	writeFile(fn, string(keyBytes))
-	return priv
+	if err = writeFile(fn, string(keyBytes)); err != nil {
+		return nil, err
+	}
+	return priv, nil
 }
 
-func loadKeyFromFile(fn string) crypto.Signer {
-	keyPem := parseOnePemBlock(readFile(fn))
+func loadKeyFromFile(fn string) (crypto.Signer, error) {
+	data, err := readFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	keyPem, err := parseOnePemBlock(data)
+	if err != nil {
+		return nil, err
+	}
 	key, err := x509.ParseECPrivateKey(keyPem.Bytes)
-	subcommands.DieNotNil(err)
-	return key
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
 }
 
-func (s *fileStorage) genAndSaveKey() crypto.Signer {
+func (s *fileStorage) genAndSaveKey() (crypto.Signer, error) {
 	return genAndSaveKeyToFile(s.Filename)
 }
 
-func (s *fileStorage) loadKey() crypto.Signer {
+func (s *fileStorage) loadKey() (crypto.Signer, error) {
 	return loadKeyFromFile(s.Filename)
 }
@@ -8,53 +8,60 @@ import (
 	"fmt"
 
 	"github.com/ThalesIgnite/crypto11"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 const hsmObjectId = "1"
 
-func newPkcs11Session(hsm HsmInfo) *crypto11.Context {
+func newPkcs11Session(hsm HsmInfo) (*crypto11.Context, error) {
 	cfg := crypto11.Config{
 		Path:        hsm.Module,
 		TokenLabel:  hsm.TokenLabel,
 		Pin:         hsm.Pin,
 		MaxSessions: 0,
 	}
-
-	ctx, err := crypto11.Configure(&cfg)
-	subcommands.DieNotNil(err)
-	return ctx
+	return crypto11.Configure(&cfg)
 }
 
-func genAndSaveKeyToHsm(hsm HsmInfo, id, label string) crypto.Signer {
+func genAndSaveKeyToHsm(hsm HsmInfo, id, label string) (crypto.Signer, error) {
 	// See storage_pkcs11_tool.go why we need to first check for the key existence.
-	ctx := newPkcs11Session(hsm)
+	ctx, err := newPkcs11Session(hsm)
+	if err != nil {
+		return nil, err
+	}
 	key, err := ctx.FindKeyPair([]byte(id), []byte(label))
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 	if key != nil {
-		subcommands.DieNotNil(fmt.Errorf("Key %s already exists on the HSM device", label))
+		return nil, fmt.Errorf("Key %s already exists on the HSM device", label)
 	}
 
 	key, err = ctx.GenerateECDSAKeyPairWithLabel([]byte(id), []byte(label), elliptic.P256())
-	subcommands.DieNotNil(err)
-	return key
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
 }
 
-func loadKeyFromHsm(hsm HsmInfo, id, label string) crypto.Signer {
-	ctx := newPkcs11Session(hsm)
+func loadKeyFromHsm(hsm HsmInfo, id, label string) (crypto.Signer, error) {
+	ctx, err := newPkcs11Session(hsm)
+	if err != nil {
+		return nil, err
+	}
 	key, err := ctx.FindKeyPair([]byte(id), []byte(label))
-	subcommands.DieNotNil(err)
+	if err != nil {
+		return nil, err
+	}
 	if key == nil {
-		subcommands.DieNotNil(fmt.Errorf("Key %s not found on the HSM device", label))
+		return nil, fmt.Errorf("Key %s not found on the HSM device", label)
 	}
-	return key
+	return key, nil
 }
 
-func (s *hsmStorage) genAndSaveKey() crypto.Signer {
+func (s *hsmStorage) genAndSaveKey() (crypto.Signer, error) {
 	return genAndSaveKeyToHsm(s.HsmInfo, hsmObjectId, s.Label)
 }
 
-func (s *hsmStorage) loadKey() crypto.Signer {
+func (s *hsmStorage) loadKey() (crypto.Signer, error) {
 	return loadKeyFromHsm(s.HsmInfo, hsmObjectId, s.Label)
 }
@@ -3,6 +3,7 @@
 package x509
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/x509"
 	"errors"
@@ -11,8 +12,6 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-
-	"github.com/foundriesio/fioctl/subcommands"
 )
 
 const hsmObjectId = "1"
@@ -40,24 +39,25 @@ func (s *hsmSigner) keyArgs() []string {
 }
 
 func (s *hsmSigner) Public() crypto.PublicKey {
-	if s.pub != nil {
-		return s.pub
-	}
+	return s.pub
+}
+
+// readPublic reads a public key of this signer from the HSM device; it returns nil if the key does not exist.
+func (s *hsmSigner) readPublic() (crypto.PublicKey, error) {
 	args := append(s.keyArgs(), "--read-object", "--type=pubkey")
 	cmd := exec.Command("pkcs11-tool", args...)
 	out, err := cmd.Output()
 	var ex *exec.ExitError
 	if errors.As(err, &ex) {
 		if strings.HasPrefix(string(ex.Stderr), "error: object not found") {
-			return nil
+			return nil, nil
 		}
 		fmt.Println(string(ex.Stderr))
 	}
-	subcommands.DieNotNil(err)
-	key, err := x509.ParsePKIXPublicKey(out)
-	subcommands.DieNotNil(err)
-	s.pub = key
-	return key
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKIXPublicKey(out)
 }
 
 func (s *hsmSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
@@ -65,46 +65,45 @@ func (s *hsmSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts)
 	args := append(s.keyArgs(), "--sign", "--mechanism=ECDSA", "--signature-format=sequence")
 	cmd := exec.Command("pkcs11-tool", args...)
 	cmd.Stderr = os.Stderr
-	in, err := cmd.StdinPipe()
-	subcommands.DieNotNil(err)
-
-	go func() {
-		defer in.Close()
-		_, err := in.Write(digest)
-		subcommands.DieNotNil(err)
-	}()
-
+	cmd.Stdin = bytes.NewReader(digest)
 	return cmd.Output()
 }
 
-func genAndSaveKeyToHsm(hsm HsmInfo, id, label string) crypto.Signer {
+func genAndSaveKeyToHsm(hsm HsmInfo, id, label string) (crypto.Signer, error) {
 	// The pkcs11-tool allows creating many objects with the same ID and label, potentially corrupting the storage.
 	// For now, allow to create only one object.
 	// In the future we will use the ID field to allow key rotation.
 	key := &hsmSigner{hsm, id, label, nil}
-	if key.Public() != nil {
-		subcommands.DieNotNil(fmt.Errorf("Key %s already exists on the HSM device", label))
+	if pub, err := key.readPublic(); err != nil {
+		return nil, err
+	} else if pub != nil {
+		return nil, fmt.Errorf("Key %s already exists on the HSM device", label)
 	}
 
 	args := append(key.keyArgs(), "--keypairgen", "--key-type=EC:prime256v1")
 	cmd := exec.Command("pkcs11-tool", args...)
 	cmd.Stderr = os.Stderr
-	subcommands.DieNotNil(cmd.Run())
-	return key
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return loadKeyFromHsm(hsm, id, label)
 }
 
-func loadKeyFromHsm(hsm HsmInfo, id, label string) crypto.Signer {
+func loadKeyFromHsm(hsm HsmInfo, id, label string) (crypto.Signer, error) {
 	key := &hsmSigner{hsm, id, label, nil}
-	if key.Public() == nil {
-		subcommands.DieNotNil(fmt.Errorf("Key %s not found on the HSM device", label))
+	var err error
+	if key.pub, err = key.readPublic(); err != nil {
+		return nil, err
+	} else if key.pub == nil {
+		return nil, fmt.Errorf("Key %s not found on the HSM device", label)
 	}
-	return key
+	return key, nil
 }
 
-func (s *hsmStorage) genAndSaveKey() crypto.Signer {
+func (s *hsmStorage) genAndSaveKey() (crypto.Signer, error) {
 	return genAndSaveKeyToHsm(s.HsmInfo, hsmObjectId, s.Label)
 }
 
-func (s *hsmStorage) loadKey() crypto.Signer {
+func (s *hsmStorage) loadKey() (crypto.Signer, error) {
 	return loadKeyFromHsm(s.HsmInfo, hsmObjectId, s.Label)
 }